@@ -1,6 +1,7 @@
 package cache
 
 import (
+	"context"
 	"time"
 
 	"github.com/pkg/errors"
@@ -15,4 +16,25 @@ var (
 type Cache interface {
 	Set(key, value string, expiration time.Duration) error
 	Get(key string) (string, error)
+	Delete(key string) error
+	// CompareAndSwap replaces the value of a key only when it still has the old value, an empty old value means
+	// that the key must not exist. It returns false when the value was changed by someone else.
+	CompareAndSwap(key, old, new string, expiration time.Duration) (bool, error)
+	// SetNX sets the value of a key only when it doesn't exist and returns false when it already exists
+	SetNX(key, value string, expiration time.Duration) (bool, error)
+	// Replace sets the value of a key only when it exists and returns false when it doesn't exist
+	Replace(key, value string, expiration time.Duration) (bool, error)
+	// Increment atomically increments a counter and resets its expiration, a missing counter starts at 0
+	Increment(key string, expiration time.Duration) (int64, error)
+	// Expire resets the expiration of a key
+	Expire(key string, expiration time.Duration) error
+	// AddToSet adds a member to a set and resets the expiration of the whole set
+	AddToSet(key, member string, expiration time.Duration) error
+	RemoveFromSet(key, member string) error
+	SetMembers(key string) ([]string, error)
+	// AddToTimeWindow records an event at the given time, drops the events which are older than the window
+	// and returns the times of the events which are left in ascending order
+	AddToTimeWindow(key string, at time.Time, window time.Duration) ([]time.Time, error)
+	// Ping checks that the cache can be reached
+	Ping(ctx context.Context) error
 }
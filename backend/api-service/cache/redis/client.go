@@ -2,9 +2,11 @@ package redis
 
 import (
 	"context"
+	"strconv"
 	"time"
 
 	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/cache"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/id"
 	"github.com/go-redis/redis/v8"
 )
 
@@ -48,3 +50,114 @@ func (client *Client) Get(key string) (result string, err error) {
 
 	return result, err
 }
+
+// Delete removes a value from the cache
+func (client *Client) Delete(key string) error {
+	return client.db.Del(context.Background(), key).Err()
+}
+
+// compareAndSwapScript sets the key only when its current value is ARGV[1], a missing key matches an empty string
+var compareAndSwapScript = redis.NewScript(`
+local current = redis.call("GET", KEYS[1])
+if current == false then
+	current = ""
+end
+if current ~= ARGV[1] then
+	return 0
+end
+redis.call("SET", KEYS[1], ARGV[2], "PX", ARGV[3])
+return 1
+`)
+
+// CompareAndSwap replaces the value of a key atomically when it still has the old value
+func (client *Client) CompareAndSwap(key string, old string, new string, expiration time.Duration) (bool, error) {
+	swapped, err := compareAndSwapScript.Run(context.Background(), client.db, []string{key}, old, new, expiration.Milliseconds()).Int()
+	if err != nil {
+		return false, err
+	}
+
+	return swapped == 1, nil
+}
+
+// SetNX sets the value of a key which doesn't exist yet
+func (client *Client) SetNX(key string, value string, expiration time.Duration) (bool, error) {
+	return client.db.SetNX(context.Background(), key, value, expiration).Result()
+}
+
+// Replace sets the value of an existing key with SET XX
+func (client *Client) Replace(key string, value string, expiration time.Duration) (bool, error) {
+	return client.db.SetXX(context.Background(), key, value, expiration).Result()
+}
+
+// Increment increments a counter and resets its expiration in a single transaction
+func (client *Client) Increment(key string, expiration time.Duration) (int64, error) {
+	ctx := context.Background()
+
+	var value *redis.IntCmd
+	_, err := client.db.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		value = pipe.Incr(ctx, key)
+		pipe.PExpire(ctx, key, expiration)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return value.Val(), nil
+}
+
+// Expire resets the expiration of a key
+func (client *Client) Expire(key string, expiration time.Duration) error {
+	return client.db.PExpire(context.Background(), key, expiration).Err()
+}
+
+// AddToSet adds a member to a set and resets the expiration of the set
+func (client *Client) AddToSet(key string, member string, expiration time.Duration) error {
+	ctx := context.Background()
+	_, err := client.db.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.SAdd(ctx, key, member)
+		pipe.Expire(ctx, key, expiration)
+		return nil
+	})
+
+	return err
+}
+
+// RemoveFromSet removes a member from a set
+func (client *Client) RemoveFromSet(key string, member string) error {
+	return client.db.SRem(context.Background(), key, member).Err()
+}
+
+// SetMembers returns all the members of a set
+func (client *Client) SetMembers(key string) ([]string, error) {
+	return client.db.SMembers(context.Background(), key).Result()
+}
+
+// AddToTimeWindow records an event in a sorted set scored by time so that the window slides with every call
+func (client *Client) AddToTimeWindow(key string, at time.Time, window time.Duration) ([]time.Time, error) {
+	ctx := context.Background()
+
+	var scores *redis.ZSliceCmd
+	_, err := client.db.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZRemRangeByScore(ctx, key, "-inf", strconv.FormatInt(at.Add(-window).UnixNano(), 10))
+		pipe.ZAdd(ctx, key, &redis.Z{Score: float64(at.UnixNano()), Member: id.New().String()})
+		pipe.Expire(ctx, key, window)
+		scores = pipe.ZRangeWithScores(ctx, key, 0, -1)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]time.Time, len(scores.Val()))
+	for index, member := range scores.Val() {
+		events[index] = time.Unix(0, int64(member.Score)).UTC()
+	}
+
+	return events, nil
+}
+
+// Ping checks that redis can be reached
+func (client *Client) Ping(ctx context.Context) error {
+	return client.db.Ping(ctx).Err()
+}
@@ -0,0 +1,21 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/entities"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/id"
+)
+
+// AccessTokenRepository stores the personal access tokens of users
+type AccessTokenRepository interface {
+	Store(ctx context.Context, accessToken entities.AccessToken) error
+	// FindByTokenHash returns errors.ErrEntityNotFound when no access token has the hash
+	FindByTokenHash(ctx context.Context, tokenHash string) (*entities.AccessToken, error)
+	FetchForUser(ctx context.Context, userID id.ID) ([]entities.AccessToken, error)
+	UpdateLastUsedAt(ctx context.Context, accessTokenID id.ID, lastUsedAt time.Time) error
+	// Delete returns false when the user doesn't have an access token with the id
+	Delete(ctx context.Context, userID id.ID, accessTokenID id.ID) (bool, error)
+	DeleteForUser(ctx context.Context, userID id.ID) error
+}
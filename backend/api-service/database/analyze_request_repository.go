@@ -1,13 +1,87 @@
 package database
 
 import (
+	"context"
+	internalTime "time"
+
 	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/entities"
 	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/id"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/time"
+)
+
+// AnalyzeRequestSortField is an indexed field which analyze requests can be sorted by
+type AnalyzeRequestSortField string
+
+// String converts the sort field to a string
+func (field AnalyzeRequestSortField) String() string {
+	return string(field)
+}
+
+const (
+	// AnalyzeRequestSortFieldCreatedAt sorts analyze requests by their creation time
+	AnalyzeRequestSortFieldCreatedAt = AnalyzeRequestSortField("created_at")
+
+	// AnalyzeRequestSortFieldStartDate sorts analyze requests by the start of their period
+	AnalyzeRequestSortFieldStartDate = AnalyzeRequestSortField("start_date")
+
+	// AnalyzeRequestSortFieldEndDate sorts analyze requests by the end of their period
+	AnalyzeRequestSortFieldEndDate = AnalyzeRequestSortField("end_date")
+
+	// AnalyzeRequestSortFieldOvChipkaartNumber sorts analyze requests by their card number
+	AnalyzeRequestSortFieldOvChipkaartNumber = AnalyzeRequestSortField("ov_chipkaart_number")
 )
 
+// AnalyzeRequestSortFields are all the fields which analyze requests can be sorted by
+var AnalyzeRequestSortFields = []AnalyzeRequestSortField{
+	AnalyzeRequestSortFieldCreatedAt,
+	AnalyzeRequestSortFieldStartDate,
+	AnalyzeRequestSortFieldEndDate,
+	AnalyzeRequestSortFieldOvChipkaartNumber,
+}
+
+// Value returns the value of the sort field for an analyze request as a string
+func (field AnalyzeRequestSortField) Value(analyzeRequest entities.AnalyzeRequest) string {
+	switch field {
+	case AnalyzeRequestSortFieldStartDate:
+		return analyzeRequest.StartDate.Format(time.DateFormat)
+	case AnalyzeRequestSortFieldEndDate:
+		return analyzeRequest.EndDate.Format(time.DateFormat)
+	case AnalyzeRequestSortFieldOvChipkaartNumber:
+		return analyzeRequest.OvChipkaartNumber
+	default:
+		return analyzeRequest.CreatedAt.Format(internalTime.RFC3339Nano)
+	}
+}
+
+// AnalyzeRequestOrder is the order in which analyze requests are listed
+type AnalyzeRequestOrder struct {
+	Field      AnalyzeRequestSortField
+	Descending bool
+}
+
+// AnalyzeRequestFilter restricts the analyze requests which are listed. Empty fields are ignored.
+type AnalyzeRequestFilter struct {
+	Statuses          []entities.AnalyzeRequestStatus
+	OvChipkaartNumber *string
+	// PeriodStart and PeriodEnd select the analyze requests whose period overlaps with them
+	PeriodStart *internalTime.Time
+	PeriodEnd   *internalTime.Time
+}
+
+// AnalyzeRequestCursor is the position of an analyze request in a sorted list
+type AnalyzeRequestCursor struct {
+	// Value is the value of the sort field as returned by AnalyzeRequestSortField.Value
+	Value string
+	ID    id.ID
+}
+
 // AnalyzeRequestRepository is an instance of the user repository
 type AnalyzeRequestRepository interface {
-	Store(analyzeRequest entities.AnalyzeRequest) error
-	FindByID(analyzeRequestID id.ID) (entities.AnalyzeRequest, error)
-	IndexForUser(userID id.ID, skip *int, limit *int, sortBy *string, sortDirection *string) (analyzeRequests []entities.AnalyzeRequest, err error)
+	CreateIndexes(ctx context.Context) error
+	Store(ctx context.Context, analyzeRequest entities.AnalyzeRequest) error
+	FindByID(ctx context.Context, analyzeRequestID id.ID) (entities.AnalyzeRequest, error)
+	IndexForUser(ctx context.Context, userID id.ID, filter AnalyzeRequestFilter, order AnalyzeRequestOrder, after *AnalyzeRequestCursor, limit int) (analyzeRequests []entities.AnalyzeRequest, err error)
+	CountForUser(ctx context.Context, userID id.ID, filter AnalyzeRequestFilter) (count int64, err error)
+	FetchIDsForUser(ctx context.Context, userID id.ID) (analyzeRequestIDs []id.ID, err error)
+	DeleteForUser(ctx context.Context, userID id.ID) error
 }
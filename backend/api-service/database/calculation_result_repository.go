@@ -0,0 +1,13 @@
+package database
+
+import (
+	"context"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/entities"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/id"
+)
+
+// CalculationResultRepository reads the prices which the analysis calculated for every subscription and deletes them with the account
+type CalculationResultRepository interface {
+	FetchForAnalyzeRequest(ctx context.Context, analyzeRequestID id.ID) ([]entities.CalculationResult, error)
+	DeleteForAnalyzeRequests(ctx context.Context, analyzeRequestIDs []id.ID) error
+}
@@ -4,4 +4,10 @@ package database
 type DB interface {
 	UserRepository() UserRepository
 	AnalyzeRequestRepository() AnalyzeRequestRepository
+	PasswordResetTokenRepository() PasswordResetTokenRepository
+	EmailVerificationTokenRepository() EmailVerificationTokenRepository
+	DataExportRepository() DataExportRepository
+	AccessTokenRepository() AccessTokenRepository
+	EnrichedRecordRepository() EnrichedRecordRepository
+	CalculationResultRepository() CalculationResultRepository
 }
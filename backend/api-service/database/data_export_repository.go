@@ -0,0 +1,26 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/entities"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/id"
+)
+
+// DataExportRepository stores the data exports requested by users
+type DataExportRepository interface {
+	Store(ctx context.Context, dataExport entities.DataExport) error
+	// FindByTokenHash returns errors.ErrEntityNotFound when no export has the token
+	FindByTokenHash(ctx context.Context, tokenHash string) (*entities.DataExport, error)
+	// FindLatestForUser returns errors.ErrEntityNotFound when the user has not requested an export
+	FindLatestForUser(ctx context.Context, userID id.ID) (*entities.DataExport, error)
+	FetchForUser(ctx context.Context, userID id.ID) ([]entities.DataExport, error)
+	// FetchExpired returns the data exports whose download link expired before the given time
+	FetchExpired(ctx context.Context, before time.Time) ([]entities.DataExport, error)
+	UpdateStatus(ctx context.Context, dataExportID id.ID, status entities.DataExportStatus, updatedAt time.Time) error
+	// FailPendingCreatedBefore marks the pending data exports which were requested before the given time as failed
+	FailPendingCreatedBefore(ctx context.Context, before time.Time, updatedAt time.Time) (count int64, err error)
+	Delete(ctx context.Context, dataExportID id.ID) error
+	DeleteForUser(ctx context.Context, userID id.ID) error
+}
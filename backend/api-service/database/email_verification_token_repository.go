@@ -0,0 +1,18 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/entities"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/id"
+)
+
+// EmailVerificationTokenRepository stores email verification tokens
+type EmailVerificationTokenRepository interface {
+	Store(ctx context.Context, token entities.EmailVerificationToken) error
+	// Consume marks an unused and unexpired token as used and returns it.
+	// errors.ErrEntityNotFound is returned when no such token exists.
+	Consume(ctx context.Context, tokenHash string, now time.Time) (*entities.EmailVerificationToken, error)
+	DeleteForUser(ctx context.Context, userID id.ID) error
+}
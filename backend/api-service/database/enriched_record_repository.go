@@ -0,0 +1,13 @@
+package database
+
+import (
+	"context"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/entities"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/id"
+)
+
+// EnrichedRecordRepository reads the journeys which the analysis derived from the raw records and deletes them with the account
+type EnrichedRecordRepository interface {
+	FetchForAnalyzeRequest(ctx context.Context, analyzeRequestID id.ID) ([]entities.EnrichedRecord, error)
+	DeleteForAnalyzeRequests(ctx context.Context, analyzeRequestIDs []id.ID) error
+}
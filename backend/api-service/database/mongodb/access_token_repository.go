@@ -0,0 +1,196 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/database"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/entities"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/errors"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/id"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/mongodb"
+	"github.com/palantir/stacktrace"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// AccessTokenRepository stores access tokens in mongodb
+type AccessTokenRepository struct {
+	mongodb.Repository
+}
+
+// NewAccessTokenRepository creates a new instance of the access token repository
+func NewAccessTokenRepository(db *mongo.Database, collection string) database.AccessTokenRepository {
+	return &AccessTokenRepository{mongodb.NewRepository(db, collection)}
+}
+
+// Store stores a new access token
+func (repository *AccessTokenRepository) Store(ctx context.Context, accessToken entities.AccessToken) error {
+	ctx, cancel := repository.TimeoutContext(ctx)
+	defer cancel()
+
+	scopes := make([]string, len(accessToken.Scopes))
+	for index, scope := range accessToken.Scopes {
+		scopes[index] = scope.String()
+	}
+
+	var expiresAt interface{}
+	if accessToken.ExpiresAt != nil {
+		expiresAt = primitive.NewDateTimeFromTime(*accessToken.ExpiresAt)
+	}
+
+	_, err := repository.Collection().InsertOne(ctx, bson.M{
+		"id":           accessToken.ID.String(),
+		"user_id":      accessToken.UserID.String(),
+		"name":         accessToken.Name,
+		"token_hash":   accessToken.TokenHash,
+		"scopes":       scopes,
+		"expires_at":   expiresAt,
+		"last_used_at": nil,
+		"created_at":   primitive.NewDateTimeFromTime(accessToken.CreatedAt),
+	})
+	if err != nil {
+		return stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseError, "cannot insert access token into the database")
+	}
+
+	return nil
+}
+
+// FindByTokenHash finds the access token with the given hash
+func (repository *AccessTokenRepository) FindByTokenHash(ctx context.Context, tokenHash string) (accessToken *entities.AccessToken, err error) {
+	ctx, cancel := repository.TimeoutContext(ctx)
+	defer cancel()
+
+	dbRecord := map[string]interface{}{}
+	err = repository.Collection().FindOne(ctx, bson.M{"token_hash": tokenHash}).Decode(&dbRecord)
+	if err == mongo.ErrNoDocuments {
+		return accessToken, errors.ErrEntityNotFound
+	}
+	if err != nil {
+		return accessToken, stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseError, "cannot fetch access token")
+	}
+
+	return repository.hydrateAccessTokenFromDBRecord(dbRecord)
+}
+
+// FetchForUser returns the access tokens of a user with the newest token first
+func (repository *AccessTokenRepository) FetchForUser(ctx context.Context, userID id.ID) (accessTokens []entities.AccessToken, err error) {
+	ctx, cancel := repository.TimeoutContext(ctx)
+	defer cancel()
+
+	cursor, err := repository.Collection().Find(
+		ctx,
+		bson.M{"user_id": userID.String()},
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: mongodb.SortOrderDescending}}),
+	)
+	if err != nil {
+		return accessTokens, stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseError, "could not fetch access tokens of user with id %s", userID.String())
+	}
+
+	var rawResults []map[string]interface{}
+	err = cursor.All(ctx, &rawResults)
+	if err != nil {
+		return accessTokens, stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseError, "could not decode access tokens")
+	}
+
+	accessTokens = make([]entities.AccessToken, len(rawResults))
+	for index, dbRecord := range rawResults {
+		accessToken, err := repository.hydrateAccessTokenFromDBRecord(dbRecord)
+		if err != nil {
+			return accessTokens, err
+		}
+		accessTokens[index] = *accessToken
+	}
+
+	return accessTokens, nil
+}
+
+// UpdateLastUsedAt records when an access token was last used
+func (repository *AccessTokenRepository) UpdateLastUsedAt(ctx context.Context, accessTokenID id.ID, lastUsedAt time.Time) error {
+	ctx, cancel := repository.TimeoutContext(ctx)
+	defer cancel()
+
+	_, err := repository.Collection().UpdateOne(
+		ctx,
+		bson.M{"id": accessTokenID.String()},
+		bson.M{"$set": bson.M{"last_used_at": primitive.NewDateTimeFromTime(lastUsedAt)}},
+	)
+	if err != nil {
+		return stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseError, "cannot update last used at of access token with id %s", accessTokenID.String())
+	}
+
+	return nil
+}
+
+// Delete deletes an access token of a user
+func (repository *AccessTokenRepository) Delete(ctx context.Context, userID id.ID, accessTokenID id.ID) (bool, error) {
+	ctx, cancel := repository.TimeoutContext(ctx)
+	defer cancel()
+
+	result, err := repository.Collection().DeleteOne(
+		ctx,
+		bson.M{"id": accessTokenID.String(), "user_id": userID.String()},
+	)
+	if err != nil {
+		return false, stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseError, "cannot delete access token with id %s", accessTokenID.String())
+	}
+
+	return result.DeletedCount == 1, nil
+}
+
+// DeleteForUser deletes all the access tokens of a user
+func (repository *AccessTokenRepository) DeleteForUser(ctx context.Context, userID id.ID) error {
+	ctx, cancel := repository.TimeoutContext(ctx)
+	defer cancel()
+
+	_, err := repository.Collection().DeleteMany(ctx, bson.M{"user_id": userID.String()})
+	if err != nil {
+		return stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseError, "cannot delete access tokens of user with id %s", userID.String())
+	}
+
+	return nil
+}
+
+func (repository *AccessTokenRepository) hydrateAccessTokenFromDBRecord(dbRecord map[string]interface{}) (accessToken *entities.AccessToken, err error) {
+	accessTokenID, err := id.FromString(dbRecord["id"].(string))
+	if err != nil {
+		return accessToken, stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseHydrationError, "could not decode access token id form string")
+	}
+
+	userID, err := id.FromString(dbRecord["user_id"].(string))
+	if err != nil {
+		return accessToken, stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseHydrationError, "could not decode user id form string")
+	}
+
+	var scopes []entities.AccessTokenScope
+	if values, ok := dbRecord["scopes"].(primitive.A); ok {
+		for _, value := range values {
+			scopes = append(scopes, entities.AccessTokenScope(value.(string)))
+		}
+	}
+
+	var expiresAt *time.Time
+	if value, ok := dbRecord["expires_at"].(primitive.DateTime); ok {
+		timestamp := value.Time()
+		expiresAt = &timestamp
+	}
+
+	var lastUsedAt *time.Time
+	if value, ok := dbRecord["last_used_at"].(primitive.DateTime); ok {
+		timestamp := value.Time()
+		lastUsedAt = &timestamp
+	}
+
+	return &entities.AccessToken{
+		ID:         accessTokenID,
+		UserID:     userID,
+		Name:       dbRecord["name"].(string),
+		TokenHash:  dbRecord["token_hash"].(string),
+		Scopes:     scopes,
+		ExpiresAt:  expiresAt,
+		LastUsedAt: lastUsedAt,
+		CreatedAt:  dbRecord["created_at"].(primitive.DateTime).Time(),
+	}, nil
+}
@@ -3,6 +3,7 @@ package mongodb
 import (
 	"context"
 	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/mongodb"
+	internalTime "time"
 
 	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/database"
 	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/entities"
@@ -13,6 +14,7 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 // AnalyzeRequestRepository creates a new instance of the user repository
@@ -26,8 +28,11 @@ func NewAnalyzeRequestRepository(db *mongo.Database, collection string) database
 }
 
 // Store stores a user on the mongodb repository
-func (repository *AnalyzeRequestRepository) Store(analyzeRequest entities.AnalyzeRequest) error {
-	_, err := repository.Collection().InsertOne(context.Background(), bson.M{
+func (repository *AnalyzeRequestRepository) Store(ctx context.Context, analyzeRequest entities.AnalyzeRequest) error {
+	ctx, cancel := repository.TimeoutContext(ctx)
+	defer cancel()
+
+	_, err := repository.Collection().InsertOne(ctx, bson.M{
 		"id":                  analyzeRequest.ID.String(),
 		"user_id":             analyzeRequest.UserID.String(),
 		"input_type":          analyzeRequest.InputType,
@@ -42,15 +47,75 @@ func (repository *AnalyzeRequestRepository) Store(analyzeRequest entities.Analyz
 	return err
 }
 
-// IndexForUser fetches all the analyze requests for the given user
-func (repository *AnalyzeRequestRepository) IndexForUser(userID id.ID, skip *int, limit *int, sortBy *string, sortDirection *string) (analyzeRequests []entities.AnalyzeRequest, err error) {
-	cursor, err := repository.Collection().Find(repository.DefaultTimeoutContext(), bson.M{"user_id": userID.String()}, repository.GetFindOptions(skip, limit, sortBy, sortDirection))
+// CreateIndexes creates an index for every field which analyze requests can be sorted by
+func (repository *AnalyzeRequestRepository) CreateIndexes(ctx context.Context) error {
+	ctx, cancel := repository.TimeoutContext(ctx)
+	defer cancel()
+
+	models := make([]mongo.IndexModel, 0, len(database.AnalyzeRequestSortFields))
+	for _, field := range database.AnalyzeRequestSortFields {
+		models = append(models, mongo.IndexModel{
+			Keys: bson.D{
+				{Key: "user_id", Value: mongodb.SortOrderAscending},
+				{Key: field.String(), Value: mongodb.SortOrderAscending},
+				{Key: "id", Value: mongodb.SortOrderAscending},
+			},
+			Options: options.Index().SetName("user_id_" + field.String() + "_id"),
+		})
+	}
+
+	_, err := repository.Collection().Indexes().CreateMany(ctx, models)
+	if err != nil {
+		return stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseError, "cannot create the analyze requests indexes")
+	}
+
+	return nil
+}
+
+// IndexForUser fetches the analyze requests of a user which come after the cursor in the given order
+func (repository *AnalyzeRequestRepository) IndexForUser(
+	ctx context.Context,
+	userID id.ID,
+	filter database.AnalyzeRequestFilter,
+	order database.AnalyzeRequestOrder,
+	after *database.AnalyzeRequestCursor,
+	limit int,
+) (analyzeRequests []entities.AnalyzeRequest, err error) {
+	ctx, cancel := repository.TimeoutContext(ctx)
+	defer cancel()
+
+	query := repository.filterToQuery(userID, filter)
+
+	sortOrder := mongodb.SortOrderAscending
+	comparison := "$gt"
+	if order.Descending {
+		sortOrder = mongodb.SortOrderDescending
+		comparison = "$lt"
+	}
+
+	if after != nil {
+		value, err := repository.cursorValue(order.Field, after.Value)
+		if err != nil {
+			return analyzeRequests, stacktrace.Propagate(err, "cannot decode the cursor value")
+		}
+
+		query = bson.M{"$and": bson.A{query, bson.M{"$or": bson.A{
+			bson.M{order.Field.String(): bson.M{comparison: value}},
+			bson.M{order.Field.String(): value, "id": bson.M{comparison: after.ID.String()}},
+		}}}}
+	}
+
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: order.Field.String(), Value: sortOrder}, {Key: "id", Value: sortOrder}}).
+		SetLimit(int64(limit))
+
+	cursor, err := repository.Collection().Find(ctx, query, findOptions)
 	if err != nil {
 		return analyzeRequests, stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseError, "error fetching analyze requests from the database")
 	}
 
 	var rawResults []map[string]interface{}
-	err = cursor.All(repository.DefaultTimeoutContext(), &rawResults)
+	err = cursor.All(ctx, &rawResults)
 	if err != nil {
 		return analyzeRequests, stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseError, "could not analyze requests from the response")
 	}
@@ -66,10 +131,113 @@ func (repository *AnalyzeRequestRepository) IndexForUser(userID id.ID, skip *int
 	return analyzeRequests, nil
 }
 
+// CountForUser returns the number of analyze requests of a user which match the filter
+func (repository *AnalyzeRequestRepository) CountForUser(ctx context.Context, userID id.ID, filter database.AnalyzeRequestFilter) (count int64, err error) {
+	ctx, cancel := repository.TimeoutContext(ctx)
+	defer cancel()
+
+	count, err = repository.Collection().CountDocuments(ctx, repository.filterToQuery(userID, filter))
+	if err != nil {
+		return count, stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseError, "error counting analyze requests in the database")
+	}
+
+	return count, nil
+}
+
+// FetchIDsForUser returns the ids of all the analyze requests of a user
+func (repository *AnalyzeRequestRepository) FetchIDsForUser(ctx context.Context, userID id.ID) (analyzeRequestIDs []id.ID, err error) {
+	ctx, cancel := repository.TimeoutContext(ctx)
+	defer cancel()
+
+	cursor, err := repository.Collection().Find(
+		ctx,
+		bson.M{"user_id": userID.String()},
+		options.Find().SetProjection(bson.M{"id": 1}),
+	)
+	if err != nil {
+		return analyzeRequestIDs, stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseError, "cannot fetch analyze requests of user with id %s", userID.String())
+	}
+
+	var documents []struct {
+		ID string `bson:"id"`
+	}
+	err = cursor.All(ctx, &documents)
+	if err != nil {
+		return analyzeRequestIDs, stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseError, "cannot decode analyze requests of user with id %s", userID.String())
+	}
+
+	analyzeRequestIDs = make([]id.ID, len(documents))
+	for index, document := range documents {
+		analyzeRequestIDs[index], err = id.FromString(document.ID)
+		if err != nil {
+			return analyzeRequestIDs, stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseHydrationError, "could not decode analyze request id form string")
+		}
+	}
+
+	return analyzeRequestIDs, nil
+}
+
+// DeleteForUser deletes all the analyze requests of a user
+func (repository *AnalyzeRequestRepository) DeleteForUser(ctx context.Context, userID id.ID) error {
+	ctx, cancel := repository.TimeoutContext(ctx)
+	defer cancel()
+
+	_, err := repository.Collection().DeleteMany(ctx, bson.M{"user_id": userID.String()})
+	if err != nil {
+		return stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseError, "cannot delete analyze requests of user with id %s", userID.String())
+	}
+
+	return nil
+}
+
+func (repository *AnalyzeRequestRepository) filterToQuery(userID id.ID, filter database.AnalyzeRequestFilter) bson.M {
+	query := bson.M{"user_id": userID.String()}
+
+	if len(filter.Statuses) > 0 {
+		statuses := make([]string, len(filter.Statuses))
+		for index, status := range filter.Statuses {
+			statuses[index] = status.String()
+		}
+		query["status"] = bson.M{"$in": statuses}
+	}
+
+	if filter.OvChipkaartNumber != nil {
+		query["ov_chipkaart_number"] = *filter.OvChipkaartNumber
+	}
+
+	// dates are stored as yyyy-mm-dd so they can be compared as strings
+	if filter.PeriodStart != nil {
+		query["end_date"] = bson.M{"$gte": filter.PeriodStart.Format(time.DateFormat)}
+	}
+
+	if filter.PeriodEnd != nil {
+		query["start_date"] = bson.M{"$lte": filter.PeriodEnd.Format(time.DateFormat)}
+	}
+
+	return query
+}
+
+// cursorValue converts a cursor value into the type which is stored in the database
+func (repository *AnalyzeRequestRepository) cursorValue(field database.AnalyzeRequestSortField, value string) (interface{}, error) {
+	if field != database.AnalyzeRequestSortFieldCreatedAt {
+		return value, nil
+	}
+
+	createdAt, err := internalTime.Parse(internalTime.RFC3339Nano, value)
+	if err != nil {
+		return nil, err
+	}
+
+	return primitive.NewDateTimeFromTime(createdAt), nil
+}
+
 // FindByID finds a user in the database using it's ID
-func (repository *AnalyzeRequestRepository) FindByID(ID id.ID) (analyzeRequest entities.AnalyzeRequest, err error) {
+func (repository *AnalyzeRequestRepository) FindByID(ctx context.Context, ID id.ID) (analyzeRequest entities.AnalyzeRequest, err error) {
+	ctx, cancel := repository.TimeoutContext(ctx)
+	defer cancel()
+
 	dbRecord := map[string]interface{}{}
-	err = repository.Collection().FindOne(repository.DefaultTimeoutContext(), bson.M{"id": ID.String()}).Decode(&dbRecord)
+	err = repository.Collection().FindOne(ctx, bson.M{"id": ID.String()}).Decode(&dbRecord)
 
 	if err == mongo.ErrNoDocuments {
 		return analyzeRequest, errors.ErrEntityNotFound
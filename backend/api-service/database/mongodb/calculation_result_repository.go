@@ -0,0 +1,106 @@
+package mongodb
+
+import (
+	"context"
+
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/database"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/entities"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/errors"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/id"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/mongodb"
+	"github.com/palantir/stacktrace"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CalculationResultRepository reads the calculation results which the analysis stores in mongodb.
+// Like the enriched records, the transaction id of a result is the id of its analyze request.
+type CalculationResultRepository struct {
+	mongodb.Repository
+}
+
+// calculationResultDocument is decoded into a struct because the analysis stores the numbers as int32 or int64
+type calculationResultDocument struct {
+	Subscription            string `bson:"subscription"`
+	OffPeakFirstClassPrice  int    `bson:"off_peak_first_class_price"`
+	OffPeakSecondClassPrice int    `bson:"off_peak_second_class_price"`
+	OffPeakJourneyCount     int    `bson:"off_peak_journey_count"`
+	PeakFirstClassPrice     int    `bson:"peak_first_class_price"`
+	PeakSecondClassPrice    int    `bson:"peak_second_class_price"`
+	PeakJourneyCount        int    `bson:"peak_journey_count"`
+	PeakSupplementPrice     int    `bson:"peak_supplement_price"`
+	PeakSupplementCount     int    `bson:"peak_supplement_count"`
+	OffPeakSupplementPrice  int    `bson:"off_peak_supplement_price"`
+	OffPeakSupplementCount  int    `bson:"off_peak_supplement_count"`
+	ErrorCount              int    `bson:"error_count"`
+}
+
+// NewCalculationResultRepository creates a new instance of the calculation result repository
+func NewCalculationResultRepository(db *mongo.Database, collection string) database.CalculationResultRepository {
+	return &CalculationResultRepository{mongodb.NewRepository(db, collection)}
+}
+
+// FetchForAnalyzeRequest returns the results of every subscription which was calculated for an analyze request
+func (repository *CalculationResultRepository) FetchForAnalyzeRequest(ctx context.Context, analyzeRequestID id.ID) (results []entities.CalculationResult, err error) {
+	ctx, cancel := repository.TimeoutContext(ctx)
+	defer cancel()
+
+	cursor, err := repository.Collection().Find(
+		ctx,
+		bson.M{"transaction_id": analyzeRequestID.String()},
+		options.Find().SetSort(bson.D{{Key: "subscription", Value: mongodb.SortOrderAscending}}),
+	)
+	if err != nil {
+		return results, stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseError, "could not fetch calculation results of analyze request with id %s", analyzeRequestID.String())
+	}
+
+	var documents []calculationResultDocument
+	err = cursor.All(ctx, &documents)
+	if err != nil {
+		return results, stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseError, "could not decode calculation results")
+	}
+
+	results = make([]entities.CalculationResult, len(documents))
+	for index, document := range documents {
+		results[index] = entities.CalculationResult{
+			AnalyzeRequestID:        analyzeRequestID,
+			Subscription:            document.Subscription,
+			OffPeakFirstClassPrice:  document.OffPeakFirstClassPrice,
+			OffPeakSecondClassPrice: document.OffPeakSecondClassPrice,
+			OffPeakJourneyCount:     document.OffPeakJourneyCount,
+			PeakFirstClassPrice:     document.PeakFirstClassPrice,
+			PeakSecondClassPrice:    document.PeakSecondClassPrice,
+			PeakJourneyCount:        document.PeakJourneyCount,
+			PeakSupplementPrice:     document.PeakSupplementPrice,
+			PeakSupplementCount:     document.PeakSupplementCount,
+			OffPeakSupplementPrice:  document.OffPeakSupplementPrice,
+			OffPeakSupplementCount:  document.OffPeakSupplementCount,
+			ErrorCount:              document.ErrorCount,
+		}
+	}
+
+	return results, nil
+}
+
+// DeleteForAnalyzeRequests deletes the calculation results of the analyze requests
+func (repository *CalculationResultRepository) DeleteForAnalyzeRequests(ctx context.Context, analyzeRequestIDs []id.ID) error {
+	if len(analyzeRequestIDs) == 0 {
+		return nil
+	}
+
+	ctx, cancel := repository.TimeoutContext(ctx)
+	defer cancel()
+
+	transactionIDs := make([]string, len(analyzeRequestIDs))
+	for index, analyzeRequestID := range analyzeRequestIDs {
+		transactionIDs[index] = analyzeRequestID.String()
+	}
+
+	_, err := repository.Collection().DeleteMany(ctx, bson.M{"transaction_id": bson.M{"$in": transactionIDs}})
+	if err != nil {
+		return stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseError, "cannot delete the calculation results of %d analyze requests", len(analyzeRequestIDs))
+	}
+
+	return nil
+}
@@ -26,3 +26,33 @@ func (db *MongoDB) UserRepository() database.UserRepository {
 func (db *MongoDB) AnalyzeRequestRepository() database.AnalyzeRequestRepository {
 	return NewAnalyzeRequestRepository(db.client, "analyze_requests")
 }
+
+// PasswordResetTokenRepository returns the password reset token repository
+func (db *MongoDB) PasswordResetTokenRepository() database.PasswordResetTokenRepository {
+	return NewPasswordResetTokenRepository(db.client, "password_reset_tokens")
+}
+
+// EmailVerificationTokenRepository returns the email verification token repository
+func (db *MongoDB) EmailVerificationTokenRepository() database.EmailVerificationTokenRepository {
+	return NewEmailVerificationTokenRepository(db.client, "email_verification_tokens")
+}
+
+// DataExportRepository returns the data export repository
+func (db *MongoDB) DataExportRepository() database.DataExportRepository {
+	return NewDataExportRepository(db.client, "data_exports")
+}
+
+// AccessTokenRepository returns the access token repository
+func (db *MongoDB) AccessTokenRepository() database.AccessTokenRepository {
+	return NewAccessTokenRepository(db.client, "access_tokens")
+}
+
+// EnrichedRecordRepository returns the enriched record repository
+func (db *MongoDB) EnrichedRecordRepository() database.EnrichedRecordRepository {
+	return NewEnrichedRecordRepository(db.client, "ns_enriched_records")
+}
+
+// CalculationResultRepository returns the calculation result repository
+func (db *MongoDB) CalculationResultRepository() database.CalculationResultRepository {
+	return NewCalculationResultRepository(db.client, "ns_calculation_results")
+}
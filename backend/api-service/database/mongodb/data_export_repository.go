@@ -0,0 +1,216 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/database"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/entities"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/errors"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/id"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/mongodb"
+	"github.com/palantir/stacktrace"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// DataExportRepository stores data exports in mongodb
+type DataExportRepository struct {
+	mongodb.Repository
+}
+
+// NewDataExportRepository creates a new instance of the data export repository
+func NewDataExportRepository(db *mongo.Database, collection string) database.DataExportRepository {
+	return &DataExportRepository{mongodb.NewRepository(db, collection)}
+}
+
+// Store stores a new data export
+func (repository *DataExportRepository) Store(ctx context.Context, dataExport entities.DataExport) error {
+	ctx, cancel := repository.TimeoutContext(ctx)
+	defer cancel()
+
+	_, err := repository.Collection().InsertOne(ctx, bson.M{
+		"id":         dataExport.ID.String(),
+		"user_id":    dataExport.UserID.String(),
+		"token_hash": dataExport.TokenHash,
+		"status":     dataExport.Status.String(),
+		"expires_at": primitive.NewDateTimeFromTime(dataExport.ExpiresAt),
+		"created_at": primitive.NewDateTimeFromTime(dataExport.CreatedAt),
+		"updated_at": primitive.NewDateTimeFromTime(dataExport.UpdatedAt),
+	})
+	if err != nil {
+		return stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseError, "cannot insert data export into the database")
+	}
+
+	return nil
+}
+
+// FindByTokenHash finds the data export which can be downloaded with a token
+func (repository *DataExportRepository) FindByTokenHash(ctx context.Context, tokenHash string) (dataExport *entities.DataExport, err error) {
+	return repository.findOne(ctx, bson.M{"token_hash": tokenHash}, options.FindOne())
+}
+
+// FindLatestForUser finds the data export which was requested last by a user
+func (repository *DataExportRepository) FindLatestForUser(ctx context.Context, userID id.ID) (dataExport *entities.DataExport, err error) {
+	return repository.findOne(
+		ctx,
+		bson.M{"user_id": userID.String()},
+		options.FindOne().SetSort(bson.D{{Key: "created_at", Value: mongodb.SortOrderDescending}}),
+	)
+}
+
+// FetchForUser returns all the data exports of a user
+func (repository *DataExportRepository) FetchForUser(ctx context.Context, userID id.ID) (dataExports []entities.DataExport, err error) {
+	dataExports, err = repository.fetch(ctx, bson.M{"user_id": userID.String()})
+	if err != nil {
+		return dataExports, stacktrace.Propagate(err, "could not fetch data exports of user with id %s", userID.String())
+	}
+
+	return dataExports, nil
+}
+
+// FetchExpired returns the data exports whose download link expired before the given time
+func (repository *DataExportRepository) FetchExpired(ctx context.Context, before time.Time) (dataExports []entities.DataExport, err error) {
+	dataExports, err = repository.fetch(ctx, bson.M{"expires_at": bson.M{"$lt": primitive.NewDateTimeFromTime(before)}})
+	if err != nil {
+		return dataExports, stacktrace.Propagate(err, "could not fetch data exports which expired before %s", before.String())
+	}
+
+	return dataExports, nil
+}
+
+func (repository *DataExportRepository) fetch(ctx context.Context, filter bson.M) (dataExports []entities.DataExport, err error) {
+	ctx, cancel := repository.TimeoutContext(ctx)
+	defer cancel()
+
+	cursor, err := repository.Collection().Find(ctx, filter)
+	if err != nil {
+		return dataExports, stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseError, "could not fetch data exports")
+	}
+
+	var rawResults []map[string]interface{}
+	err = cursor.All(ctx, &rawResults)
+	if err != nil {
+		return dataExports, stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseError, "could not decode data exports")
+	}
+
+	dataExports = make([]entities.DataExport, len(rawResults))
+	for index, dbRecord := range rawResults {
+		dataExport, err := repository.hydrateDataExportFromDBRecord(dbRecord)
+		if err != nil {
+			return dataExports, err
+		}
+		dataExports[index] = *dataExport
+	}
+
+	return dataExports, nil
+}
+
+// UpdateStatus changes the status of a data export
+func (repository *DataExportRepository) UpdateStatus(ctx context.Context, dataExportID id.ID, status entities.DataExportStatus, updatedAt time.Time) error {
+	ctx, cancel := repository.TimeoutContext(ctx)
+	defer cancel()
+
+	_, err := repository.Collection().UpdateOne(
+		ctx,
+		bson.M{"id": dataExportID.String()},
+		bson.M{"$set": bson.M{
+			"status":     status.String(),
+			"updated_at": primitive.NewDateTimeFromTime(updatedAt),
+		}},
+	)
+	if err != nil {
+		return stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseError, "cannot update status of data export with id %s", dataExportID.String())
+	}
+
+	return nil
+}
+
+// FailPendingCreatedBefore marks the pending data exports which were requested before the given time as failed
+func (repository *DataExportRepository) FailPendingCreatedBefore(ctx context.Context, before time.Time, updatedAt time.Time) (count int64, err error) {
+	ctx, cancel := repository.TimeoutContext(ctx)
+	defer cancel()
+
+	result, err := repository.Collection().UpdateMany(
+		ctx,
+		bson.M{
+			"status":     entities.DataExportStatusPending.String(),
+			"created_at": bson.M{"$lt": primitive.NewDateTimeFromTime(before)},
+		},
+		bson.M{"$set": bson.M{
+			"status":     entities.DataExportStatusFailed.String(),
+			"updated_at": primitive.NewDateTimeFromTime(updatedAt),
+		}},
+	)
+	if err != nil {
+		return count, stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseError, "cannot fail the pending data exports which were requested before %s", before.String())
+	}
+
+	return result.ModifiedCount, nil
+}
+
+// Delete deletes a data export
+func (repository *DataExportRepository) Delete(ctx context.Context, dataExportID id.ID) error {
+	ctx, cancel := repository.TimeoutContext(ctx)
+	defer cancel()
+
+	_, err := repository.Collection().DeleteOne(ctx, bson.M{"id": dataExportID.String()})
+	if err != nil {
+		return stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseError, "cannot delete data export with id %s", dataExportID.String())
+	}
+
+	return nil
+}
+
+// DeleteForUser deletes all the data exports of a user
+func (repository *DataExportRepository) DeleteForUser(ctx context.Context, userID id.ID) error {
+	ctx, cancel := repository.TimeoutContext(ctx)
+	defer cancel()
+
+	_, err := repository.Collection().DeleteMany(ctx, bson.M{"user_id": userID.String()})
+	if err != nil {
+		return stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseError, "cannot delete data exports of user with id %s", userID.String())
+	}
+
+	return nil
+}
+
+func (repository *DataExportRepository) findOne(ctx context.Context, filter bson.M, findOptions *options.FindOneOptions) (dataExport *entities.DataExport, err error) {
+	ctx, cancel := repository.TimeoutContext(ctx)
+	defer cancel()
+
+	dbRecord := map[string]interface{}{}
+	err = repository.Collection().FindOne(ctx, filter, findOptions).Decode(&dbRecord)
+	if err == mongo.ErrNoDocuments {
+		return dataExport, errors.ErrEntityNotFound
+	}
+	if err != nil {
+		return dataExport, stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseError, "cannot fetch data export")
+	}
+
+	return repository.hydrateDataExportFromDBRecord(dbRecord)
+}
+
+func (repository *DataExportRepository) hydrateDataExportFromDBRecord(dbRecord map[string]interface{}) (dataExport *entities.DataExport, err error) {
+	dataExportID, err := id.FromString(dbRecord["id"].(string))
+	if err != nil {
+		return dataExport, stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseHydrationError, "could not decode data export id form string")
+	}
+
+	userID, err := id.FromString(dbRecord["user_id"].(string))
+	if err != nil {
+		return dataExport, stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseHydrationError, "could not decode user id form string")
+	}
+
+	return &entities.DataExport{
+		ID:        dataExportID,
+		UserID:    userID,
+		TokenHash: dbRecord["token_hash"].(string),
+		Status:    entities.DataExportStatus(dbRecord["status"].(string)),
+		ExpiresAt: dbRecord["expires_at"].(primitive.DateTime).Time(),
+		CreatedAt: dbRecord["created_at"].(primitive.DateTime).Time(),
+		UpdatedAt: dbRecord["updated_at"].(primitive.DateTime).Time(),
+	}, nil
+}
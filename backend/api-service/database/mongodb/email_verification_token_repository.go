@@ -0,0 +1,119 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/database"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/entities"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/errors"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/id"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/mongodb"
+	"github.com/palantir/stacktrace"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// EmailVerificationTokenRepository stores email verification tokens in mongodb
+type EmailVerificationTokenRepository struct {
+	mongodb.Repository
+}
+
+// NewEmailVerificationTokenRepository creates a new instance of the email verification token repository
+func NewEmailVerificationTokenRepository(db *mongo.Database, collection string) database.EmailVerificationTokenRepository {
+	return &EmailVerificationTokenRepository{mongodb.NewRepository(db, collection)}
+}
+
+// Store stores a new email verification token
+func (repository *EmailVerificationTokenRepository) Store(ctx context.Context, token entities.EmailVerificationToken) error {
+	ctx, cancel := repository.TimeoutContext(ctx)
+	defer cancel()
+
+	_, err := repository.Collection().InsertOne(ctx, bson.M{
+		"id":         token.ID.String(),
+		"user_id":    token.UserID.String(),
+		"email":      token.Email,
+		"token_hash": token.TokenHash,
+		"expires_at": primitive.NewDateTimeFromTime(token.ExpiresAt),
+		"used_at":    nil,
+		"created_at": primitive.NewDateTimeFromTime(token.CreatedAt),
+	})
+	if err != nil {
+		return stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseError, "cannot insert email verification token into the database")
+	}
+
+	return nil
+}
+
+// Consume marks an unused and unexpired token as used in a single operation so it cannot be used twice
+func (repository *EmailVerificationTokenRepository) Consume(ctx context.Context, tokenHash string, now time.Time) (token *entities.EmailVerificationToken, err error) {
+	ctx, cancel := repository.TimeoutContext(ctx)
+	defer cancel()
+
+	dbRecord := map[string]interface{}{}
+	err = repository.Collection().FindOneAndUpdate(
+		ctx,
+		bson.M{
+			"token_hash": tokenHash,
+			"used_at":    nil,
+			"expires_at": bson.M{"$gt": primitive.NewDateTimeFromTime(now)},
+		},
+		bson.M{"$set": bson.M{"used_at": primitive.NewDateTimeFromTime(now)}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&dbRecord)
+
+	if err == mongo.ErrNoDocuments {
+		return token, errors.ErrEntityNotFound
+	}
+	if err != nil {
+		return token, stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseError, "cannot consume email verification token")
+	}
+
+	return repository.hydrateEmailVerificationTokenFromDBRecord(dbRecord)
+}
+
+// DeleteForUser deletes all the email verification tokens of a user
+func (repository *EmailVerificationTokenRepository) DeleteForUser(ctx context.Context, userID id.ID) error {
+	ctx, cancel := repository.TimeoutContext(ctx)
+	defer cancel()
+
+	_, err := repository.Collection().DeleteMany(ctx, bson.M{"user_id": userID.String()})
+	if err != nil {
+		return stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseError, "cannot delete email verification tokens of user with id %s", userID.String())
+	}
+
+	return nil
+}
+
+func (repository *EmailVerificationTokenRepository) hydrateEmailVerificationTokenFromDBRecord(dbRecord map[string]interface{}) (token *entities.EmailVerificationToken, err error) {
+	tokenID, err := id.FromString(dbRecord["id"].(string))
+	if err != nil {
+		return token, stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseHydrationError, "could not decode email verification token id form string")
+	}
+
+	userID, err := id.FromString(dbRecord["user_id"].(string))
+	if err != nil {
+		return token, stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseHydrationError, "could not decode user id form string")
+	}
+
+	// tokens which were sent before the email was stored don't match any address
+	email, _ := dbRecord["email"].(string)
+
+	var usedAt *time.Time
+	if value, ok := dbRecord["used_at"].(primitive.DateTime); ok {
+		timestamp := value.Time()
+		usedAt = &timestamp
+	}
+
+	return &entities.EmailVerificationToken{
+		ID:        tokenID,
+		UserID:    userID,
+		Email:     email,
+		TokenHash: dbRecord["token_hash"].(string),
+		ExpiresAt: dbRecord["expires_at"].(primitive.DateTime).Time(),
+		UsedAt:    usedAt,
+		CreatedAt: dbRecord["created_at"].(primitive.DateTime).Time(),
+	}, nil
+}
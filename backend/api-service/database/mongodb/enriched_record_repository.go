@@ -0,0 +1,123 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/database"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/entities"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/errors"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/id"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/mongodb"
+	"github.com/palantir/stacktrace"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// EnrichedRecordRepository reads the enriched records which the analysis stores in mongodb.
+// The analysis processes the raw records of an analyze request as one transaction so the
+// transaction id of an enriched record is the id of its analyze request.
+type EnrichedRecordRepository struct {
+	mongodb.Repository
+}
+
+// enrichedRecordDocument is decoded into a struct because the analysis stores the numbers as int32 or int64
+type enrichedRecordDocument struct {
+	ID               string `bson:"id"`
+	RawRecordID      string `bson:"raw_record_id"`
+	TransactionID    string `bson:"transaction_id"`
+	StartTime        int64  `bson:"start_time"`
+	EndTime          int64  `bson:"end_time"`
+	StartTimeIsExact bool   `bson:"start_time_is_exact"`
+	FromStationCode  string `bson:"from_station_code"`
+	ToStationCode    string `bson:"to_station_code"`
+	CompanyName      string `bson:"company_name"`
+	TransactionType  string `bson:"transaction_type"`
+	Duration         int64  `bson:"duration"`
+}
+
+// NewEnrichedRecordRepository creates a new instance of the enriched record repository
+func NewEnrichedRecordRepository(db *mongo.Database, collection string) database.EnrichedRecordRepository {
+	return &EnrichedRecordRepository{mongodb.NewRepository(db, collection)}
+}
+
+// FetchForAnalyzeRequest returns the enriched records of an analyze request ordered by their start time
+func (repository *EnrichedRecordRepository) FetchForAnalyzeRequest(ctx context.Context, analyzeRequestID id.ID) (records []entities.EnrichedRecord, err error) {
+	ctx, cancel := repository.TimeoutContext(ctx)
+	defer cancel()
+
+	cursor, err := repository.Collection().Find(
+		ctx,
+		bson.M{"transaction_id": analyzeRequestID.String()},
+		options.Find().SetSort(bson.D{{Key: "start_time", Value: mongodb.SortOrderAscending}}),
+	)
+	if err != nil {
+		return records, stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseError, "could not fetch enriched records of analyze request with id %s", analyzeRequestID.String())
+	}
+
+	var documents []enrichedRecordDocument
+	err = cursor.All(ctx, &documents)
+	if err != nil {
+		return records, stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseError, "could not decode enriched records")
+	}
+
+	records = make([]entities.EnrichedRecord, len(documents))
+	for index, document := range documents {
+		record, err := repository.hydrateEnrichedRecordFromDocument(analyzeRequestID, document)
+		if err != nil {
+			return records, err
+		}
+		records[index] = record
+	}
+
+	return records, nil
+}
+
+// DeleteForAnalyzeRequests deletes the enriched records of the analyze requests
+func (repository *EnrichedRecordRepository) DeleteForAnalyzeRequests(ctx context.Context, analyzeRequestIDs []id.ID) error {
+	if len(analyzeRequestIDs) == 0 {
+		return nil
+	}
+
+	ctx, cancel := repository.TimeoutContext(ctx)
+	defer cancel()
+
+	transactionIDs := make([]string, len(analyzeRequestIDs))
+	for index, analyzeRequestID := range analyzeRequestIDs {
+		transactionIDs[index] = analyzeRequestID.String()
+	}
+
+	_, err := repository.Collection().DeleteMany(ctx, bson.M{"transaction_id": bson.M{"$in": transactionIDs}})
+	if err != nil {
+		return stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseError, "cannot delete the enriched records of %d analyze requests", len(analyzeRequestIDs))
+	}
+
+	return nil
+}
+
+func (repository *EnrichedRecordRepository) hydrateEnrichedRecordFromDocument(analyzeRequestID id.ID, document enrichedRecordDocument) (record entities.EnrichedRecord, err error) {
+	recordID, err := id.FromString(document.ID)
+	if err != nil {
+		return record, stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseHydrationError, "could not decode enriched record id form string")
+	}
+
+	rawRecordID, err := id.FromString(document.RawRecordID)
+	if err != nil {
+		return record, stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseHydrationError, "could not decode raw record id form string")
+	}
+
+	return entities.EnrichedRecord{
+		ID:               recordID,
+		RawRecordID:      rawRecordID,
+		AnalyzeRequestID: analyzeRequestID,
+		StartTime:        time.Unix(0, document.StartTime*int64(time.Millisecond)).UTC(),
+		EndTime:          time.Unix(0, document.EndTime*int64(time.Millisecond)).UTC(),
+		StartTimeIsExact: document.StartTimeIsExact,
+		FromStationCode:  document.FromStationCode,
+		ToStationCode:    document.ToStationCode,
+		CompanyName:      document.CompanyName,
+		TransactionType:  document.TransactionType,
+		Duration:         time.Duration(document.Duration),
+	}, nil
+}
@@ -2,6 +2,8 @@ package mongodb
 
 import (
 	"context"
+	"time"
+
 	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/mongodb"
 	"github.com/palantir/stacktrace"
 
@@ -14,6 +16,15 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
+const (
+	fieldPlaintextTwoFactorSecret    = "two_factor_secret"
+	fieldEncryptedTwoFactorSecret    = "encrypted_two_factor_secret"
+	fieldTwoFactorEnabledAt          = "two_factor_enabled_at"
+	fieldTwoFactorRecoveryCodeHashes = "two_factor_recovery_code_hashes"
+	fieldEmailVerifiedAt             = "email_verified_at"
+	fieldEmailVerificationGrace      = "email_verification_grace_started_at"
+)
+
 // UserRepository creates a new instance of the user repository
 type UserRepository struct {
 	mongodb.Repository
@@ -25,23 +36,34 @@ func NewUserRepository(db *mongo.Database, collection string) database.UserRepos
 }
 
 // Store stores a user on the mongodb repository
-func (repository *UserRepository) Store(user entities.User) error {
-	_, err := repository.Collection().InsertOne(context.Background(), bson.M{
-		"id":         user.ID.String(),
-		"first_name": user.FirstName,
-		"last_name":  user.LastName,
-		"email":      user.Email,
-		"password":   user.Password,
-		"created_at": primitive.NewDateTimeFromTime(user.CreatedAt),
-		"updated_at": primitive.NewDateTimeFromTime(user.UpdatedAt),
+func (repository *UserRepository) Store(ctx context.Context, user entities.User) error {
+	ctx, cancel := repository.TimeoutContext(ctx)
+	defer cancel()
+
+	_, err := repository.Collection().InsertOne(ctx, bson.M{
+		"id":                             user.ID.String(),
+		"first_name":                     user.FirstName,
+		"last_name":                      user.LastName,
+		"email":                          user.Email,
+		"password":                       user.Password,
+		fieldEmailVerifiedAt:             repository.optionalDateTime(user.EmailVerifiedAt),
+		fieldEmailVerificationGrace:      primitive.NewDateTimeFromTime(user.EmailVerificationGraceStartedAt),
+		fieldEncryptedTwoFactorSecret:    user.EncryptedTwoFactorSecret,
+		fieldTwoFactorEnabledAt:          repository.optionalDateTime(user.TwoFactorEnabledAt),
+		fieldTwoFactorRecoveryCodeHashes: repository.recoveryCodeHashes(user),
+		"created_at":                     primitive.NewDateTimeFromTime(user.CreatedAt),
+		"updated_at":                     primitive.NewDateTimeFromTime(user.UpdatedAt),
 	})
 	return err
 }
 
 // FindByID finds a user in the database using it's ID
-func (repository *UserRepository) FindByID(ID id.ID) (user *entities.User, err error) {
+func (repository *UserRepository) FindByID(ctx context.Context, ID id.ID) (user *entities.User, err error) {
+	ctx, cancel := repository.TimeoutContext(ctx)
+	defer cancel()
+
 	dbRecord := map[string]interface{}{}
-	err = repository.Collection().FindOne(repository.DefaultTimeoutContext(), bson.M{"id": ID.String()}).Decode(&dbRecord)
+	err = repository.Collection().FindOne(ctx, bson.M{"id": ID.String()}).Decode(&dbRecord)
 
 	if err == mongo.ErrNoDocuments {
 		return user, errors.ErrEntityNotFound
@@ -54,9 +76,12 @@ func (repository *UserRepository) FindByID(ID id.ID) (user *entities.User, err e
 }
 
 // FindByEmail searches a user using the email
-func (repository *UserRepository) FindByEmail(email string) (user *entities.User, err error) {
+func (repository *UserRepository) FindByEmail(ctx context.Context, email string) (user *entities.User, err error) {
+	ctx, cancel := repository.TimeoutContext(ctx)
+	defer cancel()
+
 	dbRecord := map[string]interface{}{}
-	err = repository.Collection().FindOne(repository.DefaultTimeoutContext(), bson.M{"email": email}).Decode(&dbRecord)
+	err = repository.Collection().FindOne(ctx, bson.M{"email": email}).Decode(&dbRecord)
 
 	if err == mongo.ErrNoDocuments {
 		return user, errors.ErrEntityNotFound
@@ -68,19 +93,261 @@ func (repository *UserRepository) FindByEmail(email string) (user *entities.User
 	return repository.hydrateUserFromDBRecord(dbRecord)
 }
 
+// UpdatePassword replaces the hashed password of a user
+func (repository *UserRepository) UpdatePassword(ctx context.Context, userID id.ID, password string, updatedAt time.Time) error {
+	ctx, cancel := repository.TimeoutContext(ctx)
+	defer cancel()
+
+	_, err := repository.Collection().UpdateOne(
+		ctx,
+		bson.M{"id": userID.String()},
+		bson.M{"$set": bson.M{
+			"password":   password,
+			"updated_at": primitive.NewDateTimeFromTime(updatedAt),
+		}},
+	)
+	if err != nil {
+		return stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseError, "cannot update password of user with id %s", userID.String())
+	}
+
+	return nil
+}
+
+// MarkEmailAsVerified stores the time when the user verified their email address.
+// The email is part of the filter so that an address which was replaced after the token was sent is not verified.
+func (repository *UserRepository) MarkEmailAsVerified(ctx context.Context, userID id.ID, email string, verifiedAt time.Time) error {
+	ctx, cancel := repository.TimeoutContext(ctx)
+	defer cancel()
+
+	result, err := repository.Collection().UpdateOne(
+		ctx,
+		bson.M{"id": userID.String(), "email": email},
+		bson.M{"$set": bson.M{
+			fieldEmailVerifiedAt: primitive.NewDateTimeFromTime(verifiedAt),
+			"updated_at":         primitive.NewDateTimeFromTime(verifiedAt),
+		}},
+	)
+	if err != nil {
+		return stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseError, "cannot mark email of user with id %s as verified", userID.String())
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.ErrEntityNotFound
+	}
+
+	return nil
+}
+
+// UpdateProfile stores the name, email and email verification time of a user
+func (repository *UserRepository) UpdateProfile(ctx context.Context, user entities.User) error {
+	ctx, cancel := repository.TimeoutContext(ctx)
+	defer cancel()
+
+	_, err := repository.Collection().UpdateOne(
+		ctx,
+		bson.M{"id": user.ID.String()},
+		bson.M{"$set": bson.M{
+			"first_name":         user.FirstName,
+			"last_name":          user.LastName,
+			"email":              user.Email,
+			fieldEmailVerifiedAt: repository.optionalDateTime(user.EmailVerifiedAt),
+			"updated_at":         primitive.NewDateTimeFromTime(user.UpdatedAt),
+		}},
+	)
+	if err != nil {
+		return stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseError, "cannot update profile of user with id %s", user.ID.String())
+	}
+
+	return nil
+}
+
+// Delete deletes a user
+func (repository *UserRepository) Delete(ctx context.Context, userID id.ID) error {
+	ctx, cancel := repository.TimeoutContext(ctx)
+	defer cancel()
+
+	_, err := repository.Collection().DeleteOne(ctx, bson.M{"id": userID.String()})
+	if err != nil {
+		return stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseError, "cannot delete user with id %s", userID.String())
+	}
+
+	return nil
+}
+
+// UpdateTwoFactor stores the two factor secret, status and recovery codes of a user
+func (repository *UserRepository) UpdateTwoFactor(ctx context.Context, user entities.User) error {
+	ctx, cancel := repository.TimeoutContext(ctx)
+	defer cancel()
+
+	_, err := repository.Collection().UpdateOne(
+		ctx,
+		bson.M{"id": user.ID.String()},
+		bson.M{"$set": bson.M{
+			fieldEncryptedTwoFactorSecret:    user.EncryptedTwoFactorSecret,
+			fieldTwoFactorEnabledAt:          repository.optionalDateTime(user.TwoFactorEnabledAt),
+			fieldTwoFactorRecoveryCodeHashes: repository.recoveryCodeHashes(user),
+			"updated_at":                     primitive.NewDateTimeFromTime(user.UpdatedAt),
+		}},
+	)
+	if err != nil {
+		return stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseError, "cannot update two factor authentication of user with id %s", user.ID.String())
+	}
+
+	return nil
+}
+
+// EncryptPlaintextTwoFactorSecrets replaces the plaintext TOTP secrets of users who enrolled before secrets were encrypted
+func (repository *UserRepository) EncryptPlaintextTwoFactorSecrets(ctx context.Context, encrypt func(userID id.ID, secret string) (string, error)) (updated int64, err error) {
+	ctx, cancel := repository.TimeoutContext(ctx)
+	defer cancel()
+
+	cursor, err := repository.Collection().Find(ctx, bson.M{fieldPlaintextTwoFactorSecret: bson.M{"$exists": true}})
+	if err != nil {
+		return updated, stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseError, "cannot fetch users with plaintext two factor secrets")
+	}
+
+	var documents []struct {
+		ID     string `bson:"id"`
+		Secret string `bson:"two_factor_secret"`
+	}
+	err = cursor.All(ctx, &documents)
+	if err != nil {
+		return updated, stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseError, "cannot decode users with plaintext two factor secrets")
+	}
+
+	for _, document := range documents {
+		userID, err := id.FromString(document.ID)
+		if err != nil {
+			return updated, stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseHydrationError, "could not decode user id form string")
+		}
+
+		// users who never enrolled have an empty secret which stays empty
+		encryptedSecret := ""
+		if document.Secret != "" {
+			encryptedSecret, err = encrypt(userID, document.Secret)
+			if err != nil {
+				return updated, stacktrace.Propagate(err, "cannot encrypt the two factor secret of user with id %s", document.ID)
+			}
+		}
+
+		_, err = repository.Collection().UpdateOne(
+			ctx,
+			bson.M{"id": document.ID},
+			bson.M{"$set": bson.M{fieldEncryptedTwoFactorSecret: encryptedSecret}, "$unset": bson.M{fieldPlaintextTwoFactorSecret: ""}},
+		)
+		if err != nil {
+			return updated, stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseError, "cannot store the encrypted two factor secret of user with id %s", document.ID)
+		}
+
+		updated++
+	}
+
+	return updated, nil
+}
+
+// ConsumeRecoveryCode pulls the recovery code in a single operation so it cannot be used twice
+func (repository *UserRepository) ConsumeRecoveryCode(ctx context.Context, userID id.ID, recoveryCodeHash string) (bool, error) {
+	ctx, cancel := repository.TimeoutContext(ctx)
+	defer cancel()
+
+	result, err := repository.Collection().UpdateOne(
+		ctx,
+		bson.M{"id": userID.String(), fieldTwoFactorRecoveryCodeHashes: recoveryCodeHash},
+		bson.M{"$pull": bson.M{fieldTwoFactorRecoveryCodeHashes: recoveryCodeHash}},
+	)
+	if err != nil {
+		return false, stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseError, "cannot consume recovery code of user with id %s", userID.String())
+	}
+
+	return result.ModifiedCount == 1, nil
+}
+
+func (repository *UserRepository) recoveryCodeHashes(user entities.User) []string {
+	if user.RecoveryCodeHashes == nil {
+		return []string{}
+	}
+
+	return user.RecoveryCodeHashes
+}
+
+// StartEmailVerificationGracePeriod starts the grace period of the users which signed up before emails were verified.
+// Their grace period would otherwise have expired long ago and they would be blocked without a warning.
+// The users are recognised by the missing email_verified_at field so running it again doesn't change anything.
+func (repository *UserRepository) StartEmailVerificationGracePeriod(ctx context.Context, startedAt time.Time) (count int64, err error) {
+	ctx, cancel := repository.TimeoutContext(ctx)
+	defer cancel()
+
+	result, err := repository.Collection().UpdateMany(
+		ctx,
+		bson.M{fieldEmailVerifiedAt: bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{
+			fieldEmailVerifiedAt:        nil,
+			fieldEmailVerificationGrace: primitive.NewDateTimeFromTime(startedAt),
+		}},
+	)
+	if err != nil {
+		return count, stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseError, "cannot start the email verification grace period of existing users")
+	}
+
+	return result.ModifiedCount, nil
+}
+
+func (repository *UserRepository) optionalDateTime(value *time.Time) interface{} {
+	if value == nil {
+		return nil
+	}
+
+	return primitive.NewDateTimeFromTime(*value)
+}
+
 func (repository *UserRepository) hydrateUserFromDBRecord(dbRecord map[string]interface{}) (user *entities.User, err error) {
 	userID, err := id.FromString(dbRecord["id"].(string))
 	if err != nil {
 		return user, stacktrace.Propagate(err, "could not decode user id form string")
 	}
 
+	// users which signed up before emails were verified don't have the field
+	var emailVerifiedAt *time.Time
+	if value, ok := dbRecord[fieldEmailVerifiedAt].(primitive.DateTime); ok {
+		timestamp := value.Time()
+		emailVerifiedAt = &timestamp
+	}
+
+	// users which signed up before the field was added started their grace period when they signed up
+	emailVerificationGraceStartedAt := dbRecord["created_at"].(primitive.DateTime).Time()
+	if value, ok := dbRecord[fieldEmailVerificationGrace].(primitive.DateTime); ok {
+		emailVerificationGraceStartedAt = value.Time()
+	}
+
+	// users which signed up before two factor authentication was added don't have the fields
+	var twoFactorEnabledAt *time.Time
+	if value, ok := dbRecord[fieldTwoFactorEnabledAt].(primitive.DateTime); ok {
+		timestamp := value.Time()
+		twoFactorEnabledAt = &timestamp
+	}
+
+	// users with a plaintext secret have an empty encrypted secret until EncryptPlaintextTwoFactorSecrets runs
+	encryptedTwoFactorSecret, _ := dbRecord[fieldEncryptedTwoFactorSecret].(string)
+
+	var recoveryCodeHashes []string
+	if values, ok := dbRecord[fieldTwoFactorRecoveryCodeHashes].(primitive.A); ok {
+		for _, value := range values {
+			recoveryCodeHashes = append(recoveryCodeHashes, value.(string))
+		}
+	}
+
 	return &entities.User{
-		ID:        userID,
-		FirstName: dbRecord["first_name"].(string),
-		LastName:  dbRecord["last_name"].(string),
-		Email:     dbRecord["email"].(string),
-		Password:  dbRecord["password"].(string),
-		CreatedAt: dbRecord["created_at"].(primitive.DateTime).Time(),
-		UpdatedAt: dbRecord["updated_at"].(primitive.DateTime).Time(),
+		ID:                              userID,
+		FirstName:                       dbRecord["first_name"].(string),
+		LastName:                        dbRecord["last_name"].(string),
+		Email:                           dbRecord["email"].(string),
+		Password:                        dbRecord["password"].(string),
+		EmailVerifiedAt:                 emailVerifiedAt,
+		EmailVerificationGraceStartedAt: emailVerificationGraceStartedAt,
+		EncryptedTwoFactorSecret:        encryptedTwoFactorSecret,
+		TwoFactorEnabledAt:              twoFactorEnabledAt,
+		RecoveryCodeHashes:              recoveryCodeHashes,
+		CreatedAt:                       dbRecord["created_at"].(primitive.DateTime).Time(),
+		UpdatedAt:                       dbRecord["updated_at"].(primitive.DateTime).Time(),
 	}, err
 }
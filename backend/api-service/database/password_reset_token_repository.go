@@ -0,0 +1,18 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/entities"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/id"
+)
+
+// PasswordResetTokenRepository stores password reset tokens
+type PasswordResetTokenRepository interface {
+	Store(ctx context.Context, token entities.PasswordResetToken) error
+	// Consume marks an unused and unexpired token as used and returns it.
+	// errors.ErrEntityNotFound is returned when no such token exists.
+	Consume(ctx context.Context, tokenHash string, now time.Time) (*entities.PasswordResetToken, error)
+	DeleteForUser(ctx context.Context, userID id.ID) error
+}
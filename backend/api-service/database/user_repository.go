@@ -1,13 +1,28 @@
 package database
 
 import (
+	"context"
+	"time"
+
 	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/entities"
 	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/id"
 )
 
 // UserRepository is an instance of the user repository
 type UserRepository interface {
-	Store(user entities.User) error
-	FindByID(userID id.ID) (*entities.User, error)
-	FindByEmail(email string) (*entities.User, error)
+	Store(ctx context.Context, user entities.User) error
+	FindByID(ctx context.Context, userID id.ID) (*entities.User, error)
+	FindByEmail(ctx context.Context, email string) (*entities.User, error)
+	UpdatePassword(ctx context.Context, userID id.ID, password string, updatedAt time.Time) error
+	// MarkEmailAsVerified returns errors.ErrEntityNotFound when the user changed their email in the meantime
+	MarkEmailAsVerified(ctx context.Context, userID id.ID, email string, verifiedAt time.Time) error
+	UpdateProfile(ctx context.Context, user entities.User) error
+	Delete(ctx context.Context, userID id.ID) error
+	UpdateTwoFactor(ctx context.Context, user entities.User) error
+	// ConsumeRecoveryCode removes an unused recovery code and returns false when the user doesn't have it
+	ConsumeRecoveryCode(ctx context.Context, userID id.ID, recoveryCodeHash string) (bool, error)
+	// EncryptPlaintextTwoFactorSecrets encrypts the TOTP secrets which were stored before secrets were encrypted at rest
+	EncryptPlaintextTwoFactorSecrets(ctx context.Context, encrypt func(userID id.ID, secret string) (string, error)) (updated int64, err error)
+	// StartEmailVerificationGracePeriod starts the grace period of the users which signed up before emails were verified
+	StartEmailVerificationGracePeriod(ctx context.Context, startedAt time.Time) (count int64, err error)
 }
@@ -0,0 +1,51 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/id"
+)
+
+// AccessTokenScope limits what can be done with an access token
+type AccessTokenScope string
+
+// String converts the scope to a string
+func (scope AccessTokenScope) String() string {
+	return string(scope)
+}
+
+const (
+	// AccessTokenScopeRead allows queries
+	AccessTokenScopeRead = AccessTokenScope("read")
+	// AccessTokenScopeWrite allows mutations
+	AccessTokenScopeWrite = AccessTokenScope("write")
+)
+
+// AccessToken is a long lived token which users create for scripts and integrations
+type AccessToken struct {
+	ID        id.ID
+	UserID    id.ID
+	Name      string
+	TokenHash string
+	Scopes    []AccessTokenScope
+	// ExpiresAt is nil when the token doesn't expire
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	CreatedAt  time.Time
+}
+
+// HasScope checks if the token was granted a scope
+func (accessToken AccessToken) HasScope(scope AccessTokenScope) bool {
+	for _, value := range accessToken.Scopes {
+		if value == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsExpired checks if the token has expired at the given time
+func (accessToken AccessToken) IsExpired(at time.Time) bool {
+	return accessToken.ExpiresAt != nil && !at.Before(*accessToken.ExpiresAt)
+}
@@ -0,0 +1,23 @@
+package entities
+
+import (
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/id"
+)
+
+// CalculationResult is the price of the journeys of an analyze request with one of the NS subscriptions.
+// The prices are in euro cents.
+type CalculationResult struct {
+	AnalyzeRequestID        id.ID
+	Subscription            string
+	OffPeakFirstClassPrice  int
+	OffPeakSecondClassPrice int
+	OffPeakJourneyCount     int
+	PeakFirstClassPrice     int
+	PeakSecondClassPrice    int
+	PeakJourneyCount        int
+	PeakSupplementPrice     int
+	PeakSupplementCount     int
+	OffPeakSupplementPrice  int
+	OffPeakSupplementCount  int
+	ErrorCount              int
+}
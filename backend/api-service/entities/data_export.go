@@ -0,0 +1,35 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/id"
+)
+
+// DataExportStatus is the status of a data export
+type DataExportStatus string
+
+// String converts the status to a string
+func (status DataExportStatus) String() string {
+	return string(status)
+}
+
+const (
+	// DataExportStatusPending indicates that the archive is being built
+	DataExportStatusPending = DataExportStatus("pending")
+	// DataExportStatusCompleted indicates that the archive can be downloaded
+	DataExportStatusCompleted = DataExportStatus("completed")
+	// DataExportStatusFailed indicates that the archive could not be built
+	DataExportStatusFailed = DataExportStatus("failed")
+)
+
+// DataExport is an archive with all the data of a user which can be downloaded until it expires
+type DataExport struct {
+	ID        id.ID
+	UserID    id.ID
+	TokenHash string
+	Status    DataExportStatus
+	ExpiresAt time.Time
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
@@ -0,0 +1,18 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/id"
+)
+
+// EmailVerificationToken proves that a user owns the email address which it was sent to when it is used
+type EmailVerificationToken struct {
+	ID        id.ID
+	UserID    id.ID
+	Email     string
+	TokenHash string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
@@ -0,0 +1,22 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/id"
+)
+
+// EnrichedRecord is a journey which the analysis derived from the raw records of an analyze request
+type EnrichedRecord struct {
+	ID               id.ID
+	RawRecordID      id.ID
+	AnalyzeRequestID id.ID
+	StartTime        time.Time
+	EndTime          time.Time
+	StartTimeIsExact bool
+	FromStationCode  string
+	ToStationCode    string
+	CompanyName      string
+	TransactionType  string
+	Duration         time.Duration
+}
@@ -0,0 +1,17 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/id"
+)
+
+// PasswordResetToken allows a user to set a new password once before it expires
+type PasswordResetToken struct {
+	ID        id.ID
+	UserID    id.ID
+	TokenHash string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
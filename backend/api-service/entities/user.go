@@ -13,6 +13,27 @@ type User struct {
 	LastName  string
 	Email     string
 	Password  string
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	// EmailVerifiedAt is nil until the user proves that they own the email address
+	EmailVerifiedAt *time.Time
+	// EmailVerificationGraceStartedAt is when the grace period to verify the email address started.
+	// It is the sign up time except for users which signed up before emails were verified.
+	EmailVerificationGraceStartedAt time.Time
+	// EncryptedTwoFactorSecret is the encrypted TOTP secret, it is set during the enrolment before two factor authentication is enabled
+	EncryptedTwoFactorSecret string
+	// TwoFactorEnabledAt is nil until the user confirms the enrolment with a valid code
+	TwoFactorEnabledAt *time.Time
+	// RecoveryCodeHashes are the hashes of the unused recovery codes
+	RecoveryCodeHashes []string
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+}
+
+// IsEmailVerified checks if the user has verified their email address
+func (user User) IsEmailVerified() bool {
+	return user.EmailVerifiedAt != nil
+}
+
+// IsTwoFactorEnabled checks if the user must enter a TOTP code after their password
+func (user User) IsTwoFactorEnabled() bool {
+	return user.TwoFactorEnabledAt != nil
 }
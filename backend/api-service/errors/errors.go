@@ -15,8 +15,18 @@ var (
 
 	// ErrInvalidJWTToken is thrown when the JWT token is invalid
 	ErrInvalidJWTToken = errors.New("invalid JWT token")
+
+	// ErrEmailNotVerified is thrown when the user must verify their email address before continuing
+	ErrEmailNotVerified = errors.New("email address is not verified")
+
+	// ErrDataExportTooSoon is thrown when the user requests a data export shortly after the previous one
+	ErrDataExportTooSoon = errors.New("a data export was requested recently, please try again later")
+
+	// ErrTwoFactorAlreadyEnabled is thrown when the user enrols in two factor authentication twice
+	ErrTwoFactorAlreadyEnabled = errors.New("two factor authentication is already enabled")
 )
 
+// Error codes of the api service and its services start at 71 so that they don't collide with the codes in shared/errors
 var (
 	// ErrCodeMissingJWT code when he jwt token is not present
 	ErrCodeMissingJWT = stacktrace.ErrorCode(71)
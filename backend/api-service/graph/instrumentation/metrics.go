@@ -0,0 +1,114 @@
+package instrumentation
+
+import (
+	"context"
+	"regexp"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	operationNameAnonymous = "anonymous"
+	operationNameInvalid   = "invalid"
+)
+
+var (
+	// operationNameRegex limits the operation names which are used as labels because clients choose them
+	operationNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]{0,63}$`)
+
+	operationDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metrics.Namespace,
+		Name:      "graphql_operation_duration_seconds",
+		Help:      "The time it took to execute GraphQL operations by name, type and outcome.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"operation", "type", "status"})
+
+	rootFieldDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metrics.Namespace,
+		Name:      "graphql_root_field_duration_seconds",
+		Help:      "The time it took to resolve the queries and mutations of the schema.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"object", "field", "status"})
+)
+
+// Metrics is a gqlgen extension which records the duration of every operation and root field
+type Metrics struct{}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.ResponseInterceptor
+	graphql.FieldInterceptor
+} = Metrics{}
+
+// ExtensionName returns the name of the extension
+func (Metrics) ExtensionName() string {
+	return "Metrics"
+}
+
+// Validate checks the schema before the extension is used
+func (Metrics) Validate(graphql.ExecutableSchema) error {
+	return nil
+}
+
+// InterceptResponse records the duration of an operation once its response is ready
+func (Metrics) InterceptResponse(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+	if !graphql.HasOperationContext(ctx) {
+		return next(ctx)
+	}
+
+	operationContext := graphql.GetOperationContext(ctx)
+	response := next(ctx)
+
+	operationType := "unknown"
+	if operationContext.Operation != nil {
+		operationType = string(operationContext.Operation.Operation)
+	}
+
+	operationDuration.
+		WithLabelValues(operationName(operationContext.OperationName), operationType, status(response != nil && len(response.Errors) > 0)).
+		Observe(time.Since(operationContext.Stats.OperationStart).Seconds())
+
+	return response
+}
+
+// InterceptField records the duration of the root fields, nested fields would create too many metrics
+func (Metrics) InterceptField(ctx context.Context, next graphql.Resolver) (interface{}, error) {
+	fieldContext := graphql.GetFieldContext(ctx)
+	if fieldContext == nil || fieldContext.Parent == nil || fieldContext.Parent.Parent != nil || !fieldContext.IsResolver {
+		return next(ctx)
+	}
+
+	start := time.Now()
+	result, err := next(ctx)
+
+	hasErrors := err != nil || len(graphql.GetFieldErrors(ctx, fieldContext)) > 0
+	rootFieldDuration.
+		WithLabelValues(fieldContext.Object, fieldContext.Field.Name, status(hasErrors)).
+		Observe(time.Since(start).Seconds())
+
+	return result, err
+}
+
+func operationName(name string) string {
+	if name == "" {
+		return operationNameAnonymous
+	}
+
+	if !operationNameRegex.MatchString(name) {
+		return operationNameInvalid
+	}
+
+	return name
+}
+
+func status(hasErrors bool) string {
+	if hasErrors {
+		return "error"
+	}
+
+	return "ok"
+}
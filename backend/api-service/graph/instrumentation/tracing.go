@@ -0,0 +1,88 @@
+package instrumentation
+
+import (
+	"context"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/tracing"
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/label"
+)
+
+// Tracing is a gqlgen extension which creates a span for every operation and for every field which has a resolver.
+// Fields which are read from a struct are not traced because they would add a span per item of a list.
+type Tracing struct{}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.ResponseInterceptor
+	graphql.FieldInterceptor
+} = Tracing{}
+
+// ExtensionName returns the name of the extension
+func (Tracing) ExtensionName() string {
+	return "Tracing"
+}
+
+// Validate checks the schema before the extension is used
+func (Tracing) Validate(graphql.ExecutableSchema) error {
+	return nil
+}
+
+// InterceptResponse creates the span of an operation which is the parent of the spans of its resolvers
+func (Tracing) InterceptResponse(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+	if !graphql.HasOperationContext(ctx) {
+		return next(ctx)
+	}
+
+	operationContext := graphql.GetOperationContext(ctx)
+
+	operationType := "unknown"
+	if operationContext.Operation != nil {
+		operationType = string(operationContext.Operation.Operation)
+	}
+
+	ctx, span := tracing.Tracer().Start(
+		ctx,
+		"graphql."+operationType+" "+operationName(operationContext.OperationName),
+		trace.WithAttributes(
+			label.String("graphql.operation.type", operationType),
+			label.String("graphql.operation.name", operationName(operationContext.OperationName)),
+		),
+	)
+	defer span.End()
+
+	response := next(ctx)
+	if response != nil && len(response.Errors) > 0 {
+		span.SetStatus(codes.Unknown, response.Errors.Error())
+	}
+
+	return response
+}
+
+// InterceptField creates a span for every field which is resolved by a resolver
+func (Tracing) InterceptField(ctx context.Context, next graphql.Resolver) (interface{}, error) {
+	fieldContext := graphql.GetFieldContext(ctx)
+	if fieldContext == nil || !fieldContext.IsResolver {
+		return next(ctx)
+	}
+
+	ctx, span := tracing.Tracer().Start(
+		ctx,
+		fieldContext.Object+"."+fieldContext.Field.Name,
+		trace.WithAttributes(
+			label.String("graphql.field.path", fieldContext.Path().String()),
+		),
+	)
+	defer span.End()
+
+	result, err := next(ctx)
+	if err != nil {
+		span.SetStatus(codes.Unknown, err.Error())
+	} else if errs := graphql.GetFieldErrors(ctx, fieldContext); len(errs) > 0 {
+		span.SetStatus(codes.Unknown, errs.Error())
+	}
+
+	return result, err
+}
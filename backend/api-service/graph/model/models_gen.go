@@ -3,9 +3,23 @@
 package model
 
 import (
+	"fmt"
+	"io"
+	"strconv"
+
 	"github.com/99designs/gqlgen/graphql"
 )
 
+// A personal access token which is sent in the Authorization header instead of a JWT.
+type AccessToken struct {
+	ID         string             `json:"id"`
+	Name       string             `json:"name"`
+	Scopes     []AccessTokenScope `json:"scopes"`
+	ExpiresAt  *string            `json:"expiresAt"`
+	LastUsedAt *string            `json:"lastUsedAt"`
+	CreatedAt  string             `json:"createdAt"`
+}
+
 type AnalyzeRequest struct {
 	StartDate         string `json:"startDate"`
 	EndDate           string `json:"endDate"`
@@ -16,13 +30,76 @@ type AnalyzeRequest struct {
 	UpdatedAt         string `json:"updatedAt"`
 }
 
+type AnalyzeRequestConnection struct {
+	Edges      []*AnalyzeRequestEdge `json:"edges"`
+	PageInfo   *PageInfo             `json:"pageInfo"`
+	TotalCount int                   `json:"totalCount"`
+}
+
+type AnalyzeRequestEdge struct {
+	Cursor string          `json:"cursor"`
+	Node   *AnalyzeRequest `json:"node"`
+}
+
+// Filters the analyze requests of a user. The period is inclusive, formatted as yyyy-mm-dd and matches requests which overlap with it.
+type AnalyzeRequestsFilter struct {
+	Statuses          []string `json:"statuses"`
+	OvChipkaartNumber *string  `json:"ovChipkaartNumber"`
+	PeriodStart       *string  `json:"periodStart"`
+	PeriodEnd         *string  `json:"periodEnd"`
+}
+
+type AnalyzeRequestsOrder struct {
+	Field     AnalyzeRequestSortField `json:"field"`
+	Direction SortDirection           `json:"direction"`
+}
+
 type AnalzyeRequestDetails struct {
 	AnalyzeRequestID string `json:"analyzeRequestId"`
 }
 
+// The user and token are null when the user has enabled two factor authentication, the challenge is then verified with verifyTwoFactor.
 type AuthOutput struct {
-	User  *User  `json:"user"`
-	Token *Token `json:"token"`
+	User               *User               `json:"user"`
+	Token              *Token              `json:"token"`
+	TwoFactorChallenge *TwoFactorChallenge `json:"twoFactorChallenge"`
+}
+
+// A proof of work challenge. Clients submit `challenge:nonce` as the captcha where sha256 of it starts with `difficulty` zero bits.
+type CaptchaChallenge struct {
+	Challenge  string `json:"challenge"`
+	Difficulty int    `json:"difficulty"`
+}
+
+type Card struct {
+	ID                string  `json:"id"`
+	OvChipkaartNumber string  `json:"ovChipkaartNumber"`
+	Status            string  `json:"status"`
+	LastSyncedAt      *string `json:"lastSyncedAt"`
+	NextSyncAt        string  `json:"nextSyncAt"`
+	CreatedAt         string  `json:"createdAt"`
+}
+
+type ChangePasswordInput struct {
+	CurrentPassword string `json:"currentPassword"`
+	NewPassword     string `json:"newPassword"`
+}
+
+type ConfirmTwoFactorInput struct {
+	Code string `json:"code"`
+}
+
+// The access token expires at the end of expiresAt which is formatted as yyyy-mm-dd, it never expires when expiresAt is null.
+type CreateAccessTokenInput struct {
+	Name      string             `json:"name"`
+	Scopes    []AccessTokenScope `json:"scopes"`
+	ExpiresAt *string            `json:"expiresAt"`
+}
+
+// The token is only returned when the access token is created.
+type CreateAccessTokenOutput struct {
+	AccessToken *AccessToken `json:"accessToken"`
+	Token       string       `json:"token"`
 }
 
 type CreateUserInput struct {
@@ -33,6 +110,24 @@ type CreateUserInput struct {
 	ReCaptcha string `json:"reCaptcha"`
 }
 
+// An archive with all the data of the user. The download link works once the status is completed and until it expires.
+type DataExport struct {
+	ID          string `json:"id"`
+	Status      string `json:"status"`
+	DownloadURL string `json:"downloadUrl"`
+	ExpiresAt   string `json:"expiresAt"`
+	CreatedAt   string `json:"createdAt"`
+}
+
+type DeleteAccountInput struct {
+	Password string `json:"password"`
+}
+
+type DisableTwoFactorInput struct {
+	Password string `json:"password"`
+	Code     string `json:"code"`
+}
+
 type LoginInput struct {
 	Email      string `json:"email"`
 	Password   string `json:"password"`
@@ -40,10 +135,53 @@ type LoginInput struct {
 	ReCaptcha  string `json:"reCaptcha"`
 }
 
+type PageInfo struct {
+	HasNextPage     bool    `json:"hasNextPage"`
+	HasPreviousPage bool    `json:"hasPreviousPage"`
+	StartCursor     *string `json:"startCursor"`
+	EndCursor       *string `json:"endCursor"`
+}
+
 type RefreshTokenInput struct {
 	Token string `json:"token"`
 }
 
+type RegisterCardInput struct {
+	OvChipkaartUsername string `json:"ovChipkaartUsername"`
+	OvChipkaartPassword string `json:"ovChipkaartPassword"`
+	OvChipkaartNumber   string `json:"ovChipkaartNumber"`
+}
+
+type RequestPasswordResetInput struct {
+	Email     string `json:"email"`
+	ReCaptcha string `json:"reCaptcha"`
+}
+
+type ResetPasswordInput struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+type RevokeAccessTokenInput struct {
+	ID string `json:"id"`
+}
+
+type RevokeSessionInput struct {
+	ID string `json:"id"`
+}
+
+// A signed in client of the user. The device is derived from the user agent.
+type Session struct {
+	ID         string `json:"id"`
+	Device     string `json:"device"`
+	UserAgent  string `json:"userAgent"`
+	IPAddress  string `json:"ipAddress"`
+	IsCurrent  bool   `json:"isCurrent"`
+	LastSeenAt string `json:"lastSeenAt"`
+	ExpiresAt  string `json:"expiresAt"`
+	CreatedAt  string `json:"createdAt"`
+}
+
 type StoreAnalyzeRequestInput struct {
 	OvChipkaartUsername *string         `json:"ovChipkaartUsername"`
 	OvChipkaartPassword *string         `json:"ovChipkaartPassword"`
@@ -53,15 +191,255 @@ type StoreAnalyzeRequestInput struct {
 	OvChipkaartNumber   string          `json:"ovChipkaartNumber"`
 }
 
+// An access token which expires after a short time and the refresh token which renews it.
 type Token struct {
-	Value string `json:"value"`
+	Value        string `json:"value"`
+	ExpiresAt    string `json:"expiresAt"`
+	RefreshToken string `json:"refreshToken"`
 }
 
-type User struct {
-	ID        string `json:"id"`
+type Transaction struct {
+	ID                  string   `json:"id"`
+	OvChipkaartNumber   string   `json:"ovChipkaartNumber"`
+	TransactionDateTime string   `json:"transactionDateTime"`
+	TransactionName     string   `json:"transactionName"`
+	Station             string   `json:"station"`
+	Operator            string   `json:"operator"`
+	ModalType           string   `json:"modalType"`
+	Fare                *float64 `json:"fare"`
+	ProductText         string   `json:"productText"`
+	Source              string   `json:"source"`
+}
+
+type TransactionConnection struct {
+	Edges      []*TransactionEdge `json:"edges"`
+	PageInfo   *PageInfo          `json:"pageInfo"`
+	TotalCount int                `json:"totalCount"`
+}
+
+type TransactionEdge struct {
+	Cursor string       `json:"cursor"`
+	Node   *Transaction `json:"node"`
+}
+
+// Filters the transactions of a user. The start and end dates are inclusive and formatted as yyyy-mm-dd.
+type TransactionsFilter struct {
+	StartDate         *string           `json:"startDate"`
+	EndDate           *string           `json:"endDate"`
+	OvChipkaartNumber *string           `json:"ovChipkaartNumber"`
+	Operators         []string          `json:"operators"`
+	ModalTypes        []string          `json:"modalTypes"`
+	Kinds             []TransactionKind `json:"kinds"`
+	Stations          []string          `json:"stations"`
+}
+
+type TwoFactorChallenge struct {
+	Token     string `json:"token"`
+	ExpiresAt string `json:"expiresAt"`
+}
+
+// A TOTP secret which is added to an authenticator app by scanning the QR code, a PNG data URI of the otpauth URI.
+type TwoFactorEnrolment struct {
+	Secret     string `json:"secret"`
+	OtpauthURI string `json:"otpauthUri"`
+	QrCode     string `json:"qrCode"`
+}
+
+// Changing the email address requires it to be verified again.
+type UpdateProfileInput struct {
 	FirstName string `json:"firstName"`
 	LastName  string `json:"lastName"`
 	Email     string `json:"email"`
-	CreatedAt string `json:"createdAt"`
-	UpdatedAt string `json:"updatedAt"`
+}
+
+type User struct {
+	ID               string  `json:"id"`
+	FirstName        string  `json:"firstName"`
+	LastName         string  `json:"lastName"`
+	Email            string  `json:"email"`
+	EmailVerifiedAt  *string `json:"emailVerifiedAt"`
+	TwoFactorEnabled bool    `json:"twoFactorEnabled"`
+	CreatedAt        string  `json:"createdAt"`
+	UpdatedAt        string  `json:"updatedAt"`
+}
+
+type VerifyEmailInput struct {
+	Token string `json:"token"`
+}
+
+// The code is a 6 digit code of the authenticator app or a recovery code.
+type VerifyTwoFactorInput struct {
+	ChallengeToken string `json:"challengeToken"`
+	Code           string `json:"code"`
+}
+
+// READ allows queries and WRITE allows mutations. Access tokens cannot manage the account, its credentials or other access tokens.
+type AccessTokenScope string
+
+const (
+	AccessTokenScopeRead  AccessTokenScope = "READ"
+	AccessTokenScopeWrite AccessTokenScope = "WRITE"
+)
+
+var AllAccessTokenScope = []AccessTokenScope{
+	AccessTokenScopeRead,
+	AccessTokenScopeWrite,
+}
+
+func (e AccessTokenScope) IsValid() bool {
+	switch e {
+	case AccessTokenScopeRead, AccessTokenScopeWrite:
+		return true
+	}
+	return false
+}
+
+func (e AccessTokenScope) String() string {
+	return string(e)
+}
+
+func (e *AccessTokenScope) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = AccessTokenScope(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid AccessTokenScope", str)
+	}
+	return nil
+}
+
+func (e AccessTokenScope) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type AnalyzeRequestSortField string
+
+const (
+	AnalyzeRequestSortFieldCreatedAt         AnalyzeRequestSortField = "CREATED_AT"
+	AnalyzeRequestSortFieldStartDate         AnalyzeRequestSortField = "START_DATE"
+	AnalyzeRequestSortFieldEndDate           AnalyzeRequestSortField = "END_DATE"
+	AnalyzeRequestSortFieldOvChipkaartNumber AnalyzeRequestSortField = "OV_CHIPKAART_NUMBER"
+)
+
+var AllAnalyzeRequestSortField = []AnalyzeRequestSortField{
+	AnalyzeRequestSortFieldCreatedAt,
+	AnalyzeRequestSortFieldStartDate,
+	AnalyzeRequestSortFieldEndDate,
+	AnalyzeRequestSortFieldOvChipkaartNumber,
+}
+
+func (e AnalyzeRequestSortField) IsValid() bool {
+	switch e {
+	case AnalyzeRequestSortFieldCreatedAt, AnalyzeRequestSortFieldStartDate, AnalyzeRequestSortFieldEndDate, AnalyzeRequestSortFieldOvChipkaartNumber:
+		return true
+	}
+	return false
+}
+
+func (e AnalyzeRequestSortField) String() string {
+	return string(e)
+}
+
+func (e *AnalyzeRequestSortField) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = AnalyzeRequestSortField(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid AnalyzeRequestSortField", str)
+	}
+	return nil
+}
+
+func (e AnalyzeRequestSortField) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type SortDirection string
+
+const (
+	SortDirectionAsc  SortDirection = "ASC"
+	SortDirectionDesc SortDirection = "DESC"
+)
+
+var AllSortDirection = []SortDirection{
+	SortDirectionAsc,
+	SortDirectionDesc,
+}
+
+func (e SortDirection) IsValid() bool {
+	switch e {
+	case SortDirectionAsc, SortDirectionDesc:
+		return true
+	}
+	return false
+}
+
+func (e SortDirection) String() string {
+	return string(e)
+}
+
+func (e *SortDirection) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = SortDirection(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid SortDirection", str)
+	}
+	return nil
+}
+
+func (e SortDirection) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type TransactionKind string
+
+const (
+	TransactionKindCheckIn                  TransactionKind = "CHECK_IN"
+	TransactionKindCheckOut                 TransactionKind = "CHECK_OUT"
+	TransactionKindIntercityDirectSurcharge TransactionKind = "INTERCITY_DIRECT_SURCHARGE"
+)
+
+var AllTransactionKind = []TransactionKind{
+	TransactionKindCheckIn,
+	TransactionKindCheckOut,
+	TransactionKindIntercityDirectSurcharge,
+}
+
+func (e TransactionKind) IsValid() bool {
+	switch e {
+	case TransactionKindCheckIn, TransactionKindCheckOut, TransactionKindIntercityDirectSurcharge:
+		return true
+	}
+	return false
+}
+
+func (e TransactionKind) String() string {
+	return string(e)
+}
+
+func (e *TransactionKind) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = TransactionKind(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid TransactionKind", str)
+	}
+	return nil
+}
+
+func (e TransactionKind) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
 }
@@ -0,0 +1,46 @@
+package persistedquery
+
+import (
+	"context"
+	"time"
+
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/cache"
+)
+
+// cacheKeyPrefix prefixes the sha256 hash of a persisted query
+const cacheKeyPrefix = "persisted_query:"
+
+// Cache stores automatic persisted queries in redis so they are shared by all the instances of the api service.
+// Errors are ignored because clients send the full query again when a persisted query is not found.
+type Cache struct {
+	cache    cache.Cache
+	lifetime time.Duration
+}
+
+// NewCache creates a new persisted query cache
+func NewCache(cache cache.Cache, lifetime time.Duration) Cache {
+	return Cache{
+		cache:    cache,
+		lifetime: lifetime,
+	}
+}
+
+// Get returns the query with the given hash
+func (persistedQueries Cache) Get(_ context.Context, hash string) (interface{}, bool) {
+	query, err := persistedQueries.cache.Get(cacheKeyPrefix + hash)
+	if err != nil {
+		return nil, false
+	}
+
+	return query, true
+}
+
+// Add stores a query by its hash
+func (persistedQueries Cache) Add(_ context.Context, hash string, query interface{}) {
+	value, ok := query.(string)
+	if !ok {
+		return
+	}
+
+	_ = persistedQueries.cache.Set(cacheKeyPrefix+hash, value, persistedQueries.lifetime)
+}
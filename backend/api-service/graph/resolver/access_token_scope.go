@@ -0,0 +1,44 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/entities"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/middlewares"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+const (
+	// CodeInsufficientScope is returned when an access token doesn't have the scope which an operation requires
+	CodeInsufficientScope = "INSUFFICIENT_SCOPE"
+)
+
+// AuthorizeAccessTokenScope rejects queries which are sent with an access token that doesn't have the read scope
+// and mutations which are sent with an access token that doesn't have the write scope
+func AuthorizeAccessTokenScope(ctx context.Context, next graphql.OperationHandler) graphql.ResponseHandler {
+	accessToken, ok := ctx.Value(middlewares.ContextKeyAccessToken).(*entities.AccessToken)
+	if !ok {
+		return next(ctx)
+	}
+
+	scope := entities.AccessTokenScopeRead
+	if graphql.GetOperationContext(ctx).Operation.Operation == ast.Mutation {
+		scope = entities.AccessTokenScopeWrite
+	}
+
+	if accessToken.HasScope(scope) {
+		return next(ctx)
+	}
+
+	return graphql.OneShot(&graphql.Response{
+		Errors: gqlerror.List{{
+			Message: fmt.Sprintf("The access token does not have the %s scope", scope),
+			Extensions: map[string]interface{}{
+				"code": CodeInsufficientScope,
+			},
+		}},
+	})
+}
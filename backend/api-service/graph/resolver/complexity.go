@@ -0,0 +1,51 @@
+package resolver
+
+import (
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/graph/generated"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/graph/model"
+)
+
+const (
+	// listComplexityMultiplier is the expected number of items in lists which are not paginated
+	listComplexityMultiplier = 10
+
+	// remoteCallComplexity is the extra cost of fields which call another service
+	remoteCallComplexity = 10
+)
+
+// Complexity returns the cost of the fields whose cost grows with the number of items they return.
+// The other fields cost 1 plus the cost of their children.
+func Complexity() generated.ComplexityRoot {
+	complexity := generated.ComplexityRoot{}
+
+	complexity.Query.AnalyzeRequests = func(childComplexity int, _ *model.AnalyzeRequestsFilter, _ *model.AnalyzeRequestsOrder, first *int, _ *string) int {
+		return pageComplexity(childComplexity, first, defaultAnalyzeRequestsPageSize)
+	}
+	complexity.Query.Transactions = func(childComplexity int, _ *model.TransactionsFilter, first *int, _ *string) int {
+		return remoteCallComplexity + pageComplexity(childComplexity, first, defaultTransactionsPageSize)
+	}
+	complexity.Query.Cards = func(childComplexity int) int {
+		return remoteCallComplexity + listComplexity(childComplexity)
+	}
+	complexity.Query.AccessTokens = func(childComplexity int) int {
+		return listComplexity(childComplexity)
+	}
+	complexity.Query.ActiveSessions = func(childComplexity int) int {
+		return listComplexity(childComplexity)
+	}
+
+	return complexity
+}
+
+func pageComplexity(childComplexity int, first *int, defaultPageSize int) int {
+	pageSize := defaultPageSize
+	if first != nil && *first > 0 {
+		pageSize = *first
+	}
+
+	return 1 + pageSize*childComplexity
+}
+
+func listComplexity(childComplexity int) int {
+	return 1 + listComplexityMultiplier*childComplexity
+}
@@ -0,0 +1,384 @@
+package resolver
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/cache/redis"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/database"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/entities"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/graph/model"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/graph/validator"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/middlewares"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/services/dataexport"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/services/jwt"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/services/refreshtoken"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/services/session"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/errors"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/id"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/logger"
+	"github.com/alicebob/miniredis/v2"
+	"golang.org/x/text/language"
+)
+
+// fakeDB keeps the documents of the api service in memory so that resolvers can be tested without mongoDB.
+// The repositories embed their interface so calling a method which a test doesn't expect panics.
+type fakeDB struct {
+	database.DB
+	users                   []entities.User
+	analyzeRequests         []entities.AnalyzeRequest
+	enrichedRecords         []entities.EnrichedRecord
+	calculationResults      []entities.CalculationResult
+	passwordResetTokens     []entities.PasswordResetToken
+	emailVerificationTokens []entities.EmailVerificationToken
+	dataExports             []entities.DataExport
+	accessTokens            []entities.AccessToken
+}
+
+func (db *fakeDB) UserRepository() database.UserRepository {
+	return fakeUserRepository{db: db}
+}
+
+func (db *fakeDB) AnalyzeRequestRepository() database.AnalyzeRequestRepository {
+	return fakeAnalyzeRequestRepository{db: db}
+}
+
+func (db *fakeDB) EnrichedRecordRepository() database.EnrichedRecordRepository {
+	return fakeEnrichedRecordRepository{db: db}
+}
+
+func (db *fakeDB) CalculationResultRepository() database.CalculationResultRepository {
+	return fakeCalculationResultRepository{db: db}
+}
+
+func (db *fakeDB) PasswordResetTokenRepository() database.PasswordResetTokenRepository {
+	return fakePasswordResetTokenRepository{db: db}
+}
+
+func (db *fakeDB) EmailVerificationTokenRepository() database.EmailVerificationTokenRepository {
+	return fakeEmailVerificationTokenRepository{db: db}
+}
+
+func (db *fakeDB) DataExportRepository() database.DataExportRepository {
+	return fakeDataExportRepository{db: db}
+}
+
+func (db *fakeDB) AccessTokenRepository() database.AccessTokenRepository {
+	return fakeAccessTokenRepository{db: db}
+}
+
+// analyzeRequestIDs returns the ids of the analyze requests of a user
+func (db *fakeDB) analyzeRequestIDs(userID id.ID) map[id.ID]bool {
+	analyzeRequestIDs := map[id.ID]bool{}
+	for _, analyzeRequest := range db.analyzeRequests {
+		if analyzeRequest.UserID == userID {
+			analyzeRequestIDs[analyzeRequest.ID] = true
+		}
+	}
+
+	return analyzeRequestIDs
+}
+
+// countForUser returns the number of documents of a user in every collection.
+// The results of the analysis are counted for the analyze requests which the user had before they were deleted.
+func (db *fakeDB) countForUser(userID id.ID, analyzeRequestIDs map[id.ID]bool) map[string]int {
+	counts := map[string]int{}
+
+	for _, user := range db.users {
+		if user.ID == userID {
+			counts["users"]++
+		}
+	}
+	counts["analyze_requests"] = len(db.analyzeRequestIDs(userID))
+	for _, record := range db.enrichedRecords {
+		if analyzeRequestIDs[record.AnalyzeRequestID] {
+			counts["ns_enriched_records"]++
+		}
+	}
+	for _, result := range db.calculationResults {
+		if analyzeRequestIDs[result.AnalyzeRequestID] {
+			counts["ns_calculation_results"]++
+		}
+	}
+	for _, token := range db.passwordResetTokens {
+		if token.UserID == userID {
+			counts["password_reset_tokens"]++
+		}
+	}
+	for _, token := range db.emailVerificationTokens {
+		if token.UserID == userID {
+			counts["email_verification_tokens"]++
+		}
+	}
+	for _, dataExport := range db.dataExports {
+		if dataExport.UserID == userID {
+			counts["data_exports"]++
+		}
+	}
+	for _, accessToken := range db.accessTokens {
+		if accessToken.UserID == userID {
+			counts["access_tokens"]++
+		}
+	}
+
+	return counts
+}
+
+type fakeUserRepository struct {
+	database.UserRepository
+	db *fakeDB
+}
+
+func (repository fakeUserRepository) FindByID(_ context.Context, userID id.ID) (*entities.User, error) {
+	for _, user := range repository.db.users {
+		if user.ID == userID {
+			return &user, nil
+		}
+	}
+
+	return nil, errors.ErrEntityNotFound
+}
+
+func (repository fakeUserRepository) Delete(_ context.Context, userID id.ID) error {
+	users := repository.db.users[:0]
+	for _, user := range repository.db.users {
+		if user.ID != userID {
+			users = append(users, user)
+		}
+	}
+	repository.db.users = users
+
+	return nil
+}
+
+type fakeAnalyzeRequestRepository struct {
+	database.AnalyzeRequestRepository
+	db *fakeDB
+}
+
+func (repository fakeAnalyzeRequestRepository) FetchIDsForUser(_ context.Context, userID id.ID) (analyzeRequestIDs []id.ID, err error) {
+	for analyzeRequestID := range repository.db.analyzeRequestIDs(userID) {
+		analyzeRequestIDs = append(analyzeRequestIDs, analyzeRequestID)
+	}
+
+	return analyzeRequestIDs, nil
+}
+
+func (repository fakeAnalyzeRequestRepository) DeleteForUser(_ context.Context, userID id.ID) error {
+	analyzeRequests := repository.db.analyzeRequests[:0]
+	for _, analyzeRequest := range repository.db.analyzeRequests {
+		if analyzeRequest.UserID != userID {
+			analyzeRequests = append(analyzeRequests, analyzeRequest)
+		}
+	}
+	repository.db.analyzeRequests = analyzeRequests
+
+	return nil
+}
+
+type fakeEnrichedRecordRepository struct {
+	database.EnrichedRecordRepository
+	db *fakeDB
+}
+
+func (repository fakeEnrichedRecordRepository) DeleteForAnalyzeRequests(_ context.Context, analyzeRequestIDs []id.ID) error {
+	records := repository.db.enrichedRecords[:0]
+	for _, record := range repository.db.enrichedRecords {
+		if !containsID(analyzeRequestIDs, record.AnalyzeRequestID) {
+			records = append(records, record)
+		}
+	}
+	repository.db.enrichedRecords = records
+
+	return nil
+}
+
+type fakeCalculationResultRepository struct {
+	database.CalculationResultRepository
+	db *fakeDB
+}
+
+func (repository fakeCalculationResultRepository) DeleteForAnalyzeRequests(_ context.Context, analyzeRequestIDs []id.ID) error {
+	results := repository.db.calculationResults[:0]
+	for _, result := range repository.db.calculationResults {
+		if !containsID(analyzeRequestIDs, result.AnalyzeRequestID) {
+			results = append(results, result)
+		}
+	}
+	repository.db.calculationResults = results
+
+	return nil
+}
+
+type fakePasswordResetTokenRepository struct {
+	database.PasswordResetTokenRepository
+	db *fakeDB
+}
+
+func (repository fakePasswordResetTokenRepository) DeleteForUser(_ context.Context, userID id.ID) error {
+	tokens := repository.db.passwordResetTokens[:0]
+	for _, token := range repository.db.passwordResetTokens {
+		if token.UserID != userID {
+			tokens = append(tokens, token)
+		}
+	}
+	repository.db.passwordResetTokens = tokens
+
+	return nil
+}
+
+type fakeEmailVerificationTokenRepository struct {
+	database.EmailVerificationTokenRepository
+	db *fakeDB
+}
+
+func (repository fakeEmailVerificationTokenRepository) DeleteForUser(_ context.Context, userID id.ID) error {
+	tokens := repository.db.emailVerificationTokens[:0]
+	for _, token := range repository.db.emailVerificationTokens {
+		if token.UserID != userID {
+			tokens = append(tokens, token)
+		}
+	}
+	repository.db.emailVerificationTokens = tokens
+
+	return nil
+}
+
+type fakeDataExportRepository struct {
+	database.DataExportRepository
+	db *fakeDB
+}
+
+func (repository fakeDataExportRepository) FetchForUser(_ context.Context, userID id.ID) (dataExports []entities.DataExport, err error) {
+	for _, dataExport := range repository.db.dataExports {
+		if dataExport.UserID == userID {
+			dataExports = append(dataExports, dataExport)
+		}
+	}
+
+	return dataExports, nil
+}
+
+func (repository fakeDataExportRepository) DeleteForUser(_ context.Context, userID id.ID) error {
+	dataExports := repository.db.dataExports[:0]
+	for _, dataExport := range repository.db.dataExports {
+		if dataExport.UserID != userID {
+			dataExports = append(dataExports, dataExport)
+		}
+	}
+	repository.db.dataExports = dataExports
+
+	return nil
+}
+
+type fakeAccessTokenRepository struct {
+	database.AccessTokenRepository
+	db *fakeDB
+}
+
+func (repository fakeAccessTokenRepository) DeleteForUser(_ context.Context, userID id.ID) error {
+	accessTokens := repository.db.accessTokens[:0]
+	for _, accessToken := range repository.db.accessTokens {
+		if accessToken.UserID != userID {
+			accessTokens = append(accessTokens, accessToken)
+		}
+	}
+	repository.db.accessTokens = accessTokens
+
+	return nil
+}
+
+func containsID(ids []id.ID, value id.ID) bool {
+	for _, item := range ids {
+		if item == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// testErrorHandler fails the test when a resolver captures an error
+type testErrorHandler struct {
+	t *testing.T
+}
+
+func (handler testErrorHandler) CaptureError(_ context.Context, err error) {
+	handler.t.Errorf("the resolver captured the error: %s", err)
+}
+
+// plainPasswordService compares passwords without hashing them so that the tests are fast
+type plainPasswordService struct{}
+
+func (plainPasswordService) HashPassword(password string) (string, error) {
+	return password, nil
+}
+
+func (plainPasswordService) CheckPasswordHash(password string, hash string) bool {
+	return password == hash
+}
+
+// passingValidator accepts every input
+type passingValidator struct {
+	validator.Validator
+}
+
+func (passingValidator) ValidateDeleteAccountInput(_ model.DeleteAccountInput, _ language.Tag) validator.ValidationResult {
+	return validator.ValidationResult{}
+}
+
+// newTestResolver creates a resolver whose tokens and sessions are stored in miniredis
+func newTestResolver(t *testing.T, db *fakeDB) *Resolver {
+	server, err := miniredis.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(server.Close)
+
+	cache := redis.NewClient(redis.Options{Address: server.Addr()})
+
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keySet, err := jwt.NewKeySet("test", []jwt.Key{{ID: "test", Method: jwt.SigningMethodEdDSA, PrivateKey: privateKey}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testLogger := logger.NewGoKitLogger(ioutil.Discard, logger.LevelError)
+
+	return &Resolver{
+		db:                  db,
+		validator:           passingValidator{},
+		passwordService:     plainPasswordService{},
+		errorHandler:        testErrorHandler{t: t},
+		logger:              testLogger,
+		jwtService:          jwt.NewService(keySet, cache, 15*time.Minute),
+		refreshTokenService: refreshtoken.NewService(cache, refreshtoken.Options{Lifetime: time.Hour, RememberMeLifetime: 24 * time.Hour}),
+		dataExporter:        dataexport.NewExporter(db, nil, nil, nil, testErrorHandler{t: t}, testLogger, dataexport.Options{Directory: t.TempDir()}),
+		sessionRegistry:     session.NewRegistry(cache, 24*time.Hour),
+	}
+}
+
+// userContext is the context of a request which was authenticated with a JWT of the user
+func userContext(userID id.ID) context.Context {
+	tag := language.English
+
+	ctx := context.WithValue(context.Background(), middlewares.ContextKeyUserID, userID)
+	ctx = context.WithValue(ctx, middlewares.ContextKeyLanguageTag, &tag)
+	ctx = context.WithValue(ctx, middlewares.ContextKeyClientIP, "127.0.0.1")
+	return context.WithValue(ctx, middlewares.ContextKeyUserAgent, "Mozilla/5.0")
+}
+
+// fieldContext adds the contexts which gqlgen creates for a resolver so that it can add errors to the response
+func fieldContext(ctx context.Context) context.Context {
+	ctx = graphql.WithResponseContext(ctx, graphql.DefaultErrorPresenter, graphql.DefaultRecover)
+	return graphql.WithFieldContext(ctx, &graphql.FieldContext{})
+}
@@ -1,6 +1,10 @@
 package resolver
 
 var (
-	fieldPassword = "password"
-	fieldEmail    = "email"
+	fieldPassword        = "password"
+	fieldEmail           = "email"
+	fieldCurrentPassword = "currentPassword"
+	fieldCode            = "code"
+	fieldChallengeToken  = "challengeToken"
+	fieldID              = "id"
 )
@@ -0,0 +1,78 @@
+package resolver
+
+import (
+	"context"
+	"time"
+
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/entities"
+	internalErrors "github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/errors"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/graph/model"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/id"
+	internalTime "github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/time"
+	"github.com/palantir/stacktrace"
+)
+
+func (r *mutationResolver) createAccessToken(ctx context.Context, input model.CreateAccessTokenInput) (*model.CreateAccessTokenOutput, error) {
+	// check that the user is authorized
+	userID, err := r.sessionUserIDFromContext(ctx)
+	if err != nil {
+		return nil, ErrUnauthorizedRequest
+	}
+
+	validationResult := r.validator.ValidateCreateAccessTokenInput(input, r.languageTagFromContext(ctx))
+	if validationResult.HasError {
+		r.addValidationErrors(ctx, validationResult)
+		return nil, internalErrors.ErrValidationError
+	}
+
+	scopes := make([]entities.AccessTokenScope, len(input.Scopes))
+	for index, scope := range input.Scopes {
+		scopes[index] = r.accessTokenScopeFromModel(scope)
+	}
+
+	var expiresAt *time.Time
+	if input.ExpiresAt != nil {
+		// the input is validated so the date can be parsed
+		expiryDate, _ := internalTime.FromDate(*input.ExpiresAt)
+		endOfDay := expiryDate.UTC().Add(24 * time.Hour)
+		expiresAt = &endOfDay
+	}
+
+	token, accessToken, err := r.accessTokenService.Create(ctx, userID, input.Name, scopes, expiresAt)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "cannot create access token for user with ID: %s", userID.String()))
+		return nil, internalErrors.ErrInternalServerError
+	}
+
+	return &model.CreateAccessTokenOutput{
+		AccessToken: r.accessTokenToModel(accessToken),
+		Token:       token,
+	}, nil
+}
+
+func (r *mutationResolver) revokeAccessToken(ctx context.Context, input model.RevokeAccessTokenInput) (bool, error) {
+	// check that the user is authorized
+	userID, err := r.sessionUserIDFromContext(ctx)
+	if err != nil {
+		return false, ErrUnauthorizedRequest
+	}
+
+	accessTokenID, err := id.FromString(input.ID)
+	if err != nil {
+		r.addError(ctx, fieldID, "The access token does not exist", CodeValidationError)
+		return false, internalErrors.ErrValidationError
+	}
+
+	isDeleted, err := r.db.AccessTokenRepository().Delete(ctx, userID, accessTokenID)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "cannot revoke access token with ID: %s", input.ID))
+		return false, internalErrors.ErrInternalServerError
+	}
+
+	if !isDeleted {
+		r.addError(ctx, fieldID, "The access token does not exist", CodeValidationError)
+		return false, internalErrors.ErrValidationError
+	}
+
+	return true, nil
+}
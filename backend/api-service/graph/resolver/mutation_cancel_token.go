@@ -23,5 +23,25 @@ func (r *mutationResolver) cancelToken(ctx context.Context) (bool, error) {
 		return false, internalErrors.ErrInternalServerError
 	}
 
+	// the session is ended so it cannot be renewed
+	sessionID, err := r.jwtService.GetSessionIDFromToken(jwt)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, err)
+		return false, internalErrors.ErrInternalServerError
+	}
+
+	if sessionID != "" {
+		userID, err := r.userIDFromContext(ctx)
+		if err != nil {
+			return false, ErrUnauthorizedRequest
+		}
+
+		err = r.endSession(userID, sessionID)
+		if err != nil {
+			r.errorHandler.CaptureError(ctx, err)
+			return false, internalErrors.ErrInternalServerError
+		}
+	}
+
 	return true, nil
 }
@@ -0,0 +1,73 @@
+package resolver
+
+import (
+	"context"
+	"time"
+
+	internalErrors "github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/errors"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/graph/model"
+	"github.com/palantir/stacktrace"
+)
+
+func (r *mutationResolver) changePassword(ctx context.Context, input model.ChangePasswordInput) (*model.Token, error) {
+	// check that the user is authorized
+	userID, err := r.sessionUserIDFromContext(ctx)
+	if err != nil {
+		return nil, ErrUnauthorizedRequest
+	}
+
+	validationResult := r.validator.ValidateChangePasswordInput(input, r.languageTagFromContext(ctx))
+	if validationResult.HasError {
+		r.addValidationErrors(ctx, validationResult)
+		return nil, internalErrors.ErrValidationError
+	}
+
+	user, err := r.db.UserRepository().FindByID(ctx, userID)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "cannot find user with ID: %s", userID.String()))
+		return nil, internalErrors.ErrInternalServerError
+	}
+
+	if !r.passwordService.CheckPasswordHash(input.CurrentPassword, user.Password) {
+		r.addError(ctx, fieldCurrentPassword, "The current password is incorrect", CodeValidationError)
+		return nil, internalErrors.ErrValidationError
+	}
+
+	hashedPassword, err := r.passwordService.HashPassword(input.NewPassword)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "could not hash password"))
+		return nil, internalErrors.ErrInternalServerError
+	}
+
+	err = r.db.UserRepository().UpdatePassword(ctx, userID, hashedPassword, time.Now().UTC())
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "cannot update password for user with ID: %s", userID.String()))
+		return nil, internalErrors.ErrInternalServerError
+	}
+
+	// the current session keeps its remember me preference when it is replaced
+	rememberMe := false
+	jwt, err := r.tokenFromContext(ctx)
+	if err == nil {
+		sessionID, err := r.jwtService.GetSessionIDFromToken(jwt)
+		if err == nil && sessionID != "" {
+			family, err := r.refreshTokenService.FindFamily(sessionID)
+			rememberMe = err == nil && family.RememberMe
+		}
+	}
+
+	// every session including the current one is revoked and the current client receives tokens for a new session
+	err = r.jwtService.InvalidateTokensForUser(userID)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "cannot invalidate tokens for user with ID: %s", userID.String()))
+		return nil, internalErrors.ErrInternalServerError
+	}
+
+	err = r.endAllSessions(userID)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "cannot end sessions of user with ID: %s", userID.String()))
+		return nil, internalErrors.ErrInternalServerError
+	}
+
+	return r.issueTokens(ctx, userID, rememberMe)
+}
@@ -8,11 +8,15 @@ import (
 	internalErrors "github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/errors"
 	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/graph/model"
 	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/id"
-	internalTime "github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/time"
 	pkgErrors "github.com/pkg/errors"
 )
 
 func (r *mutationResolver) createUser(ctx context.Context, input model.CreateUserInput) (*model.AuthOutput, error) {
+	err := r.checkRateLimits(ctx, createUserIPRateLimit, createUserEmailRateLimit, input.Email)
+	if err != nil {
+		return nil, err
+	}
+
 	validationResult := r.validator.ValidateCreateUserInput(input, r.languageTagFromContext(ctx))
 	if validationResult.HasError {
 		r.addValidationErrors(ctx, validationResult)
@@ -34,30 +38,27 @@ func (r *mutationResolver) createUser(ctx context.Context, input model.CreateUse
 		CreatedAt: time.Now().UTC(),
 		UpdatedAt: time.Now().UTC(),
 	}
+	user.EmailVerificationGraceStartedAt = user.CreatedAt
 
-	err = r.db.UserRepository().Store(user)
+	err = r.db.UserRepository().Store(ctx, user)
 	if err != nil {
 		r.errorHandler.CaptureError(ctx, pkgErrors.Wrap(err, "cannot save user in the database"))
 		return nil, internalErrors.ErrInternalServerError
 	}
 
-	token, err := r.jwtService.GenerateTokenForUserID(user.ID)
+	// the account is usable during the verification grace period so a failed email doesn't block the sign up
+	err = r.sendEmailVerification(ctx, user)
 	if err != nil {
-		r.errorHandler.CaptureError(ctx, pkgErrors.Wrapf(err, "cannot generate jwt token for user with ID: %s", user.ID.String()))
-		return nil, internalErrors.ErrInternalServerError
+		r.errorHandler.CaptureError(ctx, err)
+	}
+
+	token, err := r.issueTokens(ctx, user.ID, false)
+	if err != nil {
+		return nil, err
 	}
 
 	return &model.AuthOutput{
-		User: &model.User{
-			ID:        user.ID.String(),
-			FirstName: user.FirstName,
-			LastName:  user.LastName,
-			Email:     user.Email,
-			CreatedAt: user.CreatedAt.Format(internalTime.DefaultFormat),
-			UpdatedAt: user.UpdatedAt.Format(internalTime.DefaultFormat),
-		},
-		Token: &model.Token{
-			Value: token,
-		},
+		User:  r.userToModel(&user),
+		Token: token,
 	}, nil
 }
@@ -0,0 +1,124 @@
+package resolver
+
+import (
+	"context"
+	"time"
+
+	internalErrors "github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/errors"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/graph/model"
+	raw_records_service "github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/proto/raw-records-service"
+	"github.com/palantir/stacktrace"
+)
+
+const (
+	// userDataDeletionTimeout is how long the raw records service can take to delete the cards and raw records of a user
+	userDataDeletionTimeout = 30 * time.Second
+)
+
+func (r *mutationResolver) deleteAccount(ctx context.Context, input model.DeleteAccountInput) (bool, error) {
+	// check that the user is authorized
+	userID, err := r.sessionUserIDFromContext(ctx)
+	if err != nil {
+		return false, ErrUnauthorizedRequest
+	}
+
+	validationResult := r.validator.ValidateDeleteAccountInput(input, r.languageTagFromContext(ctx))
+	if validationResult.HasError {
+		r.addValidationErrors(ctx, validationResult)
+		return false, internalErrors.ErrValidationError
+	}
+
+	user, err := r.db.UserRepository().FindByID(ctx, userID)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "cannot find user with ID: %s", userID.String()))
+		return false, internalErrors.ErrInternalServerError
+	}
+
+	if !r.passwordService.CheckPasswordHash(input.Password, user.Password) {
+		r.addError(ctx, fieldPassword, "The password is incorrect", CodeValidationError)
+		return false, internalErrors.ErrValidationError
+	}
+
+	// the user is deleted last so a failed deletion can be retried by the user
+	grpcCtx, cancel := context.WithTimeout(ctx, userDataDeletionTimeout)
+	defer cancel()
+
+	deleteResponse, err := r.rawRecordsServiceClient.DeleteUserData(grpcCtx, &raw_records_service.DeleteUserDataRequest{
+		UserId: userID.String(),
+	})
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "cannot delete raw records and cards of user with ID: %s", userID.String()))
+		return false, internalErrors.ErrInternalServerError
+	}
+
+	// the results of the analysis are found through the analyze requests so they are deleted first
+	analyzeRequestIDs, err := r.db.AnalyzeRequestRepository().FetchIDsForUser(ctx, userID)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "cannot fetch analyze requests of user with ID: %s", userID.String()))
+		return false, internalErrors.ErrInternalServerError
+	}
+
+	err = r.db.EnrichedRecordRepository().DeleteForAnalyzeRequests(ctx, analyzeRequestIDs)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "cannot delete enriched records of user with ID: %s", userID.String()))
+		return false, internalErrors.ErrInternalServerError
+	}
+
+	err = r.db.CalculationResultRepository().DeleteForAnalyzeRequests(ctx, analyzeRequestIDs)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "cannot delete calculation results of user with ID: %s", userID.String()))
+		return false, internalErrors.ErrInternalServerError
+	}
+
+	err = r.db.AnalyzeRequestRepository().DeleteForUser(ctx, userID)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "cannot delete analyze requests of user with ID: %s", userID.String()))
+		return false, internalErrors.ErrInternalServerError
+	}
+
+	err = r.db.PasswordResetTokenRepository().DeleteForUser(ctx, userID)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "cannot delete password reset tokens of user with ID: %s", userID.String()))
+		return false, internalErrors.ErrInternalServerError
+	}
+
+	err = r.db.EmailVerificationTokenRepository().DeleteForUser(ctx, userID)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "cannot delete email verification tokens of user with ID: %s", userID.String()))
+		return false, internalErrors.ErrInternalServerError
+	}
+
+	err = r.dataExporter.DeleteForUser(ctx, userID)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "cannot delete data exports of user with ID: %s", userID.String()))
+		return false, internalErrors.ErrInternalServerError
+	}
+
+	err = r.db.AccessTokenRepository().DeleteForUser(ctx, userID)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "cannot delete access tokens of user with ID: %s", userID.String()))
+		return false, internalErrors.ErrInternalServerError
+	}
+
+	err = r.endAllSessions(userID)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "cannot end sessions of user with ID: %s", userID.String()))
+		return false, internalErrors.ErrInternalServerError
+	}
+
+	err = r.jwtService.InvalidateTokensForUser(userID)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "cannot invalidate tokens for user with ID: %s", userID.String()))
+		return false, internalErrors.ErrInternalServerError
+	}
+
+	err = r.db.UserRepository().Delete(ctx, userID)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "cannot delete user with ID: %s", userID.String()))
+		return false, internalErrors.ErrInternalServerError
+	}
+
+	r.logger.Info(ctx, "deleted account", "raw_records", deleteResponse.GetDeletedRawRecords(), "cards", deleteResponse.GetDeletedCards())
+
+	return true, nil
+}
@@ -0,0 +1,88 @@
+package resolver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/entities"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/graph/model"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/id"
+	raw_records_service "github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/proto/raw-records-service"
+	"google.golang.org/grpc"
+)
+
+// fakeRawRecordsServiceClient records the users whose raw records and cards were deleted
+type fakeRawRecordsServiceClient struct {
+	raw_records_service.RawRecordsServiceClient
+	deletedUserIDs []string
+}
+
+func (client *fakeRawRecordsServiceClient) DeleteUserData(_ context.Context, in *raw_records_service.DeleteUserDataRequest, _ ...grpc.CallOption) (*raw_records_service.DeleteUserDataResponse, error) {
+	client.deletedUserIDs = append(client.deletedUserIDs, in.GetUserId())
+	return &raw_records_service.DeleteUserDataResponse{}, nil
+}
+
+// storeUserDocuments stores a document of the user in every collection
+func storeUserDocuments(db *fakeDB, userID id.ID, password string) {
+	analyzeRequestID := id.New()
+
+	db.users = append(db.users, entities.User{ID: userID, Password: password})
+	db.analyzeRequests = append(db.analyzeRequests, entities.AnalyzeRequest{ID: analyzeRequestID, UserID: userID})
+	db.enrichedRecords = append(db.enrichedRecords, entities.EnrichedRecord{ID: id.New(), AnalyzeRequestID: analyzeRequestID})
+	db.calculationResults = append(db.calculationResults, entities.CalculationResult{AnalyzeRequestID: analyzeRequestID})
+	db.passwordResetTokens = append(db.passwordResetTokens, entities.PasswordResetToken{ID: id.New(), UserID: userID})
+	db.emailVerificationTokens = append(db.emailVerificationTokens, entities.EmailVerificationToken{ID: id.New(), UserID: userID})
+	db.dataExports = append(db.dataExports, entities.DataExport{ID: id.New(), UserID: userID})
+	db.accessTokens = append(db.accessTokens, entities.AccessToken{ID: id.New(), UserID: userID})
+}
+
+func TestMutationResolverDeleteAccount(t *testing.T) {
+	userID, otherUserID := id.New(), id.New()
+
+	db := &fakeDB{}
+	storeUserDocuments(db, userID, "password")
+	storeUserDocuments(db, otherUserID, "other password")
+
+	rawRecordsServiceClient := &fakeRawRecordsServiceClient{}
+	resolver := newTestResolver(t, db)
+	resolver.rawRecordsServiceClient = rawRecordsServiceClient
+
+	ctx := userContext(userID)
+	_, err := resolver.issueTokens(ctx, userID, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	analyzeRequestIDs := db.analyzeRequestIDs(userID)
+	otherAnalyzeRequestIDs := db.analyzeRequestIDs(otherUserID)
+	otherCounts := db.countForUser(otherUserID, otherAnalyzeRequestIDs)
+
+	deleted, err := (&mutationResolver{resolver}).deleteAccount(ctx, model.DeleteAccountInput{Password: "password"})
+	if err != nil || !deleted {
+		t.Fatalf("deleteAccount returned %t and the error %v, expected the account to be deleted", deleted, err)
+	}
+
+	for collection, count := range db.countForUser(userID, analyzeRequestIDs) {
+		if count != 0 {
+			t.Errorf("%d documents of the user remain in %s", count, collection)
+		}
+	}
+
+	for collection, count := range db.countForUser(otherUserID, otherAnalyzeRequestIDs) {
+		if count != otherCounts[collection] {
+			t.Errorf("%d documents of the other user remain in %s, expected %d", count, collection, otherCounts[collection])
+		}
+	}
+
+	if len(rawRecordsServiceClient.deletedUserIDs) != 1 || rawRecordsServiceClient.deletedUserIDs[0] != userID.String() {
+		t.Errorf("the raw records of the users %q were deleted, expected only %s", rawRecordsServiceClient.deletedUserIDs, userID)
+	}
+
+	sessions, err := resolver.sessionRegistry.ListForUser(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 0 {
+		t.Errorf("the user has %d sessions, expected them to be ended", len(sessions))
+	}
+}
@@ -2,23 +2,32 @@ package resolver
 
 import (
 	"context"
+	"fmt"
+	"time"
 
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/entities"
 	apiErrors "github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/errors"
 	internalErrors "github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/errors"
 	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/graph/model"
 	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/graph/validator"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/services/mailer"
 	internalTime "github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/time"
 	pkgErrors "github.com/pkg/errors"
 )
 
 func (r *mutationResolver) login(ctx context.Context, input model.LoginInput) (*model.AuthOutput, error) {
+	err := r.checkRateLimits(ctx, loginIPRateLimit, loginEmailRateLimit, input.Email)
+	if err != nil {
+		return nil, err
+	}
+
 	validationResult := r.validator.ValidateLoginInput(input, r.languageTagFromContext(ctx))
 	if validationResult.HasError {
 		r.addValidationErrors(ctx, validationResult)
 		return nil, apiErrors.ErrValidationError
 	}
 
-	user, err := r.db.UserRepository().FindByEmail(input.Email)
+	user, err := r.db.UserRepository().FindByEmail(ctx, input.Email)
 	if err == internalErrors.ErrEntityNotFound {
 		r.addError(ctx, fieldEmail, validator.ErrInvalidEmailOrPassword.Error(), CodeValidationError)
 		r.addError(ctx, fieldPassword, validator.ErrInvalidEmailOrPassword.Error(), CodeValidationError)
@@ -30,30 +39,67 @@ func (r *mutationResolver) login(ctx context.Context, input model.LoginInput) (*
 		return nil, apiErrors.ErrInternalServerError
 	}
 
+	// a locked account cannot log in even with the right password so the password cannot be guessed during the lockout
+	lockedFor, err := r.loginLockout.LockedFor(user.ID.String())
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, pkgErrors.Wrapf(err, "cannot check lockout of user with ID: %s", user.ID.String()))
+	}
+	if lockedFor > 0 {
+		return nil, r.retryAfterError(CodeAccountLocked, "The account is locked after too many failed logins, please try again later", lockedFor)
+	}
+
 	passwordIsValid := r.passwordService.CheckPasswordHash(input.Password, user.Password)
 	if !passwordIsValid {
+		r.recordFailedLogin(ctx, *user)
 		r.addError(ctx, fieldEmail, validator.ErrInvalidEmailOrPassword.Error(), CodeValidationError)
 		r.addError(ctx, fieldPassword, validator.ErrInvalidEmailOrPassword.Error(), CodeValidationError)
 		return nil, apiErrors.ErrValidationError
 	}
 
-	token, err := r.jwtService.GenerateTokenForUserID(user.ID)
+	// the lockout is reset after the second factor so failed codes keep counting towards it
+	if user.IsTwoFactorEnabled() {
+		return r.issueTwoFactorChallenge(ctx, *user, input.RememberMe)
+	}
+
+	err = r.loginLockout.Reset(user.ID.String())
 	if err != nil {
-		r.errorHandler.CaptureError(ctx, pkgErrors.Wrapf(err, "cannot generate jwt token for user with ID: %s", user.ID.String()))
-		return nil, apiErrors.ErrInternalServerError
+		r.errorHandler.CaptureError(ctx, pkgErrors.Wrapf(err, "cannot reset lockout of user with ID: %s", user.ID.String()))
+	}
+
+	token, err := r.issueTokens(ctx, user.ID, input.RememberMe)
+	if err != nil {
+		return nil, err
 	}
 
 	return &model.AuthOutput{
-		User: &model.User{
-			ID:        user.ID.String(),
-			FirstName: user.FirstName,
-			LastName:  user.LastName,
-			Email:     user.Email,
-			CreatedAt: user.CreatedAt.Format(internalTime.DefaultFormat),
-			UpdatedAt: user.UpdatedAt.Format(internalTime.DefaultFormat),
-		},
-		Token: &model.Token{
-			Value: token,
-		},
+		User:  r.userToModel(user),
+		Token: token,
 	}, nil
 }
+
+// recordFailedLogin locks the account after too many failed logins and lets the user know
+func (r *mutationResolver) recordFailedLogin(ctx context.Context, user entities.User) {
+	lockedFor, err := r.loginLockout.RecordFailure(user.ID.String())
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, pkgErrors.Wrapf(err, "cannot record failed login of user with ID: %s", user.ID.String()))
+		return
+	}
+
+	if lockedFor == 0 {
+		return
+	}
+
+	err = r.mailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Your account has been locked",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nWe locked your account until %s UTC after too many failed login attempts from %s.\n\nIf this wasn't you, we recommend resetting your password.\n",
+			user.FirstName,
+			time.Now().UTC().Add(lockedFor).Format(internalTime.DefaultFormat),
+			r.clientIPFromContext(ctx),
+		),
+	})
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, pkgErrors.Wrapf(err, "cannot send lockout email to user with ID: %s", user.ID.String()))
+	}
+}
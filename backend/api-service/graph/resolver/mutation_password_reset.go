@@ -0,0 +1,126 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/entities"
+	internalErrors "github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/errors"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/graph/model"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/services/mailer"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/services/token"
+	sharedErrors "github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/errors"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/id"
+	"github.com/palantir/stacktrace"
+)
+
+const (
+	passwordResetTokenLifetime = time.Hour
+	fieldToken                 = "token"
+)
+
+func (r *mutationResolver) requestPasswordReset(ctx context.Context, input model.RequestPasswordResetInput) (bool, error) {
+	validationResult := r.validator.ValidateRequestPasswordResetInput(input, r.languageTagFromContext(ctx))
+	if validationResult.HasError {
+		r.addValidationErrors(ctx, validationResult)
+		return false, internalErrors.ErrValidationError
+	}
+
+	// the response is the same when the email doesn't exist so users cannot be enumerated
+	user, err := r.db.UserRepository().FindByEmail(ctx, input.Email)
+	if err == sharedErrors.ErrEntityNotFound {
+		return true, nil
+	}
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "cannot find user by email"))
+		return false, internalErrors.ErrInternalServerError
+	}
+
+	plainToken, tokenHash, err := token.Generate()
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "cannot generate password reset token"))
+		return false, internalErrors.ErrInternalServerError
+	}
+
+	err = r.db.PasswordResetTokenRepository().Store(ctx, entities.PasswordResetToken{
+		ID:        id.New(),
+		UserID:    user.ID,
+		TokenHash: tokenHash,
+		ExpiresAt: time.Now().UTC().Add(passwordResetTokenLifetime),
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "cannot store password reset token for user with ID: %s", user.ID.String()))
+		return false, internalErrors.ErrInternalServerError
+	}
+
+	err = r.mailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nUse the link below to choose a new password. The link expires in 1 hour and can only be used once.\n\n%s/reset-password?token=%s\n\nIf you didn't request a password reset you can ignore this email.\n",
+			user.FirstName,
+			r.appURL,
+			plainToken,
+		),
+	})
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "cannot send password reset email to user with ID: %s", user.ID.String()))
+		return false, internalErrors.ErrInternalServerError
+	}
+
+	return true, nil
+}
+
+func (r *mutationResolver) resetPassword(ctx context.Context, input model.ResetPasswordInput) (bool, error) {
+	validationResult := r.validator.ValidateResetPasswordInput(input, r.languageTagFromContext(ctx))
+	if validationResult.HasError {
+		r.addValidationErrors(ctx, validationResult)
+		return false, internalErrors.ErrValidationError
+	}
+
+	resetToken, err := r.db.PasswordResetTokenRepository().Consume(ctx, token.Hash(input.Token), time.Now().UTC())
+	if err == sharedErrors.ErrEntityNotFound {
+		r.addError(ctx, fieldToken, "The password reset token is invalid or has expired", CodeValidationError)
+		return false, internalErrors.ErrValidationError
+	}
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "cannot consume password reset token"))
+		return false, internalErrors.ErrInternalServerError
+	}
+
+	hashedPassword, err := r.passwordService.HashPassword(input.Password)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "could not hash password"))
+		return false, internalErrors.ErrInternalServerError
+	}
+
+	err = r.db.UserRepository().UpdatePassword(ctx, resetToken.UserID, hashedPassword, time.Now().UTC())
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "cannot update password for user with ID: %s", resetToken.UserID.String()))
+		return false, internalErrors.ErrInternalServerError
+	}
+
+	// the other links which were sent to the user must not be able to change the new password
+	err = r.db.PasswordResetTokenRepository().DeleteForUser(ctx, resetToken.UserID)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "cannot delete password reset tokens of user with ID: %s", resetToken.UserID.String()))
+		return false, internalErrors.ErrInternalServerError
+	}
+
+	// sessions which were opened with the old password must not outlive it
+	err = r.jwtService.InvalidateTokensForUser(resetToken.UserID)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "cannot invalidate tokens for user with ID: %s", resetToken.UserID.String()))
+		return false, internalErrors.ErrInternalServerError
+	}
+
+	err = r.endAllSessions(resetToken.UserID)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "cannot end sessions of user with ID: %s", resetToken.UserID.String()))
+		return false, internalErrors.ErrInternalServerError
+	}
+
+	return true, nil
+}
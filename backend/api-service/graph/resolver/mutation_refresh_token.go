@@ -0,0 +1,102 @@
+package resolver
+
+import (
+	"context"
+	"time"
+
+	internalErrors "github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/errors"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/graph/model"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/services/refreshtoken"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/services/session"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/id"
+	internalTime "github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/time"
+	"github.com/palantir/stacktrace"
+)
+
+func (r *mutationResolver) refreshToken(ctx context.Context, input model.RefreshTokenInput) (*model.Token, error) {
+	refreshToken, family, err := r.refreshTokenService.Rotate(input.Token)
+	if stacktrace.GetCode(err) == refreshtoken.ErrCodeRefreshTokenReused {
+		// the token was stolen so the access tokens which were issued to the session are rejected as well
+		r.endReusedSession(ctx, family)
+		r.addError(ctx, fieldToken, "The refresh token is invalid or has expired", CodeValidationError)
+		return nil, internalErrors.ErrValidationError
+	}
+	if stacktrace.GetCode(err) == refreshtoken.ErrCodeInvalidRefreshToken {
+		r.addError(ctx, fieldToken, "The refresh token is invalid or has expired", CodeValidationError)
+		return nil, internalErrors.ErrValidationError
+	}
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "cannot rotate refresh token"))
+		return nil, internalErrors.ErrInternalServerError
+	}
+
+	userID, err := id.FromString(family.UserID)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "refresh token family %s has an invalid user id", family.ID))
+		return nil, internalErrors.ErrInternalServerError
+	}
+
+	_, err = r.sessionRegistry.Extend(family.ID, r.clientIPFromContext(ctx), family.ExpiresAt)
+	if stacktrace.GetCode(err) == session.ErrCodeSessionNotFound {
+		// the session was revoked so its refresh tokens cannot be used anymore
+		err = r.refreshTokenService.RevokeFamily(family)
+		if err != nil {
+			r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "cannot revoke refresh token family %s without a session", family.ID))
+		}
+
+		r.addError(ctx, fieldToken, "The refresh token is invalid or has expired", CodeValidationError)
+		return nil, internalErrors.ErrValidationError
+	}
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "cannot extend session %s", family.ID))
+		return nil, internalErrors.ErrInternalServerError
+	}
+
+	return r.accessTokenForFamily(ctx, userID, family, refreshToken)
+}
+
+// endReusedSession ends the session of a refresh token family which was revoked because one of its tokens was reused
+func (r *Resolver) endReusedSession(ctx context.Context, family refreshtoken.Family) {
+	userID, err := id.FromString(family.UserID)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "refresh token family %s has an invalid user id", family.ID))
+		return
+	}
+
+	err = r.endSession(userID, family.ID)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "cannot end session %s after its refresh token was reused", family.ID))
+	}
+}
+
+// issueTokens starts a new session for the user
+func (r *Resolver) issueTokens(ctx context.Context, userID id.ID, rememberMe bool) (*model.Token, error) {
+	refreshToken, family, err := r.refreshTokenService.Issue(userID, rememberMe)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "cannot issue refresh token for user with ID: %s", userID.String()))
+		return nil, internalErrors.ErrInternalServerError
+	}
+
+	_, err = r.sessionRegistry.Start(family.ID, userID, r.userAgentFromContext(ctx), r.clientIPFromContext(ctx), family.ExpiresAt)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "cannot start session for user with ID: %s", userID.String()))
+		return nil, internalErrors.ErrInternalServerError
+	}
+
+	return r.accessTokenForFamily(ctx, userID, family, refreshToken)
+}
+
+func (r *Resolver) accessTokenForFamily(ctx context.Context, userID id.ID, family refreshtoken.Family, refreshToken string) (*model.Token, error) {
+	expiresAt := time.Now().UTC().Add(r.jwtService.Lifetime())
+	accessToken, err := r.jwtService.GenerateTokenForUserID(userID, family.ID)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "cannot generate jwt token for user with ID: %s", userID.String()))
+		return nil, internalErrors.ErrInternalServerError
+	}
+
+	return &model.Token{
+		Value:        accessToken,
+		ExpiresAt:    expiresAt.Format(internalTime.DefaultFormat),
+		RefreshToken: refreshToken,
+	}, nil
+}
@@ -0,0 +1,72 @@
+package resolver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	internalErrors "github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/errors"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/graph/model"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/middlewares"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/services/accesstoken"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/id"
+)
+
+func TestMutationResolverRefreshTokenReused(t *testing.T) {
+	userID := id.New()
+	db := &fakeDB{}
+	resolver := newTestResolver(t, db)
+	mutationResolver := &mutationResolver{resolver}
+
+	// isAccepted checks if a request with the access token is authenticated
+	isAccepted := func(accessToken string) bool {
+		authenticated := false
+		handler := middlewares.New().EnrichUserID(resolver.jwtService, accesstoken.NewService(db), resolver.sessionRegistry)(
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				authenticated = true
+			}),
+		)
+
+		request := httptest.NewRequest(http.MethodPost, "/query", nil)
+		request.Header.Set("Authorization", accessToken)
+		handler.ServeHTTP(httptest.NewRecorder(), request)
+
+		return authenticated
+	}
+
+	issued, err := resolver.issueTokens(userContext(userID), userID, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rotated, err := mutationResolver.refreshToken(fieldContext(userContext(userID)), model.RefreshTokenInput{Token: issued.RefreshToken})
+	if err != nil {
+		t.Fatalf("refreshToken returned an error: %s", err)
+	}
+
+	if !isAccepted(issued.Value) || !isAccepted(rotated.Value) {
+		t.Fatal("the access tokens of the session are rejected before the refresh token was reused")
+	}
+
+	// the attacker uses the refresh token which was stolen before it was rotated
+	_, err = mutationResolver.refreshToken(fieldContext(userContext(userID)), model.RefreshTokenInput{Token: issued.RefreshToken})
+	if err != internalErrors.ErrValidationError {
+		t.Fatalf("refreshToken returned the error %v, expected %v", err, internalErrors.ErrValidationError)
+	}
+
+	if isAccepted(issued.Value) {
+		t.Error("the access token which was issued with the reused refresh token is accepted")
+	}
+
+	if isAccepted(rotated.Value) {
+		t.Error("the access token which was issued with the rotated refresh token is accepted")
+	}
+
+	sessions, err := resolver.sessionRegistry.ListForUser(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 0 {
+		t.Errorf("the user has %d active sessions, expected the session to be ended", len(sessions))
+	}
+}
@@ -0,0 +1,43 @@
+package resolver
+
+import (
+	"context"
+
+	internalErrors "github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/errors"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/graph/model"
+	raw_records_service "github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/proto/raw-records-service"
+	pkgErrors "github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (r *mutationResolver) registerCard(ctx context.Context, input model.RegisterCardInput) (*model.Card, error) {
+	// check that the user is authorized
+	userID, err := r.userIDFromContext(ctx)
+	if err != nil {
+		return nil, ErrUnauthorizedRequest
+	}
+
+	validationResult := r.validator.ValidateRegisterCardInput(ctx, input, r.languageTagFromContext(ctx))
+	if validationResult.HasError {
+		r.addValidationErrors(ctx, validationResult)
+		return nil, internalErrors.ErrValidationError
+	}
+
+	cardSyncState, err := r.rawRecordsServiceClient.RegisterCard(ctx, &raw_records_service.RegisterCardRequest{
+		UserId:     userID.String(),
+		CardNumber: input.OvChipkaartNumber,
+		Username:   input.OvChipkaartUsername,
+		Password:   input.OvChipkaartPassword,
+	})
+	if status.Code(err) == codes.AlreadyExists {
+		r.addError(ctx, "ovChipkaartNumber", "You already registered this ov chipkaart", CodeValidationError)
+		return nil, internalErrors.ErrValidationError
+	}
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, pkgErrors.Wrap(err, "cannot register card"))
+		return nil, internalErrors.ErrInternalServerError
+	}
+
+	return r.cardSyncStateToCard(cardSyncState), nil
+}
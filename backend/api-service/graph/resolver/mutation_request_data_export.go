@@ -0,0 +1,58 @@
+package resolver
+
+import (
+	"context"
+	"time"
+
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/entities"
+	internalErrors "github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/errors"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/graph/model"
+	sharedErrors "github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/errors"
+	internalTime "github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/time"
+	"github.com/palantir/stacktrace"
+)
+
+const (
+	// dataExportInterval is the minimum time between two data exports of a user because building an archive is expensive
+	dataExportInterval = time.Hour
+)
+
+func (r *mutationResolver) requestDataExport(ctx context.Context) (*model.DataExport, error) {
+	// check that the user is authorized
+	userID, err := r.userIDFromContext(ctx)
+	if err != nil {
+		return nil, ErrUnauthorizedRequest
+	}
+
+	user, err := r.db.UserRepository().FindByID(ctx, userID)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "cannot find user with ID: %s", userID.String()))
+		return nil, internalErrors.ErrInternalServerError
+	}
+
+	latestDataExport, err := r.db.DataExportRepository().FindLatestForUser(ctx, userID)
+	if err != nil && err != sharedErrors.ErrEntityNotFound {
+		r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "cannot find latest data export of user with ID: %s", userID.String()))
+		return nil, internalErrors.ErrInternalServerError
+	}
+
+	if err == nil &&
+		latestDataExport.Status != entities.DataExportStatusFailed &&
+		time.Now().UTC().Before(latestDataExport.CreatedAt.Add(dataExportInterval)) {
+		return nil, internalErrors.ErrDataExportTooSoon
+	}
+
+	dataExport, downloadURL, err := r.dataExporter.Request(ctx, *user)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, err)
+		return nil, internalErrors.ErrInternalServerError
+	}
+
+	return &model.DataExport{
+		ID:          dataExport.ID.String(),
+		Status:      dataExport.Status.String(),
+		DownloadURL: downloadURL,
+		ExpiresAt:   dataExport.ExpiresAt.Format(internalTime.DefaultFormat),
+		CreatedAt:   dataExport.CreatedAt.Format(internalTime.DefaultFormat),
+	}, nil
+}
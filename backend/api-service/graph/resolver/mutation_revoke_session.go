@@ -0,0 +1,86 @@
+package resolver
+
+import (
+	"context"
+
+	internalErrors "github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/errors"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/graph/model"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/services/refreshtoken"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/services/session"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/id"
+	"github.com/palantir/stacktrace"
+)
+
+func (r *mutationResolver) revokeSession(ctx context.Context, input model.RevokeSessionInput) (bool, error) {
+	// check that the user is authorized
+	userID, err := r.sessionUserIDFromContext(ctx)
+	if err != nil {
+		return false, ErrUnauthorizedRequest
+	}
+
+	activeSession, err := r.sessionRegistry.Find(input.ID)
+	if stacktrace.GetCode(err) == session.ErrCodeSessionNotFound || (err == nil && activeSession.UserID != userID.String()) {
+		r.addError(ctx, fieldID, "The session does not exist or has already ended", CodeValidationError)
+		return false, internalErrors.ErrValidationError
+	}
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "cannot find session %s", input.ID))
+		return false, internalErrors.ErrInternalServerError
+	}
+
+	err = r.endSession(userID, activeSession.ID)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "cannot end session %s of user with ID: %s", activeSession.ID, userID.String()))
+		return false, internalErrors.ErrInternalServerError
+	}
+
+	return true, nil
+}
+
+func (r *mutationResolver) revokeAllOtherSessions(ctx context.Context) (bool, error) {
+	// check that the user is authorized
+	userID, err := r.sessionUserIDFromContext(ctx)
+	if err != nil {
+		return false, ErrUnauthorizedRequest
+	}
+
+	sessions, err := r.sessionRegistry.ListForUser(userID)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "cannot fetch sessions of user with ID: %s", userID.String()))
+		return false, internalErrors.ErrInternalServerError
+	}
+
+	currentSessionID := r.sessionIDFromContext(ctx)
+	for _, activeSession := range sessions {
+		if activeSession.ID == currentSessionID {
+			continue
+		}
+
+		err = r.endSession(userID, activeSession.ID)
+		if err != nil {
+			r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "cannot end session %s of user with ID: %s", activeSession.ID, userID.String()))
+			return false, internalErrors.ErrInternalServerError
+		}
+	}
+
+	return true, nil
+}
+
+// endSession revokes the refresh tokens of a session and removes it from the registry so its access tokens are rejected
+func (r *Resolver) endSession(userID id.ID, sessionID string) error {
+	err := r.refreshTokenService.RevokeFamily(refreshtoken.Family{ID: sessionID, UserID: userID.String()})
+	if err != nil {
+		return stacktrace.Propagate(err, "cannot revoke refresh tokens of session %s", sessionID)
+	}
+
+	return r.sessionRegistry.Revoke(userID, sessionID)
+}
+
+func (r *Resolver) endAllSessions(userID id.ID) error {
+	err := r.refreshTokenService.RevokeAllForUser(userID)
+	if err != nil {
+		return stacktrace.Propagate(err, "cannot revoke refresh tokens of user with id %s", userID.String())
+	}
+
+	return r.sessionRegistry.RevokeAllForUser(userID)
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/id"
 	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/proto/transactions-service"
 	internalTime "github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/time"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/tracing"
 	"github.com/golang/protobuf/ptypes"
 	pkgErrors "github.com/pkg/errors"
 )
@@ -27,7 +28,17 @@ func (r *mutationResolver) storeAnalyzeRequest(ctx context.Context, input model.
 		return false, ErrUnauthorizedRequest
 	}
 
-	validationResult := r.validator.ValidateStoreAnalzyeRequest(input, r.languageTagFromContext(ctx))
+	user, err := r.db.UserRepository().FindByID(ctx, userID)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, pkgErrors.Wrapf(err, "cannot find user with ID: %s", userID.String()))
+		return false, internalErrors.ErrInternalServerError
+	}
+
+	if r.hasVerificationGracePeriodExpired(*user) {
+		return false, internalErrors.ErrEmailNotVerified
+	}
+
+	validationResult := r.validator.ValidateStoreAnalzyeRequest(ctx, input, r.languageTagFromContext(ctx))
 	if validationResult.HasError {
 		r.addValidationErrors(ctx, validationResult)
 		return false, internalErrors.ErrValidationError
@@ -52,8 +63,10 @@ func (r *mutationResolver) storeAnalyzeRequest(ctx context.Context, input model.
 		UpdatedAt:         time.Now().UTC(),
 	}
 
-	grpcCtx := context.WithValue(context.Background(), internalContext.KeyAnalyzeRequestID, analyzeRequest.ID.String())
-	grpcCtx, cancel := context.WithTimeout(grpcCtx, time.Second*5)
+	// the analyze request id correlates the logs, errors and traces of this request in every service
+	ctx = tracing.WithValue(ctx, internalContext.KeyAnalyzeRequestID, analyzeRequest.ID.String())
+
+	grpcCtx, cancel := context.WithTimeout(ctx, time.Second*5)
 	defer cancel()
 
 	protoStartDate, err := ptypes.TimestampProto(analyzeRequest.StartDate)
@@ -106,19 +119,20 @@ func (r *mutationResolver) storeAnalyzeRequest(ctx context.Context, input model.
 		return false, errors.New("error while processing ov chipkaart transactions")
 	}
 
-	err = r.db.AnalyzeRequestRepository().Store(analyzeRequest)
+	err = r.db.AnalyzeRequestRepository().Store(ctx, analyzeRequest)
 	if err != nil {
 		r.errorHandler.CaptureError(ctx, pkgErrors.Wrap(err, "cannot save analyze request in the database"))
 		return false, internalErrors.ErrInternalServerError
 	}
 
-	grpcCtx = context.WithValue(context.Background(), internalContext.KeyAnalyzeRequestID, analyzeRequest.ID.String())
-	grpcCtx, cancel = context.WithTimeout(grpcCtx, time.Second*5)
+	grpcCtx, cancel = context.WithTimeout(ctx, time.Second*5)
 	defer cancel()
-	_, err = r.rawRecordsServiceClient.StoreTransactions(grpcCtx, &raw_records_service.StoreTransactionsRequest{
+	storeResponse, err := r.rawRecordsServiceClient.StoreTransactions(grpcCtx, &raw_records_service.StoreTransactionsRequest{
 		Transactions:     recordsResponse.Transactions,
 		Source:           source.String(),
 		AnalyzeRequestId: analyzeRequest.ID.String(),
+		UserId:           analyzeRequest.UserID.String(),
+		CardNumber:       analyzeRequest.OvChipkaartNumber,
 	})
 
 	if err != nil {
@@ -126,5 +140,7 @@ func (r *mutationResolver) storeAnalyzeRequest(ctx context.Context, input model.
 		return false, internalErrors.ErrInternalServerError
 	}
 
+	r.logger.Info(ctx, "stored raw records", "inserted", storeResponse.GetInserted(), "skipped", storeResponse.GetSkipped())
+
 	return true, nil
 }
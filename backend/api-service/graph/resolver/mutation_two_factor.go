@@ -0,0 +1,289 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/entities"
+	internalErrors "github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/errors"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/graph/model"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/services/mailer"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/services/twofactor"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/id"
+	internalTime "github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/time"
+	"github.com/palantir/stacktrace"
+)
+
+var (
+	totpCodeRegex = regexp.MustCompile(`^[0-9]{6}$`)
+)
+
+func (r *mutationResolver) enableTwoFactor(ctx context.Context) (*model.TwoFactorEnrolment, error) {
+	// check that the user is authorized
+	userID, err := r.sessionUserIDFromContext(ctx)
+	if err != nil {
+		return nil, ErrUnauthorizedRequest
+	}
+
+	user, err := r.db.UserRepository().FindByID(ctx, userID)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "cannot find user with ID: %s", userID.String()))
+		return nil, internalErrors.ErrInternalServerError
+	}
+
+	if user.IsTwoFactorEnabled() {
+		return nil, internalErrors.ErrTwoFactorAlreadyEnabled
+	}
+
+	enrolment, err := r.twoFactorService.NewEnrolment(user.ID, user.Email)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "cannot create two factor enrolment for user with ID: %s", userID.String()))
+		return nil, internalErrors.ErrInternalServerError
+	}
+
+	// a new enrolment replaces the secret of an enrolment which was not confirmed
+	user.EncryptedTwoFactorSecret = enrolment.EncryptedSecret
+	user.UpdatedAt = time.Now().UTC()
+
+	err = r.db.UserRepository().UpdateTwoFactor(ctx, *user)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "cannot store two factor secret of user with ID: %s", userID.String()))
+		return nil, internalErrors.ErrInternalServerError
+	}
+
+	return &model.TwoFactorEnrolment{
+		Secret:     enrolment.Secret,
+		OtpauthURI: enrolment.URI,
+		QrCode:     enrolment.QRCode,
+	}, nil
+}
+
+func (r *mutationResolver) confirmTwoFactor(ctx context.Context, input model.ConfirmTwoFactorInput) ([]string, error) {
+	// check that the user is authorized
+	userID, err := r.sessionUserIDFromContext(ctx)
+	if err != nil {
+		return nil, ErrUnauthorizedRequest
+	}
+
+	validationResult := r.validator.ValidateConfirmTwoFactorInput(input, r.languageTagFromContext(ctx))
+	if validationResult.HasError {
+		r.addValidationErrors(ctx, validationResult)
+		return nil, internalErrors.ErrValidationError
+	}
+
+	user, err := r.db.UserRepository().FindByID(ctx, userID)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "cannot find user with ID: %s", userID.String()))
+		return nil, internalErrors.ErrInternalServerError
+	}
+
+	if user.IsTwoFactorEnabled() {
+		return nil, internalErrors.ErrTwoFactorAlreadyEnabled
+	}
+
+	if user.EncryptedTwoFactorSecret == "" {
+		r.addError(ctx, fieldCode, "Two factor authentication must be enabled before it can be confirmed", CodeValidationError)
+		return nil, internalErrors.ErrValidationError
+	}
+
+	isValid, err := r.twoFactorService.ValidateCode(user.ID, user.EncryptedTwoFactorSecret, input.Code)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "cannot validate two factor code of user with ID: %s", userID.String()))
+		return nil, internalErrors.ErrInternalServerError
+	}
+	if !isValid {
+		r.addError(ctx, fieldCode, "The code is invalid or has expired", CodeValidationError)
+		return nil, internalErrors.ErrValidationError
+	}
+
+	recoveryCodes, recoveryCodeHashes, err := twofactor.GenerateRecoveryCodes()
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, err)
+		return nil, internalErrors.ErrInternalServerError
+	}
+
+	now := time.Now().UTC()
+	user.TwoFactorEnabledAt = &now
+	user.RecoveryCodeHashes = recoveryCodeHashes
+	user.UpdatedAt = now
+
+	err = r.db.UserRepository().UpdateTwoFactor(ctx, *user)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "cannot enable two factor authentication for user with ID: %s", userID.String()))
+		return nil, internalErrors.ErrInternalServerError
+	}
+
+	return recoveryCodes, nil
+}
+
+func (r *mutationResolver) verifyTwoFactor(ctx context.Context, input model.VerifyTwoFactorInput) (*model.AuthOutput, error) {
+	validationResult := r.validator.ValidateVerifyTwoFactorInput(input, r.languageTagFromContext(ctx))
+	if validationResult.HasError {
+		r.addValidationErrors(ctx, validationResult)
+		return nil, internalErrors.ErrValidationError
+	}
+
+	challenge, err := r.twoFactorService.FindChallenge(input.ChallengeToken)
+	if stacktrace.GetCode(err) == twofactor.ErrCodeInvalidChallenge {
+		r.addError(ctx, fieldChallengeToken, "The challenge is invalid or has expired, please login again", CodeValidationError)
+		return nil, internalErrors.ErrValidationError
+	}
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, err)
+		return nil, internalErrors.ErrInternalServerError
+	}
+
+	userID, err := id.FromString(challenge.UserID)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "two factor challenge has an invalid user id"))
+		return nil, internalErrors.ErrInternalServerError
+	}
+
+	user, err := r.db.UserRepository().FindByID(ctx, userID)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "cannot find user with ID: %s", userID.String()))
+		return nil, internalErrors.ErrInternalServerError
+	}
+
+	lockedFor, err := r.loginLockout.LockedFor(user.ID.String())
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "cannot check lockout of user with ID: %s", user.ID.String()))
+	}
+	if lockedFor > 0 {
+		return nil, r.retryAfterError(CodeAccountLocked, "The account is locked after too many failed logins, please try again later", lockedFor)
+	}
+
+	isValid, err := r.verifySecondFactor(ctx, *user, input.Code)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, err)
+		return nil, internalErrors.ErrInternalServerError
+	}
+	if !isValid {
+		r.recordFailedLogin(ctx, *user)
+		r.addError(ctx, fieldCode, "The code is invalid or has expired", CodeValidationError)
+		return nil, internalErrors.ErrValidationError
+	}
+
+	err = r.twoFactorService.DeleteChallenge(input.ChallengeToken)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, err)
+		return nil, internalErrors.ErrInternalServerError
+	}
+
+	err = r.loginLockout.Reset(user.ID.String())
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "cannot reset lockout of user with ID: %s", user.ID.String()))
+	}
+
+	token, err := r.issueTokens(ctx, user.ID, challenge.RememberMe)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.AuthOutput{
+		User:  r.userToModel(user),
+		Token: token,
+	}, nil
+}
+
+func (r *mutationResolver) disableTwoFactor(ctx context.Context, input model.DisableTwoFactorInput) (bool, error) {
+	// check that the user is authorized
+	userID, err := r.sessionUserIDFromContext(ctx)
+	if err != nil {
+		return false, ErrUnauthorizedRequest
+	}
+
+	validationResult := r.validator.ValidateDisableTwoFactorInput(input, r.languageTagFromContext(ctx))
+	if validationResult.HasError {
+		r.addValidationErrors(ctx, validationResult)
+		return false, internalErrors.ErrValidationError
+	}
+
+	user, err := r.db.UserRepository().FindByID(ctx, userID)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "cannot find user with ID: %s", userID.String()))
+		return false, internalErrors.ErrInternalServerError
+	}
+
+	if !user.IsTwoFactorEnabled() {
+		return true, nil
+	}
+
+	if !r.passwordService.CheckPasswordHash(input.Password, user.Password) {
+		r.addError(ctx, fieldPassword, "The password is incorrect", CodeValidationError)
+		return false, internalErrors.ErrValidationError
+	}
+
+	isValid, err := r.verifySecondFactor(ctx, *user, input.Code)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, err)
+		return false, internalErrors.ErrInternalServerError
+	}
+	if !isValid {
+		r.addError(ctx, fieldCode, "The code is invalid or has expired", CodeValidationError)
+		return false, internalErrors.ErrValidationError
+	}
+
+	user.EncryptedTwoFactorSecret = ""
+	user.TwoFactorEnabledAt = nil
+	user.RecoveryCodeHashes = nil
+	user.UpdatedAt = time.Now().UTC()
+
+	err = r.db.UserRepository().UpdateTwoFactor(ctx, *user)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "cannot disable two factor authentication for user with ID: %s", userID.String()))
+		return false, internalErrors.ErrInternalServerError
+	}
+
+	err = r.mailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Two factor authentication has been disabled",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nTwo factor authentication was disabled for your account on %s UTC.\n\nIf this wasn't you, please reset your password and enable two factor authentication again.\n",
+			user.FirstName,
+			user.UpdatedAt.Format(internalTime.DefaultFormat),
+		),
+	})
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "cannot send two factor disabled email to user with ID: %s", userID.String()))
+	}
+
+	return true, nil
+}
+
+// issueTwoFactorChallenge is returned by login instead of tokens when the user has enabled two factor authentication
+func (r *Resolver) issueTwoFactorChallenge(ctx context.Context, user entities.User, rememberMe bool) (*model.AuthOutput, error) {
+	challengeToken, challenge, err := r.twoFactorService.IssueChallenge(user.ID, rememberMe)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "cannot issue two factor challenge for user with ID: %s", user.ID.String()))
+		return nil, internalErrors.ErrInternalServerError
+	}
+
+	return &model.AuthOutput{
+		TwoFactorChallenge: &model.TwoFactorChallenge{
+			Token:     challengeToken,
+			ExpiresAt: challenge.ExpiresAt.Format(internalTime.DefaultFormat),
+		},
+	}, nil
+}
+
+// verifySecondFactor accepts a code of the authenticator app or an unused recovery code
+func (r *Resolver) verifySecondFactor(ctx context.Context, user entities.User, code string) (bool, error) {
+	if totpCodeRegex.MatchString(code) {
+		isValid, err := r.twoFactorService.ValidateCode(user.ID, user.EncryptedTwoFactorSecret, code)
+		if err != nil {
+			return false, stacktrace.Propagate(err, "cannot validate two factor code of user with ID: %s", user.ID.String())
+		}
+
+		return isValid, nil
+	}
+
+	isValid, err := r.db.UserRepository().ConsumeRecoveryCode(ctx, user.ID, twofactor.HashRecoveryCode(code))
+	if err != nil {
+		return false, stacktrace.Propagate(err, "cannot consume recovery code of user with ID: %s", user.ID.String())
+	}
+
+	return isValid, nil
+}
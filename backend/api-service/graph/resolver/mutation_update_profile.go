@@ -0,0 +1,63 @@
+package resolver
+
+import (
+	"context"
+	"time"
+
+	internalErrors "github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/errors"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/graph/model"
+	"github.com/palantir/stacktrace"
+)
+
+func (r *mutationResolver) updateProfile(ctx context.Context, input model.UpdateProfileInput) (*model.User, error) {
+	// check that the user is authorized
+	userID, err := r.sessionUserIDFromContext(ctx)
+	if err != nil {
+		return nil, ErrUnauthorizedRequest
+	}
+
+	user, err := r.db.UserRepository().FindByID(ctx, userID)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "cannot find user with ID: %s", userID.String()))
+		return nil, internalErrors.ErrInternalServerError
+	}
+
+	validationResult := r.validator.ValidateUpdateProfileInput(input, user.Email, r.languageTagFromContext(ctx))
+	if validationResult.HasError {
+		r.addValidationErrors(ctx, validationResult)
+		return nil, internalErrors.ErrValidationError
+	}
+
+	emailHasChanged := input.Email != user.Email
+
+	user.FirstName = input.FirstName
+	user.LastName = input.LastName
+	user.Email = input.Email
+	user.UpdatedAt = time.Now().UTC()
+	if emailHasChanged {
+		user.EmailVerifiedAt = nil
+	}
+
+	err = r.db.UserRepository().UpdateProfile(ctx, *user)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "cannot update profile of user with ID: %s", userID.String()))
+		return nil, internalErrors.ErrInternalServerError
+	}
+
+	if emailHasChanged {
+		// the tokens which were sent to the old address must not verify the new address
+		err = r.db.EmailVerificationTokenRepository().DeleteForUser(ctx, user.ID)
+		if err != nil {
+			r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "cannot delete email verification tokens of user with ID: %s", userID.String()))
+			return nil, internalErrors.ErrInternalServerError
+		}
+
+		// the user can request a new verification email so a failed email doesn't fail the update
+		err = r.sendEmailVerification(ctx, *user)
+		if err != nil {
+			r.errorHandler.CaptureError(ctx, err)
+		}
+	}
+
+	return r.userToModel(user), nil
+}
@@ -0,0 +1,121 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/entities"
+	internalErrors "github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/errors"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/graph/model"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/services/mailer"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/services/token"
+	sharedErrors "github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/errors"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/id"
+	"github.com/palantir/stacktrace"
+)
+
+const (
+	emailVerificationTokenLifetime = 7 * 24 * time.Hour
+
+	// emailVerificationGracePeriod is how long a new user can analyze requests without verifying their email
+	emailVerificationGracePeriod = 24 * time.Hour
+)
+
+func (r *mutationResolver) verifyEmail(ctx context.Context, input model.VerifyEmailInput) (bool, error) {
+	validationResult := r.validator.ValidateVerifyEmailInput(input, r.languageTagFromContext(ctx))
+	if validationResult.HasError {
+		r.addValidationErrors(ctx, validationResult)
+		return false, internalErrors.ErrValidationError
+	}
+
+	verificationToken, err := r.db.EmailVerificationTokenRepository().Consume(ctx, token.Hash(input.Token), time.Now().UTC())
+	if err == sharedErrors.ErrEntityNotFound {
+		r.addError(ctx, fieldToken, "The email verification token is invalid or has expired", CodeValidationError)
+		return false, internalErrors.ErrValidationError
+	}
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "cannot consume email verification token"))
+		return false, internalErrors.ErrInternalServerError
+	}
+
+	// the token is only valid for the address it was sent to
+	err = r.db.UserRepository().MarkEmailAsVerified(ctx, verificationToken.UserID, verificationToken.Email, time.Now().UTC())
+	if err == sharedErrors.ErrEntityNotFound {
+		r.addError(ctx, fieldToken, "The email verification token is invalid or has expired", CodeValidationError)
+		return false, internalErrors.ErrValidationError
+	}
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "cannot verify email for user with ID: %s", verificationToken.UserID.String()))
+		return false, internalErrors.ErrInternalServerError
+	}
+
+	return true, nil
+}
+
+func (r *mutationResolver) resendVerificationEmail(ctx context.Context) (bool, error) {
+	// check that the user is authorized
+	userID, err := r.userIDFromContext(ctx)
+	if err != nil {
+		return false, ErrUnauthorizedRequest
+	}
+
+	user, err := r.db.UserRepository().FindByID(ctx, userID)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "cannot find user with ID: %s", userID.String()))
+		return false, internalErrors.ErrInternalServerError
+	}
+
+	if user.IsEmailVerified() {
+		return true, nil
+	}
+
+	err = r.sendEmailVerification(ctx, *user)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, err)
+		return false, internalErrors.ErrInternalServerError
+	}
+
+	return true, nil
+}
+
+// sendEmailVerification emails a link which verifies the email address of the user
+func (r *Resolver) sendEmailVerification(ctx context.Context, user entities.User) error {
+	plainToken, tokenHash, err := token.Generate()
+	if err != nil {
+		return stacktrace.Propagate(err, "cannot generate email verification token")
+	}
+
+	err = r.db.EmailVerificationTokenRepository().Store(ctx, entities.EmailVerificationToken{
+		ID:        id.New(),
+		UserID:    user.ID,
+		Email:     user.Email,
+		TokenHash: tokenHash,
+		ExpiresAt: time.Now().UTC().Add(emailVerificationTokenLifetime),
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return stacktrace.Propagate(err, "cannot store email verification token for user with ID: %s", user.ID.String())
+	}
+
+	err = r.mailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nUse the link below to verify your email address.\n\n%s/verify-email?token=%s\n\nIf you didn't create an account you can ignore this email.\n",
+			user.FirstName,
+			r.appURL,
+			plainToken,
+		),
+	})
+	if err != nil {
+		return stacktrace.Propagate(err, "cannot send verification email to user with ID: %s", user.ID.String())
+	}
+
+	return nil
+}
+
+// hasVerificationGracePeriodExpired checks if the user must verify their email before continuing
+func (r *Resolver) hasVerificationGracePeriodExpired(user entities.User) bool {
+	return !user.IsEmailVerified() && time.Now().UTC().After(user.EmailVerificationGraceStartedAt.Add(emailVerificationGracePeriod))
+}
@@ -0,0 +1,71 @@
+package resolver
+
+import (
+	"context"
+
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/entities"
+	internalErrors "github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/errors"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/graph/model"
+	internalTime "github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/time"
+	"github.com/palantir/stacktrace"
+)
+
+func (r *queryResolver) accessTokens(ctx context.Context) ([]*model.AccessToken, error) {
+	// check that the user is authorized
+	userID, err := r.userIDFromContext(ctx)
+	if err != nil {
+		return nil, ErrUnauthorizedRequest
+	}
+
+	accessTokens, err := r.db.AccessTokenRepository().FetchForUser(ctx, userID)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "cannot fetch access tokens of user with ID: %s", userID.String()))
+		return nil, internalErrors.ErrInternalServerError
+	}
+
+	results := make([]*model.AccessToken, len(accessTokens))
+	for index, accessToken := range accessTokens {
+		results[index] = r.accessTokenToModel(accessToken)
+	}
+
+	return results, nil
+}
+
+func (r *Resolver) accessTokenScopeFromModel(scope model.AccessTokenScope) entities.AccessTokenScope {
+	if scope == model.AccessTokenScopeWrite {
+		return entities.AccessTokenScopeWrite
+	}
+
+	return entities.AccessTokenScopeRead
+}
+
+func (r *Resolver) accessTokenToModel(accessToken entities.AccessToken) *model.AccessToken {
+	scopes := make([]model.AccessTokenScope, len(accessToken.Scopes))
+	for index, scope := range accessToken.Scopes {
+		scopes[index] = model.AccessTokenScopeRead
+		if scope == entities.AccessTokenScopeWrite {
+			scopes[index] = model.AccessTokenScopeWrite
+		}
+	}
+
+	var expiresAt *string
+	if accessToken.ExpiresAt != nil {
+		value := accessToken.ExpiresAt.Format(internalTime.DefaultFormat)
+		expiresAt = &value
+	}
+
+	var lastUsedAt *string
+	if accessToken.LastUsedAt != nil {
+		value := accessToken.LastUsedAt.Format(internalTime.DefaultFormat)
+		lastUsedAt = &value
+	}
+
+	return &model.AccessToken{
+		ID:         accessToken.ID.String(),
+		Name:       accessToken.Name,
+		Scopes:     scopes,
+		ExpiresAt:  expiresAt,
+		LastUsedAt: lastUsedAt,
+		CreatedAt:  accessToken.CreatedAt.Format(internalTime.DefaultFormat),
+	}
+}
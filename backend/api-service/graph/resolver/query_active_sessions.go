@@ -0,0 +1,47 @@
+package resolver
+
+import (
+	"context"
+
+	internalErrors "github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/errors"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/graph/model"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/services/session"
+	internalTime "github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/time"
+	"github.com/palantir/stacktrace"
+)
+
+func (r *queryResolver) activeSessions(ctx context.Context) ([]*model.Session, error) {
+	// check that the user is authorized
+	userID, err := r.userIDFromContext(ctx)
+	if err != nil {
+		return nil, ErrUnauthorizedRequest
+	}
+
+	sessions, err := r.sessionRegistry.ListForUser(userID)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "cannot fetch sessions of user with ID: %s", userID.String()))
+		return nil, internalErrors.ErrInternalServerError
+	}
+
+	currentSessionID := r.sessionIDFromContext(ctx)
+
+	results := make([]*model.Session, len(sessions))
+	for index, activeSession := range sessions {
+		results[index] = r.sessionToModel(activeSession, currentSessionID)
+	}
+
+	return results, nil
+}
+
+func (r *Resolver) sessionToModel(activeSession session.Session, currentSessionID string) *model.Session {
+	return &model.Session{
+		ID:         activeSession.ID,
+		Device:     activeSession.Device,
+		UserAgent:  activeSession.UserAgent,
+		IPAddress:  activeSession.IPAddress,
+		IsCurrent:  activeSession.ID == currentSessionID,
+		LastSeenAt: activeSession.LastSeenAt.Format(internalTime.DefaultFormat),
+		ExpiresAt:  activeSession.ExpiresAt.Format(internalTime.DefaultFormat),
+		CreatedAt:  activeSession.CreatedAt.Format(internalTime.DefaultFormat),
+	}
+}
@@ -2,44 +2,177 @@ package resolver
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/database"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/entities"
 	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/errors"
 	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/graph/model"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/id"
 	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/time"
 	"github.com/palantir/stacktrace"
 )
 
-func (r *queryResolver) analyzeRequests(ctx context.Context, skip *int, limit *int, orderBy *string, orderDirection *string) ([]*model.AnalyzeRequest, error) {
+const defaultAnalyzeRequestsPageSize = 20
+
+var analyzeRequestSortFields = map[model.AnalyzeRequestSortField]database.AnalyzeRequestSortField{
+	model.AnalyzeRequestSortFieldCreatedAt:         database.AnalyzeRequestSortFieldCreatedAt,
+	model.AnalyzeRequestSortFieldStartDate:         database.AnalyzeRequestSortFieldStartDate,
+	model.AnalyzeRequestSortFieldEndDate:           database.AnalyzeRequestSortFieldEndDate,
+	model.AnalyzeRequestSortFieldOvChipkaartNumber: database.AnalyzeRequestSortFieldOvChipkaartNumber,
+}
+
+// analyzeRequestCursor is encoded in the cursors of the analyze requests connection.
+// The order is part of the cursor because a cursor is only valid for the order it was created with.
+type analyzeRequestCursor struct {
+	Field      database.AnalyzeRequestSortField `json:"f"`
+	Descending bool                             `json:"d"`
+	Value      string                           `json:"v"`
+	ID         string                           `json:"i"`
+}
+
+func (r *queryResolver) analyzeRequests(ctx context.Context, filter *model.AnalyzeRequestsFilter, orderBy *model.AnalyzeRequestsOrder, first *int, after *string) (*model.AnalyzeRequestConnection, error) {
 	// check that the user is authorized
 	userID, err := r.userIDFromContext(ctx)
 	if err != nil {
 		return nil, ErrUnauthorizedRequest
 	}
 
-	validationResult := r.validator.ValidateAnalzyeRequestsInput(skip, limit, orderBy, orderDirection, r.languageTagFromContext(ctx))
+	validationResult := r.validator.ValidateAnalyzeRequestsInput(filter, first, r.languageTagFromContext(ctx))
 	if validationResult.HasError {
 		r.addValidationErrors(ctx, validationResult)
 		return nil, errors.ErrValidationError
 	}
 
-	dbResults, err := r.db.AnalyzeRequestRepository().IndexForUser(userID, skip, limit, orderBy, orderDirection)
+	order := database.AnalyzeRequestOrder{Field: database.AnalyzeRequestSortFieldCreatedAt, Descending: true}
+	if orderBy != nil {
+		order = database.AnalyzeRequestOrder{
+			Field:      analyzeRequestSortFields[orderBy.Field],
+			Descending: orderBy.Direction == model.SortDirectionDesc,
+		}
+	}
+
+	var cursor *database.AnalyzeRequestCursor
+	if after != nil {
+		cursor, err = r.decodeAnalyzeRequestCursor(*after, order)
+		if err != nil {
+			r.addError(ctx, fieldAfter, "The after cursor is invalid", CodeValidationError)
+			return nil, errors.ErrValidationError
+		}
+	}
+
+	pageSize := defaultAnalyzeRequestsPageSize
+	if first != nil {
+		pageSize = *first
+	}
+
+	dbFilter := r.analyzeRequestsFilterToDBFilter(filter)
+
+	// an extra analyze request is fetched to know if there is a next page
+	dbResults, err := r.db.AnalyzeRequestRepository().IndexForUser(ctx, userID, dbFilter, order, cursor, pageSize+1)
 	if err != nil {
 		r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "error handling the analzye requests query"))
 		return nil, errors.ErrInternalServerError
 	}
 
-	results := make([]*model.AnalyzeRequest, len(dbResults))
-	for index, dbResult := range dbResults {
-		results[index] = &model.AnalyzeRequest{
-			StartDate:         dbResult.StartDate.Format(time.DateFormat),
-			EndDate:           dbResult.EndDate.Format(time.DateFormat),
-			OvChipkaartNumber: dbResult.OvChipkaartNumber,
-			ID:                dbResult.ID.String(),
-			Status:            dbResult.Status.String(),
-			CreatedAt:         dbResult.CreatedAt.Format(time.DefaultFormat),
-			UpdatedAt:         dbResult.UpdatedAt.Format(time.DefaultFormat),
+	connection := &model.AnalyzeRequestConnection{
+		Edges:    make([]*model.AnalyzeRequestEdge, 0, len(dbResults)),
+		PageInfo: &model.PageInfo{HasNextPage: len(dbResults) > pageSize},
+	}
+	if connection.PageInfo.HasNextPage {
+		dbResults = dbResults[:pageSize]
+	}
+
+	if r.isFieldRequested(ctx, fieldTotalCount) {
+		totalCount, err := r.db.AnalyzeRequestRepository().CountForUser(ctx, userID, dbFilter)
+		if err != nil {
+			r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "error counting the analzye requests"))
+			return nil, errors.ErrInternalServerError
 		}
+		connection.TotalCount = int(totalCount)
+	}
+
+	for _, dbResult := range dbResults {
+		connection.Edges = append(connection.Edges, &model.AnalyzeRequestEdge{
+			Cursor: r.encodeAnalyzeRequestCursor(dbResult, order),
+			Node: &model.AnalyzeRequest{
+				StartDate:         dbResult.StartDate.Format(time.DateFormat),
+				EndDate:           dbResult.EndDate.Format(time.DateFormat),
+				OvChipkaartNumber: dbResult.OvChipkaartNumber,
+				ID:                dbResult.ID.String(),
+				Status:            dbResult.Status.String(),
+				CreatedAt:         dbResult.CreatedAt.Format(time.DefaultFormat),
+				UpdatedAt:         dbResult.UpdatedAt.Format(time.DefaultFormat),
+			},
+		})
+	}
+
+	if len(connection.Edges) > 0 {
+		connection.PageInfo.StartCursor = &connection.Edges[0].Cursor
+		connection.PageInfo.EndCursor = &connection.Edges[len(connection.Edges)-1].Cursor
+	}
+
+	return connection, nil
+}
+
+func (r *Resolver) analyzeRequestsFilterToDBFilter(filter *model.AnalyzeRequestsFilter) (dbFilter database.AnalyzeRequestFilter) {
+	if filter == nil {
+		return dbFilter
+	}
+
+	for _, status := range filter.Statuses {
+		dbFilter.Statuses = append(dbFilter.Statuses, entities.AnalyzeRequestStatus(status))
+	}
+
+	dbFilter.OvChipkaartNumber = filter.OvChipkaartNumber
+
+	// the dates have been validated
+	if filter.PeriodStart != nil {
+		periodStart, _ := time.FromDate(*filter.PeriodStart)
+		dbFilter.PeriodStart = &periodStart
+	}
+
+	if filter.PeriodEnd != nil {
+		periodEnd, _ := time.FromDate(*filter.PeriodEnd)
+		dbFilter.PeriodEnd = &periodEnd
+	}
+
+	return dbFilter
+}
+
+func (r *Resolver) encodeAnalyzeRequestCursor(analyzeRequest entities.AnalyzeRequest, order database.AnalyzeRequestOrder) string {
+	// marshalling a struct of strings and booleans cannot fail
+	value, _ := json.Marshal(analyzeRequestCursor{
+		Field:      order.Field,
+		Descending: order.Descending,
+		Value:      order.Field.Value(analyzeRequest),
+		ID:         analyzeRequest.ID.String(),
+	})
+
+	return base64.RawURLEncoding.EncodeToString(value)
+}
+
+func (r *Resolver) decodeAnalyzeRequestCursor(encoded string, order database.AnalyzeRequestOrder) (*database.AnalyzeRequestCursor, error) {
+	value, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "cursor is not base64 encoded")
+	}
+
+	var cursor analyzeRequestCursor
+	err = json.Unmarshal(value, &cursor)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "cursor is not valid json")
+	}
+
+	if cursor.Field != order.Field || cursor.Descending != order.Descending {
+		return nil, stacktrace.NewError("cursor was created for a different order")
+	}
+
+	analyzeRequestID, err := id.FromString(cursor.ID)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "cursor has an invalid id")
 	}
 
-	return results, nil
+	return &database.AnalyzeRequestCursor{Value: cursor.Value, ID: analyzeRequestID}, nil
 }
@@ -0,0 +1,28 @@
+package resolver
+
+import (
+	"context"
+
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/errors"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/graph/model"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/services/captcha"
+	"github.com/palantir/stacktrace"
+)
+
+func (r *queryResolver) captchaChallenge(ctx context.Context) (*model.CaptchaChallenge, error) {
+	issuer, ok := r.captchaVerifier.(captcha.ChallengeIssuer)
+	if !ok {
+		return nil, nil
+	}
+
+	challenge, err := issuer.IssueChallenge()
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "cannot issue captcha challenge"))
+		return nil, errors.ErrInternalServerError
+	}
+
+	return &model.CaptchaChallenge{
+		Challenge:  challenge.Value,
+		Difficulty: challenge.Difficulty,
+	}, nil
+}
@@ -0,0 +1,51 @@
+package resolver
+
+import (
+	"context"
+
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/errors"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/graph/model"
+	raw_records_service "github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/proto/raw-records-service"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/time"
+	"github.com/palantir/stacktrace"
+)
+
+func (r *queryResolver) cards(ctx context.Context) ([]*model.Card, error) {
+	// check that the user is authorized
+	userID, err := r.userIDFromContext(ctx)
+	if err != nil {
+		return nil, ErrUnauthorizedRequest
+	}
+
+	response, err := r.rawRecordsServiceClient.FetchCardSyncStates(ctx, &raw_records_service.FetchCardSyncStatesRequest{
+		UserId: userID.String(),
+	})
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "error fetching the card sync states"))
+		return nil, errors.ErrInternalServerError
+	}
+
+	results := make([]*model.Card, len(response.GetCards()))
+	for index, cardSyncState := range response.GetCards() {
+		results[index] = r.cardSyncStateToCard(cardSyncState)
+	}
+
+	return results, nil
+}
+
+func (r *Resolver) cardSyncStateToCard(cardSyncState *raw_records_service.CardSyncState) *model.Card {
+	var lastSyncedAt *string
+	if cardSyncState.GetLastSyncedAt() != nil {
+		value := cardSyncState.GetLastSyncedAt().AsTime().Format(time.DefaultFormat)
+		lastSyncedAt = &value
+	}
+
+	return &model.Card{
+		ID:                cardSyncState.GetId(),
+		OvChipkaartNumber: cardSyncState.GetCardNumber(),
+		Status:            cardSyncState.GetStatus(),
+		LastSyncedAt:      lastSyncedAt,
+		NextSyncAt:        cardSyncState.GetNextSyncAt().AsTime().Format(time.DefaultFormat),
+		CreatedAt:         cardSyncState.GetCreatedAt().AsTime().Format(time.DefaultFormat),
+	}
+}
@@ -0,0 +1,185 @@
+package resolver
+
+import (
+	"context"
+	"io"
+	internalTime "time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/errors"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/graph/model"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/id"
+	raw_records_service_v2 "github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/proto/raw-records-service/v2"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/time"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/types"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/palantir/stacktrace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultTransactionsPageSize = 20
+	fieldAfter                  = "after"
+	fieldTotalCount             = "totalCount"
+)
+
+var transactionKindNames = map[model.TransactionKind]types.TransactionName{
+	model.TransactionKindCheckIn:                  types.TransactionNameCheckIn,
+	model.TransactionKindCheckOut:                 types.TransactionNameCheckOut,
+	model.TransactionKindIntercityDirectSurcharge: types.TransactionNameIntercityDirectSurcharge,
+}
+
+func (r *queryResolver) transactions(ctx context.Context, filter *model.TransactionsFilter, first *int, after *string) (*model.TransactionConnection, error) {
+	// check that the user is authorized
+	userID, err := r.userIDFromContext(ctx)
+	if err != nil {
+		return nil, ErrUnauthorizedRequest
+	}
+
+	validationResult := r.validator.ValidateTransactionsInput(filter, first, r.languageTagFromContext(ctx))
+	if validationResult.HasError {
+		r.addValidationErrors(ctx, validationResult)
+		return nil, errors.ErrValidationError
+	}
+
+	request, err := r.transactionsFilterToListRequest(userID, filter)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "cannot create the list raw records request"))
+		return nil, errors.ErrInternalServerError
+	}
+
+	request.PageSize = defaultTransactionsPageSize
+	if first != nil {
+		request.PageSize = int32(*first)
+	}
+
+	if after != nil {
+		request.PageToken = *after
+	}
+
+	request.IncludeTotalCount = r.isFieldRequested(ctx, fieldTotalCount)
+
+	stream, err := r.rawRecordsServiceV2Client.ListRawRecords(ctx, request)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "cannot list the raw records"))
+		return nil, errors.ErrInternalServerError
+	}
+
+	connection := &model.TransactionConnection{
+		Edges:    []*model.TransactionEdge{},
+		PageInfo: &model.PageInfo{},
+	}
+	for {
+		response, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+
+		// the page token is only validated by the raw records service
+		if status.Code(err) == codes.InvalidArgument && after != nil {
+			r.addError(ctx, fieldAfter, "The after cursor is invalid", CodeValidationError)
+			return nil, errors.ErrValidationError
+		}
+
+		if err != nil {
+			r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "cannot receive the raw records"))
+			return nil, errors.ErrInternalServerError
+		}
+
+		for index, rawRecord := range response.GetRawRecords() {
+			connection.Edges = append(connection.Edges, &model.TransactionEdge{
+				Cursor: response.GetPageTokens()[index],
+				Node:   r.rawRecordToTransaction(rawRecord),
+			})
+		}
+
+		connection.PageInfo.HasNextPage = response.GetNextPageToken() != ""
+		connection.TotalCount = int(response.GetTotalCount())
+	}
+
+	if len(connection.Edges) > 0 {
+		connection.PageInfo.StartCursor = &connection.Edges[0].Cursor
+		connection.PageInfo.EndCursor = &connection.Edges[len(connection.Edges)-1].Cursor
+	}
+
+	return connection, nil
+}
+
+func (r *Resolver) transactionsFilterToListRequest(userID id.ID, filter *model.TransactionsFilter) (*raw_records_service_v2.ListRawRecordsRequest, error) {
+	request := &raw_records_service_v2.ListRawRecordsRequest{UserId: userID.String()}
+	if filter == nil {
+		return request, nil
+	}
+
+	if filter.StartDate != nil {
+		startDate, err := time.FromDate(*filter.StartDate)
+		if err != nil {
+			return nil, err
+		}
+
+		request.StartDateTime, err = ptypes.TimestampProto(startDate)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// the end date is inclusive for users while the raw records service excludes the end datetime
+	if filter.EndDate != nil {
+		endDate, err := time.FromDate(*filter.EndDate)
+		if err != nil {
+			return nil, err
+		}
+
+		request.EndDateTime, err = ptypes.TimestampProto(endDate.Add(24 * internalTime.Hour))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if filter.OvChipkaartNumber != nil {
+		request.CardNumber = *filter.OvChipkaartNumber
+	}
+
+	for _, kind := range filter.Kinds {
+		request.TransactionNames = append(request.TransactionNames, transactionKindNames[kind].String())
+	}
+
+	request.Ptos = filter.Operators
+	request.ModalTypes = filter.ModalTypes
+	request.Stations = filter.Stations
+
+	return request, nil
+}
+
+func (r *Resolver) rawRecordToTransaction(rawRecord *raw_records_service_v2.RawRecord) *model.Transaction {
+	var fare *float64
+	if rawRecord.GetFare() != nil {
+		value := rawRecord.GetFare().GetValue()
+		fare = &value
+	}
+
+	return &model.Transaction{
+		ID:                  rawRecord.GetId(),
+		OvChipkaartNumber:   rawRecord.GetCardNumber(),
+		TransactionDateTime: rawRecord.GetTransactionDateTime().AsTime().Format(time.DefaultFormat),
+		TransactionName:     rawRecord.GetTransactionName(),
+		Station:             rawRecord.GetTransactionInfo(),
+		Operator:            rawRecord.GetPto(),
+		ModalType:           rawRecord.GetModalType(),
+		Fare:                fare,
+		ProductText:         rawRecord.GetProductText(),
+		Source:              rawRecord.GetSource(),
+	}
+}
+
+// isFieldRequested checks if a field is selected on the object returned by the current resolver
+func (r *Resolver) isFieldRequested(ctx context.Context, name string) bool {
+	for _, field := range graphql.CollectFieldsCtx(ctx, nil) {
+		if field.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,30 @@
+package resolver
+
+import (
+	"context"
+
+	internalErrors "github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/errors"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/graph/model"
+	sharedErrors "github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/errors"
+	"github.com/palantir/stacktrace"
+)
+
+func (r *queryResolver) user(ctx context.Context) (*model.User, error) {
+	// check that the user is authorized
+	userID, err := r.userIDFromContext(ctx)
+	if err != nil {
+		return nil, ErrUnauthorizedRequest
+	}
+
+	user, err := r.db.UserRepository().FindByID(ctx, userID)
+	if err == sharedErrors.ErrEntityNotFound {
+		// the account was deleted while the token was still valid
+		return nil, ErrUnauthorizedRequest
+	}
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "cannot find user with ID: %s", userID.String()))
+		return nil, internalErrors.ErrInternalServerError
+	}
+
+	return r.userToModel(user), nil
+}
@@ -0,0 +1,63 @@
+package resolver
+
+import (
+	"context"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/services/ratelimit"
+	"github.com/palantir/stacktrace"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+const (
+	// CodeRateLimited is the code that is returned when a client made too many attempts
+	CodeRateLimited = "RATE_LIMITED"
+
+	// CodeAccountLocked is the code that is returned when an account is locked after too many failed logins
+	CodeAccountLocked = "ACCOUNT_LOCKED"
+)
+
+var (
+	loginIPRateLimit         = ratelimit.Rule{Name: "login_ip", Limit: 20, Window: 15 * time.Minute}
+	loginEmailRateLimit      = ratelimit.Rule{Name: "login_email", Limit: 10, Window: 15 * time.Minute}
+	createUserIPRateLimit    = ratelimit.Rule{Name: "create_user_ip", Limit: 5, Window: time.Hour}
+	createUserEmailRateLimit = ratelimit.Rule{Name: "create_user_email", Limit: 3, Window: time.Hour}
+)
+
+// checkRateLimits records an attempt of the client for the IP and email rules.
+// Rate limits fail open so an unavailable cache doesn't prevent users from logging in.
+func (r *Resolver) checkRateLimits(ctx context.Context, ipRule ratelimit.Rule, emailRule ratelimit.Rule, email string) error {
+	err := r.checkRateLimit(ctx, ipRule, r.clientIPFromContext(ctx))
+	if err != nil {
+		return err
+	}
+
+	return r.checkRateLimit(ctx, emailRule, strings.ToLower(strings.TrimSpace(email)))
+}
+
+func (r *Resolver) checkRateLimit(ctx context.Context, rule ratelimit.Rule, key string) error {
+	allowed, retryAfter, err := r.rateLimiter.Allow(rule, key)
+	if err != nil {
+		r.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "cannot check rate limit %s", rule.Name))
+		return nil
+	}
+
+	if allowed {
+		return nil
+	}
+
+	return r.retryAfterError(CodeRateLimited, "Too many attempts, please try again later", retryAfter)
+}
+
+// retryAfterError tells the client how many seconds to wait before trying again
+func (r *Resolver) retryAfterError(code string, message string, retryAfter time.Duration) error {
+	return &gqlerror.Error{
+		Message: message,
+		Extensions: map[string]interface{}{
+			"code":       code,
+			"retryAfter": int(math.Ceil(retryAfter.Seconds())),
+		},
+	}
+}
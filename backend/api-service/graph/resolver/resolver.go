@@ -3,6 +3,7 @@ package resolver
 import (
 	"context"
 	raw_records_service "github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/proto/raw-records-service"
+	raw_records_service_v2 "github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/proto/raw-records-service/v2"
 
 	internalErrors "github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/errors"
 
@@ -14,12 +15,23 @@ import (
 
 	"github.com/99designs/gqlgen/graphql"
 	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/database"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/entities"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/graph/model"
 	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/graph/validator"
 	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/middlewares"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/services/accesstoken"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/services/captcha"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/services/dataexport"
 	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/services/jwt"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/services/mailer"
 	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/services/password"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/services/ratelimit"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/services/refreshtoken"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/services/session"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/services/twofactor"
 	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/errorhandler"
 	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/logger"
+	internalTime "github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/time"
 	"github.com/pkg/errors"
 	"github.com/vektah/gqlparser/v2/ast"
 	"github.com/vektah/gqlparser/v2/gqlerror"
@@ -48,8 +60,19 @@ type Resolver struct {
 	errorHandler              errorhandler.ErrorHandler
 	logger                    logger.Logger
 	jwtService                jwt.Service
+	refreshTokenService       refreshtoken.Service
 	transactionsServiceClient transactions_service.TransactionsServiceClient
 	rawRecordsServiceClient raw_records_service.RawRecordsServiceClient
+	rawRecordsServiceV2Client raw_records_service_v2.RawRecordsServiceClient
+	mailer                    mailer.Mailer
+	captchaVerifier           captcha.Verifier
+	dataExporter              dataexport.Exporter
+	rateLimiter               ratelimit.Limiter
+	loginLockout              ratelimit.Lockout
+	twoFactorService          twofactor.Service
+	accessTokenService        accesstoken.Service
+	sessionRegistry           session.Registry
+	appURL                    string
 }
 
 // NewResolver creates a new instance of the resolver
@@ -60,8 +83,19 @@ func NewResolver(
 	errorHandler errorhandler.ErrorHandler,
 	logger logger.Logger,
 	jwtService jwt.Service,
+	refreshTokenService refreshtoken.Service,
 	transactionsServiceClient transactions_service.TransactionsServiceClient,
 	rawRecordsServiceClient raw_records_service.RawRecordsServiceClient,
+	rawRecordsServiceV2Client raw_records_service_v2.RawRecordsServiceClient,
+	mailer mailer.Mailer,
+	captchaVerifier captcha.Verifier,
+	dataExporter dataexport.Exporter,
+	rateLimiter ratelimit.Limiter,
+	loginLockout ratelimit.Lockout,
+	twoFactorService twofactor.Service,
+	accessTokenService accesstoken.Service,
+	sessionRegistry session.Registry,
+	appURL string,
 ) *Resolver {
 	return &Resolver{
 		db:                        db,
@@ -70,8 +104,19 @@ func NewResolver(
 		errorHandler:              errorHandler,
 		logger:                    logger,
 		jwtService:                jwtService,
+		refreshTokenService:       refreshTokenService,
 		transactionsServiceClient: transactionsServiceClient,
 		rawRecordsServiceClient:rawRecordsServiceClient,
+		rawRecordsServiceV2Client: rawRecordsServiceV2Client,
+		mailer:                    mailer,
+		captchaVerifier:           captchaVerifier,
+		dataExporter:              dataExporter,
+		rateLimiter:               rateLimiter,
+		loginLockout:              loginLockout,
+		twoFactorService:          twoFactorService,
+		accessTokenService:        accessTokenService,
+		sessionRegistry:           sessionRegistry,
+		appURL:                    appURL,
 	}
 }
 
@@ -103,6 +148,39 @@ func (r *Resolver) userIDFromContext(ctx context.Context) (userID id.ID, err err
 	return userID, err
 }
 
+// sessionUserIDFromContext returns the id of a user who signed in, personal access tokens cannot manage the account
+func (r *Resolver) sessionUserIDFromContext(ctx context.Context) (userID id.ID, err error) {
+	if _, ok := ctx.Value(middlewares.ContextKeyAccessToken).(*entities.AccessToken); ok {
+		return userID, ErrUnauthorizedRequest
+	}
+
+	return r.userIDFromContext(ctx)
+}
+
+func (r *Resolver) clientIPFromContext(ctx context.Context) string {
+	clientIP, ok := ctx.Value(middlewares.ContextKeyClientIP).(string)
+	if !ok {
+		r.errorHandler.CaptureError(ctx, stacktrace.NewError("cannot fetch client ip from context"))
+	}
+
+	return clientIP
+}
+
+func (r *Resolver) userAgentFromContext(ctx context.Context) string {
+	userAgent, ok := ctx.Value(middlewares.ContextKeyUserAgent).(string)
+	if !ok {
+		r.errorHandler.CaptureError(ctx, stacktrace.NewError("cannot fetch user agent from context"))
+	}
+
+	return userAgent
+}
+
+// sessionIDFromContext returns an empty string when the request was not authenticated with a JWT
+func (r *Resolver) sessionIDFromContext(ctx context.Context) string {
+	sessionID, _ := ctx.Value(middlewares.ContextKeySessionID).(string)
+	return sessionID
+}
+
 func (r *Resolver) addValidationErrors(ctx context.Context, result validator.ValidationResult) {
 	for field, fieldErrors := range result.Errors {
 		for _, err := range fieldErrors {
@@ -120,3 +198,22 @@ func (r *Resolver) addError(ctx context.Context, pathName string, err string, co
 		},
 	})
 }
+
+func (r *Resolver) userToModel(user *entities.User) *model.User {
+	var emailVerifiedAt *string
+	if user.EmailVerifiedAt != nil {
+		value := user.EmailVerifiedAt.Format(internalTime.DefaultFormat)
+		emailVerifiedAt = &value
+	}
+
+	return &model.User{
+		ID:               user.ID.String(),
+		FirstName:        user.FirstName,
+		LastName:         user.LastName,
+		Email:            user.Email,
+		EmailVerifiedAt:  emailVerifiedAt,
+		TwoFactorEnabled: user.IsTwoFactorEnabled(),
+		CreatedAt:        user.CreatedAt.Format(internalTime.DefaultFormat),
+		UpdatedAt:        user.UpdatedAt.Format(internalTime.DefaultFormat),
+	}
+}
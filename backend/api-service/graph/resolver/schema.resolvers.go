@@ -23,20 +23,108 @@ func (r *mutationResolver) CancelToken(ctx context.Context) (bool, error) {
 	return r.cancelToken(ctx)
 }
 
-func (r *mutationResolver) RefreshToken(ctx context.Context, input model.RefreshTokenInput) (string, error) {
-	panic(fmt.Errorf("not implemented"))
+func (r *mutationResolver) RefreshToken(ctx context.Context, input model.RefreshTokenInput) (*model.Token, error) {
+	return r.refreshToken(ctx, input)
 }
 
 func (r *mutationResolver) StoreAnalyzeRequest(ctx context.Context, input model.StoreAnalyzeRequestInput) (bool, error) {
 	return r.storeAnalyzeRequest(ctx, input)
 }
 
+func (r *mutationResolver) RegisterCard(ctx context.Context, input model.RegisterCardInput) (*model.Card, error) {
+	return r.registerCard(ctx, input)
+}
+
+func (r *mutationResolver) RequestPasswordReset(ctx context.Context, input model.RequestPasswordResetInput) (bool, error) {
+	return r.requestPasswordReset(ctx, input)
+}
+
+func (r *mutationResolver) ResetPassword(ctx context.Context, input model.ResetPasswordInput) (bool, error) {
+	return r.resetPassword(ctx, input)
+}
+
+func (r *mutationResolver) VerifyEmail(ctx context.Context, input model.VerifyEmailInput) (bool, error) {
+	return r.verifyEmail(ctx, input)
+}
+
+func (r *mutationResolver) ResendVerificationEmail(ctx context.Context) (bool, error) {
+	return r.resendVerificationEmail(ctx)
+}
+
+func (r *mutationResolver) UpdateProfile(ctx context.Context, input model.UpdateProfileInput) (*model.User, error) {
+	return r.updateProfile(ctx, input)
+}
+
+func (r *mutationResolver) ChangePassword(ctx context.Context, input model.ChangePasswordInput) (*model.Token, error) {
+	return r.changePassword(ctx, input)
+}
+
+func (r *mutationResolver) DeleteAccount(ctx context.Context, input model.DeleteAccountInput) (bool, error) {
+	return r.deleteAccount(ctx, input)
+}
+
+func (r *mutationResolver) RequestDataExport(ctx context.Context) (*model.DataExport, error) {
+	return r.requestDataExport(ctx)
+}
+
+func (r *mutationResolver) EnableTwoFactor(ctx context.Context) (*model.TwoFactorEnrolment, error) {
+	return r.enableTwoFactor(ctx)
+}
+
+func (r *mutationResolver) ConfirmTwoFactor(ctx context.Context, input model.ConfirmTwoFactorInput) ([]string, error) {
+	return r.confirmTwoFactor(ctx, input)
+}
+
+func (r *mutationResolver) VerifyTwoFactor(ctx context.Context, input model.VerifyTwoFactorInput) (*model.AuthOutput, error) {
+	return r.verifyTwoFactor(ctx, input)
+}
+
+func (r *mutationResolver) DisableTwoFactor(ctx context.Context, input model.DisableTwoFactorInput) (bool, error) {
+	return r.disableTwoFactor(ctx, input)
+}
+
+func (r *mutationResolver) CreateAccessToken(ctx context.Context, input model.CreateAccessTokenInput) (*model.CreateAccessTokenOutput, error) {
+	return r.createAccessToken(ctx, input)
+}
+
+func (r *mutationResolver) RevokeAccessToken(ctx context.Context, input model.RevokeAccessTokenInput) (bool, error) {
+	return r.revokeAccessToken(ctx, input)
+}
+
+func (r *mutationResolver) RevokeSession(ctx context.Context, input model.RevokeSessionInput) (bool, error) {
+	return r.revokeSession(ctx, input)
+}
+
+func (r *mutationResolver) RevokeAllOtherSessions(ctx context.Context) (bool, error) {
+	return r.revokeAllOtherSessions(ctx)
+}
+
 func (r *queryResolver) User(ctx context.Context) (*model.User, error) {
-	return &model.User{}, nil
+	return r.user(ctx)
+}
+
+func (r *queryResolver) AnalyzeRequests(ctx context.Context, filter *model.AnalyzeRequestsFilter, orderBy *model.AnalyzeRequestsOrder, first *int, after *string) (*model.AnalyzeRequestConnection, error) {
+	return r.analyzeRequests(ctx, filter, orderBy, first, after)
+}
+
+func (r *queryResolver) Cards(ctx context.Context) ([]*model.Card, error) {
+	return r.cards(ctx)
+}
+
+func (r *queryResolver) Transactions(ctx context.Context, filter *model.TransactionsFilter, first *int, after *string) (*model.TransactionConnection, error) {
+	return r.transactions(ctx, filter, first, after)
+}
+
+func (r *queryResolver) CaptchaChallenge(ctx context.Context) (*model.CaptchaChallenge, error) {
+	return r.captchaChallenge(ctx)
+}
+
+func (r *queryResolver) AccessTokens(ctx context.Context) ([]*model.AccessToken, error) {
+	return r.accessTokens(ctx)
 }
 
-func (r *queryResolver) AnalyzeRequests(ctx context.Context, skip *int, take *int, orderBy *string, orderDirection *string) ([]*model.AnalyzeRequest, error) {
-	return r.analyzeRequests(ctx, skip, take, orderBy, orderDirection)
+func (r *queryResolver) ActiveSessions(ctx context.Context) ([]*model.Session, error) {
+	return r.activeSessions(ctx)
 }
 
 // Mutation returns generated.MutationResolver implementation.
@@ -51,9 +139,9 @@ type queryResolver struct{ *Resolver }
 // !!! WARNING !!!
 // The code below was going to be deleted when updating resolvers. It has been copied here so you have
 // one last chance to move it out of harms way if you want. There are two reasons this happens:
-//  - When renaming or deleting a resolver the old code will be put in here. You can safely delete
-//    it when you're done.
-//  - You have helper methods in this file. Move them out to keep these resolver files clean.
+//   - When renaming or deleting a resolver the old code will be put in here. You can safely delete
+//     it when you're done.
+//   - You have helper methods in this file. Move them out to keep these resolver files clean.
 func (r *mutationResolver) StoreRequest(ctx context.Context, input *model.StoreAnalyzeRequestInput) (bool, error) {
 	panic(fmt.Errorf("not implemented"))
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/url"
+	"regexp"
 	internalTime "time"
 
 	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/ovchipkaart"
@@ -13,6 +14,7 @@ import (
 	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/database"
 	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/graph/model"
 	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/graph/validator"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/services/captcha"
 	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/errorhandler"
 	"github.com/palantir/stacktrace"
 	"github.com/pkg/errors"
@@ -22,18 +24,27 @@ import (
 
 const (
 	ruleUserEmailIsUnique = "user_email_is_unique"
+
+	maxPageSize = 100
+
+	fieldReCaptcha = "reCaptcha"
+)
+
+var (
+	ovChipkaartNumberRegex = regexp.MustCompile(`^[0-9]{16}$`)
 )
 
 // GoValidator is a validator using the govalidator package
 type GoValidator struct {
-	db           database.DB
-	helpers      validator.Helpers
-	errorHandler errorhandler.ErrorHandler
+	db              database.DB
+	helpers         validator.Helpers
+	errorHandler    errorhandler.ErrorHandler
+	captchaVerifier captcha.Verifier
 }
 
 // New creates a new go validator
-func New(db database.DB, helpers validator.Helpers, errorHandler errorhandler.ErrorHandler) validator.Validator {
-	service := &GoValidator{db, helpers, errorHandler}
+func New(db database.DB, helpers validator.Helpers, errorHandler errorhandler.ErrorHandler, captchaVerifier captcha.Verifier) validator.Validator {
+	service := &GoValidator{db, helpers, errorHandler, captchaVerifier}
 	service.init()
 
 	return service
@@ -52,7 +63,7 @@ func (service GoValidator) ValidateCreateUserInput(input model.CreateUserInput,
 		},
 	})
 
-	return service.urlValuesToResult(v.ValidateStruct())
+	return service.urlValuesToResult(service.validateCaptcha(v.ValidateStruct(), input.ReCaptcha, captcha.ActionCreateUser))
 }
 
 // ValidateLoginInput validates the login input object
@@ -67,11 +78,11 @@ func (service GoValidator) ValidateLoginInput(input model.LoginInput, _ language
 		},
 	})
 
-	return service.urlValuesToResult(v.ValidateStruct())
+	return service.urlValuesToResult(service.validateCaptcha(v.ValidateStruct(), input.ReCaptcha, captcha.ActionLogin))
 }
 
 // ValidateStoreAnalzyeRequest validates the store analyze request input
-func (service GoValidator) ValidateStoreAnalzyeRequest(input model.StoreAnalyzeRequestInput, _ language.Tag) validator.ValidationResult {
+func (service GoValidator) ValidateStoreAnalzyeRequest(ctx context.Context, input model.StoreAnalyzeRequestInput, _ language.Tag) validator.ValidationResult {
 	v := govalidator.New(govalidator.Options{
 		Data: &input,
 		Rules: govalidator.MapData{
@@ -118,9 +129,9 @@ func (service GoValidator) ValidateStoreAnalzyeRequest(input model.StoreAnalyzeR
 	}
 
 	if input.OvChipkaartPassword != nil || input.OvChipkaartUsername != nil {
-		err := service.helpers.ValidateOvChipkaartCredentials(*input.OvChipkaartUsername, *input.OvChipkaartPassword)
+		err := service.helpers.ValidateOvChipkaartCredentials(ctx, *input.OvChipkaartUsername, *input.OvChipkaartPassword)
 		if err != nil {
-			service.errorHandler.CaptureError(context.Background(), err)
+			service.errorHandler.CaptureError(ctx, err)
 			if stacktrace.GetCode(err) == ovchipkaart.ErrCodeUnauthorized {
 				values.Add("ovChipkaartUsername", "Invalid OV Chipkaart username or password")
 				values.Add("ovChipkaartPassword", "Invalid OV Chipkaart username or password")
@@ -134,30 +145,282 @@ func (service GoValidator) ValidateStoreAnalzyeRequest(input model.StoreAnalyzeR
 	return service.urlValuesToResult(values)
 }
 
-// ValidateAnalzyeRequestsInput validates the analyze request query inputs
-func (service GoValidator) ValidateAnalzyeRequestsInput(skip *int, take *int, orderBy *string, orderDirection *string, _ language.Tag) validator.ValidationResult {
-	input := struct {
-		skip, take              *int
-		orderBy, orderDirection *string
-	}{
-		skip,
-		take,
-		orderBy,
-		orderDirection,
+// ValidateAnalyzeRequestsInput validates the analyze requests query inputs
+func (service GoValidator) ValidateAnalyzeRequestsInput(filter *model.AnalyzeRequestsFilter, first *int, _ language.Tag) validator.ValidationResult {
+	values := url.Values{}
+	service.validatePageSize(values, first)
+
+	if filter == nil {
+		return service.urlValuesToResult(values)
 	}
 
+	if filter.OvChipkaartNumber != nil && !ovChipkaartNumberRegex.MatchString(*filter.OvChipkaartNumber) {
+		values.Add("ovChipkaartNumber", "The ov chipkaart number must be 16 digits")
+	}
+
+	service.validatePeriod(values, "periodStart", filter.PeriodStart, "periodEnd", filter.PeriodEnd)
+
+	return service.urlValuesToResult(values)
+}
+
+// ValidateRegisterCardInput validates the register card input
+func (service GoValidator) ValidateRegisterCardInput(ctx context.Context, input model.RegisterCardInput, _ language.Tag) validator.ValidationResult {
+	v := govalidator.New(govalidator.Options{
+		Data: &input,
+		Rules: govalidator.MapData{
+			"ovChipkaartUsername": []string{"required"},
+			"ovChipkaartPassword": []string{"required"},
+			"ovChipkaartNumber":   []string{"required", "min:16", "max:16", "numeric"},
+		},
+	})
+
+	values := v.ValidateStruct()
+	if len(values) > 0 {
+		return service.urlValuesToResult(values)
+	}
+
+	err := service.helpers.ValidateOvChipkaartCredentials(ctx, input.OvChipkaartUsername, input.OvChipkaartPassword)
+	if err != nil {
+		service.errorHandler.CaptureError(ctx, err)
+		if stacktrace.GetCode(err) == ovchipkaart.ErrCodeUnauthorized {
+			values.Add("ovChipkaartUsername", "Invalid OV Chipkaart username or password")
+			values.Add("ovChipkaartPassword", "Invalid OV Chipkaart username or password")
+		} else {
+			values.Add("ovChipkaartUsername", "Internal error while verifying your ov chipkaart username")
+			values.Add("ovChipkaartPassword", "Internal error while verifying your ov chipkaart password")
+		}
+	}
+
+	return service.urlValuesToResult(values)
+}
+
+// ValidateRequestPasswordResetInput validates the request password reset input
+func (service GoValidator) ValidateRequestPasswordResetInput(input model.RequestPasswordResetInput, _ language.Tag) validator.ValidationResult {
+	v := govalidator.New(govalidator.Options{
+		Data: &input,
+		Rules: govalidator.MapData{
+			"email":     []string{"required", "email"},
+			"reCaptcha": []string{"required"},
+		},
+	})
+
+	return service.urlValuesToResult(service.validateCaptcha(v.ValidateStruct(), input.ReCaptcha, captcha.ActionRequestPasswordReset))
+}
+
+// ValidateResetPasswordInput validates the reset password input
+func (service GoValidator) ValidateResetPasswordInput(input model.ResetPasswordInput, _ language.Tag) validator.ValidationResult {
+	v := govalidator.New(govalidator.Options{
+		Data: &input,
+		Rules: govalidator.MapData{
+			"token":    []string{"required"},
+			"password": []string{"required", "min:8"},
+		},
+	})
+
+	return service.urlValuesToResult(v.ValidateStruct())
+}
+
+// ValidateVerifyEmailInput validates the verify email input
+func (service GoValidator) ValidateVerifyEmailInput(input model.VerifyEmailInput, _ language.Tag) validator.ValidationResult {
+	v := govalidator.New(govalidator.Options{
+		Data: &input,
+		Rules: govalidator.MapData{
+			"token": []string{"required"},
+		},
+	})
+
+	return service.urlValuesToResult(v.ValidateStruct())
+}
+
+// ValidateTransactionsInput validates the transactions query inputs
+func (service GoValidator) ValidateTransactionsInput(filter *model.TransactionsFilter, first *int, _ language.Tag) validator.ValidationResult {
+	values := url.Values{}
+	service.validatePageSize(values, first)
+
+	if filter == nil {
+		return service.urlValuesToResult(values)
+	}
+
+	service.validatePeriod(values, "startDate", filter.StartDate, "endDate", filter.EndDate)
+
+	for _, kind := range filter.Kinds {
+		if !kind.IsValid() {
+			values.Add("kinds", fmt.Sprintf("%s is not a valid transaction kind", kind))
+		}
+	}
+
+	return service.urlValuesToResult(values)
+}
+
+// ValidateUpdateProfileInput validates the update profile input. The email must be unique unless it is the current email of the user.
+func (service GoValidator) ValidateUpdateProfileInput(input model.UpdateProfileInput, currentEmail string, _ language.Tag) validator.ValidationResult {
+	emailRules := []string{"required", "email"}
+	if input.Email != currentEmail {
+		emailRules = append(emailRules, ruleUserEmailIsUnique)
+	}
+
+	v := govalidator.New(govalidator.Options{
+		Data: &input,
+		Rules: govalidator.MapData{
+			"firstName": []string{"required", "min:1", "max:50"},
+			"lastName":  []string{"required", "min:1", "max:50"},
+			"email":     emailRules,
+		},
+	})
+
+	return service.urlValuesToResult(v.ValidateStruct())
+}
+
+// ValidateChangePasswordInput validates the change password input
+func (service GoValidator) ValidateChangePasswordInput(input model.ChangePasswordInput, _ language.Tag) validator.ValidationResult {
+	v := govalidator.New(govalidator.Options{
+		Data: &input,
+		Rules: govalidator.MapData{
+			"currentPassword": []string{"required"},
+			"newPassword":     []string{"required", "min:8"},
+		},
+	})
+
+	return service.urlValuesToResult(v.ValidateStruct())
+}
+
+// ValidateDeleteAccountInput validates the delete account input
+func (service GoValidator) ValidateDeleteAccountInput(input model.DeleteAccountInput, _ language.Tag) validator.ValidationResult {
 	v := govalidator.New(govalidator.Options{
 		Data: &input,
 		Rules: govalidator.MapData{
-			"skip":           []string{"numeric", "min:0"},
-			"take":           []string{"numeric", "min:0"},
-			"orderBy":        []string{"in:createdAt,ovChipkaartNumber,startDate,EndDate"},
-			"orderDirection": []string{"in:DESC,desc,ASC,asc"},
+			"password": []string{"required"},
 		},
 	})
 
 	return service.urlValuesToResult(v.ValidateStruct())
+}
+
+// ValidateConfirmTwoFactorInput validates the confirm two factor input
+func (service GoValidator) ValidateConfirmTwoFactorInput(input model.ConfirmTwoFactorInput, _ language.Tag) validator.ValidationResult {
+	v := govalidator.New(govalidator.Options{
+		Data: &input,
+		Rules: govalidator.MapData{
+			"code": []string{"required", "digits:6"},
+		},
+	})
+
+	return service.urlValuesToResult(v.ValidateStruct())
+}
 
+// ValidateVerifyTwoFactorInput validates the verify two factor input
+func (service GoValidator) ValidateVerifyTwoFactorInput(input model.VerifyTwoFactorInput, _ language.Tag) validator.ValidationResult {
+	v := govalidator.New(govalidator.Options{
+		Data: &input,
+		Rules: govalidator.MapData{
+			"challengeToken": []string{"required"},
+			"code":           []string{"required"},
+		},
+	})
+
+	return service.urlValuesToResult(v.ValidateStruct())
+}
+
+// ValidateDisableTwoFactorInput validates the disable two factor input
+func (service GoValidator) ValidateDisableTwoFactorInput(input model.DisableTwoFactorInput, _ language.Tag) validator.ValidationResult {
+	v := govalidator.New(govalidator.Options{
+		Data: &input,
+		Rules: govalidator.MapData{
+			"password": []string{"required"},
+			"code":     []string{"required"},
+		},
+	})
+
+	return service.urlValuesToResult(v.ValidateStruct())
+}
+
+// ValidateCreateAccessTokenInput validates the create access token input. The expiry date must be in the future.
+func (service GoValidator) ValidateCreateAccessTokenInput(input model.CreateAccessTokenInput, _ language.Tag) validator.ValidationResult {
+	v := govalidator.New(govalidator.Options{
+		Data: &input,
+		Rules: govalidator.MapData{
+			"name": []string{"required", "min:1", "max:100"},
+		},
+	})
+
+	values := v.ValidateStruct()
+
+	if len(input.Scopes) == 0 {
+		values.Add("scopes", "The access token must have at least one scope")
+	}
+
+	for _, scope := range input.Scopes {
+		if !scope.IsValid() {
+			values.Add("scopes", fmt.Sprintf("%s is not a valid access token scope", scope))
+		}
+	}
+
+	if input.ExpiresAt != nil {
+		expiresAt, err := time.FromDate(*input.ExpiresAt)
+		if err != nil {
+			values.Add("expiresAt", "The expiry date must be a date in the format yyyy-mm-dd")
+		} else if expiresAt.Before(internalTime.Now().UTC().Truncate(24 * internalTime.Hour)) {
+			values.Add("expiresAt", "The expiry date must not be in the past")
+		}
+	}
+
+	return service.urlValuesToResult(values)
+}
+
+// validateCaptcha verifies the captcha response so that bots cannot submit forms in bulk
+func (service GoValidator) validateCaptcha(values url.Values, response string, action string) url.Values {
+	if response == "" {
+		return values
+	}
+
+	err := service.captchaVerifier.Verify(response, action)
+	if err == nil {
+		return values
+	}
+
+	if stacktrace.GetCode(err) == captcha.ErrCodeInvalidCaptcha {
+		values.Add(fieldReCaptcha, "The captcha is invalid, please try again")
+		return values
+	}
+
+	service.errorHandler.CaptureError(context.Background(), stacktrace.Propagate(err, "cannot verify the captcha"))
+	values.Add(fieldReCaptcha, "Internal error while verifying the captcha")
+
+	return values
+}
+
+func (service GoValidator) validatePageSize(values url.Values, first *int) {
+	if first != nil && (*first < 1 || *first > maxPageSize) {
+		values.Add("first", fmt.Sprintf("The first field must be between 1 and %d", maxPageSize))
+	}
+}
+
+// validatePeriod checks that optional start and end dates are formatted as yyyy-mm-dd and in the right order
+func (service GoValidator) validatePeriod(values url.Values, startField string, start *string, endField string, end *string) {
+	var startDate, endDate internalTime.Time
+	var err error
+	hasError := false
+
+	if start != nil {
+		startDate, err = time.FromDate(*start)
+		if err != nil {
+			hasError = true
+			values.Add(startField, "The start date must be a date in the format yyyy-mm-dd")
+		}
+	}
+
+	if end != nil {
+		endDate, err = time.FromDate(*end)
+		if err != nil {
+			hasError = true
+			values.Add(endField, "The end date must be a date in the format yyyy-mm-dd")
+		}
+	}
+
+	if !hasError && start != nil && end != nil && startDate.After(endDate) {
+		values.Add(startField, "The start date must be before the end date")
+		values.Add(endField, "The end date must be after the start date")
+	}
 }
 
 func (service GoValidator) urlValuesToResult(value url.Values) validator.ValidationResult {
@@ -175,13 +438,15 @@ func (service GoValidator) ruleUserEmailExists() func(field string, rule string,
 	return func(field string, rule string, message string, value interface{}) error {
 		email := value.(string)
 
-		_, err := service.db.UserRepository().FindByEmail(email)
+		// custom rules are registered globally so they don't have access to the request context
+		ctx := context.Background()
+		_, err := service.db.UserRepository().FindByEmail(ctx, email)
 		if err == internalErrors.ErrEntityNotFound {
 			return nil
 		}
 
 		if err != nil {
-			service.errorHandler.CaptureError(context.Background(), stacktrace.Propagate(err, "cannot fetch user by email"))
+			service.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "cannot fetch user by email"))
 			return fmt.Errorf("internal error when verifying the %s '%s'", field, value)
 		}
 
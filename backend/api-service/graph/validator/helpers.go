@@ -1,6 +1,8 @@
 package validator
 
 import (
+	"context"
+
 	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/ovchipkaart"
 )
 
@@ -15,7 +17,7 @@ func NewHelpers(ovChipkaartAPIClient ovchipkaart.APIClient) Helpers {
 }
 
 // ValidateOvChipkaartCredentials checks that the username and password for an ov chipkaart are valid
-func (h Helpers) ValidateOvChipkaartCredentials(username string, password string) (err error) {
-	_, err = h.ovChipkaartAPIClient.GetAuthorisationToken(username, password)
+func (h Helpers) ValidateOvChipkaartCredentials(ctx context.Context, username string, password string) (err error) {
+	_, err = h.ovChipkaartAPIClient.GetAuthorisationToken(ctx, username, password)
 	return err
 }
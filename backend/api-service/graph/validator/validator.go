@@ -1,6 +1,7 @@
 package validator
 
 import (
+	"context"
 	"net/url"
 
 	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/graph/model"
@@ -23,6 +24,18 @@ type ValidationResult struct {
 type Validator interface {
 	ValidateCreateUserInput(input model.CreateUserInput, localeTag language.Tag) ValidationResult
 	ValidateLoginInput(input model.LoginInput, localeTag language.Tag) ValidationResult
-	ValidateStoreAnalzyeRequest(input model.StoreAnalyzeRequestInput, localTag language.Tag) ValidationResult
-	ValidateAnalzyeRequestsInput(skip *int, take *int, orderBy *string, orderDirection *string, localTag language.Tag) ValidationResult
+	ValidateStoreAnalzyeRequest(ctx context.Context, input model.StoreAnalyzeRequestInput, localTag language.Tag) ValidationResult
+	ValidateAnalyzeRequestsInput(filter *model.AnalyzeRequestsFilter, first *int, localTag language.Tag) ValidationResult
+	ValidateRegisterCardInput(ctx context.Context, input model.RegisterCardInput, localTag language.Tag) ValidationResult
+	ValidateRequestPasswordResetInput(input model.RequestPasswordResetInput, localTag language.Tag) ValidationResult
+	ValidateResetPasswordInput(input model.ResetPasswordInput, localTag language.Tag) ValidationResult
+	ValidateVerifyEmailInput(input model.VerifyEmailInput, localTag language.Tag) ValidationResult
+	ValidateTransactionsInput(filter *model.TransactionsFilter, first *int, localTag language.Tag) ValidationResult
+	ValidateUpdateProfileInput(input model.UpdateProfileInput, currentEmail string, localTag language.Tag) ValidationResult
+	ValidateChangePasswordInput(input model.ChangePasswordInput, localTag language.Tag) ValidationResult
+	ValidateDeleteAccountInput(input model.DeleteAccountInput, localTag language.Tag) ValidationResult
+	ValidateConfirmTwoFactorInput(input model.ConfirmTwoFactorInput, localTag language.Tag) ValidationResult
+	ValidateVerifyTwoFactorInput(input model.VerifyTwoFactorInput, localTag language.Tag) ValidationResult
+	ValidateDisableTwoFactorInput(input model.DisableTwoFactorInput, localTag language.Tag) ValidationResult
+	ValidateCreateAccessTokenInput(input model.CreateAccessTokenInput, localTag language.Tag) ValidationResult
 }
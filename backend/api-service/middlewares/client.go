@@ -11,6 +11,18 @@ const (
 
 	// ContextKeyJWTToken is the auth token
 	ContextKeyJWTToken = internalContext.Key("jwt-token")
+
+	// ContextKeyClientIP is the IP address of the client
+	ContextKeyClientIP = internalContext.Key("client-ip")
+
+	// ContextKeyAccessToken is the personal access token which authenticated the request instead of a JWT
+	ContextKeyAccessToken = internalContext.Key("access-token")
+
+	// ContextKeyUserAgent is the user agent of the client
+	ContextKeyUserAgent = internalContext.Key("user-agent")
+
+	// ContextKeySessionID is the id of the session which the JWT was issued for
+	ContextKeySessionID = internalContext.Key("session-id")
 )
 
 // Client provides the collection of middlewares.
@@ -0,0 +1,49 @@
+package middlewares
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// AddClientIP adds the IP address of the client to the context.
+// The X-Forwarded-For header can be spoofed so it is only used when the api service runs behind trusted proxies.
+// Every proxy appends the address it received the request from so the client is the entry which was appended by
+// the outermost trusted proxy, the entries to its left are sent by the client and cannot be trusted.
+func (middleware Client) AddClientIP(trustProxyHeaders bool, trustedProxyHops int) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			clientIP, _, err := net.SplitHostPort(r.RemoteAddr)
+			if err != nil {
+				clientIP = r.RemoteAddr
+			}
+
+			if forwardedFor := r.Header.Get("X-Forwarded-For"); trustProxyHeaders && forwardedFor != "" {
+				clientIP = forwardedClientIP(forwardedFor, trustedProxyHops)
+			}
+
+			// put it in context
+			ctx := context.WithValue(r.Context(), ContextKeyClientIP, clientIP)
+
+			// and call the next with our new context
+			r = r.WithContext(ctx)
+			next.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+// forwardedClientIP returns the entry of the X-Forwarded-For header which was appended by the outermost trusted proxy.
+// The header can have fewer entries when a request didn't pass through all the proxies, the leftmost entry is used then.
+func forwardedClientIP(forwardedFor string, trustedProxyHops int) string {
+	entries := strings.Split(forwardedFor, ",")
+
+	index := len(entries) - trustedProxyHops
+	if index < 0 {
+		index = 0
+	}
+
+	return strings.TrimSpace(entries[index])
+}
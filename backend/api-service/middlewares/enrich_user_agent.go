@@ -0,0 +1,22 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+)
+
+// AddUserAgent adds the user agent of the client to the context
+func (middleware Client) AddUserAgent() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			// put it in context
+			ctx := context.WithValue(r.Context(), ContextKeyUserAgent, r.UserAgent())
+
+			// and call the next with our new context
+			r = r.WithContext(ctx)
+			next.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
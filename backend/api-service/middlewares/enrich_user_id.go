@@ -7,11 +7,18 @@ import (
 
 	"github.com/vektah/gqlparser/v2/gqlerror"
 
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/services/accesstoken"
 	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/services/jwt"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/services/session"
+	internalContext "github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/context"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/id"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/tracing"
 )
 
-// EnrichUserID adds the user id to the context
-func (middleware Client) EnrichUserID(jwtService jwt.Service) func(http.Handler) http.Handler {
+// EnrichUserID adds the user id to the context. The Authorization header contains either a JWT or a personal access token.
+// A JWT is only accepted while its session is in the registry so revoked sessions are signed out immediately.
+// The client IP must be added to the context before this middleware runs.
+func (middleware Client) EnrichUserID(jwtService jwt.Service, accessTokenService accesstoken.Service, sessionRegistry session.Registry) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		fn := func(w http.ResponseWriter, r *http.Request) {
 			header := r.Header.Get("Authorization")
@@ -22,24 +29,49 @@ func (middleware Client) EnrichUserID(jwtService jwt.Service) func(http.Handler)
 				return
 			}
 
-			//validate jwt token
 			tokenString := header
 
-			userID, err := jwtService.GetUserIDFromToken(tokenString)
-			if err != nil {
-				errorResponse, err := json.Marshal(gqlerror.Errorf("invalid token"))
+			if accesstoken.IsAccessToken(tokenString) {
+				accessToken, err := accessTokenService.Authenticate(r.Context(), tokenString)
 				if err != nil {
-					http.Error(w, "invalid token", http.StatusUnauthorized)
+					middleware.invalidToken(w)
 					return
 				}
 
-				http.Error(w, string(errorResponse), http.StatusUnauthorized)
+				ctx := context.WithValue(r.Context(), ContextKeyUserID, accessToken.UserID)
+				ctx = tracing.WithValue(ctx, internalContext.KeyUserID, accessToken.UserID.String())
+				ctx = context.WithValue(ctx, ContextKeyAccessToken, accessToken)
+
+				r = r.WithContext(ctx)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			//validate jwt token
+			claims, err := jwtService.GetClaimsFromToken(tokenString)
+			if err != nil {
+				middleware.invalidToken(w)
+				return
+			}
+
+			userID, err := id.FromString(claims.Subject)
+			if err != nil {
+				middleware.invalidToken(w)
+				return
+			}
+
+			clientIP, _ := r.Context().Value(ContextKeyClientIP).(string)
+			activeSession, err := sessionRegistry.Touch(claims.SessionID, clientIP)
+			if err != nil || activeSession.UserID != userID.String() {
+				middleware.invalidToken(w)
 				return
 			}
 
 			// put it in context
 			ctx := context.WithValue(r.Context(), ContextKeyUserID, userID)
+			ctx = tracing.WithValue(ctx, internalContext.KeyUserID, userID.String())
 			ctx = context.WithValue(ctx, ContextKeyJWTToken, tokenString)
+			ctx = context.WithValue(ctx, ContextKeySessionID, activeSession.ID)
 
 			// and call the next with our new context
 			r = r.WithContext(ctx)
@@ -49,3 +81,13 @@ func (middleware Client) EnrichUserID(jwtService jwt.Service) func(http.Handler)
 		return http.HandlerFunc(fn)
 	}
 }
+
+func (middleware Client) invalidToken(w http.ResponseWriter) {
+	errorResponse, err := json.Marshal(gqlerror.Errorf("invalid token"))
+	if err != nil {
+		http.Error(w, "invalid token", http.StatusUnauthorized)
+		return
+	}
+
+	http.Error(w, string(errorResponse), http.StatusUnauthorized)
+}
@@ -5,8 +5,6 @@ import (
 	"runtime/debug"
 	"time"
 
-	internalTime "github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/time"
-
 	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/logger"
 )
 
@@ -41,27 +39,26 @@ func (rw *responseWriter) WriteHeader(code int) {
 }
 
 // LogRequest logs the incoming HTTP request & its duration.
+// The route template is logged instead of the path because paths can contain tokens.
 func (middleware Client) LogRequest(logger logger.Logger) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		fn := func(w http.ResponseWriter, r *http.Request) {
 			defer func() {
 				if err := recover(); err != nil {
 					w.WriteHeader(http.StatusInternalServerError)
-					_ = logger.Log(
-						"err", err,
-						"trace", debug.Stack(),
-					)
+					logger.Error(r.Context(), "recovered from panic", "err", err, "trace", string(debug.Stack()))
 				}
 			}()
 
 			start := time.Now().UTC()
 			wrapped := wrapResponseWriter(w)
 			next.ServeHTTP(wrapped, r)
-			_ = logger.Log(
-				"at", time.Now().UTC().Format(internalTime.DefaultFormat),
+			logger.Info(
+				r.Context(),
+				"handled request",
 				"status", wrapped.status,
 				"method", r.Method,
-				"path", r.URL.EscapedPath(),
+				"route", routeTemplate(r),
 				"duration", time.Now().UTC().Sub(start),
 				"ip", r.RemoteAddr,
 			)
@@ -0,0 +1,64 @@
+package middlewares
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/metrics"
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metrics.Namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "The time it took to handle HTTP requests by route, method and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method", "code"})
+
+	httpRequestsInFlight = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: metrics.Namespace,
+		Name:      "http_requests_in_flight",
+		Help:      "The number of HTTP requests which are being handled.",
+	})
+)
+
+// RecordMetrics records the duration of the HTTP requests.
+// The route template is used instead of the path so that ids in the path don't create a metric per request.
+func (middleware Client) RecordMetrics() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			route := routeTemplate(r)
+
+			httpRequestsInFlight.Inc()
+			defer httpRequestsInFlight.Dec()
+
+			start := time.Now()
+			wrapped := wrapResponseWriter(w)
+			next.ServeHTTP(wrapped, r)
+
+			status := wrapped.status
+			if !wrapped.wroteHeader {
+				status = http.StatusOK
+			}
+
+			httpRequestDuration.WithLabelValues(route, r.Method, strconv.Itoa(status)).Observe(time.Since(start).Seconds())
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+// routeTemplate returns the path template of the route which matched the request e.g. /data-exports/{token}
+func routeTemplate(r *http.Request) string {
+	if currentRoute := mux.CurrentRoute(r); currentRoute != nil {
+		if template, err := currentRoute.GetPathTemplate(); err == nil {
+			return template
+		}
+	}
+
+	return "unknown"
+}
@@ -0,0 +1,60 @@
+package middlewares
+
+import (
+	"net/http"
+	"regexp"
+
+	internalContext "github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/context"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/id"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/tracing"
+	"go.opentelemetry.io/otel/api/global"
+	"go.opentelemetry.io/otel/api/propagation"
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/semconv"
+)
+
+// headerRequestID is the header which contains the id of a request
+const headerRequestID = "X-Request-Id"
+
+// requestIDRegex limits the request ids which are accepted from clients and proxies
+var requestIDRegex = regexp.MustCompile(`^[A-Za-z0-9\-_.]{1,64}$`)
+
+// TraceRequest starts the trace of an HTTP request and adds the request id to the context and the response.
+// The route template is used as the span name and the path is not recorded because it can contain tokens.
+func (middleware Client) TraceRequest() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			route := routeTemplate(r)
+
+			ctx := propagation.ExtractHTTP(r.Context(), global.Propagators(), r.Header)
+			ctx, span := tracing.Tracer().Start(
+				ctx,
+				r.Method+" "+route,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(semconv.HTTPMethodKey.String(r.Method), semconv.HTTPRouteKey.String(route)),
+			)
+			defer span.End()
+
+			requestID := r.Header.Get(headerRequestID)
+			if !requestIDRegex.MatchString(requestID) {
+				requestID = id.New().String()
+			}
+
+			ctx = tracing.WithValue(ctx, internalContext.KeyRequestID, requestID)
+			w.Header().Set(headerRequestID, requestID)
+
+			wrapped := wrapResponseWriter(w)
+			next.ServeHTTP(wrapped, r.WithContext(ctx))
+
+			status := wrapped.status
+			if !wrapped.wroteHeader {
+				status = http.StatusOK
+			}
+
+			span.SetAttributes(semconv.HTTPStatusCodeKey.Int(status))
+			span.SetStatus(semconv.SpanStatusFromHTTPStatusCode(status))
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
@@ -2,13 +2,19 @@ package main
 
 import (
 	"context"
+	"io/ioutil"
 	rawRecordsService "github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/proto/raw-records-service"
+	rawRecordsServiceV2 "github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/proto/raw-records-service/v2"
 	"log"
 	"net/http"
-	"strconv"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/99designs/gqlgen/graphql/handler/apollotracing"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/lru"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
 
 	"github.com/99designs/gqlgen/graphql/playground"
 	"github.com/rs/cors"
@@ -25,8 +31,14 @@ import (
 	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/graph/validator"
 	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/graph/validator/govalidator"
 	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/services/password"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/config"
 	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/errorhandler"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/httpclient"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/lifecycle"
 	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/logger"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/metrics"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/serviceauth"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/tracing"
 	"github.com/getsentry/sentry-go"
 
 	"github.com/99designs/gqlgen/graphql/handler"
@@ -35,19 +47,34 @@ import (
 	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/database"
 	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/database/mongodb"
 	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/graph/generated"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/graph/instrumentation"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/graph/persistedquery"
 	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/graph/resolver"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/services/accesstoken"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/services/captcha"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/services/dataexport"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/services/health"
 	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/services/jwt"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/services/ratelimit"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/services/refreshtoken"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/services/session"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/services/twofactor"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/services/mailer"
 	"github.com/gorilla/mux"
-	"github.com/joho/godotenv"
 	"github.com/pkg/errors"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
 )
 
-const defaultPort = "8080"
-
 type Singletons struct {
-	errorHandler errorhandler.ErrorHandler
+	config                        *config.APIService
+	errorHandler                  errorhandler.ErrorHandler
+	mongoClient                   *mongo.Client
+	db                            database.DB
+	dataExporter                  *dataexport.Exporter
+	transactionsServiceConnection *grpc.ClientConn
+	rawRecordsServiceConnection   *grpc.ClientConn
 }
 
 var (
@@ -55,40 +82,145 @@ var (
 )
 
 func main() {
-	err := godotenv.Load()
-	if err != nil {
-		log.Fatal("error loading .env file")
-	}
+	port := initializeConfig().Port
 
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = defaultPort
-	}
+	shutdownTracing := initializeTracing()
+
+	migrateDB()
 
 	router := mux.NewRouter()
 
 	middlewareClient := initializeMiddlewares()
 
+	router.Use(middlewareClient.TraceRequest())
 	router.Use(middlewareClient.LogRequest(initializeLogger()))
-	router.Use(middlewareClient.EnrichUserID(initializeJWTService()))
+	router.Use(middlewareClient.RecordMetrics())
+	router.Use(middlewareClient.AddClientIP(initializeConfig().TrustProxyHeaders, initializeConfig().TrustedProxyHops))
+	router.Use(middlewareClient.AddUserAgent())
+	router.Use(middlewareClient.EnrichUserID(initializeJWTService(), initializeAccessTokenService(), initializeSessionRegistry()))
 	router.Use(middlewareClient.AddLanguageTag())
 
 	router.HandleFunc("/", playground.Handler("GraphQL playground", "/query"))
+	router.HandleFunc("/.well-known/jwks.json", initializeJWTService().JWKSHandler())
+	router.HandleFunc(dataexport.DownloadRoute, initializeDataExporter().DownloadHandler())
 	router.Handle("/query", initializeGraphQLServer())
 
-	log.Printf("connect to http://localhost:%s/ for GraphQL playground", port)
-	log.Fatal(http.ListenAndServe(":"+port, cors.AllowAll().Handler(router)))
+	healthChecker := initializeHealthChecker()
+
+	// the health and metrics endpoints skip the middlewares so that probes and scrapes are not logged or authenticated
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", healthChecker.LivenessHandler())
+	mux.HandleFunc("/readyz", healthChecker.ReadinessHandler())
+	mux.Handle("/metrics", metrics.Handler())
+	mux.Handle("/", cors.AllowAll().Handler(router))
+
+	server := &http.Server{Addr: ":" + port, Handler: mux}
+
+	cleanupCtx, stopCleanup := context.WithCancel(context.Background())
+	cleanupStopped := make(chan struct{})
+	go func() {
+		initializeDataExporter().RunCleanup(cleanupCtx, initializeConfig().DataExport.CleanupInterval)
+		close(cleanupStopped)
+	}()
+
+	go func() {
+		initializeLogger().Info(context.Background(), "connect to http://localhost:"+port+"/ for GraphQL playground")
+		err := server.ListenAndServe()
+		if err != nil && err != http.ErrServerClosed {
+			log.Fatal(err)
+		}
+	}()
+
+	initializeLogger().Info(context.Background(), "shutting down", "signal", lifecycle.WaitForSignal().String())
+	stopCleanup()
+	<-cleanupStopped
+	shutdown(server, healthChecker)
+	shutdownTracing()
 }
 
+// shutdown fails the readiness check until load balancers stop sending requests and then waits for the
+// in-flight requests and data exports to complete so that uploads are not dropped during deploys
+func shutdown(server *http.Server, healthChecker *health.Checker) {
+	healthChecker.StartDraining()
+	time.Sleep(initializeConfig().Shutdown.DrainDelay)
+
+	ctx, cancel := context.WithTimeout(context.Background(), initializeConfig().Shutdown.Timeout)
+	defer cancel()
+
+	err := server.Shutdown(ctx)
+	if err != nil {
+		initializeLogger().Error(ctx, "cannot complete the in-flight requests", "err", err)
+	}
+
+	// the archives use the database and the raw records service so they must be written before the connections are closed
+	deadline, _ := ctx.Deadline()
+	if singletons.dataExporter != nil && !singletons.dataExporter.Wait(time.Until(deadline)) {
+		initializeLogger().Error(ctx, "cannot complete the data exports which are being built")
+	}
+
+	for _, conn := range []*grpc.ClientConn{singletons.transactionsServiceConnection, singletons.rawRecordsServiceConnection} {
+		if conn != nil {
+			_ = conn.Close()
+		}
+	}
+
+	if singletons.mongoClient != nil {
+		err = singletons.mongoClient.Disconnect(ctx)
+		if err != nil {
+			initializeLogger().Error(ctx, "cannot disconnect from mongoDB", "err", err)
+		}
+	}
+}
+
+func initializeHealthChecker() *health.Checker {
+	mongoClient := initializeMongoClient()
+	redisCache := initializeCache()
+
+	return health.NewChecker(initializeConfig().HealthCheckTimeout, map[string]health.Check{
+		"mongodb": func(ctx context.Context) error {
+			return mongoClient.Ping(ctx, readpref.Primary())
+		},
+		"redis": func(ctx context.Context) error {
+			return redisCache.Ping(ctx)
+		},
+		"transactions-service": health.GRPCCheck(initializeTransactionsServiceConnection()),
+		"raw-records-service":  health.GRPCCheck(initializeRawRecordsServiceConnection()),
+	})
+}
+
+// initializeGraphQLServer configures the same transports as handler.NewDefaultServer but rejects queries
+// above the complexity limit and shares the automatic persisted queries between instances through redis.
 func initializeGraphQLServer() *handler.Server {
-	server := handler.NewDefaultServer(
+	server := handler.New(
 		generated.NewExecutableSchema(
 			generated.Config{
-				Resolvers: initializeResolver(),
+				Resolvers:  initializeResolver(),
+				Complexity: resolver.Complexity(),
 			},
 		),
 	)
-	server.Use(apollotracing.Tracer{})
+
+	server.AddTransport(transport.Websocket{KeepAlivePingInterval: 10 * time.Second})
+	server.AddTransport(transport.Options{})
+	server.AddTransport(transport.GET{})
+	server.AddTransport(transport.POST{})
+	server.AddTransport(transport.MultipartForm{})
+
+	server.SetQueryCache(lru.New(1000))
+
+	server.Use(extension.Introspection{})
+	server.Use(extension.AutomaticPersistedQuery{
+		Cache: persistedquery.NewCache(initializeCache(), initializeConfig().GraphQL.PersistedQueryLifetime),
+	})
+	server.Use(extension.FixedComplexityLimit(initializeConfig().GraphQL.ComplexityLimit))
+	server.Use(instrumentation.Metrics{})
+	server.Use(instrumentation.Tracing{})
+
+	if initializeConfig().GraphQL.Tracing {
+		server.Use(apollotracing.Tracer{})
+	}
+
+	server.AroundOperations(resolver.AuthorizeAccessTokenScope)
 	return server
 }
 func initializeResolver() *resolver.Resolver {
@@ -99,17 +231,28 @@ func initializeResolver() *resolver.Resolver {
 		initializeErrorHandler(),
 		initializeLogger(),
 		initializeJWTService(),
+		initializeRefreshTokenService(),
 		initializeTransactionsServiceClient(),
 		initializeRawRecordsServiceClient(),
+		initializeRawRecordsServiceV2Client(),
+		initializeMailer(),
+		initializeCaptchaVerifier(),
+		initializeDataExporter(),
+		ratelimit.NewLimiter(initializeCache()),
+		initializeLoginLockout(),
+		initializeTwoFactorService(),
+		initializeAccessTokenService(),
+		initializeSessionRegistry(),
+		initializeConfig().AppURL,
 	)
 }
 
 func initializeOvChipkaartAPIClient() ovchipkaart.APIClient {
 	return ovchipkaart.NewAPIService(ovchipkaart.APIServiceConfig{
-		ClientID:     os.Getenv("OV_CHIPKAART_API_CLIENT_ID"),
-		ClientSecret: os.Getenv("OV_CHIPKAART_API_CLIENT_SECRET"),
+		ClientID:     initializeConfig().OvChipkaartAPI.ClientID,
+		ClientSecret: initializeConfig().OvChipkaartAPI.ClientSecret,
 		Locale:       "en",
-		Client:       &http.Client{},
+		Client:       httpclient.New(metrics.NewInstrumentedHTTPClient("ov-chipkaart", tracing.NewHTTPClient(&http.Client{})), ovchipkaart.HTTPClientOptions()),
 	})
 }
 
@@ -118,7 +261,7 @@ func initializeValidatorHelpers() validator.Helpers {
 }
 
 func initializeValidator() validator.Validator {
-	return govalidator.New(initializeDB(), initializeValidatorHelpers(), initializeErrorHandler())
+	return govalidator.New(initializeDB(), initializeValidatorHelpers(), initializeErrorHandler(), initializeCaptchaVerifier())
 }
 
 func initializePasswordService() password.Service {
@@ -126,33 +269,129 @@ func initializePasswordService() password.Service {
 }
 
 func initializeJWTService() jwt.Service {
-	sessionDays, err := strconv.Atoi(os.Getenv("AUTH_SESSION_DAYS"))
+	return jwt.NewService(initializeJWTKeySet(), initializeCache(), initializeConfig().JWT.AccessTokenLifetime)
+}
+
+// initializeJWTKeySet loads the signing keys from JWT_PRIVATE_KEYS which is a comma separated list of kid=path pairs.
+// During a rotation the new key is added first, then made active with JWT_ACTIVE_KEY_ID and the old key is removed
+// once the tokens it signed have expired.
+func initializeJWTKeySet() jwt.KeySet {
+	var keys []jwt.Key
+	for _, pair := range strings.Split(initializeConfig().JWT.PrivateKeys, ",") {
+		parts := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+
+		pemBytes, err := ioutil.ReadFile(parts[1])
+		if err != nil {
+			log.Fatal(errors.Wrapf(err, "cannot read jwt key %s", parts[0]))
+		}
+
+		key, err := jwt.KeyFromPEM(parts[0], pemBytes)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		keys = append(keys, key)
+	}
+
+	keySet, err := jwt.NewKeySet(initializeConfig().JWT.ActiveKeyID, keys)
 	if err != nil {
-		log.Fatal(err.Error())
+		log.Fatal(err)
 	}
 
-	if sessionDays < 1 {
-		log.Fatal("AUTH_SESSION_DAYS cannot be < 1")
+	return keySet
+}
+
+func initializeLoginLockout() ratelimit.Lockout {
+	return ratelimit.NewLockout(initializeCache(), ratelimit.LockoutOptions{
+		MaxFailures: 5,
+		Backoff:     initializeConfig().LoginLockout.Backoff,
+		MaxBackoff:  initializeConfig().LoginLockout.MaxBackoff,
+		ResetAfter:  24 * time.Hour,
+	})
+}
+
+// initializeTwoFactorService encrypts the TOTP secrets with the two factor keyring
+func initializeTwoFactorService() twofactor.Service {
+	keyring, err := initializeConfig().TwoFactor.Keyring()
+	if err != nil {
+		log.Fatal(errors.Wrap(err, "cannot initialize the two factor encryption keyring"))
 	}
 
-	return jwt.NewService(os.Getenv("JWT_SECRET"), initializeCache(), sessionDays)
+	return twofactor.NewService(initializeCache(), keyring, twofactor.Options{
+		Issuer:            initializeConfig().TwoFactor.Issuer,
+		ChallengeLifetime: initializeConfig().TwoFactor.ChallengeLifetime,
+	})
 }
 
-func initializeDB() database.DB {
-	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI(os.Getenv("MONGODB_URI")))
+func initializeAccessTokenService() accesstoken.Service {
+	return accesstoken.NewService(initializeDB())
+}
+
+func initializeRefreshTokenService() refreshtoken.Service {
+	return refreshtoken.NewService(initializeCache(), refreshtoken.Options{
+		Lifetime:           initializeConfig().RefreshToken.Lifetime,
+		RememberMeLifetime: initializeConfig().RefreshToken.RememberMeLifetime,
+	})
+}
+
+// initializeSessionRegistry keeps the index of a user's sessions as long as the longest refresh token family
+func initializeSessionRegistry() session.Registry {
+	return session.NewRegistry(initializeCache(), initializeConfig().RefreshToken.RememberMeLifetime)
+}
+
+func initializeMongoClient() *mongo.Client {
+	if singletons.mongoClient != nil {
+		return singletons.mongoClient
+	}
+
+	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI(initializeConfig().MongoDB.URI).SetMonitor(tracing.NewMongoMonitor()))
 	if err != nil {
 		log.Fatal(errors.Wrapf(err, "cannot connect to mongoDB"))
 	}
 
-	db := client.Database(os.Getenv("MONGODB_DB_NAME"))
+	singletons.mongoClient = client
+	return singletons.mongoClient
+}
+
+func initializeDB() database.DB {
+	if singletons.db != nil {
+		return singletons.db
+	}
 
-	return mongodb.NewMongoDB(db)
+	singletons.db = mongodb.NewMongoDB(initializeMongoClient().Database(initializeConfig().MongoDB.DBName))
+	return singletons.db
+}
+
+// migrateDB creates the indexes and migrates the existing documents once before the server accepts requests
+func migrateDB() {
+	db := initializeDB()
+
+	err := db.AnalyzeRequestRepository().CreateIndexes(context.Background())
+	if err != nil {
+		log.Fatal(errors.Wrapf(err, "cannot create analyze requests indexes"))
+	}
+
+	count, err := db.UserRepository().StartEmailVerificationGracePeriod(context.Background(), time.Now().UTC())
+	if err != nil {
+		log.Fatal(errors.Wrapf(err, "cannot start the email verification grace period of existing users"))
+	}
+	if count > 0 {
+		initializeLogger().Info(context.Background(), "started the email verification grace period of existing users", "count", count)
+	}
+
+	encrypted, err := db.UserRepository().EncryptPlaintextTwoFactorSecrets(context.Background(), initializeTwoFactorService().EncryptSecret)
+	if err != nil {
+		log.Fatal(errors.Wrapf(err, "cannot encrypt the plaintext two factor secrets of existing users"))
+	}
+	if encrypted > 0 {
+		initializeLogger().Info(context.Background(), "encrypted the plaintext two factor secrets of existing users", "users", encrypted)
+	}
 }
 
 func initializeCache() cache.Cache {
 	return redis.NewClient(redis.Options{
-		Address:  os.Getenv("REDIS_ADDRESS"),
-		Password: os.Getenv("REDIS_PASSWORD"),
+		Address:  initializeConfig().Redis.Address,
+		Password: initializeConfig().Redis.Password,
 		DB:       0,
 	})
 }
@@ -161,8 +400,87 @@ func initializeMiddlewares() middlewares.Client {
 	return middlewares.New()
 }
 
+func initializeCaptchaVerifier() captcha.Verifier {
+	captchaConfig := initializeConfig().Captcha
+
+	switch captchaConfig.Driver {
+	case config.CaptchaDriverHCaptcha:
+		return captcha.NewHCaptchaVerifier(captcha.HCaptchaOptions{
+			Secret:  captchaConfig.HCaptchaSecret,
+			SiteKey: captchaConfig.HCaptchaSiteKey,
+			Client:  tracing.NewHTTPClient(&http.Client{Timeout: 5 * time.Second}),
+		})
+	case config.CaptchaDriverProofOfWork:
+		return captcha.NewProofOfWorkVerifier(captcha.ProofOfWorkOptions{
+			Secret:     captchaConfig.ProofOfWorkSecret,
+			Difficulty: captchaConfig.ProofOfWorkDifficulty,
+			Lifetime:   10 * time.Minute,
+			Cache:      initializeCache(),
+		})
+	default:
+		hostname := captchaConfig.RecaptchaHostname
+		if hostname == "" {
+			appURL, err := url.Parse(initializeConfig().AppURL)
+			if err != nil {
+				log.Fatal(errors.Wrap(err, "cannot parse APP_URL"))
+			}
+			hostname = appURL.Hostname()
+		}
+
+		return captcha.NewRecaptchaVerifier(captcha.RecaptchaOptions{
+			Secret:   captchaConfig.RecaptchaSecret,
+			MinScore: captchaConfig.RecaptchaMinScore,
+			Hostname: hostname,
+			Client:   tracing.NewHTTPClient(&http.Client{Timeout: 5 * time.Second}),
+		})
+	}
+}
+
+func initializeDataExporter() dataexport.Exporter {
+	if singletons.dataExporter != nil {
+		return *singletons.dataExporter
+	}
+
+	dataExporter := dataexport.NewExporter(
+		initializeDB(),
+		initializeRawRecordsServiceClient(),
+		initializeRawRecordsServiceV2Client(),
+		initializeMailer(),
+		initializeErrorHandler(),
+		initializeLogger(),
+		dataexport.Options{
+			Directory:    initializeConfig().DataExport.Directory,
+			APIURL:       initializeConfig().APIURL,
+			LinkLifetime: initializeConfig().DataExport.LinkLifetime,
+		},
+	)
+
+	singletons.dataExporter = &dataExporter
+	return dataExporter
+}
+
+func initializeMailer() mailer.Mailer {
+	mailerConfig := initializeConfig().Mailer
+	if mailerConfig.Driver != config.MailerDriverSMTP {
+		return mailer.NewLogMailer(initializeLogger())
+	}
+
+	return mailer.NewSMTPMailer(mailer.SMTPOptions{
+		Host:     mailerConfig.SMTPHost,
+		Port:     mailerConfig.SMTPPort,
+		Username: mailerConfig.SMTPUsername,
+		Password: mailerConfig.SMTPPassword,
+		From:     mailerConfig.FromAddress,
+	})
+}
+
 func initializeLogger() logger.Logger {
-	return logger.NewGoKitLogger(os.Stdout)
+	level, err := logger.ParseLevel(initializeConfig().Logging.Level)
+	if err != nil {
+		log.Fatal(errors.Wrap(err, "cannot parse LOG_LEVEL"))
+	}
+
+	return logger.NewGoKitLogger(os.Stdout, level)
 }
 
 func initializeErrorHandler() errorhandler.ErrorHandler {
@@ -171,12 +489,11 @@ func initializeErrorHandler() errorhandler.ErrorHandler {
 	}
 
 	errorHandlerSingleton, err := errorhandler.NewSentryErrorHandler(sentry.ClientOptions{
-		// Either set your DSN here or set the SENTRY_DSN environment variable.
-		Dsn: os.Getenv("SENTRY_DSN"),
+		Dsn: initializeConfig().SentryDSN,
 		// Enable printing of SDK debug messages.
 		// Useful when getting started or trying to figure something out.
 		Debug: true,
-	})
+	}, initializeLogger())
 
 	if err != nil {
 		log.Fatal(err.Error())
@@ -187,21 +504,96 @@ func initializeErrorHandler() errorhandler.ErrorHandler {
 }
 
 func initializeTransactionsServiceClient() transactions_service.TransactionsServiceClient {
-	ctx, _ := context.WithTimeout(context.Background(), time.Second)
-	conn, err := grpc.DialContext(ctx, os.Getenv("TRANSACTIONS_SERVICE_TARGET"), grpc.WithInsecure(), grpc.WithBlock())
+	return transactions_service.NewTransactionsServiceClient(initializeTransactionsServiceConnection())
+}
+
+func initializeRawRecordsServiceClient() rawRecordsService.RawRecordsServiceClient {
+	return rawRecordsService.NewRawRecordsServiceClient(initializeRawRecordsServiceConnection())
+}
+
+func initializeRawRecordsServiceV2Client() rawRecordsServiceV2.RawRecordsServiceClient {
+	return rawRecordsServiceV2.NewRawRecordsServiceClient(initializeRawRecordsServiceConnection())
+}
+
+func initializeTransactionsServiceConnection() *grpc.ClientConn {
+	if singletons.transactionsServiceConnection == nil {
+		singletons.transactionsServiceConnection = dialGRPC(initializeConfig().TransactionsServiceTarget, serviceauth.ServiceTransactions)
+	}
+
+	return singletons.transactionsServiceConnection
+}
+
+// initializeRawRecordsServiceConnection is shared by both versions of the raw records service
+func initializeRawRecordsServiceConnection() *grpc.ClientConn {
+	if singletons.rawRecordsServiceConnection == nil {
+		singletons.rawRecordsServiceConnection = dialGRPC(initializeConfig().RawRecordsServiceTarget, serviceauth.ServiceRawRecords)
+	}
+
+	return singletons.rawRecordsServiceConnection
+}
+
+// dialGRPC connects to a gRPC service with a token which is only accepted by that service
+func dialGRPC(target string, service string) *grpc.ClientConn {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	authOptions, err := serviceauth.GRPCDialOptions(initializeServiceAuthConfig(), service)
+	if err != nil {
+		log.Fatal(errors.Wrapf(err, "cannot initialize the service authentication for %s", service))
+	}
+
+	dialOptions := append([]grpc.DialOption{grpc.WithBlock()}, authOptions...)
+	dialOptions = append(dialOptions, metrics.GRPCDialOptions()...)
+	dialOptions = append(dialOptions, tracing.GRPCDialOptions()...)
+
+	conn, err := grpc.DialContext(ctx, target, dialOptions...)
 	if err != nil {
 		log.Fatalln(err)
 	}
 
-	return transactions_service.NewTransactionsServiceClient(conn)
+	return conn
 }
 
-func initializeRawRecordsServiceClient() rawRecordsService.RawRecordsServiceClient {
-	ctx, _ := context.WithTimeout(context.Background(), time.Second)
-	conn, err := grpc.DialContext(ctx, os.Getenv("RAW_RECORDS_SERVICE_TARGET"), grpc.WithInsecure(), grpc.WithBlock())
+func initializeServiceAuthConfig() serviceauth.Config {
+	return serviceauth.Config{
+		ServiceName: serviceauth.ServiceAPI,
+		Insecure:    initializeConfig().ServiceAuth.Insecure,
+		CertFile:    initializeConfig().ServiceAuth.CertFile,
+		KeyFile:     initializeConfig().ServiceAuth.KeyFile,
+		CAFile:      initializeConfig().ServiceAuth.CAFile,
+		TokenSecret: initializeConfig().ServiceAuth.TokenSecret,
+	}
+}
+
+// initializeTracing exports the spans to the OTLP collector and returns a function which flushes the remaining spans
+func initializeTracing() func() {
+	shutdown, err := tracing.Initialize(tracing.Config{
+		ServiceName: "api-service",
+		Endpoint:    initializeConfig().Tracing.Endpoint,
+		Insecure:    initializeConfig().Tracing.Insecure,
+		SampleRatio: initializeConfig().Tracing.SampleRatio,
+	})
 	if err != nil {
-		log.Fatalln(err)
+		log.Fatal(errors.Wrap(err, "cannot initialize tracing"))
 	}
 
-	return rawRecordsService.NewRawRecordsServiceClient(conn)
-}
\ No newline at end of file
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		err := shutdown(ctx)
+		if err != nil {
+			initializeLogger().Error(ctx, "cannot flush the remaining spans", "err", err)
+		}
+	}
+}
+
+// initializeConfig loads the configuration once and exits with every problem when it is invalid
+func initializeConfig() *config.APIService {
+	if singletons.config == nil {
+		singletons.config = &config.APIService{}
+		config.MustLoad("api-service", singletons.config)
+	}
+
+	return singletons.config
+}
@@ -0,0 +1,94 @@
+package accesstoken
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/database"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/entities"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/services/token"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/errors"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/id"
+	"github.com/palantir/stacktrace"
+)
+
+const (
+	// Prefix distinguishes access tokens from JWTs in the Authorization header and makes leaked tokens easy to scan for
+	Prefix = "ovat_"
+
+	// lastUsedInterval limits how often the last used time of a token is written to the database
+	lastUsedInterval = time.Minute
+)
+
+var (
+	// ErrCodeInvalidAccessToken is the error code when an access token doesn't exist or has expired
+	ErrCodeInvalidAccessToken = stacktrace.ErrorCode(76)
+)
+
+// Service creates and authenticates personal access tokens
+type Service struct {
+	db database.DB
+}
+
+// NewService creates a new instance of the access token service
+func NewService(db database.DB) Service {
+	return Service{db: db}
+}
+
+// IsAccessToken checks if the value of the Authorization header is an access token instead of a JWT
+func IsAccessToken(tokenString string) bool {
+	return strings.HasPrefix(tokenString, Prefix)
+}
+
+// Create stores a new access token and returns the token which is only shown once
+func (service Service) Create(ctx context.Context, userID id.ID, name string, scopes []entities.AccessTokenScope, expiresAt *time.Time) (tokenString string, accessToken entities.AccessToken, err error) {
+	secret, _, err := token.Generate()
+	if err != nil {
+		return tokenString, accessToken, stacktrace.Propagate(err, "cannot generate access token for user with id %s", userID.String())
+	}
+	tokenString = Prefix + secret
+
+	accessToken = entities.AccessToken{
+		ID:        id.New(),
+		UserID:    userID,
+		Name:      name,
+		TokenHash: token.Hash(tokenString),
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	err = service.db.AccessTokenRepository().Store(ctx, accessToken)
+	if err != nil {
+		return tokenString, accessToken, stacktrace.Propagate(err, "cannot store access token for user with id %s", userID.String())
+	}
+
+	return tokenString, accessToken, nil
+}
+
+// Authenticate returns the access token which belongs to the token string and records that it was used
+func (service Service) Authenticate(ctx context.Context, tokenString string) (*entities.AccessToken, error) {
+	accessToken, err := service.db.AccessTokenRepository().FindByTokenHash(ctx, token.Hash(tokenString))
+	if err == errors.ErrEntityNotFound {
+		return nil, stacktrace.NewErrorWithCode(ErrCodeInvalidAccessToken, "the access token does not exist")
+	}
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "cannot find access token")
+	}
+
+	now := time.Now().UTC()
+	if accessToken.IsExpired(now) {
+		return nil, stacktrace.NewErrorWithCode(ErrCodeInvalidAccessToken, "the access token with id %s has expired", accessToken.ID.String())
+	}
+
+	if accessToken.LastUsedAt == nil || now.Sub(*accessToken.LastUsedAt) > lastUsedInterval {
+		err = service.db.AccessTokenRepository().UpdateLastUsedAt(ctx, accessToken.ID, now)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "cannot record usage of access token with id %s", accessToken.ID.String())
+		}
+		accessToken.LastUsedAt = &now
+	}
+
+	return accessToken, nil
+}
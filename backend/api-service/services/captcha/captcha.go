@@ -0,0 +1,41 @@
+package captcha
+
+import (
+	"github.com/palantir/stacktrace"
+)
+
+var (
+	// ErrCodeInvalidCaptcha is the error code when the captcha was not solved by a human
+	ErrCodeInvalidCaptcha = stacktrace.ErrorCode(78)
+)
+
+// Actions are the names of the forms which are protected by a captcha. reCAPTCHA v3 tokens are only valid for
+// the action they were executed with so a token which was solved for one form cannot be used for another form.
+const (
+	// ActionCreateUser is the action of the sign up form
+	ActionCreateUser = "create_user"
+
+	// ActionLogin is the action of the login form
+	ActionLogin = "login"
+
+	// ActionRequestPasswordReset is the action of the forgot password form
+	ActionRequestPasswordReset = "request_password_reset"
+)
+
+// Verifier verifies the captcha response which was submitted by a client
+type Verifier interface {
+	// Verify returns an error with the code ErrCodeInvalidCaptcha when the response is not valid for the action.
+	// Other errors mean that the response could not be verified.
+	Verify(response string, action string) error
+}
+
+// Challenge is a puzzle which the client must solve before submitting a form
+type Challenge struct {
+	Value      string
+	Difficulty int
+}
+
+// ChallengeIssuer is implemented by verifiers which issue their own challenges
+type ChallengeIssuer interface {
+	IssueChallenge() (Challenge, error)
+}
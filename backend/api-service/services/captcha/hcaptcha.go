@@ -0,0 +1,58 @@
+package captcha
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	"github.com/palantir/stacktrace"
+)
+
+const hcaptchaVerifyURL = "https://hcaptcha.com/siteverify"
+
+// HCaptchaOptions are the params for initializing the hCaptcha verifier
+type HCaptchaOptions struct {
+	Secret  string
+	SiteKey string
+	Client  *http.Client
+}
+
+// HCaptchaVerifier verifies hCaptcha responses
+type HCaptchaVerifier struct {
+	options HCaptchaOptions
+}
+
+type hcaptchaResponse struct {
+	Success    bool     `json:"success"`
+	ErrorCodes []string `json:"error-codes"`
+}
+
+// NewHCaptchaVerifier creates a new instance of the hCaptcha verifier
+func NewHCaptchaVerifier(options HCaptchaOptions) Verifier {
+	return &HCaptchaVerifier{options: options}
+}
+
+// Verify checks the response with hCaptcha
+func (verifier HCaptchaVerifier) Verify(response string, _ string) error {
+	httpResponse, err := verifier.options.Client.PostForm(hcaptchaVerifyURL, url.Values{
+		"secret":   {verifier.options.Secret},
+		"sitekey":  {verifier.options.SiteKey},
+		"response": {response},
+	})
+	if err != nil {
+		return stacktrace.Propagate(err, "cannot verify hCaptcha response")
+	}
+	defer httpResponse.Body.Close()
+
+	var result hcaptchaResponse
+	err = json.NewDecoder(httpResponse.Body).Decode(&result)
+	if err != nil {
+		return stacktrace.Propagate(err, "cannot decode hCaptcha verification response")
+	}
+
+	if !result.Success {
+		return stacktrace.NewErrorWithCode(ErrCodeInvalidCaptcha, "hCaptcha verification failed with errors %v", result.ErrorCodes)
+	}
+
+	return nil
+}
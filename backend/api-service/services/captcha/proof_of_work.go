@@ -0,0 +1,125 @@
+package captcha
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"math/bits"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/cache"
+	"github.com/palantir/stacktrace"
+)
+
+const (
+	proofOfWorkSeparator = "."
+	// proofOfWorkNonceSeparator separates the challenge from the nonce in a response
+	proofOfWorkNonceSeparator = ":"
+
+	cacheKeyPrefixUsedChallenge = "captcha_challenge_used:"
+)
+
+// ProofOfWorkOptions are the params for initializing the proof of work verifier
+type ProofOfWorkOptions struct {
+	// Secret signs the challenges so clients cannot create their own
+	Secret string
+	// Difficulty is the number of leading zero bits the hash of a response must have
+	Difficulty int
+	// Lifetime is how long a challenge can be solved after it was issued
+	Lifetime time.Duration
+	// Cache stores the challenges which were used so they cannot be replayed
+	Cache cache.Cache
+}
+
+// ProofOfWorkVerifier is a self-hosted verifier for deployments without internet access.
+// The client finds a nonce so that sha256(challenge:nonce) starts with a number of zero bits
+// which makes automated form submissions expensive.
+type ProofOfWorkVerifier struct {
+	options ProofOfWorkOptions
+}
+
+// NewProofOfWorkVerifier creates a new instance of the proof of work verifier
+func NewProofOfWorkVerifier(options ProofOfWorkOptions) *ProofOfWorkVerifier {
+	return &ProofOfWorkVerifier{options: options}
+}
+
+// IssueChallenge creates a signed challenge
+func (verifier ProofOfWorkVerifier) IssueChallenge() (challenge Challenge, err error) {
+	random := make([]byte, 16)
+	_, err = rand.Read(random)
+	if err != nil {
+		return challenge, stacktrace.Propagate(err, "cannot read random bytes for a challenge")
+	}
+
+	payload := strconv.FormatInt(time.Now().UTC().Unix(), 10) + proofOfWorkSeparator + hex.EncodeToString(random)
+
+	return Challenge{
+		Value:      payload + proofOfWorkSeparator + verifier.sign(payload),
+		Difficulty: verifier.options.Difficulty,
+	}, nil
+}
+
+// Verify checks that the response solves a challenge which was issued by this verifier and was not used before
+func (verifier ProofOfWorkVerifier) Verify(response string, _ string) error {
+	separatorIndex := strings.LastIndex(response, proofOfWorkNonceSeparator)
+	if separatorIndex == -1 {
+		return stacktrace.NewErrorWithCode(ErrCodeInvalidCaptcha, "proof of work response has no nonce")
+	}
+	challenge := response[:separatorIndex]
+
+	parts := strings.Split(challenge, proofOfWorkSeparator)
+	if len(parts) != 3 {
+		return stacktrace.NewErrorWithCode(ErrCodeInvalidCaptcha, "proof of work challenge is malformed")
+	}
+
+	payload := parts[0] + proofOfWorkSeparator + parts[1]
+	if !hmac.Equal([]byte(verifier.sign(payload)), []byte(parts[2])) {
+		return stacktrace.NewErrorWithCode(ErrCodeInvalidCaptcha, "proof of work challenge has an invalid signature")
+	}
+
+	issuedAt, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return stacktrace.PropagateWithCode(err, ErrCodeInvalidCaptcha, "proof of work challenge has an invalid timestamp")
+	}
+
+	remainingLifetime := time.Unix(issuedAt, 0).Add(verifier.options.Lifetime).Sub(time.Now())
+	if remainingLifetime <= 0 {
+		return stacktrace.NewErrorWithCode(ErrCodeInvalidCaptcha, "proof of work challenge has expired")
+	}
+
+	if verifier.leadingZeroBits(sha256.Sum256([]byte(response))) < verifier.options.Difficulty {
+		return stacktrace.NewErrorWithCode(ErrCodeInvalidCaptcha, "proof of work response doesn't solve the challenge")
+	}
+
+	// the challenge is marked as used in a single operation so that it cannot be replayed by concurrent requests
+	unused, err := verifier.options.Cache.SetNX(cacheKeyPrefixUsedChallenge+challenge, "", remainingLifetime)
+	if err != nil {
+		return stacktrace.Propagate(err, "cannot mark the proof of work challenge as used")
+	}
+
+	if !unused {
+		return stacktrace.NewErrorWithCode(ErrCodeInvalidCaptcha, "proof of work challenge has already been used")
+	}
+
+	return nil
+}
+
+func (verifier ProofOfWorkVerifier) sign(payload string) string {
+	mac := hmac.New(sha256.New, []byte(verifier.options.Secret))
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (verifier ProofOfWorkVerifier) leadingZeroBits(hash [sha256.Size]byte) (count int) {
+	for _, value := range hash {
+		if value != 0 {
+			return count + bits.LeadingZeros8(value)
+		}
+		count += 8
+	}
+
+	return count
+}
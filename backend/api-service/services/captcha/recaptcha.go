@@ -0,0 +1,75 @@
+package captcha
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	"github.com/palantir/stacktrace"
+)
+
+const recaptchaVerifyURL = "https://www.google.com/recaptcha/api/siteverify"
+
+// RecaptchaOptions are the params for initializing the reCAPTCHA v3 verifier
+type RecaptchaOptions struct {
+	Secret string
+	// MinScore is the lowest score between 0.0 (bot) and 1.0 (human) which is accepted
+	MinScore float64
+	// Hostname is the hostname of the site where the reCAPTCHA was solved
+	Hostname string
+	Client   *http.Client
+}
+
+// RecaptchaVerifier verifies Google reCAPTCHA v3 responses
+type RecaptchaVerifier struct {
+	options RecaptchaOptions
+}
+
+type recaptchaResponse struct {
+	Success    bool     `json:"success"`
+	Score      float64  `json:"score"`
+	Action     string   `json:"action"`
+	Hostname   string   `json:"hostname"`
+	ErrorCodes []string `json:"error-codes"`
+}
+
+// NewRecaptchaVerifier creates a new instance of the reCAPTCHA v3 verifier
+func NewRecaptchaVerifier(options RecaptchaOptions) Verifier {
+	return &RecaptchaVerifier{options: options}
+}
+
+// Verify checks the response with Google and enforces the action, the hostname and the minimum score
+func (verifier RecaptchaVerifier) Verify(response string, action string) error {
+	httpResponse, err := verifier.options.Client.PostForm(recaptchaVerifyURL, url.Values{
+		"secret":   {verifier.options.Secret},
+		"response": {response},
+	})
+	if err != nil {
+		return stacktrace.Propagate(err, "cannot verify reCAPTCHA response")
+	}
+	defer httpResponse.Body.Close()
+
+	var result recaptchaResponse
+	err = json.NewDecoder(httpResponse.Body).Decode(&result)
+	if err != nil {
+		return stacktrace.Propagate(err, "cannot decode reCAPTCHA verification response")
+	}
+
+	if !result.Success {
+		return stacktrace.NewErrorWithCode(ErrCodeInvalidCaptcha, "reCAPTCHA verification failed with errors %v", result.ErrorCodes)
+	}
+
+	if result.Action != action {
+		return stacktrace.NewErrorWithCode(ErrCodeInvalidCaptcha, "reCAPTCHA action %q doesn't match the expected action %q", result.Action, action)
+	}
+
+	if result.Hostname != verifier.options.Hostname {
+		return stacktrace.NewErrorWithCode(ErrCodeInvalidCaptcha, "reCAPTCHA hostname %q doesn't match the expected hostname %q", result.Hostname, verifier.options.Hostname)
+	}
+
+	if result.Score < verifier.options.MinScore {
+		return stacktrace.NewErrorWithCode(ErrCodeInvalidCaptcha, "reCAPTCHA score %.1f is lower than %.1f", result.Score, verifier.options.MinScore)
+	}
+
+	return nil
+}
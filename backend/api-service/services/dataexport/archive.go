@@ -0,0 +1,594 @@
+package dataexport
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/database"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/entities"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/id"
+	raw_records_service "github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/proto/raw-records-service"
+	raw_records_service_v2 "github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/proto/raw-records-service/v2"
+	internalTime "github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/time"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/golang/protobuf/ptypes/wrappers"
+	"github.com/palantir/stacktrace"
+)
+
+const (
+	analyzeRequestsPageSize = 100
+	rawRecordsPageSize      = 1000
+
+	readme = `This archive contains all the data which OV-Chipkaart Dashboard stores about you.
+Every file is available as JSON and as CSV, timestamps are in UTC.
+
+profile          your account details
+analyze_requests the periods you asked us to analyze
+cards            the cards which are synced periodically, the ov-chipkaart credentials are not included
+raw_records      the transactions which were imported from your cards and travel history files
+enriched_records the journeys which were derived from the raw records of your analyze requests
+results          the price of the journeys of your analyze requests with every NS subscription in euro cents
+`
+)
+
+// table is a dataset which is written to the archive as JSON and as CSV
+type table struct {
+	name    string
+	rows    interface{}
+	header  []string
+	records [][]string
+}
+
+type profileRow struct {
+	ID              string  `json:"id"`
+	FirstName       string  `json:"firstName"`
+	LastName        string  `json:"lastName"`
+	Email           string  `json:"email"`
+	EmailVerifiedAt *string `json:"emailVerifiedAt"`
+	CreatedAt       string  `json:"createdAt"`
+	UpdatedAt       string  `json:"updatedAt"`
+}
+
+type analyzeRequestRow struct {
+	ID                string `json:"id"`
+	InputType         string `json:"inputType"`
+	OvChipkaartNumber string `json:"ovChipkaartNumber"`
+	Status            string `json:"status"`
+	StartDate         string `json:"startDate"`
+	EndDate           string `json:"endDate"`
+	CreatedAt         string `json:"createdAt"`
+	UpdatedAt         string `json:"updatedAt"`
+}
+
+type cardRow struct {
+	ID                      string  `json:"id"`
+	OvChipkaartNumber       string  `json:"ovChipkaartNumber"`
+	Status                  string  `json:"status"`
+	LastTransactionDateTime *string `json:"lastTransactionDateTime"`
+	LastSyncedAt            *string `json:"lastSyncedAt"`
+	NextSyncAt              *string `json:"nextSyncAt"`
+	LastError               string  `json:"lastError"`
+	CreatedAt               *string `json:"createdAt"`
+}
+
+type rawRecordRow struct {
+	ID                     string   `json:"id"`
+	AnalyzeRequestID       string   `json:"analyzeRequestId"`
+	OvChipkaartNumber      string   `json:"ovChipkaartNumber"`
+	TransactionDateTime    *string  `json:"transactionDateTime"`
+	TransactionName        string   `json:"transactionName"`
+	TransactionInfo        string   `json:"transactionInfo"`
+	TransactionExplanation string   `json:"transactionExplanation"`
+	TransactionPriority    string   `json:"transactionPriority"`
+	CheckInInfo            string   `json:"checkInInfo"`
+	CheckInText            string   `json:"checkInText"`
+	Fare                   *float64 `json:"fare"`
+	FareCalculation        string   `json:"fareCalculation"`
+	FareText               string   `json:"fareText"`
+	ModalType              string   `json:"modalType"`
+	ProductInfo            string   `json:"productInfo"`
+	ProductText            string   `json:"productText"`
+	Operator               string   `json:"operator"`
+	EPurseMut              *float64 `json:"ePurseMut"`
+	EPurseMutInfo          string   `json:"ePurseMutInfo"`
+	Source                 string   `json:"source"`
+	CreatedAt              *string  `json:"createdAt"`
+}
+
+type enrichedRecordRow struct {
+	ID               string `json:"id"`
+	RawRecordID      string `json:"rawRecordId"`
+	AnalyzeRequestID string `json:"analyzeRequestId"`
+	StartTime        string `json:"startTime"`
+	EndTime          string `json:"endTime"`
+	StartTimeIsExact bool   `json:"startTimeIsExact"`
+	FromStationCode  string `json:"fromStationCode"`
+	ToStationCode    string `json:"toStationCode"`
+	CompanyName      string `json:"companyName"`
+	TransactionType  string `json:"transactionType"`
+	DurationSeconds  int64  `json:"durationSeconds"`
+}
+
+type calculationResultRow struct {
+	AnalyzeRequestID        string `json:"analyzeRequestId"`
+	Subscription            string `json:"subscription"`
+	OffPeakFirstClassPrice  int    `json:"offPeakFirstClassPrice"`
+	OffPeakSecondClassPrice int    `json:"offPeakSecondClassPrice"`
+	OffPeakJourneyCount     int    `json:"offPeakJourneyCount"`
+	PeakFirstClassPrice     int    `json:"peakFirstClassPrice"`
+	PeakSecondClassPrice    int    `json:"peakSecondClassPrice"`
+	PeakJourneyCount        int    `json:"peakJourneyCount"`
+	PeakSupplementPrice     int    `json:"peakSupplementPrice"`
+	PeakSupplementCount     int    `json:"peakSupplementCount"`
+	OffPeakSupplementPrice  int    `json:"offPeakSupplementPrice"`
+	OffPeakSupplementCount  int    `json:"offPeakSupplementCount"`
+	FailedRecordCount       int    `json:"failedRecordCount"`
+}
+
+// writeArchive collects the data of the user from the api service and the raw records service and writes it to a ZIP file
+func (exporter Exporter) writeArchive(ctx context.Context, user entities.User, path string) error {
+	tables := []table{exporter.profileTable(user)}
+
+	analyzeRequests, analyzeRequestIDs, err := exporter.analyzeRequestsTable(ctx, user)
+	if err != nil {
+		return err
+	}
+	tables = append(tables, analyzeRequests)
+
+	cards, err := exporter.cardsTable(ctx, user)
+	if err != nil {
+		return err
+	}
+	tables = append(tables, cards)
+
+	rawRecords, err := exporter.rawRecordsTable(ctx, user)
+	if err != nil {
+		return err
+	}
+	tables = append(tables, rawRecords)
+
+	enrichedRecords, err := exporter.enrichedRecordsTable(ctx, analyzeRequestIDs)
+	if err != nil {
+		return err
+	}
+	tables = append(tables, enrichedRecords)
+
+	results, err := exporter.calculationResultsTable(ctx, analyzeRequestIDs)
+	if err != nil {
+		return err
+	}
+	tables = append(tables, results)
+
+	// the archive is written next to its final path so an incomplete archive is never served
+	temporaryPath := path + ".tmp"
+	file, err := os.Create(temporaryPath)
+	if err != nil {
+		return stacktrace.Propagate(err, "cannot create archive %s", temporaryPath)
+	}
+	defer func() { _ = os.Remove(temporaryPath) }()
+
+	err = exporter.writeTables(file, tables)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return stacktrace.Propagate(err, "cannot write archive %s", temporaryPath)
+	}
+
+	err = os.Rename(temporaryPath, path)
+	if err != nil {
+		return stacktrace.Propagate(err, "cannot move archive to %s", path)
+	}
+
+	return nil
+}
+
+func (exporter Exporter) writeTables(writer io.Writer, tables []table) error {
+	archive := zip.NewWriter(writer)
+
+	entry, err := archive.Create("README.txt")
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(entry, readme)
+	if err != nil {
+		return err
+	}
+
+	for _, table := range tables {
+		entry, err = archive.Create(table.name + ".json")
+		if err != nil {
+			return err
+		}
+
+		encoder := json.NewEncoder(entry)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(table.rows)
+		if err != nil {
+			return stacktrace.Propagate(err, "cannot encode %s as json", table.name)
+		}
+
+		entry, err = archive.Create(table.name + ".csv")
+		if err != nil {
+			return err
+		}
+
+		csvWriter := csv.NewWriter(entry)
+		err = csvWriter.WriteAll(append([][]string{table.header}, table.records...))
+		if err != nil {
+			return stacktrace.Propagate(err, "cannot encode %s as csv", table.name)
+		}
+	}
+
+	return archive.Close()
+}
+
+func (exporter Exporter) profileTable(user entities.User) table {
+	row := profileRow{
+		ID:              user.ID.String(),
+		FirstName:       user.FirstName,
+		LastName:        user.LastName,
+		Email:           user.Email,
+		EmailVerifiedAt: exporter.formatOptionalTime(user.EmailVerifiedAt),
+		CreatedAt:       user.CreatedAt.Format(internalTime.DefaultFormat),
+		UpdatedAt:       user.UpdatedAt.Format(internalTime.DefaultFormat),
+	}
+
+	return table{
+		name:   "profile",
+		rows:   row,
+		header: []string{"id", "firstName", "lastName", "email", "emailVerifiedAt", "createdAt", "updatedAt"},
+		records: [][]string{{
+			row.ID, row.FirstName, row.LastName, row.Email, exporter.stringOrEmpty(row.EmailVerifiedAt), row.CreatedAt, row.UpdatedAt,
+		}},
+	}
+}
+
+func (exporter Exporter) analyzeRequestsTable(ctx context.Context, user entities.User) (result table, analyzeRequestIDs []id.ID, err error) {
+	order := database.AnalyzeRequestOrder{Field: database.AnalyzeRequestSortFieldCreatedAt}
+
+	rows := []analyzeRequestRow{}
+	var after *database.AnalyzeRequestCursor
+	for {
+		analyzeRequests, err := exporter.db.AnalyzeRequestRepository().IndexForUser(ctx, user.ID, database.AnalyzeRequestFilter{}, order, after, analyzeRequestsPageSize)
+		if err != nil {
+			return result, analyzeRequestIDs, stacktrace.Propagate(err, "cannot fetch analyze requests of user with ID: %s", user.ID.String())
+		}
+
+		for _, analyzeRequest := range analyzeRequests {
+			analyzeRequestIDs = append(analyzeRequestIDs, analyzeRequest.ID)
+			rows = append(rows, analyzeRequestRow{
+				ID:                analyzeRequest.ID.String(),
+				InputType:         analyzeRequest.InputType,
+				OvChipkaartNumber: analyzeRequest.OvChipkaartNumber,
+				Status:            analyzeRequest.Status.String(),
+				StartDate:         analyzeRequest.StartDate.Format(internalTime.DateFormat),
+				EndDate:           analyzeRequest.EndDate.Format(internalTime.DateFormat),
+				CreatedAt:         analyzeRequest.CreatedAt.Format(internalTime.DefaultFormat),
+				UpdatedAt:         analyzeRequest.UpdatedAt.Format(internalTime.DefaultFormat),
+			})
+		}
+
+		if len(analyzeRequests) < analyzeRequestsPageSize {
+			break
+		}
+
+		last := analyzeRequests[len(analyzeRequests)-1]
+		after = &database.AnalyzeRequestCursor{Value: order.Field.Value(last), ID: last.ID}
+	}
+
+	result = table{
+		name:   "analyze_requests",
+		rows:   rows,
+		header: []string{"id", "inputType", "ovChipkaartNumber", "status", "startDate", "endDate", "createdAt", "updatedAt"},
+	}
+	for _, row := range rows {
+		result.records = append(result.records, []string{
+			row.ID, row.InputType, row.OvChipkaartNumber, row.Status, row.StartDate, row.EndDate, row.CreatedAt, row.UpdatedAt,
+		})
+	}
+
+	return result, analyzeRequestIDs, nil
+}
+
+func (exporter Exporter) cardsTable(ctx context.Context, user entities.User) (result table, err error) {
+	response, err := exporter.rawRecordsServiceClient.FetchCardSyncStates(ctx, &raw_records_service.FetchCardSyncStatesRequest{
+		UserId: user.ID.String(),
+	})
+	if err != nil {
+		return result, stacktrace.Propagate(err, "cannot fetch cards of user with ID: %s", user.ID.String())
+	}
+
+	rows := make([]cardRow, len(response.GetCards()))
+	for index, card := range response.GetCards() {
+		rows[index] = cardRow{
+			ID:                      card.GetId(),
+			OvChipkaartNumber:       card.GetCardNumber(),
+			Status:                  card.GetStatus(),
+			LastTransactionDateTime: exporter.formatTimestamp(card.GetLastTransactionDateTime()),
+			LastSyncedAt:            exporter.formatTimestamp(card.GetLastSyncedAt()),
+			NextSyncAt:              exporter.formatTimestamp(card.GetNextSyncAt()),
+			LastError:               card.GetLastError(),
+			CreatedAt:               exporter.formatTimestamp(card.GetCreatedAt()),
+		}
+	}
+
+	result = table{
+		name:   "cards",
+		rows:   rows,
+		header: []string{"id", "ovChipkaartNumber", "status", "lastTransactionDateTime", "lastSyncedAt", "nextSyncAt", "lastError", "createdAt"},
+	}
+	for _, row := range rows {
+		result.records = append(result.records, []string{
+			row.ID,
+			row.OvChipkaartNumber,
+			row.Status,
+			exporter.stringOrEmpty(row.LastTransactionDateTime),
+			exporter.stringOrEmpty(row.LastSyncedAt),
+			exporter.stringOrEmpty(row.NextSyncAt),
+			row.LastError,
+			exporter.stringOrEmpty(row.CreatedAt),
+		})
+	}
+
+	return result, nil
+}
+
+func (exporter Exporter) rawRecordsTable(ctx context.Context, user entities.User) (result table, err error) {
+	rows := []rawRecordRow{}
+	pageToken := ""
+	for {
+		stream, err := exporter.rawRecordsServiceV2Client.ListRawRecords(ctx, &raw_records_service_v2.ListRawRecordsRequest{
+			UserId:    user.ID.String(),
+			PageSize:  rawRecordsPageSize,
+			PageToken: pageToken,
+		})
+		if err != nil {
+			return result, stacktrace.Propagate(err, "cannot list raw records of user with ID: %s", user.ID.String())
+		}
+
+		pageToken = ""
+		for {
+			response, err := stream.Recv()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return result, stacktrace.Propagate(err, "cannot receive raw records of user with ID: %s", user.ID.String())
+			}
+
+			for _, record := range response.GetRawRecords() {
+				rows = append(rows, exporter.rawRecordToRow(record))
+			}
+
+			if response.GetNextPageToken() != "" {
+				pageToken = response.GetNextPageToken()
+			}
+		}
+
+		if pageToken == "" {
+			break
+		}
+	}
+
+	result = table{
+		name: "raw_records",
+		rows: rows,
+		header: []string{
+			"id", "analyzeRequestId", "ovChipkaartNumber", "transactionDateTime", "transactionName", "transactionInfo",
+			"transactionExplanation", "transactionPriority", "checkInInfo", "checkInText", "fare", "fareCalculation", "fareText",
+			"modalType", "productInfo", "productText", "operator", "ePurseMut", "ePurseMutInfo", "source", "createdAt",
+		},
+	}
+	for _, row := range rows {
+		result.records = append(result.records, []string{
+			row.ID,
+			row.AnalyzeRequestID,
+			row.OvChipkaartNumber,
+			exporter.stringOrEmpty(row.TransactionDateTime),
+			row.TransactionName,
+			row.TransactionInfo,
+			row.TransactionExplanation,
+			row.TransactionPriority,
+			row.CheckInInfo,
+			row.CheckInText,
+			exporter.formatOptionalFloat(row.Fare),
+			row.FareCalculation,
+			row.FareText,
+			row.ModalType,
+			row.ProductInfo,
+			row.ProductText,
+			row.Operator,
+			exporter.formatOptionalFloat(row.EPurseMut),
+			row.EPurseMutInfo,
+			row.Source,
+			exporter.stringOrEmpty(row.CreatedAt),
+		})
+	}
+
+	return result, nil
+}
+
+func (exporter Exporter) enrichedRecordsTable(ctx context.Context, analyzeRequestIDs []id.ID) (result table, err error) {
+	rows := []enrichedRecordRow{}
+	for _, analyzeRequestID := range analyzeRequestIDs {
+		records, err := exporter.db.EnrichedRecordRepository().FetchForAnalyzeRequest(ctx, analyzeRequestID)
+		if err != nil {
+			return result, stacktrace.Propagate(err, "cannot fetch enriched records of analyze request with ID: %s", analyzeRequestID.String())
+		}
+
+		for _, record := range records {
+			rows = append(rows, enrichedRecordRow{
+				ID:               record.ID.String(),
+				RawRecordID:      record.RawRecordID.String(),
+				AnalyzeRequestID: record.AnalyzeRequestID.String(),
+				StartTime:        record.StartTime.Format(internalTime.DefaultFormat),
+				EndTime:          record.EndTime.Format(internalTime.DefaultFormat),
+				StartTimeIsExact: record.StartTimeIsExact,
+				FromStationCode:  record.FromStationCode,
+				ToStationCode:    record.ToStationCode,
+				CompanyName:      record.CompanyName,
+				TransactionType:  record.TransactionType,
+				DurationSeconds:  int64(record.Duration / time.Second),
+			})
+		}
+	}
+
+	result = table{
+		name: "enriched_records",
+		rows: rows,
+		header: []string{
+			"id", "rawRecordId", "analyzeRequestId", "startTime", "endTime", "startTimeIsExact", "fromStationCode",
+			"toStationCode", "companyName", "transactionType", "durationSeconds",
+		},
+	}
+	for _, row := range rows {
+		result.records = append(result.records, []string{
+			row.ID,
+			row.RawRecordID,
+			row.AnalyzeRequestID,
+			row.StartTime,
+			row.EndTime,
+			strconv.FormatBool(row.StartTimeIsExact),
+			row.FromStationCode,
+			row.ToStationCode,
+			row.CompanyName,
+			row.TransactionType,
+			strconv.FormatInt(row.DurationSeconds, 10),
+		})
+	}
+
+	return result, nil
+}
+
+func (exporter Exporter) calculationResultsTable(ctx context.Context, analyzeRequestIDs []id.ID) (result table, err error) {
+	rows := []calculationResultRow{}
+	for _, analyzeRequestID := range analyzeRequestIDs {
+		calculationResults, err := exporter.db.CalculationResultRepository().FetchForAnalyzeRequest(ctx, analyzeRequestID)
+		if err != nil {
+			return result, stacktrace.Propagate(err, "cannot fetch calculation results of analyze request with ID: %s", analyzeRequestID.String())
+		}
+
+		for _, calculationResult := range calculationResults {
+			rows = append(rows, calculationResultRow{
+				AnalyzeRequestID:        calculationResult.AnalyzeRequestID.String(),
+				Subscription:            calculationResult.Subscription,
+				OffPeakFirstClassPrice:  calculationResult.OffPeakFirstClassPrice,
+				OffPeakSecondClassPrice: calculationResult.OffPeakSecondClassPrice,
+				OffPeakJourneyCount:     calculationResult.OffPeakJourneyCount,
+				PeakFirstClassPrice:     calculationResult.PeakFirstClassPrice,
+				PeakSecondClassPrice:    calculationResult.PeakSecondClassPrice,
+				PeakJourneyCount:        calculationResult.PeakJourneyCount,
+				PeakSupplementPrice:     calculationResult.PeakSupplementPrice,
+				PeakSupplementCount:     calculationResult.PeakSupplementCount,
+				OffPeakSupplementPrice:  calculationResult.OffPeakSupplementPrice,
+				OffPeakSupplementCount:  calculationResult.OffPeakSupplementCount,
+				FailedRecordCount:       calculationResult.ErrorCount,
+			})
+		}
+	}
+
+	result = table{
+		name: "results",
+		rows: rows,
+		header: []string{
+			"analyzeRequestId", "subscription", "offPeakFirstClassPrice", "offPeakSecondClassPrice", "offPeakJourneyCount",
+			"peakFirstClassPrice", "peakSecondClassPrice", "peakJourneyCount", "peakSupplementPrice", "peakSupplementCount",
+			"offPeakSupplementPrice", "offPeakSupplementCount", "failedRecordCount",
+		},
+	}
+	for _, row := range rows {
+		result.records = append(result.records, []string{
+			row.AnalyzeRequestID,
+			row.Subscription,
+			strconv.Itoa(row.OffPeakFirstClassPrice),
+			strconv.Itoa(row.OffPeakSecondClassPrice),
+			strconv.Itoa(row.OffPeakJourneyCount),
+			strconv.Itoa(row.PeakFirstClassPrice),
+			strconv.Itoa(row.PeakSecondClassPrice),
+			strconv.Itoa(row.PeakJourneyCount),
+			strconv.Itoa(row.PeakSupplementPrice),
+			strconv.Itoa(row.PeakSupplementCount),
+			strconv.Itoa(row.OffPeakSupplementPrice),
+			strconv.Itoa(row.OffPeakSupplementCount),
+			strconv.Itoa(row.FailedRecordCount),
+		})
+	}
+
+	return result, nil
+}
+
+func (exporter Exporter) rawRecordToRow(record *raw_records_service_v2.RawRecord) rawRecordRow {
+	return rawRecordRow{
+		ID:                     record.GetId(),
+		AnalyzeRequestID:       record.GetAnalyzeRequestId(),
+		OvChipkaartNumber:      record.GetCardNumber(),
+		TransactionDateTime:    exporter.formatTimestamp(record.GetTransactionDateTime()),
+		TransactionName:        record.GetTransactionName(),
+		TransactionInfo:        record.GetTransactionInfo(),
+		TransactionExplanation: record.GetTransactionExplanation(),
+		TransactionPriority:    record.GetTransactionPriority(),
+		CheckInInfo:            record.GetCheckInInfo(),
+		CheckInText:            record.GetCheckInText(),
+		Fare:                   exporter.optionalDouble(record.GetFare()),
+		FareCalculation:        record.GetFareCalculation(),
+		FareText:               record.GetFareText(),
+		ModalType:              record.GetModalType(),
+		ProductInfo:            record.GetProductInfo(),
+		ProductText:            record.GetProductText(),
+		Operator:               record.GetPto(),
+		EPurseMut:              exporter.optionalDouble(record.GetEPurseMut()),
+		EPurseMutInfo:          record.GetEPurseMutInfo(),
+		Source:                 record.GetSource(),
+		CreatedAt:              exporter.formatTimestamp(record.GetCreatedAt()),
+	}
+}
+
+func (exporter Exporter) formatOptionalTime(value *time.Time) *string {
+	if value == nil {
+		return nil
+	}
+
+	formatted := value.UTC().Format(internalTime.DefaultFormat)
+	return &formatted
+}
+
+func (exporter Exporter) formatTimestamp(value *timestamp.Timestamp) *string {
+	if value == nil {
+		return nil
+	}
+
+	formatted := value.AsTime().Format(internalTime.DefaultFormat)
+	return &formatted
+}
+
+func (exporter Exporter) optionalDouble(value *wrappers.DoubleValue) *float64 {
+	if value == nil {
+		return nil
+	}
+
+	result := value.GetValue()
+	return &result
+}
+
+func (exporter Exporter) formatOptionalFloat(value *float64) string {
+	if value == nil {
+		return ""
+	}
+
+	return strconv.FormatFloat(*value, 'f', -1, 64)
+}
+
+func (exporter Exporter) stringOrEmpty(value *string) string {
+	if value == nil {
+		return ""
+	}
+
+	return *value
+}
@@ -0,0 +1,61 @@
+package dataexport
+
+import (
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/entities"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/services/token"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/errors"
+	internalTime "github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/time"
+	"github.com/gorilla/mux"
+	"github.com/palantir/stacktrace"
+)
+
+const (
+	// DownloadRoute is the route of the download link, the token is the secret part of the link
+	DownloadRoute = "/data-exports/{token}"
+
+	// pendingRetryAfter is how many seconds a client should wait before checking a pending archive again
+	pendingRetryAfter = "30"
+)
+
+// DownloadHandler serves the archive of a data export to whoever has the link until the link expires
+func (exporter Exporter) DownloadHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		dataExport, err := exporter.db.DataExportRepository().FindByTokenHash(r.Context(), token.Hash(mux.Vars(r)["token"]))
+		if err == errors.ErrEntityNotFound || (err == nil && time.Now().UTC().After(dataExport.ExpiresAt)) {
+			http.Error(w, "The download link is invalid or has expired", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			exporter.errorHandler.CaptureError(r.Context(), stacktrace.Propagate(err, "cannot find data export"))
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		switch dataExport.Status {
+		case entities.DataExportStatusPending:
+			w.Header().Set("Retry-After", pendingRetryAfter)
+			http.Error(w, "The archive is being prepared, please try again later", http.StatusAccepted)
+			return
+		case entities.DataExportStatusFailed:
+			http.Error(w, "The archive could not be prepared, please request a new data export", http.StatusNotFound)
+			return
+		}
+
+		file, err := os.Open(exporter.archivePath(dataExport.ID))
+		if err != nil {
+			exporter.errorHandler.CaptureError(r.Context(), stacktrace.Propagate(err, "cannot open archive of data export with ID: %s", dataExport.ID.String()))
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		defer func() { _ = file.Close() }()
+
+		w.Header().Set("Content-Type", "application/zip")
+		w.Header().Set("Content-Disposition", `attachment; filename="ov-chipkaart-dashboard-export-`+dataExport.CreatedAt.Format(internalTime.DateFormat)+`.zip"`)
+		w.Header().Set("Cache-Control", "no-store")
+		http.ServeContent(w, r, "", dataExport.UpdatedAt, file)
+	}
+}
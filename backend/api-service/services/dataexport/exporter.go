@@ -0,0 +1,256 @@
+package dataexport
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/database"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/entities"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/services/mailer"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/services/token"
+	internalContext "github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/context"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/errorhandler"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/id"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/lifecycle"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/logger"
+	raw_records_service "github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/proto/raw-records-service"
+	raw_records_service_v2 "github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/proto/raw-records-service/v2"
+	internalTime "github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/time"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/tracing"
+	"github.com/palantir/stacktrace"
+)
+
+const (
+	// buildTimeout is how long collecting the data of a user and writing the archive can take.
+	// An export which is still pending after the build timeout was abandoned by a restart.
+	buildTimeout = 10 * time.Minute
+)
+
+// Options configures where archives are stored and how long they can be downloaded
+type Options struct {
+	// Directory is where the archives are written. An archive is downloaded from the instance which handles the
+	// request so the directory must be shared by all the instances of the api service when it runs more than one.
+	Directory string
+	// APIURL is the public URL of the api service which serves the download links
+	APIURL string
+	// LinkLifetime is how long the download link of an archive is valid
+	LinkLifetime time.Duration
+}
+
+// Exporter builds ZIP archives with all the data which is stored about a user
+type Exporter struct {
+	db                        database.DB
+	rawRecordsServiceClient   raw_records_service.RawRecordsServiceClient
+	rawRecordsServiceV2Client raw_records_service_v2.RawRecordsServiceClient
+	mailer                    mailer.Mailer
+	errorHandler              errorhandler.ErrorHandler
+	logger                    logger.Logger
+	options                   Options
+	// builds tracks the archives which are being built so that the shutdown can wait for them
+	builds *sync.WaitGroup
+}
+
+// NewExporter creates a new instance of the data exporter
+func NewExporter(
+	db database.DB,
+	rawRecordsServiceClient raw_records_service.RawRecordsServiceClient,
+	rawRecordsServiceV2Client raw_records_service_v2.RawRecordsServiceClient,
+	mailer mailer.Mailer,
+	errorHandler errorhandler.ErrorHandler,
+	logger logger.Logger,
+	options Options,
+) Exporter {
+	return Exporter{
+		db:                        db,
+		rawRecordsServiceClient:   rawRecordsServiceClient,
+		rawRecordsServiceV2Client: rawRecordsServiceV2Client,
+		mailer:                    mailer,
+		errorHandler:              errorHandler,
+		logger:                    logger,
+		options:                   options,
+		builds:                    &sync.WaitGroup{},
+	}
+}
+
+// Request stores a pending export and builds its archive in the background.
+// The download link is returned immediately and works once the archive is ready.
+func (exporter Exporter) Request(ctx context.Context, user entities.User) (dataExport entities.DataExport, downloadURL string, err error) {
+	plainToken, tokenHash, err := token.Generate()
+	if err != nil {
+		return dataExport, downloadURL, stacktrace.Propagate(err, "cannot generate data export token")
+	}
+
+	dataExport = entities.DataExport{
+		ID:        id.New(),
+		UserID:    user.ID,
+		TokenHash: tokenHash,
+		Status:    entities.DataExportStatusPending,
+		ExpiresAt: time.Now().UTC().Add(exporter.options.LinkLifetime),
+		CreatedAt: time.Now().UTC(),
+		UpdatedAt: time.Now().UTC(),
+	}
+
+	err = exporter.db.DataExportRepository().Store(ctx, dataExport)
+	if err != nil {
+		return dataExport, downloadURL, stacktrace.Propagate(err, "cannot store data export for user with ID: %s", user.ID.String())
+	}
+
+	downloadURL = exporter.options.APIURL + "/data-exports/" + plainToken
+
+	exporter.builds.Add(1)
+	go func() {
+		defer exporter.builds.Done()
+		exporter.build(dataExport, user, downloadURL)
+	}()
+
+	return dataExport, downloadURL, nil
+}
+
+// Wait blocks until the archives which are being built are written or the timeout has passed and reports if they were
+func (exporter Exporter) Wait(timeout time.Duration) bool {
+	stopped := make(chan struct{})
+	go func() {
+		exporter.builds.Wait()
+		close(stopped)
+	}()
+
+	return lifecycle.Wait(stopped, timeout)
+}
+
+// FailAbandoned marks the data exports which are still pending after the build timeout as failed.
+// Their build was interrupted by a restart so the user must be able to request a new export.
+func (exporter Exporter) FailAbandoned(ctx context.Context) error {
+	now := time.Now().UTC()
+	count, err := exporter.db.DataExportRepository().FailPendingCreatedBefore(ctx, now.Add(-buildTimeout), now)
+	if err != nil {
+		return stacktrace.Propagate(err, "cannot fail the abandoned data exports")
+	}
+
+	if count > 0 {
+		exporter.logger.Info(ctx, "failed abandoned data exports", "count", count)
+	}
+
+	return nil
+}
+
+// DeleteForUser deletes the archives and the data exports of a user
+func (exporter Exporter) DeleteForUser(ctx context.Context, userID id.ID) error {
+	dataExports, err := exporter.db.DataExportRepository().FetchForUser(ctx, userID)
+	if err != nil {
+		return stacktrace.Propagate(err, "cannot fetch data exports of user with ID: %s", userID.String())
+	}
+
+	for _, dataExport := range dataExports {
+		err = os.Remove(exporter.archivePath(dataExport.ID))
+		if err != nil && !os.IsNotExist(err) {
+			return stacktrace.Propagate(err, "cannot delete archive of data export with ID: %s", dataExport.ID.String())
+		}
+	}
+
+	return exporter.db.DataExportRepository().DeleteForUser(ctx, userID)
+}
+
+// DeleteExpired deletes the archives and the data exports whose download link has expired
+func (exporter Exporter) DeleteExpired(ctx context.Context) error {
+	dataExports, err := exporter.db.DataExportRepository().FetchExpired(ctx, time.Now().UTC())
+	if err != nil {
+		return stacktrace.Propagate(err, "cannot fetch expired data exports")
+	}
+
+	for _, dataExport := range dataExports {
+		if ctx.Err() != nil {
+			return stacktrace.Propagate(ctx.Err(), "the cleanup of the expired data exports was cancelled")
+		}
+
+		// the archive is deleted first so that a failure leaves the record which is needed to retry
+		err = os.Remove(exporter.archivePath(dataExport.ID))
+		if err != nil && !os.IsNotExist(err) {
+			return stacktrace.Propagate(err, "cannot delete archive of data export with ID: %s", dataExport.ID.String())
+		}
+
+		err = exporter.db.DataExportRepository().Delete(ctx, dataExport.ID)
+		if err != nil {
+			return stacktrace.Propagate(err, "cannot delete data export with ID: %s", dataExport.ID.String())
+		}
+	}
+
+	if len(dataExports) > 0 {
+		exporter.logger.Info(ctx, "deleted expired data exports", "count", len(dataExports))
+	}
+
+	return nil
+}
+
+// RunCleanup deletes the expired data exports and fails the abandoned data exports when it starts and at every
+// interval until the context is cancelled
+func (exporter Exporter) RunCleanup(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		err := exporter.DeleteExpired(ctx)
+		if err != nil && ctx.Err() == nil {
+			exporter.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "cannot delete expired data exports"))
+		}
+
+		err = exporter.FailAbandoned(ctx)
+		if err != nil && ctx.Err() == nil {
+			exporter.errorHandler.CaptureError(ctx, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (exporter Exporter) build(dataExport entities.DataExport, user entities.User, downloadURL string) {
+	ctx, cancel := context.WithTimeout(context.Background(), buildTimeout)
+	defer cancel()
+
+	ctx = tracing.WithValue(ctx, internalContext.KeyUserID, user.ID.String())
+
+	status := entities.DataExportStatusCompleted
+	err := exporter.writeArchive(ctx, user, exporter.archivePath(dataExport.ID))
+	if err != nil {
+		exporter.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "cannot build data export with ID: %s", dataExport.ID.String()))
+		status = entities.DataExportStatusFailed
+		_ = os.Remove(exporter.archivePath(dataExport.ID))
+	}
+
+	err = exporter.db.DataExportRepository().UpdateStatus(ctx, dataExport.ID, status, time.Now().UTC())
+	if err != nil {
+		exporter.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "cannot update status of data export with ID: %s", dataExport.ID.String()))
+		return
+	}
+
+	exporter.logger.Info(ctx, "built data export", "data_export_id", dataExport.ID.String(), "status", status.String())
+
+	if status != entities.DataExportStatusCompleted {
+		return
+	}
+
+	err = exporter.mailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Your data export is ready",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nThe archive with your data is ready. Use the link below to download it before %s UTC.\n\n%s\n\nIf you didn't request a copy of your data, please change your password.\n",
+			user.FirstName,
+			dataExport.ExpiresAt.Format(internalTime.DefaultFormat),
+			downloadURL,
+		),
+	})
+	if err != nil {
+		exporter.errorHandler.CaptureError(ctx, stacktrace.Propagate(err, "cannot send data export email to user with ID: %s", user.ID.String()))
+	}
+}
+
+func (exporter Exporter) archivePath(dataExportID id.ID) string {
+	return filepath.Join(exporter.options.Directory, dataExportID.String()+".zip")
+}
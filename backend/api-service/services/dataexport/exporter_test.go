@@ -0,0 +1,182 @@
+package dataexport
+
+import (
+	"context"
+	"io/ioutil"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/database"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/entities"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/id"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/logger"
+	raw_records_service "github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/proto/raw-records-service"
+	"github.com/palantir/stacktrace"
+	"google.golang.org/grpc"
+)
+
+// fakeDB keeps the data exports in memory, the user doesn't have any analyze requests
+type fakeDB struct {
+	database.DB
+	mutex       sync.Mutex
+	dataExports map[id.ID]entities.DataExport
+}
+
+func (db *fakeDB) DataExportRepository() database.DataExportRepository {
+	return fakeDataExportRepository{db: db}
+}
+
+func (db *fakeDB) AnalyzeRequestRepository() database.AnalyzeRequestRepository {
+	return fakeAnalyzeRequestRepository{}
+}
+
+func (db *fakeDB) find(dataExportID id.ID) entities.DataExport {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+
+	return db.dataExports[dataExportID]
+}
+
+type fakeDataExportRepository struct {
+	database.DataExportRepository
+	db *fakeDB
+}
+
+func (repository fakeDataExportRepository) Store(_ context.Context, dataExport entities.DataExport) error {
+	repository.db.mutex.Lock()
+	defer repository.db.mutex.Unlock()
+
+	repository.db.dataExports[dataExport.ID] = dataExport
+	return nil
+}
+
+func (repository fakeDataExportRepository) UpdateStatus(_ context.Context, dataExportID id.ID, status entities.DataExportStatus, updatedAt time.Time) error {
+	repository.db.mutex.Lock()
+	defer repository.db.mutex.Unlock()
+
+	dataExport := repository.db.dataExports[dataExportID]
+	dataExport.Status, dataExport.UpdatedAt = status, updatedAt
+	repository.db.dataExports[dataExportID] = dataExport
+	return nil
+}
+
+func (repository fakeDataExportRepository) FailPendingCreatedBefore(_ context.Context, before time.Time, updatedAt time.Time) (count int64, err error) {
+	repository.db.mutex.Lock()
+	defer repository.db.mutex.Unlock()
+
+	for dataExportID, dataExport := range repository.db.dataExports {
+		if dataExport.Status == entities.DataExportStatusPending && dataExport.CreatedAt.Before(before) {
+			dataExport.Status, dataExport.UpdatedAt = entities.DataExportStatusFailed, updatedAt
+			repository.db.dataExports[dataExportID] = dataExport
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+type fakeAnalyzeRequestRepository struct {
+	database.AnalyzeRequestRepository
+}
+
+func (fakeAnalyzeRequestRepository) IndexForUser(context.Context, id.ID, database.AnalyzeRequestFilter, database.AnalyzeRequestOrder, *database.AnalyzeRequestCursor, int) ([]entities.AnalyzeRequest, error) {
+	return nil, nil
+}
+
+// unavailableRawRecordsServiceClient fails to fetch the cards once it is released so that builds can be held in progress
+type unavailableRawRecordsServiceClient struct {
+	raw_records_service.RawRecordsServiceClient
+	release chan struct{}
+}
+
+func (client unavailableRawRecordsServiceClient) FetchCardSyncStates(context.Context, *raw_records_service.FetchCardSyncStatesRequest, ...grpc.CallOption) (*raw_records_service.FetchCardSyncStatesResponse, error) {
+	<-client.release
+	return nil, stacktrace.NewError("the raw records service is unavailable")
+}
+
+// discardErrorHandler ignores the errors of builds which are expected to fail
+type discardErrorHandler struct{}
+
+func (discardErrorHandler) CaptureError(context.Context, error) {}
+
+func newTestExporter(t *testing.T, db *fakeDB, release chan struct{}) Exporter {
+	return NewExporter(
+		db,
+		unavailableRawRecordsServiceClient{release: release},
+		nil,
+		nil,
+		discardErrorHandler{},
+		logger.NewGoKitLogger(ioutil.Discard, logger.LevelError),
+		Options{Directory: t.TempDir(), LinkLifetime: time.Hour},
+	)
+}
+
+func TestExporterWait(t *testing.T) {
+	db := &fakeDB{dataExports: map[id.ID]entities.DataExport{}}
+	release := make(chan struct{})
+	exporter := newTestExporter(t, db, release)
+
+	if !exporter.Wait(time.Millisecond) {
+		t.Fatal("Wait timed out without builds")
+	}
+
+	dataExport, _, err := exporter.Request(context.Background(), entities.User{ID: id.New()})
+	if err != nil {
+		t.Fatalf("Request returned an error: %s", err)
+	}
+
+	if exporter.Wait(10 * time.Millisecond) {
+		t.Fatal("Wait returned before the build completed")
+	}
+
+	close(release)
+	if !exporter.Wait(time.Second) {
+		t.Fatal("Wait timed out after the build completed")
+	}
+
+	// the status is updated before the build is done so it is final when Wait returns
+	if status := db.find(dataExport.ID).Status; status != entities.DataExportStatusFailed {
+		t.Errorf("the data export is %s, expected it to have failed", status)
+	}
+}
+
+func TestExporterFailAbandoned(t *testing.T) {
+	now := time.Now().UTC()
+	tests := []struct {
+		name           string
+		dataExport     entities.DataExport
+		expectedStatus entities.DataExportStatus
+	}{
+		{
+			name:           "a pending export which was requested before the build timeout",
+			dataExport:     entities.DataExport{ID: id.New(), Status: entities.DataExportStatusPending, CreatedAt: now.Add(-buildTimeout - time.Minute)},
+			expectedStatus: entities.DataExportStatusFailed,
+		},
+		{
+			name:           "a pending export which another instance may still be building",
+			dataExport:     entities.DataExport{ID: id.New(), Status: entities.DataExportStatusPending, CreatedAt: now.Add(-buildTimeout + time.Minute)},
+			expectedStatus: entities.DataExportStatusPending,
+		},
+		{
+			name:           "a completed export",
+			dataExport:     entities.DataExport{ID: id.New(), Status: entities.DataExportStatusCompleted, CreatedAt: now.Add(-buildTimeout - time.Minute)},
+			expectedStatus: entities.DataExportStatusCompleted,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db := &fakeDB{dataExports: map[id.ID]entities.DataExport{test.dataExport.ID: test.dataExport}}
+
+			err := newTestExporter(t, db, nil).FailAbandoned(context.Background())
+			if err != nil {
+				t.Fatalf("FailAbandoned returned an error: %s", err)
+			}
+
+			if status := db.find(test.dataExport.ID).Status; status != test.expectedStatus {
+				t.Errorf("the data export is %s, expected %s", status, test.expectedStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	statusOk          = "ok"
+	statusUnavailable = "unavailable"
+	statusDraining    = "draining"
+)
+
+// Check returns an error when a dependency cannot be used
+type Check func(ctx context.Context) error
+
+// Checker reports whether the api service is alive and ready to receive requests
+type Checker struct {
+	checks   map[string]Check
+	timeout  time.Duration
+	draining int32
+}
+
+// Response is the body of the health endpoints
+type Response struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// NewChecker creates a checker which runs every check within the timeout
+func NewChecker(timeout time.Duration, checks map[string]Check) *Checker {
+	return &Checker{
+		checks:  checks,
+		timeout: timeout,
+	}
+}
+
+// StartDraining makes the readiness check fail so that load balancers stop sending new requests during a shutdown
+func (checker *Checker) StartDraining() {
+	atomic.StoreInt32(&checker.draining, 1)
+}
+
+// LivenessHandler reports that the process is running, it doesn't check dependencies so that an outage of a
+// dependency doesn't restart every instance of the api service
+func (checker *Checker) LivenessHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		checker.respond(w, http.StatusOK, Response{Status: statusOk})
+	}
+}
+
+// ReadinessHandler reports whether every dependency can be reached
+func (checker *Checker) ReadinessHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&checker.draining) == 1 {
+			checker.respond(w, http.StatusServiceUnavailable, Response{Status: statusDraining})
+			return
+		}
+
+		results := checker.runChecks(r.Context())
+
+		response := Response{Status: statusOk, Checks: results}
+		statusCode := http.StatusOK
+		for _, result := range results {
+			if result != statusOk {
+				response.Status = statusUnavailable
+				statusCode = http.StatusServiceUnavailable
+			}
+		}
+
+		checker.respond(w, statusCode, response)
+	}
+}
+
+// runChecks runs the checks concurrently and returns ok or the error of every check
+func (checker *Checker) runChecks(ctx context.Context) map[string]string {
+	ctx, cancel := context.WithTimeout(ctx, checker.timeout)
+	defer cancel()
+
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	results := make(map[string]string, len(checker.checks))
+
+	for name, check := range checker.checks {
+		wg.Add(1)
+		go func(name string, check Check) {
+			defer wg.Done()
+
+			result := statusOk
+			if err := check(ctx); err != nil {
+				result = err.Error()
+			}
+
+			mutex.Lock()
+			results[name] = result
+			mutex.Unlock()
+		}(name, check)
+	}
+
+	wg.Wait()
+	return results
+}
+
+func (checker *Checker) respond(w http.ResponseWriter, statusCode int, response Response) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,27 @@
+package health
+
+import (
+	"context"
+
+	"github.com/palantir/stacktrace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// GRPCCheck checks that a downstream service reports itself as serving with grpc.health.v1
+func GRPCCheck(conn *grpc.ClientConn) Check {
+	client := grpc_health_v1.NewHealthClient(conn)
+
+	return func(ctx context.Context) error {
+		response, err := client.Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+		if err != nil {
+			return stacktrace.Propagate(err, "cannot check the health of %s", conn.Target())
+		}
+
+		if response.GetStatus() != grpc_health_v1.HealthCheckResponse_SERVING {
+			return stacktrace.NewError("%s is %s", conn.Target(), response.GetStatus().String())
+		}
+
+		return nil
+	}
+}
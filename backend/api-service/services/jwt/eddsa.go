@@ -0,0 +1,52 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// SigningMethodEdDSA signs tokens with Ed25519 keys as described in RFC 8037
+var SigningMethodEdDSA = &signingMethodEdDSA{}
+
+type signingMethodEdDSA struct{}
+
+func init() {
+	jwt.RegisterSigningMethod(SigningMethodEdDSA.Alg(), func() jwt.SigningMethod {
+		return SigningMethodEdDSA
+	})
+}
+
+// Alg returns the name of the algorithm in the JWT header
+func (method *signingMethodEdDSA) Alg() string {
+	return "EdDSA"
+}
+
+// Verify checks the signature with an ed25519.PublicKey
+func (method *signingMethodEdDSA) Verify(signingString string, signature string, key interface{}) error {
+	publicKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return jwt.ErrInvalidKeyType
+	}
+
+	signatureBytes, err := jwt.DecodeSegment(signature)
+	if err != nil {
+		return err
+	}
+
+	if !ed25519.Verify(publicKey, []byte(signingString), signatureBytes) {
+		return jwt.ErrSignatureInvalid
+	}
+
+	return nil
+}
+
+// Sign signs the string with an ed25519.PrivateKey
+func (method *signingMethodEdDSA) Sign(signingString string, key interface{}) (string, error) {
+	privateKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return "", jwt.ErrInvalidKeyType
+	}
+
+	return jwt.EncodeSegment(ed25519.Sign(privateKey, []byte(signingString))), nil
+}
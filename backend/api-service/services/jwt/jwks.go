@@ -0,0 +1,76 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"sort"
+)
+
+// JSONWebKey is the public part of a signing key as described in RFC 7517
+type JSONWebKey struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg"`
+	Use       string `json:"use"`
+	// N and E are set for RSA keys
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// Curve and X are set for Ed25519 keys
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+}
+
+// JSONWebKeySet is the document served at /.well-known/jwks.json
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// JWKS returns the public keys which can verify tokens issued by the service
+func (service Service) JWKS() JSONWebKeySet {
+	keySet := JSONWebKeySet{Keys: []JSONWebKey{}}
+	for _, key := range service.keySet.Keys() {
+		webKey := JSONWebKey{
+			KeyID:     key.ID,
+			Algorithm: key.Method.Alg(),
+			Use:       "sig",
+		}
+
+		switch publicKey := key.PublicKey().(type) {
+		case *rsa.PublicKey:
+			webKey.KeyType = "RSA"
+			webKey.N = base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes())
+			webKey.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes())
+		case ed25519.PublicKey:
+			webKey.KeyType = "OKP"
+			webKey.Curve = "Ed25519"
+			webKey.X = base64.RawURLEncoding.EncodeToString(publicKey)
+		default:
+			continue
+		}
+
+		keySet.Keys = append(keySet.Keys, webKey)
+	}
+
+	sort.Slice(keySet.Keys, func(i, j int) bool {
+		return keySet.Keys[i].KeyID < keySet.Keys[j].KeyID
+	})
+
+	return keySet
+}
+
+// JWKSHandler serves the public keys so other services can verify tokens without a shared secret
+func (service Service) JWKSHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "public, max-age=300")
+
+		err := json.NewEncoder(w).Encode(service.JWKS())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	}
+}
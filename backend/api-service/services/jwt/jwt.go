@@ -1,9 +1,9 @@
 package jwt
 
 import (
+	"strconv"
 	"time"
 
-	internalTime "github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/time"
 	"github.com/palantir/stacktrace"
 
 	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/id"
@@ -15,8 +15,13 @@ import (
 )
 
 const (
-	keyUserID = "user_id"
-	keyExp    = "exp"
+	headerKeyID = "kid"
+
+	// cacheKeyPrefixBlacklist prefixes the cache key of a token id which was invalidated
+	cacheKeyPrefixBlacklist = "jwt_blacklist:"
+
+	// cacheKeyPrefixUserTokensRevokedAt prefixes the cache key which stores when all the tokens of a user were revoked
+	cacheKeyPrefixUserTokensRevokedAt = "user_tokens_revoked_at:"
 )
 
 var (
@@ -25,42 +30,71 @@ var (
 )
 
 var (
-	// ErrCodeInvalidExpiryDate is the error code when claims[keyExp] is invalid
-	ErrCodeInvalidExpiryDate = stacktrace.ErrorCode(1)
+	// ErrCodeUnknownKey is the error code when a token was signed with a key which is not in the key set
+	ErrCodeUnknownKey = stacktrace.ErrorCode(74)
 )
 
+// Claims are the claims of an access token
+type Claims struct {
+	jwt.StandardClaims
+	// SessionID is the id of the refresh token family which the access token was issued for
+	SessionID string `json:"sid,omitempty"`
+	// IssuedAtNano is the issue time in nanoseconds so that a token which is issued right after a revocation
+	// is not rejected because it has the same issued at second as the revocation
+	IssuedAtNano int64 `json:"iat_ns,omitempty"`
+}
+
+// issuedAt returns the issue time with the highest precision that the token has
+func (claims Claims) issuedAt() time.Time {
+	if claims.IssuedAtNano != 0 {
+		return time.Unix(0, claims.IssuedAtNano)
+	}
+
+	return time.Unix(claims.IssuedAt, 0)
+}
+
 // Service is a new instance of the JWT service
 type Service struct {
-	secret      []byte
-	cache       cache.Cache
-	sessionDays int
+	keySet   KeySet
+	cache    cache.Cache
+	lifetime time.Duration
 }
 
-// NewService creates a new instance of the JWT service
-func NewService(secret string, cache cache.Cache, sessionDays int) Service {
+// NewService creates a new instance of the JWT service. Access tokens are short lived and renewed with refresh tokens.
+func NewService(keySet KeySet, cache cache.Cache, lifetime time.Duration) Service {
 	return Service{
-		secret:      []byte(secret),
-		cache:       cache,
-		sessionDays: sessionDays,
+		keySet:   keySet,
+		cache:    cache,
+		lifetime: lifetime,
 	}
 }
 
-//GenerateTokenForUserID generates a jwt token and assign a email to it's claims and return it
-func (service Service) GenerateTokenForUserID(UserID id.ID) (result string, err error) {
-	token := jwt.New(jwt.SigningMethodHS256)
-
-	/* Create a map to store our claims */
-	claims := token.Claims.(jwt.MapClaims)
+// Lifetime returns how long an access token is valid
+func (service Service) Lifetime() time.Duration {
+	return service.lifetime
+}
 
-	/* Set token claims */
-	claims[keyExp] = time.Now().UTC().AddDate(0, 0, service.sessionDays).String()
-	claims["nbf"] = time.Now().UTC().Unix()
-	claims["iat"] = time.Now().UTC().Unix()
-	claims[keyUserID] = UserID.String()
+//GenerateTokenForUserID generates a jwt token for a user's session and return it
+func (service Service) GenerateTokenForUserID(UserID id.ID, sessionID string) (result string, err error) {
+	now := time.Now().UTC()
+	key := service.keySet.Active()
+
+	token := jwt.NewWithClaims(key.Method, Claims{
+		StandardClaims: jwt.StandardClaims{
+			Subject:   UserID.String(),
+			Id:        id.New().String(),
+			IssuedAt:  now.Unix(),
+			NotBefore: now.Unix(),
+			ExpiresAt: now.Add(service.lifetime).Unix(),
+		},
+		SessionID:    sessionID,
+		IssuedAtNano: now.UnixNano(),
+	})
+	token.Header[headerKeyID] = key.ID
 
-	result, err = token.SignedString(service.secret)
+	result, err = token.SignedString(key.PrivateKey)
 	if err != nil {
-		return result, err
+		return result, stacktrace.Propagate(err, "cannot sign token with key %s", key.ID)
 	}
 
 	return result, nil
@@ -68,69 +102,115 @@ func (service Service) GenerateTokenForUserID(UserID id.ID) (result string, err
 
 // IsValid checks if a token is valid
 func (service Service) IsValid(tokenString string) bool {
-	_, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		return service.secret, nil
-	})
+	_, err := service.parseValidToken(tokenString)
+	return err == nil
+}
 
+// InvalidateToken invalidates a jwt token until it expires
+func (service Service) InvalidateToken(tokenString string) (err error) {
+	claims, err := service.parse(tokenString)
 	if err != nil {
-		return false
+		return err
 	}
 
-	_, err = service.cache.Get(tokenString)
-	if err == nil {
-		return false
+	return service.cache.Set(cacheKeyPrefixBlacklist+claims.Id, "", time.Until(time.Unix(claims.ExpiresAt, 0)))
+}
+
+//GetUserIDFromToken parses a jwt token and returns the user ID
+func (service Service) GetUserIDFromToken(tokenString string) (userID id.ID, err error) {
+	claims, err := service.parseValidToken(tokenString)
+	if err != nil {
+		return userID, err
 	}
 
-	return true
+	return id.FromString(claims.Subject)
 }
 
-// InvalidateToken invalidates a jwt token
-func (service Service) InvalidateToken(tokenString string) (err error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		return service.secret, nil
-	})
+// GetClaimsFromToken parses a token which has not been invalidated and returns its claims
+func (service Service) GetClaimsFromToken(tokenString string) (*Claims, error) {
+	return service.parseValidToken(tokenString)
+}
 
+// InvalidateTokensForUser invalidates all the tokens which were issued to a user until now
+func (service Service) InvalidateTokensForUser(userID id.ID) error {
+	// tokens which are older than the token lifetime are already expired
+	return service.cache.Set(cacheKeyPrefixUserTokensRevokedAt+userID.String(), time.Now().UTC().Format(time.RFC3339Nano), service.lifetime)
+}
+
+// GetSessionIDFromToken returns the id of the refresh token family which the token was issued for
+func (service Service) GetSessionIDFromToken(tokenString string) (sessionID string, err error) {
+	claims, err := service.parse(tokenString)
 	if err != nil {
-		return err
+		return sessionID, err
 	}
 
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok || !token.Valid {
-		return nil
+	return claims.SessionID, nil
+}
+
+// parseValidToken parses a token which has not been invalidated
+func (service Service) parseValidToken(tokenString string) (*Claims, error) {
+	claims, err := service.parse(tokenString)
+	if err != nil {
+		return nil, err
 	}
 
-	expiryDate, ok := claims[keyExp].(string)
-	if !ok {
-		return stacktrace.NewErrorWithCode(ErrCodeInvalidExpiryDate, "cannot get expiry date from claim %v", claims[keyExp])
+	_, err = service.cache.Get(cacheKeyPrefixBlacklist + claims.Id)
+	if err == nil {
+		return nil, ErrTokenBlacklisted
 	}
 
-	expiryTime, err := time.Parse(internalTime.StringFormat, expiryDate)
-	if err != nil {
-		return stacktrace.NewErrorWithCode(ErrCodeInvalidExpiryDate, "cannot parse claim expiry date %s with time format %s", expiryDate, internalTime.StringFormat)
+	if service.isRevokedForUser(claims) {
+		return nil, ErrTokenBlacklisted
 	}
 
-	return service.cache.Set(tokenString, "", expiryTime.Sub(time.Now().UTC()))
+	return claims, nil
 }
 
-//GetUserIDFromToken parses a jwt token and returns the user ID
-func (service Service) GetUserIDFromToken(tokenString string) (userID id.ID, err error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		return service.secret, nil
+// parse verifies the signature and the registered claims of a token
+func (service Service) parse(tokenString string) (*Claims, error) {
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		keyID, _ := token.Header[headerKeyID].(string)
+		key, ok := service.keySet.Find(keyID)
+		if !ok {
+			return nil, stacktrace.NewErrorWithCode(ErrCodeUnknownKey, "token was signed with an unknown key %s", keyID)
+		}
+
+		// the algorithm must match the key so a public key cannot be used as an HMAC secret
+		if token.Method.Alg() != key.Method.Alg() {
+			return nil, stacktrace.NewErrorWithCode(ErrCodeUnknownKey, "token algorithm %s doesn't match key %s", token.Method.Alg(), keyID)
+		}
+
+		return key.PublicKey(), nil
 	})
-
 	if err != nil {
-		return userID, err
+		return nil, err
 	}
 
-	_, err = service.cache.Get(tokenString)
-	if err == nil {
-		return userID, ErrTokenBlacklisted
+	if !token.Valid {
+		return nil, stacktrace.NewError("token is not valid")
 	}
 
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok || !token.Valid {
-		return userID, err
+	return claims, nil
+}
+
+// isRevokedForUser checks if the token was issued before or when all the tokens of its user were revoked
+func (service Service) isRevokedForUser(claims *Claims) bool {
+	revokedAt, err := service.cache.Get(cacheKeyPrefixUserTokensRevokedAt + claims.Subject)
+	if err != nil {
+		return false
+	}
+
+	revokedAtTime, err := time.Parse(time.RFC3339Nano, revokedAt)
+	if err != nil {
+		// revocations which were stored before the time had sub-second precision
+		revokedAtUnix, err := strconv.ParseInt(revokedAt, 10, 64)
+		if err != nil {
+			return false
+		}
+		revokedAtTime = time.Unix(revokedAtUnix, 0)
 	}
 
-	return id.FromString(claims[keyUserID].(string))
+	// a token which was issued at the same time as the revocation is revoked as well
+	return !claims.issuedAt().After(revokedAtTime)
 }
@@ -0,0 +1,95 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/palantir/stacktrace"
+)
+
+// Key is a private key which signs tokens. Its id is sent as the kid header so verifiers can pick the right public key.
+type Key struct {
+	ID         string
+	Method     jwt.SigningMethod
+	PrivateKey crypto.Signer
+}
+
+// PublicKey returns the public part of the key
+func (key Key) PublicKey() crypto.PublicKey {
+	return key.PrivateKey.Public()
+}
+
+// KeyFromPEM parses an RSA or Ed25519 private key. RSA keys sign with RS256 and Ed25519 keys with EdDSA.
+func KeyFromPEM(keyID string, pemBytes []byte) (key Key, err error) {
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return key, stacktrace.NewError("key %s is not PEM encoded", keyID)
+	}
+
+	var privateKey interface{}
+	if block.Type == "RSA PRIVATE KEY" {
+		privateKey, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	} else {
+		privateKey, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return key, stacktrace.Propagate(err, "cannot parse private key %s", keyID)
+	}
+
+	switch privateKey := privateKey.(type) {
+	case *rsa.PrivateKey:
+		return Key{ID: keyID, Method: jwt.SigningMethodRS256, PrivateKey: privateKey}, nil
+	case ed25519.PrivateKey:
+		return Key{ID: keyID, Method: SigningMethodEdDSA, PrivateKey: privateKey}, nil
+	default:
+		return key, stacktrace.NewError("key %s has an unsupported type %T", keyID, privateKey)
+	}
+}
+
+// KeySet contains the key which signs new tokens and the keys which are still accepted during a rotation
+type KeySet struct {
+	active Key
+	keys   map[string]Key
+}
+
+// NewKeySet creates a key set. Tokens are signed with the active key and verified with any key in the set
+// so a new key can be introduced before the old key is removed.
+func NewKeySet(activeKeyID string, keys []Key) (keySet KeySet, err error) {
+	keySet.keys = make(map[string]Key, len(keys))
+	for _, key := range keys {
+		keySet.keys[key.ID] = key
+	}
+
+	active, ok := keySet.keys[activeKeyID]
+	if !ok {
+		return keySet, stacktrace.NewError("active key %s is not in the key set", activeKeyID)
+	}
+	keySet.active = active
+
+	return keySet, nil
+}
+
+// Active returns the key which signs new tokens
+func (keySet KeySet) Active() Key {
+	return keySet.active
+}
+
+// Find returns the key with the given id
+func (keySet KeySet) Find(keyID string) (Key, bool) {
+	key, ok := keySet.keys[keyID]
+	return key, ok
+}
+
+// Keys returns all the keys in the set
+func (keySet KeySet) Keys() []Key {
+	keys := make([]Key, 0, len(keySet.keys))
+	for _, key := range keySet.keys {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
@@ -0,0 +1,24 @@
+package mailer
+
+import (
+	"context"
+
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/logger"
+)
+
+// LogMailer writes emails to a logger instead of sending them. It is used for local development.
+type LogMailer struct {
+	logger logger.Logger
+}
+
+// NewLogMailer creates a new instance of the log mailer
+func NewLogMailer(logger logger.Logger) Mailer {
+	return &LogMailer{logger: logger}
+}
+
+// Send logs the email. The body is only logged at the debug level because it contains links with tokens.
+func (mailer LogMailer) Send(message Message) error {
+	mailer.logger.Info(context.Background(), "email", "to", message.To, "subject", message.Subject)
+	mailer.logger.Debug(context.Background(), "email body", "body", message.Body)
+	return nil
+}
@@ -0,0 +1,13 @@
+package mailer
+
+// Message is an email which is sent to a single recipient
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends emails
+type Mailer interface {
+	Send(message Message) error
+}
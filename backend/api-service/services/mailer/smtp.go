@@ -0,0 +1,62 @@
+package mailer
+
+import (
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+
+	"github.com/palantir/stacktrace"
+)
+
+// SMTPOptions are the params for initializing the SMTP mailer
+type SMTPOptions struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+// SMTPMailer sends emails through an SMTP server
+type SMTPMailer struct {
+	options SMTPOptions
+}
+
+// NewSMTPMailer creates a new instance of the SMTP mailer
+func NewSMTPMailer(options SMTPOptions) Mailer {
+	return &SMTPMailer{options: options}
+}
+
+// Send sends a plain text email
+func (mailer SMTPMailer) Send(message Message) error {
+	var auth smtp.Auth
+	if mailer.options.Username != "" {
+		auth = smtp.PlainAuth("", mailer.options.Username, mailer.options.Password, mailer.options.Host)
+	}
+
+	err := smtp.SendMail(
+		net.JoinHostPort(mailer.options.Host, mailer.options.Port),
+		auth,
+		mailer.options.From,
+		[]string{message.To},
+		mailer.buildMessage(message),
+	)
+	if err != nil {
+		return stacktrace.Propagate(err, "cannot send email with subject '%s'", message.Subject)
+	}
+
+	return nil
+}
+
+func (mailer SMTPMailer) buildMessage(message Message) []byte {
+	headers := []string{
+		fmt.Sprintf("From: %s", mailer.options.From),
+		fmt.Sprintf("To: %s", message.To),
+		fmt.Sprintf("Subject: %s", message.Subject),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=\"utf-8\"",
+	}
+
+	return []byte(strings.Join(headers, "\r\n") + "\r\n\r\n" + message.Body)
+}
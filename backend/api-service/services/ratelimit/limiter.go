@@ -0,0 +1,49 @@
+package ratelimit
+
+import (
+	"time"
+
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/cache"
+	"github.com/palantir/stacktrace"
+)
+
+const (
+	cacheKeyPrefixRateLimit = "rate_limit:"
+)
+
+// Rule allows Limit attempts for the same key within a sliding window
+type Rule struct {
+	// Name separates the keys of different rules in the cache
+	Name   string
+	Limit  int
+	Window time.Duration
+}
+
+// Limiter enforces sliding window rate limits which are shared by all the instances of the api service
+type Limiter struct {
+	cache cache.Cache
+}
+
+// NewLimiter creates a new instance of the rate limiter
+func NewLimiter(cache cache.Cache) Limiter {
+	return Limiter{cache: cache}
+}
+
+// Allow records an attempt for the key and checks if it is within the limit of the rule.
+// Rejected attempts are recorded too so clients which keep retrying stay blocked.
+// When the attempt is not allowed, retryAfter is the time until the next attempt will be allowed.
+func (limiter Limiter) Allow(rule Rule, key string) (allowed bool, retryAfter time.Duration, err error) {
+	now := time.Now().UTC()
+
+	attempts, err := limiter.cache.AddToTimeWindow(cacheKeyPrefixRateLimit+rule.Name+":"+key, now, rule.Window)
+	if err != nil {
+		return false, retryAfter, stacktrace.Propagate(err, "cannot record attempt for rate limit %s", rule.Name)
+	}
+
+	if len(attempts) <= rule.Limit {
+		return true, retryAfter, nil
+	}
+
+	// the next attempt is allowed once enough attempts have left the window
+	return false, attempts[len(attempts)-rule.Limit].Add(rule.Window).Sub(now), nil
+}
@@ -0,0 +1,117 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/cache/redis"
+	"github.com/alicebob/miniredis/v2"
+)
+
+func TestLimiterAllow(t *testing.T) {
+	rule := Rule{Name: "login", Limit: 3, Window: time.Minute}
+
+	tests := []struct {
+		name string
+		// previousAttempts is how long ago the earlier attempts were made
+		previousAttempts   []time.Duration
+		expectedAllowed    bool
+		expectedRetryAfter time.Duration
+	}{
+		{
+			name:            "first attempt",
+			expectedAllowed: true,
+		},
+		{
+			name:             "last attempt within the limit",
+			previousAttempts: []time.Duration{20 * time.Second, 10 * time.Second},
+			expectedAllowed:  true,
+		},
+		{
+			name:               "attempt over the limit",
+			previousAttempts:   []time.Duration{50 * time.Second, 40 * time.Second, 30 * time.Second},
+			expectedAllowed:    false,
+			expectedRetryAfter: 20 * time.Second,
+		},
+		{
+			name:             "attempts which left the window are not counted",
+			previousAttempts: []time.Duration{90 * time.Second, 80 * time.Second, 70 * time.Second, 10 * time.Second},
+			expectedAllowed:  true,
+		},
+		{
+			name:               "rejected attempts are counted",
+			previousAttempts:   []time.Duration{50 * time.Second, 40 * time.Second, 30 * time.Second, 20 * time.Second, 10 * time.Second},
+			expectedAllowed:    false,
+			expectedRetryAfter: 40 * time.Second,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server, err := miniredis.Run()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer server.Close()
+
+			client := redis.NewClient(redis.Options{Address: server.Addr()})
+			now := time.Now().UTC()
+			for _, ago := range test.previousAttempts {
+				_, err = client.AddToTimeWindow(cacheKeyPrefixRateLimit+rule.Name+":127.0.0.1", now.Add(-ago), rule.Window)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			allowed, retryAfter, err := NewLimiter(client).Allow(rule, "127.0.0.1")
+			if err != nil {
+				t.Fatalf("Allow returned an error: %s", err)
+			}
+
+			if allowed != test.expectedAllowed {
+				t.Fatalf("Allow returned %t, expected %t", allowed, test.expectedAllowed)
+			}
+
+			// the limiter reads the clock after the attempts were added
+			if retryAfter > test.expectedRetryAfter || retryAfter < test.expectedRetryAfter-time.Second {
+				t.Errorf("Allow returned retry after %s, expected %s", retryAfter, test.expectedRetryAfter)
+			}
+		})
+	}
+}
+
+func TestLimiterAllowSeparatesKeys(t *testing.T) {
+	server, err := miniredis.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	limiter := NewLimiter(redis.NewClient(redis.Options{Address: server.Addr()}))
+	login := Rule{Name: "login", Limit: 1, Window: time.Minute}
+	signUp := Rule{Name: "sign_up", Limit: 1, Window: time.Minute}
+
+	tests := []struct {
+		name     string
+		rule     Rule
+		key      string
+		expected bool
+	}{
+		{name: "first attempt", rule: login, key: "127.0.0.1", expected: true},
+		{name: "second attempt with the same key", rule: login, key: "127.0.0.1", expected: false},
+		{name: "attempt with another key", rule: login, key: "127.0.0.2", expected: true},
+		{name: "attempt for another rule", rule: signUp, key: "127.0.0.1", expected: true},
+	}
+
+	// the attempts build on each other so the cases are not run in isolation
+	for _, test := range tests {
+		allowed, _, err := limiter.Allow(test.rule, test.key)
+		if err != nil {
+			t.Fatalf("%s: Allow returned an error: %s", test.name, err)
+		}
+
+		if allowed != test.expected {
+			t.Errorf("%s: Allow returned %t, expected %t", test.name, allowed, test.expected)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package ratelimit
+
+import (
+	"time"
+
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/cache"
+	"github.com/palantir/stacktrace"
+)
+
+const (
+	// cacheKeyPrefixFailures prefixes the counter of the failed attempts since the last successful attempt
+	cacheKeyPrefixFailures = "lockout_failures:"
+
+	// cacheKeyPrefixLockedUntil prefixes the key which stores the end of the current lockout
+	cacheKeyPrefixLockedUntil = "lockout_locked_until:"
+)
+
+// LockoutOptions configures when an account is locked and for how long
+type LockoutOptions struct {
+	// MaxFailures is the number of consecutive failures after which the account is locked
+	MaxFailures int
+
+	// Backoff is the duration of the first lockout. It doubles after every lockout until MaxBackoff is reached.
+	Backoff time.Duration
+
+	// MaxBackoff is the maximum duration of a lockout
+	MaxBackoff time.Duration
+
+	// ResetAfter is the time without failures after which the failures and lockouts are forgotten
+	ResetAfter time.Duration
+}
+
+// Lockout locks accounts temporarily after repeated failed attempts.
+// Failures are counted with an atomic increment so that concurrent attempts cannot overwrite each other's count.
+type Lockout struct {
+	cache   cache.Cache
+	options LockoutOptions
+}
+
+// NewLockout creates a new instance of the account lockout
+func NewLockout(cache cache.Cache, options LockoutOptions) Lockout {
+	return Lockout{cache: cache, options: options}
+}
+
+// LockedFor returns how long the account is still locked, it is 0 when the account is not locked
+func (lockout Lockout) LockedFor(key string) (time.Duration, error) {
+	value, err := lockout.cache.Get(cacheKeyPrefixLockedUntil + key)
+	if err == cache.ErrCacheMiss {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, stacktrace.Propagate(err, "cannot fetch lockout")
+	}
+
+	lockedUntil, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return 0, stacktrace.Propagate(err, "cannot decode lockout %s", value)
+	}
+
+	lockedFor := time.Until(lockedUntil)
+	if lockedFor < 0 {
+		return 0, nil
+	}
+
+	return lockedFor, nil
+}
+
+// RecordFailure counts a failed attempt and locks the account after every MaxFailures failures.
+// lockedFor is only set when this failure locked the account.
+func (lockout Lockout) RecordFailure(key string) (lockedFor time.Duration, err error) {
+	failures, err := lockout.cache.Increment(cacheKeyPrefixFailures+key, lockout.options.ResetAfter)
+	if err != nil {
+		return lockedFor, stacktrace.Propagate(err, "cannot count failed attempt")
+	}
+
+	// exactly one of the concurrent failures reaches the multiple of MaxFailures which locks the account
+	if failures%int64(lockout.options.MaxFailures) != 0 {
+		return lockedFor, nil
+	}
+
+	lockedFor = lockout.backoff(int(failures / int64(lockout.options.MaxFailures)))
+
+	err = lockout.cache.Set(cacheKeyPrefixLockedUntil+key, time.Now().UTC().Add(lockedFor).Format(time.RFC3339Nano), lockedFor)
+	if err != nil {
+		return lockedFor, stacktrace.Propagate(err, "cannot store lockout")
+	}
+
+	// the failures are remembered during the lockout so that the next lockout is longer
+	err = lockout.cache.Expire(cacheKeyPrefixFailures+key, lockedFor+lockout.options.ResetAfter)
+	if err != nil {
+		return lockedFor, stacktrace.Propagate(err, "cannot extend the failed attempts during the lockout")
+	}
+
+	return lockedFor, nil
+}
+
+// Reset forgets the failures and lockouts after a successful attempt
+func (lockout Lockout) Reset(key string) error {
+	err := lockout.cache.Delete(cacheKeyPrefixFailures + key)
+	if err != nil {
+		return stacktrace.Propagate(err, "cannot delete failed attempts")
+	}
+
+	err = lockout.cache.Delete(cacheKeyPrefixLockedUntil + key)
+	if err != nil {
+		return stacktrace.Propagate(err, "cannot delete lockout")
+	}
+
+	return nil
+}
+
+// backoff doubles the lockout duration after every lockout
+func (lockout Lockout) backoff(lockouts int) time.Duration {
+	backoff := lockout.options.Backoff
+	for i := 1; i < lockouts && backoff < lockout.options.MaxBackoff; i++ {
+		backoff *= 2
+	}
+
+	if backoff > lockout.options.MaxBackoff {
+		return lockout.options.MaxBackoff
+	}
+
+	return backoff
+}
@@ -0,0 +1,216 @@
+package refreshtoken
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/cache"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/services/token"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/id"
+	"github.com/palantir/stacktrace"
+)
+
+const (
+	cacheKeyPrefixFamily     = "refresh_token_family:"
+	cacheKeyPrefixUserFamily = "user_refresh_token_families:"
+
+	// familySeparator separates the family id from the secret part of a refresh token
+	familySeparator = "."
+)
+
+var (
+	// ErrCodeInvalidRefreshToken is the error code when a refresh token doesn't belong to an active family
+	ErrCodeInvalidRefreshToken = stacktrace.ErrorCode(72)
+
+	// ErrCodeRefreshTokenReused is the error code when a refresh token which was already rotated is used again
+	ErrCodeRefreshTokenReused = stacktrace.ErrorCode(73)
+)
+
+// Family is a chain of refresh tokens which were issued from a single login.
+// Only the latest token of a family is valid.
+type Family struct {
+	ID               string    `json:"id"`
+	UserID           string    `json:"user_id"`
+	CurrentTokenHash string    `json:"current_token_hash"`
+	RememberMe       bool      `json:"remember_me"`
+	CreatedAt        time.Time `json:"created_at"`
+	ExpiresAt        time.Time `json:"expires_at"`
+}
+
+// Options configures the lifetime of refresh tokens
+type Options struct {
+	// Lifetime is how long a refresh token is valid when the user didn't ask to be remembered
+	Lifetime time.Duration
+	// RememberMeLifetime is how long a refresh token is valid when the user asked to be remembered
+	RememberMeLifetime time.Duration
+}
+
+// Service issues and rotates refresh tokens
+type Service struct {
+	cache   cache.Cache
+	options Options
+}
+
+// NewService creates a new instance of the refresh token service
+func NewService(cache cache.Cache, options Options) Service {
+	return Service{
+		cache:   cache,
+		options: options,
+	}
+}
+
+// Issue starts a new family and returns its first refresh token
+func (service Service) Issue(userID id.ID, rememberMe bool) (refreshToken string, family Family, err error) {
+	family = Family{
+		ID:         id.New().String(),
+		UserID:     userID.String(),
+		RememberMe: rememberMe,
+		CreatedAt:  time.Now().UTC(),
+	}
+
+	refreshToken, family, value, lifetime, err := service.nextToken(family)
+	if err != nil {
+		return refreshToken, family, stacktrace.Propagate(err, "cannot issue refresh token for user with id %s", userID.String())
+	}
+
+	err = service.cache.Set(cacheKeyPrefixFamily+family.ID, value, lifetime)
+	if err != nil {
+		return refreshToken, family, stacktrace.Propagate(err, "cannot store refresh token family %s", family.ID)
+	}
+
+	err = service.cache.AddToSet(cacheKeyPrefixUserFamily+family.UserID, family.ID, service.options.RememberMeLifetime)
+	if err != nil {
+		return refreshToken, family, stacktrace.Propagate(err, "cannot index refresh token family for user with id %s", userID.String())
+	}
+
+	return refreshToken, family, nil
+}
+
+// Rotate exchanges a refresh token for a new one in the same family.
+// When a token which was already rotated is used again the whole family is revoked because it has been stolen.
+// The family is only replaced when it wasn't rotated in the meantime so that concurrent uses of a token are detected.
+// The revoked family is returned with ErrCodeRefreshTokenReused so that its session can be ended.
+func (service Service) Rotate(refreshToken string) (newRefreshToken string, family Family, err error) {
+	parts := strings.SplitN(refreshToken, familySeparator, 2)
+	if len(parts) != 2 {
+		return newRefreshToken, family, stacktrace.NewErrorWithCode(ErrCodeInvalidRefreshToken, "refresh token is malformed")
+	}
+
+	currentValue, family, err := service.findFamily(parts[0])
+	if err != nil {
+		return newRefreshToken, family, err
+	}
+
+	if token.Hash(refreshToken) != family.CurrentTokenHash {
+		return newRefreshToken, family, service.revokeReusedFamily(family)
+	}
+
+	newRefreshToken, nextFamily, value, lifetime, err := service.nextToken(family)
+	if err != nil {
+		return newRefreshToken, family, stacktrace.Propagate(err, "cannot rotate refresh token of family %s", family.ID)
+	}
+
+	swapped, err := service.cache.CompareAndSwap(cacheKeyPrefixFamily+family.ID, currentValue, value, lifetime)
+	if err != nil {
+		return newRefreshToken, family, stacktrace.Propagate(err, "cannot store refresh token family %s", family.ID)
+	}
+
+	if !swapped {
+		// another request rotated or revoked the family with the same token
+		return "", family, service.revokeReusedFamily(family)
+	}
+
+	return newRefreshToken, nextFamily, nil
+}
+
+// FindFamily returns an active family
+func (service Service) FindFamily(familyID string) (family Family, err error) {
+	_, family, err = service.findFamily(familyID)
+	return family, err
+}
+
+// findFamily returns an active family together with its encoded value
+func (service Service) findFamily(familyID string) (value string, family Family, err error) {
+	value, err = service.cache.Get(cacheKeyPrefixFamily + familyID)
+	if err == cache.ErrCacheMiss {
+		return value, family, stacktrace.NewErrorWithCode(ErrCodeInvalidRefreshToken, "refresh token family %s does not exist", familyID)
+	}
+	if err != nil {
+		return value, family, stacktrace.Propagate(err, "cannot fetch refresh token family %s", familyID)
+	}
+
+	err = json.Unmarshal([]byte(value), &family)
+	if err != nil {
+		return value, family, stacktrace.Propagate(err, "cannot decode refresh token family %s", familyID)
+	}
+
+	return value, family, nil
+}
+
+// revokeReusedFamily revokes a family whose refresh token was used more than once
+func (service Service) revokeReusedFamily(family Family) error {
+	err := service.RevokeFamily(family)
+	if err != nil {
+		return stacktrace.Propagate(err, "cannot revoke family %s after a refresh token was reused", family.ID)
+	}
+
+	return stacktrace.NewErrorWithCode(ErrCodeRefreshTokenReused, "refresh token of family %s was reused", family.ID)
+}
+
+// RevokeFamily invalidates all the refresh tokens of a family
+func (service Service) RevokeFamily(family Family) error {
+	err := service.cache.Delete(cacheKeyPrefixFamily + family.ID)
+	if err != nil {
+		return stacktrace.Propagate(err, "cannot delete refresh token family %s", family.ID)
+	}
+
+	err = service.cache.RemoveFromSet(cacheKeyPrefixUserFamily+family.UserID, family.ID)
+	if err != nil {
+		return stacktrace.Propagate(err, "cannot remove refresh token family %s from the user index", family.ID)
+	}
+
+	return nil
+}
+
+// RevokeAllForUser invalidates all the refresh tokens of a user
+func (service Service) RevokeAllForUser(userID id.ID) error {
+	familyIDs, err := service.cache.SetMembers(cacheKeyPrefixUserFamily + userID.String())
+	if err != nil {
+		return stacktrace.Propagate(err, "cannot fetch refresh token families for user with id %s", userID.String())
+	}
+
+	for _, familyID := range familyIDs {
+		err = service.RevokeFamily(Family{ID: familyID, UserID: userID.String()})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// nextToken generates a new token for the family and extends the family's lifetime.
+// It returns the encoded family which must be stored for the token to become valid.
+func (service Service) nextToken(family Family) (refreshToken string, _ Family, value string, lifetime time.Duration, err error) {
+	secret, _, err := token.Generate()
+	if err != nil {
+		return refreshToken, family, value, lifetime, err
+	}
+
+	lifetime = service.options.Lifetime
+	if family.RememberMe {
+		lifetime = service.options.RememberMeLifetime
+	}
+
+	refreshToken = family.ID + familySeparator + secret
+	family.CurrentTokenHash = token.Hash(refreshToken)
+	family.ExpiresAt = time.Now().UTC().Add(lifetime)
+
+	encoded, err := json.Marshal(family)
+	if err != nil {
+		return refreshToken, family, value, lifetime, stacktrace.Propagate(err, "cannot encode refresh token family %s", family.ID)
+	}
+
+	return refreshToken, family, string(encoded), lifetime, nil
+}
@@ -0,0 +1,197 @@
+package refreshtoken
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/cache/redis"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/id"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/palantir/stacktrace"
+)
+
+const (
+	malformedToken     = -1
+	unknownFamilyToken = -2
+)
+
+var testOptions = Options{Lifetime: time.Hour, RememberMeLifetime: 30 * 24 * time.Hour}
+
+func newTestService(t *testing.T) (Service, *miniredis.Miniredis) {
+	server, err := miniredis.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return NewService(redis.NewClient(redis.Options{Address: server.Addr()}), testOptions), server
+}
+
+func TestServiceRotate(t *testing.T) {
+	type rotation struct {
+		// token is the index of the token which is rotated, the issued token is 0 and every rotation adds the next one
+		token        int
+		expectedCode stacktrace.ErrorCode
+	}
+
+	tests := []struct {
+		name      string
+		rotations []rotation
+	}{
+		{
+			name: "the latest token is rotated",
+			rotations: []rotation{
+				{token: 0, expectedCode: stacktrace.NoCode},
+				{token: 1, expectedCode: stacktrace.NoCode},
+				{token: 2, expectedCode: stacktrace.NoCode},
+			},
+		},
+		{
+			name: "a reused token revokes the family",
+			rotations: []rotation{
+				{token: 0, expectedCode: stacktrace.NoCode},
+				{token: 0, expectedCode: ErrCodeRefreshTokenReused},
+				{token: 1, expectedCode: ErrCodeInvalidRefreshToken},
+			},
+		},
+		{
+			name: "an older token is reused after several rotations",
+			rotations: []rotation{
+				{token: 0, expectedCode: stacktrace.NoCode},
+				{token: 1, expectedCode: stacktrace.NoCode},
+				{token: 1, expectedCode: ErrCodeRefreshTokenReused},
+				{token: 2, expectedCode: ErrCodeInvalidRefreshToken},
+			},
+		},
+		{
+			name:      "a malformed token",
+			rotations: []rotation{{token: malformedToken, expectedCode: ErrCodeInvalidRefreshToken}},
+		},
+		{
+			name:      "a token of an unknown family",
+			rotations: []rotation{{token: unknownFamilyToken, expectedCode: ErrCodeInvalidRefreshToken}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service, server := newTestService(t)
+			defer server.Close()
+
+			refreshToken, family, err := service.Issue(id.New(), false)
+			if err != nil {
+				t.Fatalf("Issue returned an error: %s", err)
+			}
+			tokens := []string{refreshToken}
+
+			for index, rotation := range test.rotations {
+				refreshToken := "malformed"
+				if rotation.token == unknownFamilyToken {
+					refreshToken = id.New().String() + familySeparator + "secret"
+				} else if rotation.token >= 0 {
+					refreshToken = tokens[rotation.token]
+				}
+
+				newRefreshToken, rotatedFamily, err := service.Rotate(refreshToken)
+				if code := stacktrace.GetCode(err); code != rotation.expectedCode {
+					t.Fatalf("rotation %d returned the error code %d, expected %d: %v", index, code, rotation.expectedCode, err)
+				}
+
+				if rotation.expectedCode == ErrCodeRefreshTokenReused && rotatedFamily.ID != family.ID {
+					t.Fatalf("rotation %d returned the family %s, expected the reused family %s", index, rotatedFamily.ID, family.ID)
+				}
+
+				if err != nil {
+					continue
+				}
+
+				if rotatedFamily.ID != family.ID || newRefreshToken == refreshToken {
+					t.Fatalf("rotation %d returned the token %s of family %s, expected a new token of family %s", index, newRefreshToken, rotatedFamily.ID, family.ID)
+				}
+				tokens = append(tokens, newRefreshToken)
+			}
+		})
+	}
+}
+
+func TestServiceIssueLifetime(t *testing.T) {
+	tests := []struct {
+		name             string
+		rememberMe       bool
+		expectedLifetime time.Duration
+	}{
+		{name: "without remember me", rememberMe: false, expectedLifetime: testOptions.Lifetime},
+		{name: "with remember me", rememberMe: true, expectedLifetime: testOptions.RememberMeLifetime},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service, server := newTestService(t)
+			defer server.Close()
+
+			refreshToken, family, err := service.Issue(id.New(), test.rememberMe)
+			if err != nil {
+				t.Fatalf("Issue returned an error: %s", err)
+			}
+
+			if ttl := server.TTL(cacheKeyPrefixFamily + family.ID); ttl != test.expectedLifetime {
+				t.Errorf("family is stored for %s, expected %s", ttl, test.expectedLifetime)
+			}
+
+			// the lifetime is extended with every rotation
+			server.FastForward(time.Minute)
+			_, family, err = service.Rotate(refreshToken)
+			if err != nil {
+				t.Fatalf("Rotate returned an error: %s", err)
+			}
+
+			if ttl := server.TTL(cacheKeyPrefixFamily + family.ID); ttl != test.expectedLifetime {
+				t.Errorf("rotated family is stored for %s, expected %s", ttl, test.expectedLifetime)
+			}
+		})
+	}
+}
+
+func TestServiceRevokeAllForUser(t *testing.T) {
+	service, server := newTestService(t)
+	defer server.Close()
+
+	userID := id.New()
+	userToken, _, err := service.Issue(userID, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rememberedToken, _, err := service.Issue(userID, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	otherUserToken, _, err := service.Issue(id.New(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = service.RevokeAllForUser(userID)
+	if err != nil {
+		t.Fatalf("RevokeAllForUser returned an error: %s", err)
+	}
+
+	tests := []struct {
+		name         string
+		refreshToken string
+		expectedCode stacktrace.ErrorCode
+	}{
+		{name: "token of the user", refreshToken: userToken, expectedCode: ErrCodeInvalidRefreshToken},
+		{name: "remembered token of the user", refreshToken: rememberedToken, expectedCode: ErrCodeInvalidRefreshToken},
+		{name: "token of another user", refreshToken: otherUserToken, expectedCode: stacktrace.NoCode},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, _, err := service.Rotate(test.refreshToken)
+			if code := stacktrace.GetCode(err); code != test.expectedCode {
+				t.Errorf("Rotate returned the error code %d, expected %d: %v", code, test.expectedCode, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package session
+
+import "strings"
+
+// userAgentToken maps a token which appears in a user agent to a readable name
+type userAgentToken struct {
+	token string
+	name  string
+}
+
+var (
+	// browsers are checked in order because most user agents also contain the tokens of older browsers
+	browsers = []userAgentToken{
+		{token: "Edg/", name: "Edge"},
+		{token: "OPR/", name: "Opera"},
+		{token: "Firefox/", name: "Firefox"},
+		{token: "Chrome/", name: "Chrome"},
+		{token: "CriOS/", name: "Chrome"},
+		{token: "Safari/", name: "Safari"},
+	}
+
+	operatingSystems = []userAgentToken{
+		{token: "iPhone", name: "iOS"},
+		{token: "iPad", name: "iPadOS"},
+		{token: "Android", name: "Android"},
+		{token: "Windows", name: "Windows"},
+		{token: "Mac OS X", name: "macOS"},
+		{token: "CrOS", name: "ChromeOS"},
+		{token: "Linux", name: "Linux"},
+	}
+)
+
+// DeviceFromUserAgent returns a readable description of the device of a user agent e.g "Firefox on Windows"
+func DeviceFromUserAgent(userAgent string) string {
+	browser := findUserAgentToken(browsers, userAgent)
+	operatingSystem := findUserAgentToken(operatingSystems, userAgent)
+
+	switch {
+	case browser != "" && operatingSystem != "":
+		return browser + " on " + operatingSystem
+	case browser != "":
+		return browser
+	case operatingSystem != "":
+		return operatingSystem
+	default:
+		return "Unknown device"
+	}
+}
+
+func findUserAgentToken(tokens []userAgentToken, userAgent string) string {
+	for _, value := range tokens {
+		if strings.Contains(userAgent, value.token) {
+			return value.name
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,234 @@
+package session
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/cache"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/id"
+	"github.com/palantir/stacktrace"
+)
+
+const (
+	cacheKeyPrefixSession      = "session:"
+	cacheKeyPrefixUserSessions = "user_sessions:"
+
+	// lastSeenInterval limits how often the last seen time of a session is written to the cache
+	lastSeenInterval = time.Minute
+)
+
+var (
+	// ErrCodeSessionNotFound is the error code when a session was revoked or has expired
+	ErrCodeSessionNotFound = stacktrace.ErrorCode(77)
+)
+
+// Session is a signed in client of a user. It has the same id as the refresh token family which renews its access tokens.
+type Session struct {
+	ID         string    `json:"id"`
+	UserID     string    `json:"userId"`
+	Device     string    `json:"device"`
+	UserAgent  string    `json:"userAgent"`
+	IPAddress  string    `json:"ipAddress"`
+	CreatedAt  time.Time `json:"createdAt"`
+	LastSeenAt time.Time `json:"lastSeenAt"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+}
+
+// Registry stores the active sessions of users
+type Registry struct {
+	cache cache.Cache
+	// maxLifetime is the lifetime of the longest session, it is used as the expiration of the index of a user's sessions
+	maxLifetime time.Duration
+}
+
+// NewRegistry creates a new instance of the session registry
+func NewRegistry(cache cache.Cache, maxLifetime time.Duration) Registry {
+	return Registry{
+		cache:       cache,
+		maxLifetime: maxLifetime,
+	}
+}
+
+// Start records a new session which is active until it expires or is revoked
+func (registry Registry) Start(sessionID string, userID id.ID, userAgent string, ipAddress string, expiresAt time.Time) (session Session, err error) {
+	now := time.Now().UTC()
+	session = Session{
+		ID:         sessionID,
+		UserID:     userID.String(),
+		Device:     DeviceFromUserAgent(userAgent),
+		UserAgent:  userAgent,
+		IPAddress:  ipAddress,
+		CreatedAt:  now,
+		LastSeenAt: now,
+		ExpiresAt:  expiresAt,
+	}
+
+	err = registry.store(session)
+	if err != nil {
+		return session, err
+	}
+
+	err = registry.cache.AddToSet(cacheKeyPrefixUserSessions+session.UserID, session.ID, registry.maxLifetime)
+	if err != nil {
+		return session, stacktrace.Propagate(err, "cannot index session %s for user with id %s", session.ID, session.UserID)
+	}
+
+	return session, nil
+}
+
+// Find returns an active session
+func (registry Registry) Find(sessionID string) (session Session, err error) {
+	value, err := registry.cache.Get(cacheKeyPrefixSession + sessionID)
+	if err == cache.ErrCacheMiss {
+		return session, stacktrace.NewErrorWithCode(ErrCodeSessionNotFound, "session %s does not exist", sessionID)
+	}
+	if err != nil {
+		return session, stacktrace.Propagate(err, "cannot fetch session %s", sessionID)
+	}
+
+	err = json.Unmarshal([]byte(value), &session)
+	if err != nil {
+		return session, stacktrace.Propagate(err, "cannot decode session %s", sessionID)
+	}
+
+	return session, nil
+}
+
+// Touch records that a session was used from an IP address
+func (registry Registry) Touch(sessionID string, ipAddress string) (session Session, err error) {
+	session, err = registry.Find(sessionID)
+	if err != nil {
+		return session, err
+	}
+
+	now := time.Now().UTC()
+	if session.IPAddress == ipAddress && now.Sub(session.LastSeenAt) < lastSeenInterval {
+		return session, nil
+	}
+
+	session.IPAddress = ipAddress
+	session.LastSeenAt = now
+
+	return session, registry.replace(session)
+}
+
+// Extend keeps a session active until its refresh token family expires
+func (registry Registry) Extend(sessionID string, ipAddress string, expiresAt time.Time) (session Session, err error) {
+	session, err = registry.Find(sessionID)
+	if err != nil {
+		return session, err
+	}
+
+	session.IPAddress = ipAddress
+	session.LastSeenAt = time.Now().UTC()
+	session.ExpiresAt = expiresAt
+
+	err = registry.replace(session)
+	if err != nil {
+		return session, err
+	}
+
+	err = registry.cache.AddToSet(cacheKeyPrefixUserSessions+session.UserID, session.ID, registry.maxLifetime)
+	if err != nil {
+		return session, stacktrace.Propagate(err, "cannot index session %s for user with id %s", session.ID, session.UserID)
+	}
+
+	return session, nil
+}
+
+// ListForUser returns the active sessions of a user with the most recently seen session first
+func (registry Registry) ListForUser(userID id.ID) (sessions []Session, err error) {
+	sessionIDs, err := registry.cache.SetMembers(cacheKeyPrefixUserSessions + userID.String())
+	if err != nil {
+		return sessions, stacktrace.Propagate(err, "cannot fetch sessions for user with id %s", userID.String())
+	}
+
+	for _, sessionID := range sessionIDs {
+		session, err := registry.Find(sessionID)
+		if stacktrace.GetCode(err) == ErrCodeSessionNotFound {
+			// the session expired so it is removed from the index
+			err = registry.cache.RemoveFromSet(cacheKeyPrefixUserSessions+userID.String(), sessionID)
+			if err != nil {
+				return sessions, stacktrace.Propagate(err, "cannot remove expired session %s from the user index", sessionID)
+			}
+			continue
+		}
+		if err != nil {
+			return sessions, err
+		}
+
+		sessions = append(sessions, session)
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastSeenAt.After(sessions[j].LastSeenAt)
+	})
+
+	return sessions, nil
+}
+
+// Revoke ends a session of a user
+func (registry Registry) Revoke(userID id.ID, sessionID string) error {
+	err := registry.cache.Delete(cacheKeyPrefixSession + sessionID)
+	if err != nil {
+		return stacktrace.Propagate(err, "cannot delete session %s", sessionID)
+	}
+
+	err = registry.cache.RemoveFromSet(cacheKeyPrefixUserSessions+userID.String(), sessionID)
+	if err != nil {
+		return stacktrace.Propagate(err, "cannot remove session %s from the user index", sessionID)
+	}
+
+	return nil
+}
+
+// RevokeAllForUser ends all the sessions of a user
+func (registry Registry) RevokeAllForUser(userID id.ID) error {
+	sessionIDs, err := registry.cache.SetMembers(cacheKeyPrefixUserSessions + userID.String())
+	if err != nil {
+		return stacktrace.Propagate(err, "cannot fetch sessions for user with id %s", userID.String())
+	}
+
+	for _, sessionID := range sessionIDs {
+		err = registry.Revoke(userID, sessionID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (registry Registry) store(session Session) error {
+	value, err := json.Marshal(session)
+	if err != nil {
+		return stacktrace.Propagate(err, "cannot encode session %s", session.ID)
+	}
+
+	err = registry.cache.Set(cacheKeyPrefixSession+session.ID, string(value), time.Until(session.ExpiresAt))
+	if err != nil {
+		return stacktrace.Propagate(err, "cannot store session %s", session.ID)
+	}
+
+	return nil
+}
+
+// replace updates a session only when it still exists so that a session which was revoked after it was read stays revoked
+func (registry Registry) replace(session Session) error {
+	value, err := json.Marshal(session)
+	if err != nil {
+		return stacktrace.Propagate(err, "cannot encode session %s", session.ID)
+	}
+
+	replaced, err := registry.cache.Replace(cacheKeyPrefixSession+session.ID, string(value), time.Until(session.ExpiresAt))
+	if err != nil {
+		return stacktrace.Propagate(err, "cannot update session %s", session.ID)
+	}
+
+	if !replaced {
+		return stacktrace.NewErrorWithCode(ErrCodeSessionNotFound, "session %s was revoked", session.ID)
+	}
+
+	return nil
+}
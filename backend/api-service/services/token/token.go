@@ -0,0 +1,31 @@
+package token
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+
+	"github.com/palantir/stacktrace"
+)
+
+// byteLength is the number of random bytes in a token
+const byteLength = 32
+
+// Generate creates a random url safe token and the hash which should be stored instead of the token
+func Generate() (token string, hash string, err error) {
+	value := make([]byte, byteLength)
+	_, err = rand.Read(value)
+	if err != nil {
+		return token, hash, stacktrace.Propagate(err, "cannot read random bytes for a token")
+	}
+
+	token = base64.RawURLEncoding.EncodeToString(value)
+	return token, Hash(token), nil
+}
+
+// Hash returns the hash of a token so tokens are never stored in plain text
+func Hash(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
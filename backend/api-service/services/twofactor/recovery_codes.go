@@ -0,0 +1,39 @@
+package twofactor
+
+import (
+	"crypto/rand"
+	"strings"
+
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/services/token"
+	"github.com/palantir/stacktrace"
+)
+
+const (
+	recoveryCodeCount = 10
+
+	// recoveryCodeLength is the number of random bytes in a recovery code
+	recoveryCodeLength = 5
+)
+
+// GenerateRecoveryCodes creates single use codes which replace the authenticator app when it is lost.
+// Only the hashes should be stored.
+func GenerateRecoveryCodes() (codes []string, hashes []string, err error) {
+	for i := 0; i < recoveryCodeCount; i++ {
+		value := make([]byte, recoveryCodeLength*2)
+		_, err = rand.Read(value)
+		if err != nil {
+			return codes, hashes, stacktrace.Propagate(err, "cannot read random bytes for a recovery code")
+		}
+
+		code := strings.ToLower(base32Encoding.EncodeToString(value[:recoveryCodeLength]) + "-" + base32Encoding.EncodeToString(value[recoveryCodeLength:]))
+		codes = append(codes, code)
+		hashes = append(hashes, HashRecoveryCode(code))
+	}
+
+	return codes, hashes, nil
+}
+
+// HashRecoveryCode hashes a recovery code the way it is entered by the user
+func HashRecoveryCode(code string) string {
+	return token.Hash(strings.ToLower(strings.TrimSpace(code)))
+}
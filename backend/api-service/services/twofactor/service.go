@@ -0,0 +1,177 @@
+package twofactor
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/cache"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/services/token"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/encryption"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/id"
+	"github.com/palantir/stacktrace"
+)
+
+const (
+	// cacheKeyPrefixChallenge prefixes the hash of a challenge token
+	cacheKeyPrefixChallenge = "two_factor_challenge:"
+
+	// cacheKeyPrefixLastStep prefixes the key which stores the last time step used by a user
+	cacheKeyPrefixLastStep = "two_factor_last_step:"
+)
+
+var (
+	// ErrCodeInvalidChallenge is the error code when a challenge token doesn't exist or has expired
+	ErrCodeInvalidChallenge = stacktrace.ErrorCode(75)
+)
+
+// Options configures the two factor service
+type Options struct {
+	// Issuer is the name of the account in authenticator apps
+	Issuer string
+
+	// ChallengeLifetime is how long a user has to enter a code after entering their password
+	ChallengeLifetime time.Duration
+}
+
+// Challenge is issued after the password of a user who enabled two factor authentication was verified
+type Challenge struct {
+	UserID     string    `json:"userId"`
+	RememberMe bool      `json:"rememberMe"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+}
+
+// Service enrols users and verifies their codes
+type Service struct {
+	cache cache.Cache
+	// keyring encrypts the secrets at rest, the id of the user is the associated data so a secret cannot be copied to another user
+	keyring *encryption.Keyring
+	options Options
+}
+
+// NewService creates a new instance of the two factor service
+func NewService(cache cache.Cache, keyring *encryption.Keyring, options Options) Service {
+	return Service{cache: cache, keyring: keyring, options: options}
+}
+
+// NewEnrolment generates a secret for the account of a user
+func (service Service) NewEnrolment(userID id.ID, accountName string) (enrolment Enrolment, err error) {
+	enrolment.Secret, err = newSecret()
+	if err != nil {
+		return enrolment, err
+	}
+
+	enrolment.EncryptedSecret, err = service.keyring.Encrypt(enrolment.Secret, userID.String())
+	if err != nil {
+		return enrolment, stacktrace.Propagate(err, "cannot encrypt the totp secret of user with id %s", userID.String())
+	}
+
+	enrolment.URI = uri(service.options.Issuer, accountName, enrolment.Secret)
+
+	enrolment.QRCode, err = qrCode(enrolment.URI)
+	if err != nil {
+		return enrolment, err
+	}
+
+	return enrolment, nil
+}
+
+// ValidateCode checks the code of a user against the encrypted secret of their enrolment. A code is rejected when the user
+// already used a code of the same or a later time step. The last step is replaced with a compare-and-swap so that
+// concurrent requests with the same code cannot both succeed.
+func (service Service) ValidateCode(userID id.ID, encryptedSecret string, code string) (bool, error) {
+	secret, err := service.keyring.Decrypt(encryptedSecret, userID.String())
+	if err != nil {
+		return false, stacktrace.Propagate(err, "cannot decrypt the totp secret of user with id %s", userID.String())
+	}
+
+	step, ok := validateCode(secret, code, time.Now().UTC())
+	if !ok {
+		return false, nil
+	}
+
+	cacheKey := cacheKeyPrefixLastStep + userID.String()
+	for {
+		lastStep, err := service.cache.Get(cacheKey)
+		if err == cache.ErrCacheMiss {
+			lastStep = ""
+		} else if err != nil {
+			return false, stacktrace.Propagate(err, "cannot fetch last totp step of user with id %s", userID.String())
+		}
+
+		if lastStepValue, err := strconv.ParseInt(lastStep, 10, 64); err == nil && step <= lastStepValue {
+			return false, nil
+		}
+
+		// the step is remembered for as long as its code is accepted
+		swapped, err := service.cache.CompareAndSwap(cacheKey, lastStep, strconv.FormatInt(step, 10), (2*skew+1)*period*time.Second)
+		if err != nil {
+			return false, stacktrace.Propagate(err, "cannot store last totp step of user with id %s", userID.String())
+		}
+
+		if swapped {
+			return true, nil
+		}
+
+		// another code was used in the meantime so the step is checked against the new last step
+	}
+}
+
+// EncryptSecret encrypts a secret which was stored before secrets were encrypted at rest
+func (service Service) EncryptSecret(userID id.ID, secret string) (string, error) {
+	return service.keyring.Encrypt(secret, userID.String())
+}
+
+// IssueChallenge creates a short lived token which is exchanged for an access token together with a valid code
+func (service Service) IssueChallenge(userID id.ID, rememberMe bool) (challengeToken string, challenge Challenge, err error) {
+	challengeToken, tokenHash, err := token.Generate()
+	if err != nil {
+		return challengeToken, challenge, stacktrace.Propagate(err, "cannot generate two factor challenge token")
+	}
+
+	challenge = Challenge{
+		UserID:     userID.String(),
+		RememberMe: rememberMe,
+		ExpiresAt:  time.Now().UTC().Add(service.options.ChallengeLifetime),
+	}
+
+	value, err := json.Marshal(challenge)
+	if err != nil {
+		return challengeToken, challenge, stacktrace.Propagate(err, "cannot encode two factor challenge")
+	}
+
+	err = service.cache.Set(cacheKeyPrefixChallenge+tokenHash, string(value), service.options.ChallengeLifetime)
+	if err != nil {
+		return challengeToken, challenge, stacktrace.Propagate(err, "cannot store two factor challenge")
+	}
+
+	return challengeToken, challenge, nil
+}
+
+// FindChallenge returns the challenge of a token
+func (service Service) FindChallenge(challengeToken string) (challenge Challenge, err error) {
+	value, err := service.cache.Get(cacheKeyPrefixChallenge + token.Hash(challengeToken))
+	if err == cache.ErrCacheMiss {
+		return challenge, stacktrace.NewErrorWithCode(ErrCodeInvalidChallenge, "two factor challenge doesn't exist or has expired")
+	}
+	if err != nil {
+		return challenge, stacktrace.Propagate(err, "cannot fetch two factor challenge")
+	}
+
+	err = json.Unmarshal([]byte(value), &challenge)
+	if err != nil {
+		return challenge, stacktrace.Propagate(err, "cannot decode two factor challenge")
+	}
+
+	return challenge, nil
+}
+
+// DeleteChallenge invalidates a challenge token once it has been used
+func (service Service) DeleteChallenge(challengeToken string) error {
+	err := service.cache.Delete(cacheKeyPrefixChallenge + token.Hash(challengeToken))
+	if err != nil {
+		return stacktrace.Propagate(err, "cannot delete two factor challenge")
+	}
+
+	return nil
+}
@@ -0,0 +1,119 @@
+package twofactor
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/palantir/stacktrace"
+	"github.com/skip2/go-qrcode"
+)
+
+const (
+	// secretLength is the number of random bytes in a secret, RFC 4226 recommends 160 bits
+	secretLength = 20
+
+	// period is the number of seconds in a time step
+	period = 30
+
+	// digits is the number of digits in a code
+	digits = 6
+
+	// skew is the number of time steps before and after the current step which are accepted for clock drift
+	skew = 1
+
+	qrCodeSize = 256
+)
+
+var base32Encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// Enrolment is a new secret which the user adds to their authenticator app
+type Enrolment struct {
+	// Secret is shown to the user once, only EncryptedSecret is stored
+	Secret          string
+	EncryptedSecret string
+	// URI is the otpauth URI of the secret which is encoded in the QR code
+	URI string
+	// QRCode is a PNG data URI of the QR code
+	QRCode string
+}
+
+// newSecret generates a random base32 secret
+func newSecret() (string, error) {
+	secret := make([]byte, secretLength)
+	_, err := rand.Read(secret)
+	if err != nil {
+		return "", stacktrace.Propagate(err, "cannot read random bytes for a totp secret")
+	}
+
+	return base32Encoding.EncodeToString(secret), nil
+}
+
+// uri formats the secret in the key URI format understood by authenticator apps
+func uri(issuer string, accountName string, secret string) string {
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(digits))
+	query.Set("period", fmt.Sprint(period))
+
+	return (&url.URL{
+		Scheme: "otpauth",
+		Host:   "totp",
+		Path:   "/" + issuer + ":" + accountName,
+		// authenticator apps don't all decode + as a space
+		RawQuery: strings.ReplaceAll(query.Encode(), "+", "%20"),
+	}).String()
+}
+
+// qrCode encodes the content as a PNG data URI
+func qrCode(content string) (string, error) {
+	png, err := qrcode.Encode(content, qrcode.Medium, qrCodeSize)
+	if err != nil {
+		return "", stacktrace.Propagate(err, "cannot encode the qr code")
+	}
+
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(png), nil
+}
+
+// validateCode checks a code against the steps around the given time as described in RFC 6238.
+// It returns the matching time step so that a code cannot be used twice.
+func validateCode(secret string, code string, at time.Time) (step int64, ok bool) {
+	key, err := base32Encoding.DecodeString(secret)
+	if err != nil || len(code) != digits {
+		return step, false
+	}
+
+	current := at.Unix() / period
+	for step = current - skew; step <= current+skew; step++ {
+		if subtle.ConstantTimeCompare([]byte(generateCode(key, step)), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+
+	return step, false
+}
+
+// generateCode computes the HOTP value of RFC 4226 for a counter
+func generateCode(key []byte, counter int64) string {
+	message := make([]byte, 8)
+	binary.BigEndian.PutUint64(message, uint64(counter))
+
+	mac := hmac.New(sha1.New, key)
+	_, _ = mac.Write(message)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	return fmt.Sprintf("%0*d", digits, value%1000000)
+}
@@ -0,0 +1,159 @@
+package twofactor
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/api-service/cache/redis"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/encryption"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/id"
+	"github.com/alicebob/miniredis/v2"
+)
+
+// rfcSecret is the base32 encoding of the SHA1 secret of the test vectors in RFC 6238
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func newTestService(t *testing.T) Service {
+	server, err := miniredis.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(server.Close)
+
+	keyring, err := encryption.NewKeyring(map[string][]byte{"1": bytes.Repeat([]byte{1}, 32)}, "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return NewService(redis.NewClient(redis.Options{Address: server.Addr()}), keyring, Options{Issuer: "OV Chipkaart Dashboard"})
+}
+
+func TestValidateCode(t *testing.T) {
+	tests := []struct {
+		name         string
+		secret       string
+		code         string
+		at           time.Time
+		expectedStep int64
+		ok           bool
+	}{
+		{name: "rfc vector 59", secret: rfcSecret, code: "287082", at: time.Unix(59, 0), expectedStep: 1, ok: true},
+		{name: "rfc vector 1111111109", secret: rfcSecret, code: "081804", at: time.Unix(1111111109, 0), expectedStep: 37037036, ok: true},
+		{name: "rfc vector 1234567890", secret: rfcSecret, code: "005924", at: time.Unix(1234567890, 0), expectedStep: 41152263, ok: true},
+		{name: "rfc vector 2000000000", secret: rfcSecret, code: "279037", at: time.Unix(2000000000, 0), expectedStep: 66666666, ok: true},
+		{name: "previous step is accepted", secret: rfcSecret, code: "081804", at: time.Unix(1111111109+period, 0), expectedStep: 37037036, ok: true},
+		{name: "next step is accepted", secret: rfcSecret, code: "081804", at: time.Unix(1111111109-period, 0), expectedStep: 37037036, ok: true},
+		{name: "code outside the skew", secret: rfcSecret, code: "081804", at: time.Unix(1111111109+2*period, 0), ok: false},
+		{name: "wrong code", secret: rfcSecret, code: "123456", at: time.Unix(59, 0), ok: false},
+		{name: "code with too few digits", secret: rfcSecret, code: "28708", at: time.Unix(59, 0), ok: false},
+		{name: "invalid secret", secret: "not base32!", code: "287082", at: time.Unix(59, 0), ok: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			step, ok := validateCode(test.secret, test.code, test.at)
+			if ok != test.ok {
+				t.Fatalf("validateCode(%q) returned %t, expected %t", test.code, ok, test.ok)
+			}
+
+			if ok && step != test.expectedStep {
+				t.Errorf("validateCode(%q) returned step %d, expected %d", test.code, step, test.expectedStep)
+			}
+		})
+	}
+}
+
+func TestServiceValidateCode(t *testing.T) {
+	key, err := base32Encoding.DecodeString(rfcSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type attempt struct {
+		// stepOffset is the time step of the code relative to the current step
+		stepOffset int64
+		expected   bool
+	}
+
+	tests := []struct {
+		name     string
+		attempts []attempt
+	}{
+		{
+			name:     "a valid code is accepted",
+			attempts: []attempt{{stepOffset: 0, expected: true}},
+		},
+		{
+			name:     "a code cannot be used twice",
+			attempts: []attempt{{stepOffset: 0, expected: true}, {stepOffset: 0, expected: false}},
+		},
+		{
+			name:     "an older code is rejected after a newer code was used",
+			attempts: []attempt{{stepOffset: 0, expected: true}, {stepOffset: -1, expected: false}},
+		},
+		{
+			name:     "a newer code is accepted after an older code was used",
+			attempts: []attempt{{stepOffset: -1, expected: true}, {stepOffset: 0, expected: true}, {stepOffset: 1, expected: true}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service := newTestService(t)
+			userID := id.New()
+
+			encryptedSecret, err := service.EncryptSecret(userID, rfcSecret)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for index, attempt := range test.attempts {
+				code := generateCode(key, time.Now().Unix()/period+attempt.stepOffset)
+
+				valid, err := service.ValidateCode(userID, encryptedSecret, code)
+				if err != nil {
+					t.Fatalf("attempt %d returned an error: %s", index, err)
+				}
+
+				if valid != attempt.expected {
+					t.Fatalf("attempt %d returned %t, expected %t", index, valid, attempt.expected)
+				}
+			}
+		})
+	}
+}
+
+func TestServiceNewEnrolment(t *testing.T) {
+	service := newTestService(t)
+	userID := id.New()
+
+	enrolment, err := service.NewEnrolment(userID, "user@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if enrolment.EncryptedSecret == "" || enrolment.EncryptedSecret == enrolment.Secret {
+		t.Fatalf("the enrolment stores the secret as %q, expected it to be encrypted", enrolment.EncryptedSecret)
+	}
+
+	key, err := base32Encoding.DecodeString(enrolment.Secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := generateCode(key, time.Now().Unix()/period)
+
+	// the secret is bound to the user so it cannot be copied to the account of another user
+	_, err = service.ValidateCode(id.New(), enrolment.EncryptedSecret, code)
+	if err == nil {
+		t.Error("the secret was decrypted for another user")
+	}
+
+	valid, err := service.ValidateCode(userID, enrolment.EncryptedSecret, code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !valid {
+		t.Error("the code of the enrolment was rejected")
+	}
+}
@@ -6,8 +6,10 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/AchoArnold/homework/services/json"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/metrics"
 	"github.com/pkg/errors"
 	"go.uber.org/ratelimit"
 )
@@ -144,7 +146,9 @@ func (service TransactionFetcherAPIService) getTransactions(authorisationToken s
 	for i := 1; i < numberOfRequests; i++ {
 		payload.Offset = strconv.Itoa(transactionsResponse.Response.NextRequestContext.Offset)
 
+		waitStart := time.Now()
 		rateLimiter.Take()
+		metrics.ObserveRateLimitWait(metricsClientOvChipkaart, time.Since(waitStart))
 
 		transactionsResponse, err = service.getTransaction(payload)
 		if err != nil {
@@ -172,6 +176,7 @@ func (service TransactionFetcherAPIService) getTransaction(payload transactionsP
 	if err != nil {
 		return transactionsResponse, errors.Wrapf(err, "cannot perform transaction request: payload = %+#v", request)
 	}
+	defer response.Body.Close()
 
 	err = json.JsonDecode(&transactionsResponse, response.Body)
 	if err != nil {
@@ -199,6 +204,7 @@ func (service TransactionFetcherAPIService) getAuthorisationToken(authentication
 	if err != nil {
 		return authorisationToken, errors.Wrap(err, "cannot perform authorisation request")
 	}
+	defer response.Body.Close()
 
 	err = json.JsonDecode(&authorisationToken, response.Body)
 	if err != nil {
@@ -231,6 +237,7 @@ func (service TransactionFetcherAPIService) getAuthenticationToken(username, pas
 	if err != nil {
 		return authenticationToken, errors.Wrap(err, "cannot perform authentication request")
 	}
+	defer response.Body.Close()
 
 	err = json.JsonDecode(&authenticationToken, response.Body)
 	if err != nil {
@@ -250,6 +257,12 @@ func (service TransactionFetcherAPIService) doHTTPRequest(request *http.Request)
 		return nil, errors.Wrapf(err, "cannot execute %s request for %s: ", request.Method, request.URL.String())
 	}
 
+	// 4xx responses are decoded by the callers because they contain the reason why the user is not authorized
+	if apiResponse.StatusCode >= http.StatusInternalServerError || apiResponse.StatusCode == http.StatusTooManyRequests {
+		_ = apiResponse.Body.Close()
+		return nil, errors.Errorf("invalid response code %d for %s request to %s", apiResponse.StatusCode, request.Method, request.URL.String())
+	}
+
 	return apiResponse, nil
 }
 
@@ -1,11 +1,12 @@
 package main
 
 import (
-	"log"
+	"context"
 	"net/http"
 	"time"
 
 	"github.com/AchoArnold/homework/services/json"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/logger"
 	"github.com/pkg/errors"
 )
 
@@ -19,13 +20,15 @@ const (
 type CalendarificAPIClient struct {
 	apiKey     string
 	httpClient HTTPClient
+	logger     logger.Logger
 }
 
 // NewCalendarificAPIClient returns a new CalendarificAPIClient
-func NewCalendarificAPIClient(apiKey string, httpClient HTTPClient) CalendarificAPIClient {
+func NewCalendarificAPIClient(apiKey string, httpClient HTTPClient, logger logger.Logger) CalendarificAPIClient {
 	return CalendarificAPIClient{
 		apiKey:     apiKey,
 		httpClient: httpClient,
+		logger:     logger,
 	}
 }
 
@@ -73,6 +76,7 @@ func (apiClient CalendarificAPIClient) FetchNationalHolidays(timestamp time.Time
 	if err != nil {
 		return holidays, errors.Wrap(err, "could not perform services request")
 	}
+	defer response.Body.Close()
 
 	var apiResponse holidayAPIResponse
 	err = json.JsonDecode(&apiResponse, response.Body)
@@ -85,7 +89,7 @@ func (apiClient CalendarificAPIClient) FetchNationalHolidays(timestamp time.Time
 	}
 
 	for _, holiday := range apiResponse.Response.Holidays {
-		log.Println(holiday.Date.Iso)
+		apiClient.logger.Debug(context.Background(), "parsing national holiday", "name", holiday.Name, "date", holiday.Date.Iso)
 		val, err := time.Parse(dateFormat, holiday.Date.Iso)
 		if err != nil {
 			return holidays, errors.Wrap(err, "cannot convert time to date")
@@ -109,6 +113,12 @@ func (apiClient CalendarificAPIClient) doHTTPRequest(request *http.Request) (*ht
 		return nil, errors.Wrapf(err, "cannot execute %s request for %s: ", request.Method, request.URL.String())
 	}
 
+	// 4xx responses are decoded by the caller because the meta of the body explains the error
+	if apiResponse.StatusCode >= http.StatusInternalServerError || apiResponse.StatusCode == http.StatusTooManyRequests {
+		_ = apiResponse.Body.Close()
+		return nil, errors.Errorf("invalid response code %d for %s request to %s", apiResponse.StatusCode, request.Method, request.URL.String())
+	}
+
 	return apiResponse, nil
 }
 
@@ -145,6 +145,7 @@ func (service NSAPIClient) FetchJourneyPrice(nsJourney NSJourney) (price NSJourn
 	if err != nil {
 		return price, errors.Wrap(err, "cannot do http request")
 	}
+	defer response.Body.Close()
 
 	if response.StatusCode != responseCodeOk && response.StatusCode != 400 {
 		return price, errors.Wrapf(errors.New("invalid response code"), "%d", response.StatusCode)
@@ -198,6 +199,7 @@ func (service NSAPIClient) GetAllStations() (stations []NSStation, error error)
 	if err != nil {
 		return stations, errors.Wrap(err, "cannot do http request for all stations")
 	}
+	defer response.Body.Close()
 
 	if response.StatusCode != responseCodeOk && response.StatusCode != 400 {
 		return stations, errors.Wrapf(errors.New("invalid response code for all stations"), "%d", response.StatusCode)
@@ -89,3 +89,12 @@ const basicFare = 98
 
 // Used to get the time of the journey. so journey time = cost multiplier * (journey price - base fare)
 const costMultiplier = 5
+
+const (
+	// metricsClientNS is the client label of the metrics of the NS API
+	metricsClientNS = "ns"
+	// metricsClientCalendarific is the client label of the metrics of the Calendarific API
+	metricsClientCalendarific = "calendarific"
+	// metricsClientOvChipkaart is the client label of the metrics of the ov-chipkaart API
+	metricsClientOvChipkaart = "ov-chipkaart"
+)
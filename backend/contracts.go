@@ -287,3 +287,55 @@ type NationalHolidaysRepository interface {
 	HasHoliday(timestamp time.Time) (result bool, err error)
 	GetByTimestamp(timestamp time.Time) (holiday Holiday, err error)
 }
+
+// subscription names of the calculation results
+const (
+	subscriptionNoDiscount     = "no-discount"
+	subscriptionDalVoordeel    = "dal-voordeel"
+	subscriptionAltijdVoordeel = "altijd-voordeel"
+	subscriptionDalVrij        = "dal-vrij"
+)
+
+// CalculationResult is the price of the journeys of a transaction with one of the NS subscriptions.
+// The prices are stored in euro cents.
+type CalculationResult struct {
+	DBTimestamp             `bson:"db_timestamp,omitempty"`
+	TransactionID           TransactionID `bson:"transaction_id"`
+	Subscription            string        `bson:"subscription"`
+	OffPeakFirstClassPrice  int           `bson:"off_peak_first_class_price"`
+	OffPeakSecondClassPrice int           `bson:"off_peak_second_class_price"`
+	OffPeakJourneyCount     int           `bson:"off_peak_journey_count"`
+	PeakFirstClassPrice     int           `bson:"peak_first_class_price"`
+	PeakSecondClassPrice    int           `bson:"peak_second_class_price"`
+	PeakJourneyCount        int           `bson:"peak_journey_count"`
+	PeakSupplementPrice     int           `bson:"peak_supplement_price"`
+	PeakSupplementCount     int           `bson:"peak_supplement_count"`
+	OffPeakSupplementPrice  int           `bson:"off_peak_supplement_price"`
+	OffPeakSupplementCount  int           `bson:"off_peak_supplement_count"`
+	ErrorCount              int           `bson:"error_count"`
+}
+
+// NewCalculationResult converts the result of a calculator. The results of all the calculators have the same fields
+// so the results of the other calculators can be converted to an NSNoDiscountCalculatorResult.
+func NewCalculationResult(transactionID TransactionID, subscription string, result NSNoDiscountCalculatorResult) CalculationResult {
+	return CalculationResult{
+		TransactionID:           transactionID,
+		Subscription:            subscription,
+		OffPeakFirstClassPrice:  result.OffPeakFirstClassPrice.Value(),
+		OffPeakSecondClassPrice: result.OffPeakSecondClassPrice.Value(),
+		OffPeakJourneyCount:     result.OffPeakJourneyCount,
+		PeakFirstClassPrice:     result.PeakFirstClassPrice.Value(),
+		PeakSecondClassPrice:    result.PeakSecondClassPrice.Value(),
+		PeakJourneyCount:        result.PeakJourneyCount,
+		PeakSupplementPrice:     result.PeakSupplementPrice.Value(),
+		PeakSupplementCount:     result.PeakSupplementCount,
+		OffPeakSupplementPrice:  result.OffPeakSupplementPrice.Value(),
+		OffPeakSupplementCount:  result.OffPeakSupplementCount,
+		ErrorCount:              len(result.Error.ErrorRecords),
+	}
+}
+
+// CalculationResultsRepository is the repository for storing the results of the calculators
+type CalculationResultsRepository interface {
+	Store(results []CalculationResult) (err error)
+}
@@ -1,26 +1,29 @@
 package main
 
 import (
-	"log"
+	"context"
+	"fmt"
 
-	"github.com/pkg/errors"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/logger"
 )
 
 // SentryErrorHandler is an implementation of the error handler which sends errors to sentry
 type SentryErrorHandler struct {
+	logger logger.Logger
 }
 
 // NewSentryErrorHandler creates a new instance fo an error handler which sends errors to sentry
-func NewSentryErrorHandler() *SentryErrorHandler {
-	return &SentryErrorHandler{}
+func NewSentryErrorHandler(logger logger.Logger) *SentryErrorHandler {
+	return &SentryErrorHandler{logger: logger}
 }
 
 // HandleSoftError is responsible for handling non fatal errors
 func (handler SentryErrorHandler) HandleSoftError(err error) {
-	log.Printf(errors.Wrapf(err, "%+v", err).Error())
+	handler.logger.Error(context.Background(), "soft error", "err", fmt.Sprintf("%+v", err))
 }
 
 // HandleHardError is responsible for handling fatal errors
 func (handler SentryErrorHandler) HandleHardError(err error) {
-	log.Panicf(errors.Wrapf(err, "%+v", err).Error())
+	handler.logger.Error(context.Background(), "hard error", "err", fmt.Sprintf("%+v", err))
+	panic(err)
 }
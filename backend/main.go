@@ -7,11 +7,14 @@ import (
 	"os"
 	"time"
 
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/config"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/httpclient"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/logger"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/metrics"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/ovchipkaart"
 	lfucache "github.com/NdoleStudio/lfu-cache"
-	"github.com/davecgh/go-spew/spew"
 
 	"github.com/getsentry/sentry-go"
-	"github.com/joho/godotenv"
 	"github.com/lunux2008/xulu"
 	"github.com/pkg/errors"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -22,27 +25,37 @@ import (
 const localeEnglish = "en-EN"
 
 func main() {
-	err := godotenv.Load()
+	appConfig := config.Analysis{}
+	config.MustLoad("analysis", &appConfig)
+
+	level, err := logger.ParseLevel(appConfig.Logging.Level)
 	if err != nil {
-		log.Fatal("error loading .env file")
+		log.Fatal(errors.Wrap(err, "cannot parse LOG_LEVEL"))
 	}
+	appLogger := logger.NewGoKitLogger(os.Stdout, level)
 
-	err = sentry.Init(sentry.ClientOptions{Dsn: os.Getenv("SENTRY_DSN")})
+	err = sentry.Init(sentry.ClientOptions{Dsn: appConfig.SentryDSN})
 	if err != nil {
 		log.Fatalf("sentry.Init: %s", err)
 	}
 
+	if appConfig.MetricsAddress != "" {
+		go func() {
+			log.Fatal(metrics.ListenAndServe(appConfig.MetricsAddress))
+		}()
+	}
+
 	// Flush buffered events before the program terminates.
 	defer sentry.Flush(2 * time.Second)
 	sentry.CaptureMessage("It works!")
 
-	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI(os.Getenv("MONGODB_URI")))
+	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI(appConfig.MongoDB.URI))
 	if err != nil {
 		log.Fatal(errors.Wrapf(err, "cannot connect to mongoDB"))
 	}
 
-	mongodb := client.Database(os.Getenv("MONGODB_DB_NAME"))
-	//loadNsStations(mongodb)
+	mongodb := client.Database(appConfig.MongoDB.DBName)
+	//loadNsStations(mongodb, appConfig, appLogger)
 
 	/*err = mongodb.Collection(collectionRawRecords).Drop(context.Background())
 	if err != nil {
@@ -54,29 +67,29 @@ func main() {
 	//	log.Fatalf(err.Error())
 	//}
 
-	//storeNSTransactions(mongodb)
-	//storeNationalHolidays(mongodb)
+	//storeNSTransactions(mongodb, appConfig, appLogger)
+	//storeNationalHolidays(mongodb, appConfig, appLogger)
 
 	//
 	bsonService := NewBsonService()
-	errorHandler := NewSentryErrorHandler()
+	errorHandler := NewSentryErrorHandler(appLogger)
 	rawRecordsRepository := InitializeRawRecordsRepository(collectionRawRecords, mongodb)
 
 	enrichedRecordsRepository := NewMongoNSEnrichedRecordsRepository(mongodb, collectionNSEnrichedRecords, bsonService)
 	cache, err := lfucache.New(100)
-	nsClient := NewNSAPIClient(&http.Client{}, os.Getenv("NS_API_KEY_PUBLIC_TRAVEL_INFORMATION"))
+	nsClient := NewNSAPIClient(httpclient.New(metrics.NewInstrumentedHTTPClient(metricsClientNS, &http.Client{}), httpclient.Options{Name: metricsClientNS}), appConfig.NSAPIKey)
 	pricesRepository := NewMongoNSPricesRepository(mongodb, collectionNSPrices, bsonService)
-	stationsRepository := NewMongoNSStationsRepository(mongodb, collectionNSStations, bsonService)
+	stationsRepository := NewMongoNSStationsRepository(mongodb, collectionNSStations, bsonService, appLogger)
 	priceFetcher := NewNSPriceFetcher(nsClient, pricesRepository, errorHandler, cache)
-	stationCodeService := NewNSStationsCodeService(stationsRepository, errorHandler, cache)
-	enrichmentService := NewNSRawRecordsEnrichmentService(stationCodeService, priceFetcher)
+	stationCodeService := NewNSStationsCodeService(stationsRepository, errorHandler, cache, appLogger)
+	enrichmentService := NewNSRawRecordsEnrichmentService(stationCodeService, priceFetcher, appLogger)
 	//
-	log.Println("Fetching first transaction")
+	appLogger.Info(context.Background(), "fetching first transaction")
 	id, err := rawRecordsRepository.First()
 	if err != nil {
 		errorHandler.HandleHardError(err)
 	}
-	log.Println("Finished fetching first transaction")
+	appLogger.Info(context.Background(), "fetched first transaction", "transaction_id", id.TransactionID.String())
 
 	globalTransactionID := *id.TransactionID
 	//getOptions := GetRawRecordsOptions{
@@ -107,7 +120,7 @@ func main() {
 	//log.Println("Finished storing of enriched records")
 
 	nationalHolidayRepository := InitializeNationalHolidaysRepository(collectionNationalHolidays, mongodb)
-	offPeakService := NewNSOffPeakService(nationalHolidayRepository, InitializeCache(100), NewSentryErrorHandler())
+	offPeakService := NewNSOffPeakService(nationalHolidayRepository, InitializeCache(100), errorHandler)
 	noDiscountCalculatorService := NewNSNoDiscountCalculator(priceFetcher, offPeakService)
 
 	enrichedRecords, err := enrichedRecordsRepository.FetchAllForTransactionID(globalTransactionID)
@@ -116,32 +129,40 @@ func main() {
 	}
 
 	result := noDiscountCalculatorService.Calculate(enrichedRecords)
-	spew.Dump(result)
 
 	darVoordeelCalculator := NewNSDalVoordeelCalculator(priceFetcher, offPeakService)
 	dalVoordeel := darVoordeelCalculator.Calculate(enrichedRecords)
-	spew.Dump(dalVoordeel)
 
 	altijdVoordeelCalculator := NewNSAltijdVoordeelCalculator(priceFetcher, offPeakService)
 	altijdVoordeel := altijdVoordeelCalculator.Calculate(enrichedRecords)
 
-	spew.Dump(altijdVoordeel)
-
 	dalVrijCalculator := NewNSDalVrijCalculator(priceFetcher, offPeakService)
 	dalVrij := dalVrijCalculator.Calculate(enrichedRecords)
 
-	spew.Dump(dalVrij)
+	calculationResultsRepository := NewMongoCalculationResultsRepository(mongodb, collectionCalculationResults, bsonService)
+	err = calculationResultsRepository.Store([]CalculationResult{
+		NewCalculationResult(globalTransactionID, subscriptionNoDiscount, result),
+		NewCalculationResult(globalTransactionID, subscriptionDalVoordeel, NSNoDiscountCalculatorResult(dalVoordeel)),
+		NewCalculationResult(globalTransactionID, subscriptionAltijdVoordeel, NSNoDiscountCalculatorResult(altijdVoordeel)),
+		NewCalculationResult(globalTransactionID, subscriptionDalVrij, NSNoDiscountCalculatorResult(dalVrij)),
+	})
+	if err != nil {
+		errorHandler.HandleHardError(err)
+	}
+	appLogger.Info(context.Background(), "stored calculation results", "transaction_id", globalTransactionID.String())
 
 	xulu.Use(enrichmentService)
 }
 
-func storeNationalHolidays(db *mongo.Database) {
+func storeNationalHolidays(db *mongo.Database, appConfig config.Analysis, logger logger.Logger) {
 	nationalHolidaysRepository := InitializeNationalHolidaysRepository(collectionNationalHolidays, db)
-	holidaysClient := NewCalendarificAPIClient(os.Getenv("CALENDARIFIC_API_KEY"), &http.Client{})
+	holidaysClient := NewCalendarificAPIClient(appConfig.CalendarificAPIKey, httpclient.New(metrics.NewInstrumentedHTTPClient(metricsClientCalendarific, &http.Client{}), httpclient.Options{Name: metricsClientCalendarific}), logger)
 
 	rateLimiter := ratelimit.New(1)
 	for i := 0; i < 3; i++ {
+		waitStart := time.Now()
 		rateLimiter.Take()
+		metrics.ObserveRateLimitWait(metricsClientCalendarific, time.Since(waitStart))
 
 		holidays, err := holidaysClient.FetchNationalHolidays(time.Now().AddDate(i-1, 0, 0))
 		if err != nil {
@@ -154,43 +175,43 @@ func storeNationalHolidays(db *mongo.Database) {
 		}
 	}
 }
-func loadNsStations(mongodb *mongo.Database) {
+func loadNsStations(mongodb *mongo.Database, appConfig config.Analysis, logger logger.Logger) {
 	bsonService := BsonService{}
-	nsStationsRepository := NewMongoNSStationsRepository(mongodb, collectionNSStations, bsonService)
+	nsStationsRepository := NewMongoNSStationsRepository(mongodb, collectionNSStations, bsonService, logger)
 	//
-	log.Printf("Fetching Stations")
-	nsClient := NewNSAPIClient(&http.Client{}, os.Getenv("NS_API_KEY_PUBLIC_TRAVEL_INFORMATION"))
+	logger.Info(context.Background(), "fetching stations")
+	nsClient := NewNSAPIClient(httpclient.New(metrics.NewInstrumentedHTTPClient(metricsClientNS, &http.Client{}), httpclient.Options{Name: metricsClientNS}), appConfig.NSAPIKey)
 	stations, err := nsClient.GetAllStations()
 	if err != nil {
 		log.Fatalf(err.Error())
 	}
-	log.Printf("Stations fetch finished")
+	logger.Info(context.Background(), "fetched stations", "count", len(stations))
 
-	log.Printf("Storing stations in the database")
+	logger.Info(context.Background(), "storing stations in the database")
 	err = nsStationsRepository.Store(stations)
 	if err != nil {
 		log.Fatalf(err.Error())
 	}
-	log.Printf("Finished storing stations")
+	logger.Info(context.Background(), "stored stations")
 }
 
-func storeNSTransactions(mongodb *mongo.Database) {
+func storeNSTransactions(mongodb *mongo.Database, appConfig config.Analysis, logger logger.Logger) {
 	bsonService := NewBsonService()
 	rawRecordsRepository := NewMongodbRawRecordsRepository(mongodb, collectionRawRecords, bsonService)
 
 	//
-	config := TransactionFetcherAPIServiceConfig{
-		ClientID:     os.Getenv("CLIENT_ID"),
-		ClientSecret: os.Getenv("CLIENT_SECRET"),
+	apiServiceConfig := TransactionFetcherAPIServiceConfig{
+		ClientID:     appConfig.OvChipkaart.ClientID,
+		ClientSecret: appConfig.OvChipkaart.ClientSecret,
 		Locale:       localeEnglish,
-		Client:       &http.Client{},
+		Client:       httpclient.New(metrics.NewInstrumentedHTTPClient(metricsClientOvChipkaart, &http.Client{}), ovchipkaart.HTTPClientOptions()),
 	}
-	apiService := NewAPIService(config)
+	apiService := NewAPIService(apiServiceConfig)
 
 	transactionConfig := TransactionFetchOptions{
-		Username:   os.Getenv("OV_CHIPKAAT_USERNAME"),
-		Password:   os.Getenv("OV_CHIPKAAT_PASSWORD"),
-		CardNumber: os.Getenv("OV_CHIPKAAT_CARD_NUMBER"),
+		Username:   appConfig.OvChipkaart.Username,
+		Password:   appConfig.OvChipkaart.Password,
+		CardNumber: appConfig.OvChipkaart.CardNumber,
 		StartDate:  time.Unix(0, 0),
 		EndDate:    time.Now(),
 	}
@@ -198,12 +219,12 @@ func storeNSTransactions(mongodb *mongo.Database) {
 	globalTransactionID := NewTransactionID()
 
 	//
-	log.Println("Fetching Transactions")
+	logger.Info(context.Background(), "fetching transactions")
 	records, err := apiService.FetchTransactions(transactionConfig)
 	if err != nil {
 		log.Panicf(errors.Wrapf(err, "%+v", err).Error())
 	}
-	log.Println("Finished Fetching transactions")
+	logger.Info(context.Background(), "fetched transactions", "count", len(records))
 
 	// Enriching records
 	source := rawRecordSourceAPI
@@ -213,11 +234,10 @@ func storeNSTransactions(mongodb *mongo.Database) {
 		records[index].TransactionID = &transactionID
 		records[index].Source = &source
 		records[index].ID = &recordID
-		println(records[index].TransactionName)
+		logger.Debug(context.Background(), "prepared transaction", "record_id", recordID.String(), "transaction_name", records[index].TransactionName.String())
 	}
 
-	log.Println(len(records))
-	log.Println("Inserting into database")
+	logger.Info(context.Background(), "inserting transactions into the database", "count", len(records))
 	err = rawRecordsRepository.Store(records)
 	if err != nil {
 		log.Panicf(errors.Wrapf(err, "%+v", err).Error())
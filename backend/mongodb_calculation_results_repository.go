@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoCalculationResultsRepository is responsible for persisting the results of the calculators
+type MongoCalculationResultsRepository struct {
+	MongodbRepository
+}
+
+// NewMongoCalculationResultsRepository is used to initialize this class
+func NewMongoCalculationResultsRepository(db *mongo.Database, collection string, bsonService BsonService) *MongoCalculationResultsRepository {
+	return &MongoCalculationResultsRepository{MongodbRepository{db, collection, bsonService}}
+}
+
+// Store replaces the result of a subscription when the transaction is calculated again
+func (repository *MongoCalculationResultsRepository) Store(results []CalculationResult) (err error) {
+	for _, result := range results {
+		document, err := repository.bsonService.EncodeToBsonM(result)
+		if err != nil {
+			return errors.Wrapf(err, "cannot convert result to map")
+		}
+
+		_, err = repository.db.Collection(repository.collection).ReplaceOne(
+			context.Background(),
+			bson.M{"transaction_id": result.TransactionID.String(), "subscription": result.Subscription},
+			repository.SetTimestampFields(document),
+			options.Replace().SetUpsert(true),
+		)
+		if err != nil {
+			return errors.Wrapf(err, "cannot store %s result of transaction %s", result.Subscription, result.TransactionID.String())
+		}
+	}
+
+	return nil
+}
@@ -18,11 +18,12 @@ var (
 
 // collection names
 const (
-	collectionRawRecords        = "raw_records"
-	collectionNSStations        = "ns_stations"
-	collectionNSPrices          = "ns_journey_prices"
-	collectionNSEnrichedRecords = "ns_enriched_records"
-	collectionNationalHolidays  = "national_holidays"
+	collectionRawRecords         = "raw_records"
+	collectionNSStations         = "ns_stations"
+	collectionNSPrices           = "ns_journey_prices"
+	collectionNSEnrichedRecords  = "ns_enriched_records"
+	collectionNationalHolidays   = "national_holidays"
+	collectionCalculationResults = "ns_calculation_results"
 )
 
 // db keys names
@@ -2,8 +2,8 @@ package main
 
 import (
 	"context"
-	"log"
 
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/logger"
 	"github.com/pkg/errors"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -12,11 +12,12 @@ import (
 // MongoNSStationsRepository is responsible for persisting/loading prices for NS journeys
 type MongoNSStationsRepository struct {
 	MongodbRepository
+	logger logger.Logger
 }
 
 // NewMongoNSStationsRepository is used to initialize this class
-func NewMongoNSStationsRepository(db *mongo.Database, collection string, bsonService BsonService) *MongoNSStationsRepository {
-	return &MongoNSStationsRepository{MongodbRepository{db, collection, bsonService}}
+func NewMongoNSStationsRepository(db *mongo.Database, collection string, bsonService BsonService, logger logger.Logger) *MongoNSStationsRepository {
+	return &MongoNSStationsRepository{MongodbRepository{db, collection, bsonService}, logger}
 }
 
 // Store stores a list of  NS station to the database
@@ -43,9 +44,8 @@ func (repository *MongoNSStationsRepository) Store(stations []NSStation) (err er
 func (repository *MongoNSStationsRepository) GetByName(name string) (station NSStation, err error) {
 	ctx, _ := context.WithTimeout(context.Background(), dbOperationTimeout)
 
-	log.Println("Db fetching for name = ", name)
+	repository.logger.Debug(ctx, "fetching station by name", "name", name)
 	err = repository.db.Collection(repository.collection).FindOne(ctx, bson.M{"name": name}).Decode(&station)
-	log.Println("finished fetching")
 
 	if err == mongo.ErrNoDocuments {
 		return station, ErrNotFound
@@ -61,9 +61,8 @@ func (repository *MongoNSStationsRepository) GetByName(name string) (station NSS
 func (repository *MongoNSStationsRepository) GetByCode(code string) (station NSStation, err error) {
 	ctx, _ := context.WithTimeout(context.Background(), dbOperationTimeout)
 
-	log.Println("Db fetching for code = ", code)
+	repository.logger.Debug(ctx, "fetching station by code", "code", code)
 	err = repository.db.Collection(repository.collection).FindOne(ctx, bson.M{"code": code}).Decode(&station)
-	log.Println("finished fetching")
 
 	if err == mongo.ErrNoDocuments {
 		return station, ErrNotFound
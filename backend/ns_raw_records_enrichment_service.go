@@ -1,10 +1,11 @@
 package main
 
 import (
-	"log"
+	"context"
 	"time"
 
-	"github.com/davecgh/go-spew/spew"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/logger"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/metrics"
 	"github.com/pkg/errors"
 	"go.uber.org/ratelimit"
 )
@@ -13,11 +14,12 @@ import (
 type NSRawRecordsEnrichmentService struct {
 	stationsCodeService NSStationsCodeService
 	priceFetcher        NSPriceFetcherService
+	logger              logger.Logger
 }
 
 // NewNSRawRecordsEnrichmentService creates a new instance of the NSRawRecordsEnrichmentService
-func NewNSRawRecordsEnrichmentService(stationsCodeService NSStationsCodeService, priceFetcher NSPriceFetcherService) NSRawRecordsEnrichmentService {
-	return NSRawRecordsEnrichmentService{stationsCodeService, priceFetcher}
+func NewNSRawRecordsEnrichmentService(stationsCodeService NSStationsCodeService, priceFetcher NSPriceFetcherService, logger logger.Logger) NSRawRecordsEnrichmentService {
+	return NSRawRecordsEnrichmentService{stationsCodeService, priceFetcher, logger}
 }
 
 // Enrich goes over all the raw records and enriches NS specific records.
@@ -38,13 +40,14 @@ func (service NSRawRecordsEnrichmentService) Enrich(records []RawRecord) (result
 
 	rateLimiter := ratelimit.New(5)
 	for _, record := range records {
+		waitStart := time.Now()
 		rateLimiter.Take()
+		metrics.ObserveRateLimitWait(metricsClientNS, time.Since(waitStart))
 		rawRecordID := record.ID
 		transactionID := record.TransactionID
 		enrichedRecordID := NewTransactionID()
 
-		idString := rawRecordID.String()
-		log.Println("Processing ID: ", idString)
+		service.logger.Debug(context.Background(), "enriching raw record", "raw_record_id", rawRecordID.String())
 
 		// Check if record is check-in record Record
 		if record.IsCheckIn() {
@@ -77,11 +80,9 @@ func (service NSRawRecordsEnrichmentService) Enrich(records []RawRecord) (result
 		// Record is a checkout record meaning we can calculate the price
 		if record.IsCheckOut() {
 			if record.IsNS() {
-				log.Println("if start")
 				enrichedRecord, errorRecord := service.getEnrichedNsRecord(prev, record, rawRecordID, transactionID, &enrichedRecordID)
-				log.Println("Finished enrich")
 				if errorRecord.Error != nil {
-					spew.Dump(errorRecord.Error)
+					service.logger.Warn(context.Background(), "cannot enrich raw record", "raw_record_id", rawRecordID.String(), "err", errorRecord.Error)
 					enrichmentErrors.ErrorRecords = append(enrichmentErrors.ErrorRecords, errorRecord)
 				} else {
 					enrichedRecords = append(enrichedRecords, enrichedRecord)
@@ -90,9 +91,7 @@ func (service NSRawRecordsEnrichmentService) Enrich(records []RawRecord) (result
 				// If we're here we know that the record is a check-out record but we don't know the company to which it belongs.
 				// We'll check if the journey can be an NS journey and if that's the case, we'll enrich it.
 				// If the journey is not a valid NSJourney we don't bother about it.
-				log.Println("else start")
 				enrichedRecord, errorRecord := service.getEnrichedNsRecord(prev, record, rawRecordID, transactionID, &enrichedRecordID)
-				log.Println("else returne")
 				if errorRecord.Error == nil {
 					enrichedRecords = append(enrichedRecords, enrichedRecord)
 				}
@@ -104,7 +103,6 @@ func (service NSRawRecordsEnrichmentService) Enrich(records []RawRecord) (result
 		prev = record
 	}
 
-	log.Println(prev)
 	return RawRecordsEnrichmentResults{
 		ValidRecords: enrichedRecords,
 		Error:        enrichmentErrors,
@@ -121,19 +119,16 @@ func (service NSRawRecordsEnrichmentService) getEnrichedNsRecord(prev, record Ra
 		startTimeIsExact = false
 	}
 
-	spew.Dump(record)
-
-	log.Printf("Fetching from station name for %s ", record.CheckInInfo)
+	service.logger.Debug(context.Background(), "fetching the code of the departure station", "station", record.CheckInInfo)
 	fromStation, err := service.stationsCodeService.GetCodeForStationName(record.CheckInInfo)
 	if err != nil {
-		log.Println("error returned")
 		return enrichedRecord, ErrorRawRecord{
 			Record: record,
 			Error:  errors.Wrapf(err, "cannot get code for station: %s", record.CheckInInfo),
 		}
 	}
 
-	log.Printf("Fetching to station name for %s ", record.TransactionInfo)
+	service.logger.Debug(context.Background(), "fetching the code of the arrival station", "station", record.TransactionInfo)
 	toStation, err := service.stationsCodeService.GetCodeForStationName(record.TransactionInfo)
 	if err != nil {
 		return enrichedRecord, ErrorRawRecord{
@@ -143,7 +138,6 @@ func (service NSRawRecordsEnrichmentService) getEnrichedNsRecord(prev, record Ra
 	}
 
 	journey := NewNSJourney(record.TransactionDateTime.ToTime(), fromStation.Code, toStation.Code)
-	spew.Dump(journey)
 	if !startTimeIsExact {
 		price, err := service.priceFetcher.FetchPrice(journey)
 		if err != nil {
@@ -1,10 +1,11 @@
 package main
 
 import (
+	"context"
 	"fmt"
-	"log"
 	"strings"
 
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/logger"
 	"github.com/pkg/errors"
 )
 
@@ -18,11 +19,12 @@ type NSStationsCodeService struct {
 	cache        LFUCache
 	repository   NSStationsRepository
 	errorHandler ErrorHandler
+	logger       logger.Logger
 }
 
 // NewNSStationsCodeService is a service for fetching the station code based on a station name
-func NewNSStationsCodeService(repository NSStationsRepository, errorHandler ErrorHandler, cache LFUCache) NSStationsCodeService {
-	return NSStationsCodeService{cache, repository, errorHandler}
+func NewNSStationsCodeService(repository NSStationsRepository, errorHandler ErrorHandler, cache LFUCache, logger logger.Logger) NSStationsCodeService {
+	return NSStationsCodeService{cache, repository, errorHandler, logger}
 }
 
 // GetCodeForStationName gets the station code for a corresponding station name.
@@ -37,7 +39,7 @@ func (service *NSStationsCodeService) GetCodeForStationName(stationName string)
 		return val.(NSStation), error
 	}
 
-	log.Println("station name cache miss")
+	service.logger.Debug(context.Background(), "station name cache miss", "station", stationName)
 
 	// Search the database for the code
 	nsStation, err = service.repository.GetByName(stationName)
@@ -48,7 +50,7 @@ func (service *NSStationsCodeService) GetCodeForStationName(stationName string)
 			return nsStation, ErrorInvalidStationName
 		}
 
-		log.Println("Finished fetching by code")
+		service.logger.Debug(context.Background(), "fetched station by code", "station", stationName)
 
 		// log this error for debugging
 		service.errorHandler.HandleSoftError(
@@ -56,16 +58,14 @@ func (service *NSStationsCodeService) GetCodeForStationName(stationName string)
 		)
 	}
 
-	log.Println("finished fetching name")
+	service.logger.Debug(context.Background(), "fetched station", "station", stationName, "code", nsStation.Code)
+
 	// the stationName code exists so update the cache
 	err = service.cache.Set(nsStation.Name, nsStation)
-	log.Println("finished setting cache", err)
 	if err != nil {
 		// log this error for debugging
 		service.errorHandler.HandleSoftError(err)
 	}
 
-	log.Println("returning")
-
 	return nsStation, nil
 }
@@ -0,0 +1,22 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/raw-records-service/entities"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/id"
+)
+
+// CardRepository stores the cards which are synced periodically together with their sync state
+type CardRepository interface {
+	// CreateIndexes removes duplicate cards of a user and makes sure that a card can only be registered once per user
+	CreateIndexes(ctx context.Context) error
+	// EncryptPlaintextPasswords encrypts the passwords which were stored before passwords were encrypted at rest
+	EncryptPlaintextPasswords(ctx context.Context, encrypt func(cardID id.ID, password string) (string, error)) (updated int64, err error)
+	Store(ctx context.Context, card entities.Card) error
+	Update(ctx context.Context, card entities.Card) error
+	FetchDue(ctx context.Context, now time.Time) (cards []entities.Card, err error)
+	FetchForUser(ctx context.Context, userID id.ID) (cards []entities.Card, err error)
+	DeleteForUser(ctx context.Context, userID id.ID) (deleted int64, err error)
+}
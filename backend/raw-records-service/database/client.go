@@ -3,4 +3,5 @@ package database
 // DB is a collection of database repositories
 type DB interface {
 	RawRecordRepository() RawRecordRepository
+	CardRepository() CardRepository
 }
@@ -0,0 +1,274 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/raw-records-service/database"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/raw-records-service/entities"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/errors"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/id"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/mongodb"
+	"github.com/palantir/stacktrace"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	fieldNextSyncAt        = "next_sync_at"
+	fieldEncryptedPassword = "encrypted_password"
+	fieldPlaintextPassword = "password"
+)
+
+// CardRepository stores cards which are synced periodically
+type CardRepository struct {
+	mongodb.Repository
+}
+
+// NewCardRepository creates a new instance of the card repository
+func NewCardRepository(db *mongo.Database, collection string) database.CardRepository {
+	return &CardRepository{mongodb.NewRepository(db, collection)}
+}
+
+// CreateIndexes creates the unique index on the card number of a user.
+// Cards which were registered more than once are removed first, the oldest registration of a card is kept.
+func (repository *CardRepository) CreateIndexes(ctx context.Context) error {
+	ctx, cancel := repository.TimeoutContext(ctx)
+	defer cancel()
+
+	cursor, err := repository.Collection().Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$sort", Value: bson.M{"created_at": mongodb.SortOrderAscending}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   bson.M{"user_id": "$user_id", "card_number": "$card_number"},
+			"ids":   bson.M{"$push": "$id"},
+			"count": bson.M{"$sum": 1},
+		}}},
+		{{Key: "$match", Value: bson.M{"count": bson.M{"$gt": 1}}}},
+	})
+	if err != nil {
+		return stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseError, "cannot find duplicate cards")
+	}
+
+	var duplicates []struct {
+		IDs []string `bson:"ids"`
+	}
+	err = cursor.All(ctx, &duplicates)
+	if err != nil {
+		return stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseError, "cannot decode duplicate cards")
+	}
+
+	var duplicateIDs []string
+	for _, duplicate := range duplicates {
+		duplicateIDs = append(duplicateIDs, duplicate.IDs[1:]...)
+	}
+
+	if len(duplicateIDs) > 0 {
+		_, err = repository.Collection().DeleteMany(ctx, bson.M{"id": bson.M{"$in": duplicateIDs}})
+		if err != nil {
+			return stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseError, "cannot delete %d duplicate cards", len(duplicateIDs))
+		}
+	}
+
+	_, err = repository.Collection().Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "user_id", Value: mongodb.SortOrderAscending}, {Key: "card_number", Value: mongodb.SortOrderAscending}},
+		Options: options.Index().SetName("user_id_card_number_unique").SetUnique(true),
+	})
+	if err != nil {
+		return stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseError, "cannot create the cards index")
+	}
+
+	return nil
+}
+
+// EncryptPlaintextPasswords replaces the plaintext passwords of cards which were registered before passwords were encrypted
+func (repository *CardRepository) EncryptPlaintextPasswords(ctx context.Context, encrypt func(cardID id.ID, password string) (string, error)) (updated int64, err error) {
+	ctx, cancel := repository.TimeoutContext(ctx)
+	defer cancel()
+
+	cursor, err := repository.Collection().Find(ctx, bson.M{fieldPlaintextPassword: bson.M{"$exists": true}})
+	if err != nil {
+		return updated, stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseError, "cannot fetch cards with plaintext passwords")
+	}
+
+	var documents []struct {
+		ID       string `bson:"id"`
+		Password string `bson:"password"`
+	}
+	err = cursor.All(ctx, &documents)
+	if err != nil {
+		return updated, stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseError, "cannot decode cards with plaintext passwords")
+	}
+
+	for _, document := range documents {
+		cardID, err := id.FromString(document.ID)
+		if err != nil {
+			return updated, stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseHydrationError, "could not decode card id form string")
+		}
+
+		encryptedPassword, err := encrypt(cardID, document.Password)
+		if err != nil {
+			return updated, stacktrace.Propagate(err, "cannot encrypt the password of card with id %s", document.ID)
+		}
+
+		_, err = repository.Collection().UpdateOne(
+			ctx,
+			bson.M{"id": document.ID},
+			bson.M{"$set": bson.M{fieldEncryptedPassword: encryptedPassword}, "$unset": bson.M{fieldPlaintextPassword: ""}},
+		)
+		if err != nil {
+			return updated, stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseError, "cannot store the encrypted password of card with id %s", document.ID)
+		}
+
+		updated++
+	}
+
+	return updated, nil
+}
+
+// Store stores a new card. It fails with ErrCodeDuplicateEntity when the user already registered the card number.
+func (repository *CardRepository) Store(ctx context.Context, card entities.Card) error {
+	ctx, cancel := repository.TimeoutContext(ctx)
+	defer cancel()
+
+	_, err := repository.Collection().InsertOne(ctx, repository.cardToDocument(card))
+	if mongodb.IsDuplicateKeyError(err) {
+		return stacktrace.PropagateWithCode(err, errors.ErrCodeDuplicateEntity, "card %s is already registered by user with id %s", card.CardNumber, card.UserID.String())
+	}
+	if err != nil {
+		return stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseError, "cannot insert card into the database")
+	}
+
+	return nil
+}
+
+// Update replaces the stored card with the given card
+func (repository *CardRepository) Update(ctx context.Context, card entities.Card) error {
+	ctx, cancel := repository.TimeoutContext(ctx)
+	defer cancel()
+
+	_, err := repository.Collection().ReplaceOne(ctx, bson.M{"id": card.ID.String()}, repository.cardToDocument(card))
+	if err != nil {
+		return stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseError, "cannot update card with id %s", card.ID.String())
+	}
+
+	return nil
+}
+
+// FetchDue returns all the cards which should be synced at the given time
+func (repository *CardRepository) FetchDue(ctx context.Context, now time.Time) (cards []entities.Card, err error) {
+	return repository.fetch(
+		ctx,
+		bson.M{fieldNextSyncAt: bson.M{"$lte": primitive.NewDateTimeFromTime(now)}},
+		&options.FindOptions{Sort: bson.D{{Key: fieldNextSyncAt, Value: mongodb.SortOrderAscending}}},
+	)
+}
+
+// FetchForUser returns all the cards registered by a user
+func (repository *CardRepository) FetchForUser(ctx context.Context, userID id.ID) (cards []entities.Card, err error) {
+	return repository.fetch(
+		ctx,
+		bson.M{"user_id": userID.String()},
+		&options.FindOptions{Sort: bson.D{{Key: "created_at", Value: mongodb.SortOrderAscending}}},
+	)
+}
+
+// DeleteForUser deletes all the cards registered by a user together with their credentials
+func (repository *CardRepository) DeleteForUser(ctx context.Context, userID id.ID) (deleted int64, err error) {
+	ctx, cancel := repository.TimeoutContext(ctx)
+	defer cancel()
+
+	result, err := repository.Collection().DeleteMany(ctx, bson.M{"user_id": userID.String()})
+	if err != nil {
+		return deleted, stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseError, "could not delete cards of user with id %s", userID.String())
+	}
+
+	return result.DeletedCount, nil
+}
+
+func (repository *CardRepository) fetch(ctx context.Context, filter bson.M, findOptions *options.FindOptions) (cards []entities.Card, err error) {
+	ctx, cancel := repository.TimeoutContext(ctx)
+	defer cancel()
+
+	cursor, err := repository.Collection().Find(ctx, filter, findOptions)
+	if err != nil {
+		return cards, stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseError, "could not fetch cards")
+	}
+
+	var rawResults []map[string]interface{}
+	err = cursor.All(ctx, &rawResults)
+	if err != nil {
+		return cards, stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseError, "could not decode cards")
+	}
+
+	cards = make([]entities.Card, len(rawResults))
+	for index, dbRecord := range rawResults {
+		cards[index], err = repository.hydrateCardFromDBRecord(dbRecord)
+		if err != nil {
+			return cards, stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseError, "error hydrating card into model")
+		}
+	}
+
+	return cards, nil
+}
+
+func (repository *CardRepository) cardToDocument(card entities.Card) bson.M {
+	var lastSyncedAt interface{}
+	if card.LastSyncedAt != nil {
+		lastSyncedAt = primitive.NewDateTimeFromTime(*card.LastSyncedAt)
+	}
+
+	return bson.M{
+		"id":                        card.ID.String(),
+		"user_id":                   card.UserID.String(),
+		"card_number":               card.CardNumber,
+		"username":                  card.Username,
+		fieldEncryptedPassword:      card.EncryptedPassword,
+		"status":                    card.Status.String(),
+		"last_transaction_datetime": primitive.NewDateTimeFromTime(card.LastTransactionDateTime),
+		"last_synced_at":            lastSyncedAt,
+		fieldNextSyncAt:             primitive.NewDateTimeFromTime(card.NextSyncAt),
+		"authentication_failures":   card.AuthenticationFailures,
+		"last_error":                card.LastError,
+		"created_at":                primitive.NewDateTimeFromTime(card.CreatedAt),
+		"updated_at":                primitive.NewDateTimeFromTime(card.UpdatedAt),
+	}
+}
+
+func (repository *CardRepository) hydrateCardFromDBRecord(dbRecord map[string]interface{}) (card entities.Card, err error) {
+	cardID, err := id.FromString(dbRecord["id"].(string))
+	if err != nil {
+		return card, stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseHydrationError, "could not decode card id form string")
+	}
+
+	userID, err := id.FromString(dbRecord["user_id"].(string))
+	if err != nil {
+		return card, stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseHydrationError, "could not decode user id form string")
+	}
+
+	// cards with a plaintext password have an empty encrypted password until EncryptPlaintextPasswords runs
+	encryptedPassword, _ := dbRecord[fieldEncryptedPassword].(string)
+
+	var lastSyncedAt *time.Time
+	if value, ok := dbRecord["last_synced_at"].(primitive.DateTime); ok {
+		timestamp := value.Time()
+		lastSyncedAt = &timestamp
+	}
+
+	return entities.Card{
+		ID:                      cardID,
+		UserID:                  userID,
+		CardNumber:              dbRecord["card_number"].(string),
+		Username:                dbRecord["username"].(string),
+		EncryptedPassword:       encryptedPassword,
+		Status:                  entities.CardSyncStatus(dbRecord["status"].(string)),
+		LastTransactionDateTime: dbRecord["last_transaction_datetime"].(primitive.DateTime).Time(),
+		LastSyncedAt:            lastSyncedAt,
+		NextSyncAt:              dbRecord[fieldNextSyncAt].(primitive.DateTime).Time(),
+		AuthenticationFailures:  int(dbRecord["authentication_failures"].(int32)),
+		LastError:               dbRecord["last_error"].(string),
+		CreatedAt:               dbRecord["created_at"].(primitive.DateTime).Time(),
+		UpdatedAt:               dbRecord["updated_at"].(primitive.DateTime).Time(),
+	}, nil
+}
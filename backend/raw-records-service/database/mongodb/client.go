@@ -21,3 +21,8 @@ func NewMongoDB(client *mongo.Database) database.DB {
 func (db *MongoDB) RawRecordRepository() database.RawRecordRepository {
 	return NewRawRecordRepository(db.client, "raw_records")
 }
+
+// CardRepository represents the cards which are synced periodically
+func (db *MongoDB) CardRepository() database.CardRepository {
+	return NewCardRepository(db.client, "cards")
+}
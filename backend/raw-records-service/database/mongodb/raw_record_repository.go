@@ -13,11 +13,18 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"regexp"
 )
 
 const (
 	fieldAnalyzeRequestId = "analyze_request_id"
+	fieldAnalyzeRequestIds = "analyze_request_ids"
+	fieldFingerprint = "fingerprint"
+	fieldFingerprintVersion = "fingerprint_version"
 	fieldTransactionDatetime = "transaction_datetime";
+
+	// backfillBatchSize is the number of fingerprints which are stored with one bulk write
+	backfillBatchSize = 1000
 )
 
 // RawRecordRepository creates a new instance of the raw record repository
@@ -30,48 +37,319 @@ func NewRawRecordRepository(db *mongo.Database, collection string) database.RawR
 	return &RawRecordRepository{mongodb.NewRepository(db, collection)}
 }
 
-// StoreMany stores multiple raw records
-func (repository *RawRecordRepository) StoreMany(records []entities.RawRecord) error {
-	var documents []interface{}
-	for _, record := range records {
-		documents = append(documents, bson.M{
-			"id":                      record.ID.String(),
-			"user_id":                 record.UserID.String(),
-			fieldAnalyzeRequestId:     record.AnalyzeRequestID.String(),
-			"check_in_info":           record.CheckInInfo,
-			"fare":                    record.Fare,
-			"fare_calculation":        record.FareCalculation,
-			"fare_text":               record.FareText,
-			"modal_type":              record.ModalType,
-			"product_info":            record.ProductInfo,
-			"product_text":            record.ProductText,
-			"pto":                     record.Pto,
-			fieldTransactionDatetime:  primitive.NewDateTimeFromTime(record.TransactionDateTime),
-			"transaction_info":        record.TransactionInfo,
-			"transaction_name":        record.TransactionName.String(),
-			"e_purse_mut":             record.EPurseMut,
-			"e_purse_mut_info":        record.EPurseMutInfo,
-			"transaction_explanation": record.TransactionExplanation,
-			"transaction_priority":    record.TransactionPriority,
-			"source":                  record.Source,
-			"created_at":              primitive.NewDateTimeFromTime(record.CreatedAt),
-			"updated_at":              primitive.NewDateTimeFromTime(record.UpdatedAt),
-		})
-	}
-	_, err := repository.Collection().InsertMany(context.Background(), documents)
-	if err != nil {
-		return stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseError, "cannot insert raw records into the database")
+// CreateIndexes creates the unique index which prevents the same trip from being stored twice for a user
+// and the index which is used to list the records of a user page by page.
+// Records which were stored before fingerprints were added are fingerprinted and deduplicated first
+// because the unique index can't be built while the same trip is stored more than once.
+func (repository *RawRecordRepository) CreateIndexes(ctx context.Context) error {
+	// the migration scans the whole collection so it is bounded by the context of the caller instead of the default timeout
+	err := repository.backfillFingerprints(ctx)
+	if err != nil {
+		return stacktrace.Propagate(err, "cannot backfill the fingerprints of the raw records")
+	}
+
+	err = repository.deleteDuplicates(ctx)
+	if err != nil {
+		return stacktrace.Propagate(err, "cannot delete the duplicate raw records")
+	}
+
+	ctx, cancel := repository.TimeoutContext(ctx)
+	defer cancel()
+
+	_, err = repository.Collection().Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "user_id", Value: mongodb.SortOrderAscending}, {Key: fieldFingerprint, Value: mongodb.SortOrderAscending}},
+			Options: options.Index().
+				SetName("user_id_fingerprint_unique").
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{fieldFingerprint: bson.M{"$exists": true}}),
+		},
+		{
+			Keys: bson.D{
+				{Key: "user_id", Value: mongodb.SortOrderAscending},
+				{Key: fieldTransactionDatetime, Value: mongodb.SortOrderAscending},
+				{Key: "id", Value: mongodb.SortOrderAscending},
+			},
+			Options: options.Index().SetName("user_id_transaction_datetime_id"),
+		},
+	})
+	if err != nil {
+		return stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseError, "cannot create the raw records indexes")
+	}
+
+	return nil
+}
+
+// backfillFingerprints stores the fingerprint of the records which were stored before fingerprints were added or
+// whose fingerprint was computed by an older version of Fingerprint
+func (repository *RawRecordRepository) backfillFingerprints(ctx context.Context) error {
+	cursor, err := repository.Collection().Find(ctx, bson.M{fieldFingerprintVersion: bson.M{"$ne": entities.FingerprintVersion}})
+	if err != nil {
+		return stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseError, "cannot fetch raw records without a current fingerprint")
+	}
+	defer cursor.Close(ctx)
+
+	batch := make([]map[string]interface{}, 0, backfillBatchSize)
+	for cursor.Next(ctx) {
+		dbRecord := map[string]interface{}{}
+		err = cursor.Decode(&dbRecord)
+		if err != nil {
+			return stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseError, "cannot decode raw record without a current fingerprint")
+		}
+
+		batch = append(batch, dbRecord)
+		if len(batch) == backfillBatchSize {
+			err = repository.writeBackfillBatch(ctx, batch)
+			if err != nil {
+				return err
+			}
+			batch = batch[:0]
+		}
+	}
+
+	if cursor.Err() != nil {
+		return stacktrace.PropagateWithCode(cursor.Err(), errors.ErrCodeDatabaseError, "cannot iterate over raw records without a current fingerprint")
+	}
+
+	return repository.writeBackfillBatch(ctx, batch)
+}
+
+// writeBackfillBatch stores the fingerprints of a batch of records. When the unique index already exists, a record
+// whose new fingerprint belongs to another record of the user is a duplicate and it is merged into that record.
+func (repository *RawRecordRepository) writeBackfillBatch(ctx context.Context, batch []map[string]interface{}) error {
+	if len(batch) == 0 {
+		return nil
+	}
+
+	models := make([]mongo.WriteModel, len(batch))
+	fingerprints := make([]string, len(batch))
+	for index, dbRecord := range batch {
+		record, err := repository.hydrateRawRecordFromDBRecord(dbRecord)
+		if err != nil {
+			return stacktrace.Propagate(err, "cannot hydrate raw record without a current fingerprint")
+		}
+
+		fingerprints[index] = record.Fingerprint()
+		models[index] = mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": dbRecord["_id"]}).
+			SetUpdate(bson.M{"$set": bson.M{fieldFingerprint: fingerprints[index], fieldFingerprintVersion: entities.FingerprintVersion}})
+	}
+
+	_, err := repository.Collection().BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	bulkWriteException, ok := err.(mongo.BulkWriteException)
+	if err != nil && !ok {
+		return stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseError, "cannot store the fingerprints of %d raw records", len(models))
+	}
+
+	for _, writeError := range bulkWriteException.WriteErrors {
+		if !mongodb.IsDuplicateKeyError(writeError) {
+			return stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseError, "cannot store the fingerprints of %d raw records", len(models))
+		}
+
+		err = repository.mergeIntoFingerprint(ctx, batch[writeError.Index], fingerprints[writeError.Index])
+		if err != nil {
+			return err
+		}
+	}
+
+	if bulkWriteException.WriteConcernError != nil {
+		return stacktrace.PropagateWithCode(bulkWriteException, errors.ErrCodeDatabaseError, "cannot store the fingerprints of %d raw records", len(models))
+	}
+
+	return nil
+}
+
+// mergeIntoFingerprint merges a record into the record of the same user which is already stored with the fingerprint
+func (repository *RawRecordRepository) mergeIntoFingerprint(ctx context.Context, dbRecord map[string]interface{}, fingerprint string) error {
+	var kept struct {
+		ID string `bson:"id"`
+	}
+	err := repository.Collection().FindOne(ctx, bson.M{"user_id": dbRecord["user_id"], fieldFingerprint: fingerprint}).Decode(&kept)
+	if err != nil {
+		return stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseError, "cannot fetch the raw record with fingerprint %s", fingerprint)
+	}
+
+	group := duplicateGroup{IDs: []string{kept.ID, dbRecord["id"].(string)}}
+	if analyzeRequestID, ok := dbRecord[fieldAnalyzeRequestId].(string); ok {
+		group.AnalyzeRequestIDs = []string{analyzeRequestID}
+	}
+	if linkedAnalyzeRequestIDs, ok := dbRecord[fieldAnalyzeRequestIds].(primitive.A); ok {
+		linked := make([]string, 0, len(linkedAnalyzeRequestIDs))
+		for _, analyzeRequestID := range linkedAnalyzeRequestIDs {
+			if value, ok := analyzeRequestID.(string); ok {
+				linked = append(linked, value)
+			}
+		}
+		group.LinkedAnalyzeRequestIDs = [][]string{linked}
+	}
+
+	return repository.mergeDuplicateGroup(ctx, group)
+}
+
+// duplicateGroup is a trip which is stored more than once for a user. The first record is kept.
+type duplicateGroup struct {
+	IDs                     []string   `bson:"ids"`
+	AnalyzeRequestIDs       []string   `bson:"analyze_request_ids"`
+	LinkedAnalyzeRequestIDs [][]string `bson:"linked_analyze_request_ids"`
+}
+
+// analyzeRequestIDs returns the analyze requests of all the records in the group once
+func (group duplicateGroup) analyzeRequestIDs() (analyzeRequestIDs []string) {
+	all := group.AnalyzeRequestIDs
+	for _, linkedAnalyzeRequestIDs := range group.LinkedAnalyzeRequestIDs {
+		all = append(all, linkedAnalyzeRequestIDs...)
+	}
+
+	seen := make(map[string]bool, len(all))
+	for _, analyzeRequestID := range all {
+		// synced records don't belong to an analyze request
+		if analyzeRequestID == (id.ID{}).String() || seen[analyzeRequestID] {
+			continue
+		}
+		seen[analyzeRequestID] = true
+		analyzeRequestIDs = append(analyzeRequestIDs, analyzeRequestID)
+	}
+
+	return analyzeRequestIDs
+}
+
+// deleteDuplicates keeps the oldest record of every trip which is stored more than once for a user
+func (repository *RawRecordRepository) deleteDuplicates(ctx context.Context) error {
+	cursor, err := repository.Collection().Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{fieldFingerprint: bson.M{"$exists": true}}}},
+		{{Key: "$sort", Value: bson.M{"created_at": mongodb.SortOrderAscending}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":                        bson.M{"user_id": "$user_id", fieldFingerprint: "$" + fieldFingerprint},
+			"ids":                        bson.M{"$push": "$id"},
+			"analyze_request_ids":        bson.M{"$push": "$" + fieldAnalyzeRequestId},
+			"linked_analyze_request_ids": bson.M{"$push": "$" + fieldAnalyzeRequestIds},
+			"count":                      bson.M{"$sum": 1},
+		}}},
+		{{Key: "$match", Value: bson.M{"count": bson.M{"$gt": 1}}}},
+	}, options.Aggregate().SetAllowDiskUse(true))
+	if err != nil {
+		return stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseError, "cannot find duplicate raw records")
+	}
+
+	var duplicates []duplicateGroup
+	err = cursor.All(ctx, &duplicates)
+	if err != nil {
+		return stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseError, "cannot decode duplicate raw records")
+	}
+
+	for _, duplicate := range duplicates {
+		err = repository.mergeDuplicateGroup(ctx, duplicate)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// mergeDuplicateGroup links the analyze requests of the duplicates to the record which is kept and deletes the duplicates
+func (repository *RawRecordRepository) mergeDuplicateGroup(ctx context.Context, group duplicateGroup) error {
+	if analyzeRequestIDs := group.analyzeRequestIDs(); len(analyzeRequestIDs) > 0 {
+		_, err := repository.Collection().UpdateOne(
+			ctx,
+			bson.M{"id": group.IDs[0]},
+			bson.M{"$addToSet": bson.M{fieldAnalyzeRequestIds: bson.M{"$each": analyzeRequestIDs}}},
+		)
+		if err != nil {
+			return stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseError, "cannot link analyze requests to raw record with id %s", group.IDs[0])
+		}
+	}
+
+	_, err := repository.Collection().DeleteMany(ctx, bson.M{"id": bson.M{"$in": group.IDs[1:]}})
+	if err != nil {
+		return stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseError, "cannot delete %d duplicates of raw record with id %s", len(group.IDs)-1, group.IDs[0])
 	}
 
 	return nil
 }
 
+// StoreMany upserts multiple raw records using their fingerprint.
+// Records which are already stored are skipped and only linked to the new analyze request.
+func (repository *RawRecordRepository) StoreMany(ctx context.Context, records []entities.RawRecord) (result database.StoreResult, err error) {
+	if len(records) == 0 {
+		return result, nil
+	}
+
+	models := make([]mongo.WriteModel, 0, len(records))
+	fingerprints := make(map[string]bool, len(records))
+	for _, record := range records {
+		// the same trip can occur more than once in a batch e.g. when a CSV file is uploaded twice
+		fingerprint := record.Fingerprint()
+		if fingerprints[fingerprint] {
+			continue
+		}
+		fingerprints[fingerprint] = true
+
+		update := bson.M{
+			"$setOnInsert": bson.M{
+				"id":                      record.ID.String(),
+				"user_id":                 record.UserID.String(),
+				fieldAnalyzeRequestId:     record.AnalyzeRequestID.String(),
+				fieldFingerprint:          fingerprint,
+				fieldFingerprintVersion:   entities.FingerprintVersion,
+				"card_number":             record.CardNumber,
+				"check_in_info":           record.CheckInInfo,
+				"check_in_text":           record.CheckInText,
+				"fare":                    record.Fare,
+				"fare_calculation":        record.FareCalculation,
+				"fare_text":               record.FareText,
+				"modal_type":              record.ModalType,
+				"product_info":            record.ProductInfo,
+				"product_text":            record.ProductText,
+				"pto":                     record.Pto,
+				fieldTransactionDatetime:  primitive.NewDateTimeFromTime(record.TransactionDateTime),
+				"transaction_info":        record.TransactionInfo,
+				"transaction_name":        record.TransactionName.String(),
+				"e_purse_mut":             record.EPurseMut,
+				"e_purse_mut_info":        record.EPurseMutInfo,
+				"transaction_explanation": record.TransactionExplanation,
+				"transaction_priority":    record.TransactionPriority,
+				"source":                  record.Source,
+				"created_at":              primitive.NewDateTimeFromTime(record.CreatedAt),
+				"updated_at":              primitive.NewDateTimeFromTime(record.UpdatedAt),
+			},
+		}
+
+		// synced records don't belong to an analyze request
+		if record.AnalyzeRequestID != (id.ID{}) {
+			update["$addToSet"] = bson.M{fieldAnalyzeRequestIds: record.AnalyzeRequestID.String()}
+		}
+
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"user_id": record.UserID.String(), fieldFingerprint: fingerprint}).
+			SetUpdate(update).
+			SetUpsert(true),
+		)
+	}
+
+	bulkResult, err := repository.Collection().BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	if err != nil {
+		return result, stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseError, "cannot upsert raw records into the database")
+	}
+
+	result.Inserted = int(bulkResult.UpsertedCount)
+	result.Skipped = len(records) - result.Inserted
+
+	return result, nil
+}
+
 // FetchByRequestId returns all raw records with a given request id sorted in ascending order.
-func(repository *RawRecordRepository) FetchByRequestId(requestID id.ID) (records []entities.RawRecord, err error) {
+func(repository *RawRecordRepository) FetchByRequestId(ctx context.Context, requestID id.ID) (records []entities.RawRecord, err error) {
+	ctx, cancel := repository.TimeoutContext(ctx)
+	defer cancel()
+
 	cursor, err := repository.Collection().Find(
-		repository.DefaultTimeoutContext(),
-		bson.M{fieldAnalyzeRequestId: requestID.String()},
-		&options.FindOptions{Sort: bson.D{{fieldTransactionDatetime, mongodb.SortOrderAscending}}},
+		ctx,
+		bson.M{"$or": bson.A{
+			bson.M{fieldAnalyzeRequestIds: requestID.String()},
+			bson.M{fieldAnalyzeRequestId: requestID.String()},
+		}},
+		&options.FindOptions{Sort: bson.D{{Key: fieldTransactionDatetime, Value: mongodb.SortOrderAscending}}},
 	)
 
 	if err == mongo.ErrNoDocuments {
@@ -83,7 +361,7 @@ func(repository *RawRecordRepository) FetchByRequestId(requestID id.ID) (records
 	}
 
 	var rawResults []map[string]interface{}
-	err = cursor.All(repository.DefaultTimeoutContext(), &rawResults)
+	err = cursor.All(ctx, &rawResults)
 	if err != nil {
 		return records, stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseError, "could not fetch raw records by request id")
 	}
@@ -99,6 +377,128 @@ func(repository *RawRecordRepository) FetchByRequestId(requestID id.ID) (records
 	return records, nil
 }
 
+// List returns the raw records matching the filter sorted by transaction datetime and id.
+// Only records positioned after the cursor are returned so pages stay stable while new records are stored.
+func (repository *RawRecordRepository) List(ctx context.Context, filter database.RawRecordFilter, after *database.RawRecordCursor, limit int) (records []entities.RawRecord, err error) {
+	ctx, cancel := repository.TimeoutContext(ctx)
+	defer cancel()
+
+	query := repository.filterToQuery(filter)
+	if after != nil {
+		afterDateTime := primitive.NewDateTimeFromTime(after.TransactionDateTime)
+		query = bson.M{"$and": bson.A{query, bson.M{"$or": bson.A{
+			bson.M{fieldTransactionDatetime: bson.M{"$gt": afterDateTime}},
+			bson.M{fieldTransactionDatetime: afterDateTime, "id": bson.M{"$gt": after.ID.String()}},
+		}}}}
+	}
+
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: fieldTransactionDatetime, Value: mongodb.SortOrderAscending}, {Key: "id", Value: mongodb.SortOrderAscending}}).
+		SetLimit(int64(limit))
+
+	cursor, err := repository.Collection().Find(ctx, query, findOptions)
+	if err != nil {
+		return records, stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseError, "could not list raw records")
+	}
+
+	var rawResults []map[string]interface{}
+	err = cursor.All(ctx, &rawResults)
+	if err != nil {
+		return records, stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseError, "could not decode listed raw records")
+	}
+
+	records = make([]entities.RawRecord, len(rawResults))
+	for index, dbRecord := range rawResults {
+		records[index], err = repository.hydrateRawRecordFromDBRecord(dbRecord)
+		if err != nil {
+			return records, stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseError, "error hydrating raw record into model")
+		}
+	}
+
+	return records, nil
+}
+
+// Count returns the number of raw records matching the filter
+func (repository *RawRecordRepository) Count(ctx context.Context, filter database.RawRecordFilter) (count int64, err error) {
+	ctx, cancel := repository.TimeoutContext(ctx)
+	defer cancel()
+
+	count, err = repository.Collection().CountDocuments(ctx, repository.filterToQuery(filter))
+	if err != nil {
+		return count, stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseError, "could not count raw records")
+	}
+
+	return count, nil
+}
+
+// DeleteForUser deletes all the raw records of a user
+func (repository *RawRecordRepository) DeleteForUser(ctx context.Context, userID id.ID) (deleted int64, err error) {
+	ctx, cancel := repository.TimeoutContext(ctx)
+	defer cancel()
+
+	result, err := repository.Collection().DeleteMany(ctx, bson.M{"user_id": userID.String()})
+	if err != nil {
+		return deleted, stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseError, "could not delete raw records of user with id %s", userID.String())
+	}
+
+	return result.DeletedCount, nil
+}
+
+func (repository *RawRecordRepository) filterToQuery(filter database.RawRecordFilter) bson.M {
+	query := bson.M{"user_id": filter.UserID.String()}
+
+	if filter.AnalyzeRequestID != nil {
+		query["$or"] = bson.A{
+			bson.M{fieldAnalyzeRequestIds: filter.AnalyzeRequestID.String()},
+			bson.M{fieldAnalyzeRequestId: filter.AnalyzeRequestID.String()},
+		}
+	}
+
+	if filter.CardNumber != nil {
+		query["card_number"] = *filter.CardNumber
+	}
+
+	dateTimeQuery := bson.M{}
+	if filter.StartDateTime != nil {
+		dateTimeQuery["$gte"] = primitive.NewDateTimeFromTime(*filter.StartDateTime)
+	}
+	if filter.EndDateTime != nil {
+		dateTimeQuery["$lt"] = primitive.NewDateTimeFromTime(*filter.EndDateTime)
+	}
+	if len(dateTimeQuery) > 0 {
+		query[fieldTransactionDatetime] = dateTimeQuery
+	}
+
+	if len(filter.Ptos) > 0 {
+		query["pto"] = bson.M{"$in": filter.Ptos}
+	}
+
+	if len(filter.ModalTypes) > 0 {
+		query["modal_type"] = bson.M{"$in": filter.ModalTypes}
+	}
+
+	// transaction names are compared case-insensitively like TransactionName.IsTheSameAs
+	if len(filter.TransactionNames) > 0 {
+		query["transaction_name"] = repository.caseInsensitiveIn(filter.TransactionNames)
+	}
+
+	if len(filter.Stations) > 0 {
+		query["transaction_info"] = bson.M{"$in": filter.Stations}
+	}
+
+	return query
+}
+
+// caseInsensitiveIn matches a field which is equal to one of the values when their case is ignored
+func (repository *RawRecordRepository) caseInsensitiveIn(values []string) bson.M {
+	patterns := make(bson.A, len(values))
+	for index, value := range values {
+		patterns[index] = primitive.Regex{Pattern: "^" + regexp.QuoteMeta(value) + "$", Options: "i"}
+	}
+
+	return bson.M{"$in": patterns}
+}
+
 func (repository *RawRecordRepository) hydrateRawRecordFromDBRecord(dbRecord map[string]interface{}) (rawRecord entities.RawRecord, err error) {
 	userID, err := id.FromString(dbRecord["user_id"].(string))
 	if err != nil {
@@ -120,13 +520,18 @@ func (repository *RawRecordRepository) hydrateRawRecordFromDBRecord(dbRecord map
 		return rawRecord, stacktrace.PropagateWithCode(err, errors.ErrCodeDatabaseHydrationError, "could not create raw record source")
 	}
 
+	// records stored before cards were tracked don't have a card number or check in text
+	cardNumber, _ := dbRecord["card_number"].(string)
+	checkInText, _ := dbRecord["check_in_text"].(string)
+
 	return entities.RawRecord{
 		ID:                     recordID,
 		UserID:                 userID,
 		AnalyzeRequestID:       analyzeRequestID,
+		CardNumber:             cardNumber,
 		CheckInInfo:            dbRecord["check_in_info"].(string),
-		CheckInText:            dbRecord["check_in_info"].(string),
-		Fare:                   dbRecord["fare"].(*float64),
+		CheckInText:            checkInText,
+		Fare:                   repository.optionalFloat(dbRecord["fare"]),
 		FareCalculation:        dbRecord["fare_calculation"].(string),
 		FareText:               dbRecord["fare_text"].(string),
 		ModalType:              dbRecord["modal_type"].(string),
@@ -136,7 +541,7 @@ func (repository *RawRecordRepository) hydrateRawRecordFromDBRecord(dbRecord map
 		TransactionDateTime:    dbRecord["transaction_datetime"].(primitive.DateTime).Time(),
 		TransactionInfo:        dbRecord["transaction_info"].(string),
 		TransactionName:        types.TransactionName(dbRecord["transaction_name"].(string)),
-		EPurseMut:              dbRecord["e_purse_mut"].(*float64),
+		EPurseMut:              repository.optionalFloat(dbRecord["e_purse_mut"]),
 		EPurseMutInfo:          dbRecord["e_purse_mut_info"].(string),
 		TransactionExplanation: dbRecord["transaction_explanation"].(string),
 		TransactionPriority:    dbRecord["transaction_priority"].(string),
@@ -145,3 +550,13 @@ func (repository *RawRecordRepository) hydrateRawRecordFromDBRecord(dbRecord map
 		UpdatedAt:              dbRecord["updated_at"].(primitive.DateTime).Time(),
 	}, nil
 }
+
+// optionalFloat decodes a nullable double which was stored from a *float64
+func (repository *RawRecordRepository) optionalFloat(value interface{}) *float64 {
+	result, ok := value.(float64)
+	if !ok {
+		return nil
+	}
+
+	return &result
+}
@@ -0,0 +1,100 @@
+package mongodb
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/raw-records-service/database"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/id"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/types"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestDuplicateGroupAnalyzeRequestIDs(t *testing.T) {
+	synced := (id.ID{}).String()
+
+	tests := []struct {
+		name     string
+		group    duplicateGroup
+		expected []string
+	}{
+		{
+			name:     "records which were synced from a card",
+			group:    duplicateGroup{IDs: []string{"1", "2"}, AnalyzeRequestIDs: []string{synced, synced}},
+			expected: nil,
+		},
+		{
+			name: "the same trip uploaded with two analyze requests",
+			group: duplicateGroup{
+				IDs:               []string{"1", "2"},
+				AnalyzeRequestIDs: []string{"a", "b"},
+			},
+			expected: []string{"a", "b"},
+		},
+		{
+			name: "a synced record and an uploaded record",
+			group: duplicateGroup{
+				IDs:               []string{"1", "2"},
+				AnalyzeRequestIDs: []string{synced, "a"},
+			},
+			expected: []string{"a"},
+		},
+		{
+			name: "records which were already linked to analyze requests",
+			group: duplicateGroup{
+				IDs:                     []string{"1", "2", "3"},
+				AnalyzeRequestIDs:       []string{"a", "b", synced},
+				LinkedAnalyzeRequestIDs: [][]string{{"a", "c"}, {"b"}, {"c", "d"}},
+			},
+			expected: []string{"a", "b", "c", "d"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if result := test.group.analyzeRequestIDs(); !reflect.DeepEqual(result, test.expected) {
+				t.Errorf("analyzeRequestIDs() = %q, expected %q", result, test.expected)
+			}
+		})
+	}
+}
+
+func TestRawRecordRepositoryFilterToQueryTransactionNames(t *testing.T) {
+	tests := []struct {
+		name       string
+		filter     string
+		storedName string
+		expected   bool
+	}{
+		{name: "the same name", filter: types.TransactionNameCheckIn.String(), storedName: "Check-in", expected: true},
+		{name: "a lower case filter", filter: "check-in", storedName: "Check-in", expected: true},
+		{name: "an upper case record", filter: types.TransactionNameCheckOut.String(), storedName: "CHECK-UIT", expected: true},
+		{name: "another name", filter: types.TransactionNameCheckIn.String(), storedName: "Check-uit", expected: false},
+		{name: "a name which starts with the filter", filter: "check", storedName: "Check-in", expected: false},
+		{name: "a filter with regular expression characters", filter: "check.in", storedName: "Check-in", expected: false},
+	}
+
+	repository := &RawRecordRepository{}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			query := repository.filterToQuery(database.RawRecordFilter{UserID: id.New(), TransactionNames: []string{test.filter}})
+
+			patterns := query["transaction_name"].(bson.M)["$in"].(bson.A)
+			if len(patterns) != 1 {
+				t.Fatalf("the query has %d patterns, expected 1", len(patterns))
+			}
+
+			// mongoDB applies the "i" option like the (?i) flag of Go regular expressions
+			pattern := patterns[0].(primitive.Regex)
+			if pattern.Options != "i" {
+				t.Fatalf("the pattern has the options %q, expected it to ignore the case", pattern.Options)
+			}
+
+			if result := regexp.MustCompile("(?i)" + pattern.Pattern).MatchString(test.storedName); result != test.expected {
+				t.Errorf("the filter %q matches %q: %t, expected %t", test.filter, test.storedName, result, test.expected)
+			}
+		})
+	}
+}
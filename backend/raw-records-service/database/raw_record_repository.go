@@ -1,12 +1,49 @@
 package database
 
 import (
+	"context"
+	"time"
+
 	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/raw-records-service/entities"
 	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/id"
 )
 
+// StoreResult is the outcome of storing raw records
+type StoreResult struct {
+	// Inserted is the number of records which were not stored before
+	Inserted int
+	// Skipped is the number of records which were already stored
+	Skipped int
+}
+
+// RawRecordFilter restricts the raw records which are listed. Empty fields are ignored.
+type RawRecordFilter struct {
+	UserID           id.ID
+	AnalyzeRequestID *id.ID
+	CardNumber       *string
+	// StartDateTime is inclusive
+	StartDateTime *time.Time
+	// EndDateTime is exclusive
+	EndDateTime      *time.Time
+	Ptos             []string
+	ModalTypes       []string
+	TransactionNames []string
+	// Stations are matched against the transaction info of a record
+	Stations []string
+}
+
+// RawRecordCursor is the position of a raw record in a list sorted by transaction datetime and id
+type RawRecordCursor struct {
+	TransactionDateTime time.Time
+	ID                  id.ID
+}
+
 // RawRecordRepository is an instance of the user repository
 type RawRecordRepository interface {
-	StoreMany(records []entities.RawRecord) error
-	FetchByRequestId(requestID id.ID) (records []entities.RawRecord, err error)
+	CreateIndexes(ctx context.Context) error
+	StoreMany(ctx context.Context, records []entities.RawRecord) (result StoreResult, err error)
+	FetchByRequestId(ctx context.Context, requestID id.ID) (records []entities.RawRecord, err error)
+	List(ctx context.Context, filter RawRecordFilter, after *RawRecordCursor, limit int) (records []entities.RawRecord, err error)
+	Count(ctx context.Context, filter RawRecordFilter) (count int64, err error)
+	DeleteForUser(ctx context.Context, userID id.ID) (deleted int64, err error)
 }
@@ -0,0 +1,47 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/id"
+)
+
+// CardSyncStatus is the status of the last synchronisation of a card
+type CardSyncStatus string
+
+// String converts the sync status to a string
+func (status CardSyncStatus) String() string {
+	return string(status)
+}
+
+const (
+	// CardSyncStatusPending is the status of a card which has not been synced yet
+	CardSyncStatusPending = CardSyncStatus("pending")
+
+	// CardSyncStatusOk is the status of a card whose last sync was successful
+	CardSyncStatusOk = CardSyncStatus("ok")
+
+	// CardSyncStatusAuthenticationFailed is the status of a card whose credentials were rejected by the ov-chipkaart API
+	CardSyncStatusAuthenticationFailed = CardSyncStatus("authentication-failed")
+
+	// CardSyncStatusFailed is the status of a card whose last sync failed for any other reason
+	CardSyncStatusFailed = CardSyncStatus("failed")
+)
+
+// Card is an ov-chipkaart which is registered for periodic transaction syncs.
+// The password is encrypted at rest and only decrypted when the card is synced.
+type Card struct {
+	ID                      id.ID
+	UserID                  id.ID
+	CardNumber              string
+	Username                string
+	EncryptedPassword       string
+	Status                  CardSyncStatus
+	LastTransactionDateTime time.Time
+	LastSyncedAt            *time.Time
+	NextSyncAt              time.Time
+	AuthenticationFailures  int
+	LastError               string
+	CreatedAt               time.Time
+	UpdatedAt               time.Time
+}
@@ -1,9 +1,14 @@
 package entities
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/id"
 	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/types"
-	"time"
 )
 
 // RawRecord represents a transaction record
@@ -11,6 +16,7 @@ type RawRecord struct {
 	ID                     id.ID
 	UserID                 id.ID
 	AnalyzeRequestID       id.ID
+	CardNumber             string
 	CheckInInfo            string
 	CheckInText            string
 	Fare                   *float64
@@ -32,6 +38,28 @@ type RawRecord struct {
 	UpdatedAt              time.Time
 }
 
+// FingerprintVersion must be increased whenever Fingerprint changes so that the stored fingerprints are recomputed
+const FingerprintVersion = 2
+
+// Fingerprint is a stable hash which identifies the same trip across analyze requests and syncs.
+// It is derived from the datetime, the transaction name, the station and the fare. The card number is left out
+// because records which were uploaded before cards were tracked don't have one and fingerprints are unique per user.
+func (record RawRecord) Fingerprint() string {
+	fare := ""
+	if record.Fare != nil {
+		fare = strconv.FormatFloat(*record.Fare, 'f', 2, 64)
+	}
+
+	hash := sha256.Sum256([]byte(strings.Join([]string{
+		strconv.FormatInt(record.TransactionDateTime.UTC().UnixNano()/int64(time.Millisecond), 10),
+		strings.ToLower(record.TransactionName.String()),
+		record.TransactionInfo,
+		fare,
+	}, "|")))
+
+	return hex.EncodeToString(hash[:])
+}
+
 // IsCheckIn determines if a record is a check in record
 func (record RawRecord) IsCheckIn() bool {
 	return record.TransactionName.IsTheSameAs(types.TransactionNameCheckIn)
@@ -0,0 +1,106 @@
+package entities
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/id"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/types"
+)
+
+func TestRawRecordFingerprint(t *testing.T) {
+	fare := 2.5
+	otherFare := 3.1
+	transactionDateTime := time.Date(2020, 10, 1, 8, 15, 0, 0, time.UTC)
+
+	// uploaded is a record which was uploaded before cards were tracked
+	uploaded := RawRecord{
+		ID:                  id.New(),
+		UserID:              id.New(),
+		AnalyzeRequestID:    id.New(),
+		Fare:                &fare,
+		TransactionDateTime: transactionDateTime,
+		TransactionInfo:     "Amsterdam Centraal",
+		TransactionName:     types.TransactionNameCheckOut,
+		Source:              types.RawRecordSourceCSV,
+	}
+
+	tests := []struct {
+		name     string
+		change   func(record RawRecord) RawRecord
+		expected bool
+	}{
+		{
+			name: "the same trip which was synced from a card",
+			change: func(record RawRecord) RawRecord {
+				record.ID, record.AnalyzeRequestID, record.CardNumber, record.Source = id.New(), id.ID{}, "3528000000000000", types.RawRecordSourceAPI
+				return record
+			},
+			expected: true,
+		},
+		{
+			name: "the same trip in another time zone",
+			change: func(record RawRecord) RawRecord {
+				record.TransactionDateTime = record.TransactionDateTime.In(time.FixedZone("CEST", 2*60*60))
+				return record
+			},
+			expected: true,
+		},
+		{
+			name: "the same trip with a differently cased transaction name",
+			change: func(record RawRecord) RawRecord {
+				record.TransactionName = types.TransactionName("CHECK-UIT")
+				return record
+			},
+			expected: true,
+		},
+		{
+			name: "another datetime",
+			change: func(record RawRecord) RawRecord {
+				record.TransactionDateTime = record.TransactionDateTime.Add(time.Minute)
+				return record
+			},
+			expected: false,
+		},
+		{
+			name: "another station",
+			change: func(record RawRecord) RawRecord {
+				record.TransactionInfo = "Utrecht Centraal"
+				return record
+			},
+			expected: false,
+		},
+		{
+			name: "another fare",
+			change: func(record RawRecord) RawRecord {
+				record.Fare = &otherFare
+				return record
+			},
+			expected: false,
+		},
+		{
+			name: "without a fare",
+			change: func(record RawRecord) RawRecord {
+				record.Fare = nil
+				return record
+			},
+			expected: false,
+		},
+		{
+			name: "another transaction",
+			change: func(record RawRecord) RawRecord {
+				record.TransactionName = types.TransactionNameCheckIn
+				return record
+			},
+			expected: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if result := uploaded.Fingerprint() == test.change(uploaded).Fingerprint(); result != test.expected {
+				t.Errorf("the fingerprints are the same: %t, expected %t", result, test.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/id"
+	raw_records_service "github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/proto/raw-records-service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DeleteUserData deletes the cards and raw records of a user when their account is deleted.
+// Cards are deleted first so the scheduler cannot sync new records for the user in between.
+func (s *Server) DeleteUserData(ctx context.Context, request *raw_records_service.DeleteUserDataRequest) (*raw_records_service.DeleteUserDataResponse, error) {
+	userID, err := id.FromString(request.GetUserId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	deletedCards, err := s.DB.CardRepository().DeleteForUser(ctx, userID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	deletedRawRecords, err := s.DB.RawRecordRepository().DeleteForUser(ctx, userID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	s.Logger.Info(ctx, "deleted user data", "cards", deletedCards, "raw_records", deletedRawRecords)
+
+	return &raw_records_service.DeleteUserDataResponse{
+		DeletedRawRecords: deletedRawRecords,
+		DeletedCards:      deletedCards,
+	}, nil
+}
@@ -5,17 +5,18 @@ import (
 	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/errors"
 	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/id"
 	raw_records_service "github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/proto/raw-records-service"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/serviceauth"
 	"google.golang.org/grpc/status"
 	"google.golang.org/grpc/codes"
 )
 
-func (s *Server) FetchByRequestId(_ context.Context, request *raw_records_service.FetchByRequestIdRequest) (response *raw_records_service.FetchByRequestIdResponse, err error) {
+func (s *Server) FetchByRequestId(ctx context.Context, request *raw_records_service.FetchByRequestIdRequest) (response *raw_records_service.FetchByRequestIdResponse, err error) {
 	requestID, err := id.FromString(request.GetRequestID())
 	if err != nil {
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
-	records, err := s.DB.RawRecordRepository().FetchByRequestId(requestID)
+	records, err := s.DB.RawRecordRepository().FetchByRequestId(ctx, requestID)
 	if err != nil && err == errors.ErrEntityNotFound {
 		return nil, status.Error(codes.NotFound, err.Error())
 	}
@@ -23,6 +24,14 @@ func (s *Server) FetchByRequestId(_ context.Context, request *raw_records_servic
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
+	// the request doesn't contain a user id so the owner of the records is checked instead
+	for _, record := range records {
+		err = serviceauth.AuthorizeUser(ctx, record.UserID.String())
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	response, err = s.Transformers.RawRecordsToRawRecordsResponse(records)
 	if err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/id"
+	raw_records_service "github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/proto/raw-records-service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *Server) FetchCardSyncStates(ctx context.Context, request *raw_records_service.FetchCardSyncStatesRequest) (*raw_records_service.FetchCardSyncStatesResponse, error) {
+	userID, err := id.FromString(request.GetUserId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	cards, err := s.DB.CardRepository().FetchForUser(ctx, userID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	response, err := s.Transformers.CardsToCardSyncStatesResponse(cards)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return response, nil
+}
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/raw-records-service/database"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/id"
+	raw_records_service_v2 "github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/proto/raw-records-service/v2"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultPageSize = 100
+	maxPageSize     = 1000
+	// streamChunkSize is the maximum number of records sent in a single message of the stream
+	streamChunkSize = 100
+)
+
+// ListRawRecords streams a page of the raw records of a user which match the request filters
+func (s *ServerV2) ListRawRecords(request *raw_records_service_v2.ListRawRecordsRequest, stream raw_records_service_v2.RawRecordsService_ListRawRecordsServer) error {
+	ctx := stream.Context()
+
+	filter, err := s.requestToFilter(request)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	err = s.Transformers.ValidateFieldMask(&raw_records_service_v2.RawRecord{}, request.GetFieldMask())
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	var after *database.RawRecordCursor
+	if request.GetPageToken() != "" {
+		after, err = decodePageToken(request.GetPageToken())
+		if err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+
+	pageSize := int(request.GetPageSize())
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	}
+	if pageSize > maxPageSize {
+		pageSize = maxPageSize
+	}
+
+	// an extra record is fetched to know if there is a next page
+	records, err := s.DB.RawRecordRepository().List(ctx, filter, after, pageSize+1)
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	hasNextPage := len(records) > pageSize
+	if hasNextPage {
+		records = records[:pageSize]
+	}
+
+	var totalCount int64
+	if request.GetIncludeTotalCount() {
+		totalCount, err = s.DB.RawRecordRepository().Count(ctx, filter)
+		if err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+	}
+
+	response := &raw_records_service_v2.ListRawRecordsResponse{}
+	for index, record := range records {
+		rawRecord, err := s.Transformers.RawRecordToRawRecordV2(record)
+		if err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+		s.Transformers.ApplyFieldMask(rawRecord, request.GetFieldMask())
+
+		response.RawRecords = append(response.RawRecords, rawRecord)
+		response.PageTokens = append(response.PageTokens, encodePageToken(database.RawRecordCursor{TransactionDateTime: record.TransactionDateTime, ID: record.ID}))
+		if len(response.RawRecords) == streamChunkSize && index < len(records)-1 {
+			err = stream.Send(response)
+			if err != nil {
+				return err
+			}
+			response = &raw_records_service_v2.ListRawRecordsResponse{}
+		}
+	}
+
+	if hasNextPage {
+		response.NextPageToken = response.PageTokens[len(response.PageTokens)-1]
+	}
+	response.TotalCount = totalCount
+
+	return stream.Send(response)
+}
+
+func (s *ServerV2) requestToFilter(request *raw_records_service_v2.ListRawRecordsRequest) (filter database.RawRecordFilter, err error) {
+	filter.UserID, err = id.FromString(request.GetUserId())
+	if err != nil {
+		return filter, err
+	}
+
+	if request.GetAnalyzeRequestId() != "" {
+		analyzeRequestID, err := id.FromString(request.GetAnalyzeRequestId())
+		if err != nil {
+			return filter, err
+		}
+		filter.AnalyzeRequestID = &analyzeRequestID
+	}
+
+	if request.GetCardNumber() != "" {
+		cardNumber := request.GetCardNumber()
+		filter.CardNumber = &cardNumber
+	}
+
+	filter.StartDateTime, err = s.optionalTime(request.GetStartDateTime())
+	if err != nil {
+		return filter, err
+	}
+
+	filter.EndDateTime, err = s.optionalTime(request.GetEndDateTime())
+	if err != nil {
+		return filter, err
+	}
+
+	filter.Ptos = request.GetPtos()
+	filter.ModalTypes = request.GetModalTypes()
+	filter.TransactionNames = request.GetTransactionNames()
+	filter.Stations = request.GetStations()
+
+	return filter, nil
+}
+
+func (s *ServerV2) optionalTime(value *timestamp.Timestamp) (*time.Time, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	result, err := ptypes.Timestamp(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/raw-records-service/database"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/id"
+	"github.com/palantir/stacktrace"
+)
+
+const pageTokenSeparator = "|"
+
+// encodePageToken encodes the position of the last record of a page into an opaque token
+func encodePageToken(cursor database.RawRecordCursor) string {
+	value := strconv.FormatInt(cursor.TransactionDateTime.UnixNano()/int64(time.Millisecond), 10) + pageTokenSeparator + cursor.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(value))
+}
+
+// decodePageToken decodes a token created by encodePageToken
+func decodePageToken(token string) (*database.RawRecordCursor, error) {
+	value, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "page token is not base64 encoded")
+	}
+
+	parts := strings.Split(string(value), pageTokenSeparator)
+	if len(parts) != 2 {
+		return nil, stacktrace.NewError("page token is malformed")
+	}
+
+	milliseconds, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "page token has an invalid timestamp")
+	}
+
+	recordID, err := id.FromString(parts[1])
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "page token has an invalid id")
+	}
+
+	return &database.RawRecordCursor{
+		TransactionDateTime: time.Unix(0, milliseconds*int64(time.Millisecond)).UTC(),
+		ID:                  recordID,
+	}, nil
+}
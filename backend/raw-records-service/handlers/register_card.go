@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/raw-records-service/entities"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/errors"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/id"
+	raw_records_service "github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/proto/raw-records-service"
+	"github.com/palantir/stacktrace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// defaultSyncWindowMonths is how far back the first sync goes when no start date is given
+const defaultSyncWindowMonths = 6
+
+func (s *Server) RegisterCard(ctx context.Context, request *raw_records_service.RegisterCardRequest) (*raw_records_service.CardSyncState, error) {
+	userID, err := id.FromString(request.GetUserId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if request.GetCardNumber() == "" || request.GetUsername() == "" || request.GetPassword() == "" {
+		return nil, status.Error(codes.InvalidArgument, "the card number, username and password are required")
+	}
+
+	now := time.Now().UTC()
+
+	syncFrom := now.AddDate(0, -defaultSyncWindowMonths, 0)
+	if request.GetSyncFrom() != nil {
+		syncFrom = request.GetSyncFrom().AsTime()
+	}
+
+	cardID := id.New()
+
+	// the id of the card is authenticated with the password so that it cannot be copied to another card
+	encryptedPassword, err := s.Keyring.Encrypt(request.GetPassword(), cardID.String())
+	if err != nil {
+		return nil, status.Error(codes.Internal, "cannot encrypt the password of the card")
+	}
+
+	card := entities.Card{
+		ID:                      cardID,
+		UserID:                  userID,
+		CardNumber:              request.GetCardNumber(),
+		Username:                request.GetUsername(),
+		EncryptedPassword:       encryptedPassword,
+		Status:                  entities.CardSyncStatusPending,
+		LastTransactionDateTime: syncFrom,
+		NextSyncAt:              now,
+		CreatedAt:               now,
+		UpdatedAt:               now,
+	}
+
+	err = s.DB.CardRepository().Store(ctx, card)
+	if stacktrace.GetCode(err) == errors.ErrCodeDuplicateEntity {
+		return nil, status.Error(codes.AlreadyExists, "the card is already registered")
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	response, err := s.Transformers.CardToCardSyncState(card)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return response, nil
+}
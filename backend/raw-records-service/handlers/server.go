@@ -3,6 +3,7 @@ package handlers
 import (
 	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/raw-records-service/database"
 	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/raw-records-service/transformers"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/encryption"
 	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/logger"
 	raw_records_service "github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/proto/raw-records-service"
 )
@@ -12,4 +13,5 @@ type Server struct {
 	DB database.DB
 	Logger logger.Logger
 	Transformers transformers.Transformers
+	Keyring *encryption.Keyring
 }
\ No newline at end of file
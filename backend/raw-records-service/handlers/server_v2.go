@@ -0,0 +1,16 @@
+package handlers
+
+import (
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/raw-records-service/database"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/raw-records-service/transformers"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/logger"
+	raw_records_service_v2 "github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/proto/raw-records-service/v2"
+)
+
+// ServerV2 implements the v2 raw records service
+type ServerV2 struct {
+	raw_records_service_v2.UnimplementedRawRecordsServiceServer
+	DB           database.DB
+	Logger       logger.Logger
+	Transformers transformers.Transformers
+}
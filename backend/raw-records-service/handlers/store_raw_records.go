@@ -5,28 +5,35 @@ import (
 	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/id"
 	raw_records_service "github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/proto/raw-records-service"
 	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/types"
-	"github.com/golang/protobuf/ptypes/empty"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
-func (s *Server) StoreTransactions(_ context.Context, request *raw_records_service.StoreTransactionsRequest) (*empty.Empty, error) {
+func (s *Server) StoreTransactions(ctx context.Context, request *raw_records_service.StoreTransactionsRequest) (*raw_records_service.StoreTransactionsResponse, error) {
 	analyzeRequestId, err := id.FromString(request.AnalyzeRequestId)
 	if err != nil {
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
+	userID, err := id.FromString(request.UserId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
 	source, err := types.RawRecordSourceFromString(request.Source)
 	if err != nil {
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
-	rawRecords := s.Transformers.TransactionsToRawRecords(request.Transactions, analyzeRequestId, source)
+	rawRecords := s.Transformers.TransactionsToRawRecords(request.Transactions, analyzeRequestId, userID, request.CardNumber, source)
 
-	err = s.DB.RawRecordRepository().StoreMany(rawRecords)
+	result, err := s.DB.RawRecordRepository().StoreMany(ctx, rawRecords)
 	if err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
-	return nil, nil
+	return &raw_records_service.StoreTransactionsResponse{
+		Inserted: int64(result.Inserted),
+		Skipped:  int64(result.Skipped),
+	}, nil
 }
\ No newline at end of file
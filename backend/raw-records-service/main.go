@@ -5,59 +5,253 @@ import (
 	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/raw-records-service/database"
 	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/raw-records-service/database/mongodb"
 	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/raw-records-service/handlers"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/raw-records-service/scheduler"
 	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/raw-records-service/transformers"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/config"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/encryption"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/httpclient"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/id"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/lifecycle"
 	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/logger"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/metrics"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/ovchipkaart"
 	raw_records_service "github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/proto/raw-records-service"
+	raw_records_service_v2 "github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/proto/raw-records-service/v2"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/serviceauth"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/tracing"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
 	"log"
 	"net"
+	"net/http"
 	"os"
+	"time"
 
-	"github.com/joho/godotenv"
 	"github.com/palantir/stacktrace"
 	"google.golang.org/grpc"
 )
 
+var configuration *config.RawRecordsService
+
+var keyring *encryption.Keyring
+
 func main() {
-	err := godotenv.Load()
+	initializeLogger().Info(context.Background(), "server running on port "+initializeConfig().Server.Address)
+
+	listener, err := net.Listen("tcp", initializeConfig().Server.Address)
 	if err != nil {
-		log.Fatal("error loading .env file")
+		log.Fatalln(err)
+	}
+
+	shutdownTracing := initializeTracing()
+
+	serverOptions := append(metrics.GRPCServerOptions(), tracing.GRPCServerOptions()...)
+	srv := grpc.NewServer(append(serverOptions, initializeServiceAuthOptions()...)...)
+
+	mongoClient := initializeMongoClient()
+	db := initializeDB(mongoClient)
+
+	raw_records_service.RegisterRawRecordsServiceServer(srv, initializeServer(db))
+	raw_records_service_v2.RegisterRawRecordsServiceServer(srv, initializeServerV2(db))
+
+	healthServer := lifecycle.RegisterHealthServer(srv)
+	metrics.InitializeGRPCServer(srv)
+
+	if address := initializeConfig().Server.MetricsAddress; address != "" {
+		go func() {
+			log.Fatalln(metrics.ListenAndServe(address))
+		}()
 	}
 
-	log.Println("server running on port " + os.Getenv("SERVER_ADDRESS"))
+	ctx, cancel := context.WithCancel(context.Background())
+	schedulerStopped := lifecycle.Go(ctx, initializeCardSyncScheduler(db).Run)
+	go lifecycle.WatchHealth(ctx, srv, healthServer, initializeConfig().HealthCheckInterval, func(ctx context.Context) error {
+		return mongoClient.Ping(ctx, readpref.Primary())
+	})
+
+	go func() {
+		initializeLogger().Info(context.Background(), "shutting down", "signal", lifecycle.WaitForSignal().String())
 
-	listener, err := net.Listen("tcp", os.Getenv("SERVER_ADDRESS"))
+		// the scheduler doesn't start another round of syncs
+		cancel()
+		lifecycle.GracefulStop(srv, healthServer, initializeConfig().Server.ShutdownTimeout)
+	}()
+
+	err = srv.Serve(listener)
 	if err != nil {
 		log.Fatalln(err)
 	}
 
-	srv := grpc.NewServer()
+	// the sync which is in progress stores its result before the database is disconnected
+	if !lifecycle.Wait(schedulerStopped, initializeConfig().Server.ShutdownTimeout) {
+		initializeLogger().Warn(context.Background(), "the card sync scheduler did not stop within the shutdown timeout")
+	}
 
-	raw_records_service.RegisterRawRecordsServiceServer(srv, initializeServer())
+	err = mongoClient.Disconnect(context.Background())
+	if err != nil {
+		initializeLogger().Error(context.Background(), "cannot disconnect from mongoDB", "err", err)
+	}
 
-	log.Fatalln(srv.Serve(listener))
+	shutdownTracing()
 }
 
-func initializeServer() *handlers.Server {
+func initializeServer(db database.DB) *handlers.Server {
 	return &handlers.Server{
-		DB: initializeDB(),
+		DB: db,
 		Logger: initializeLogger(),
 		Transformers: transformers.Transformers{},
+		Keyring: initializeKeyring(),
+	}
+}
+
+func initializeServerV2(db database.DB) *handlers.ServerV2 {
+	return &handlers.ServerV2{
+		DB:           db,
+		Logger:       initializeLogger(),
+		Transformers: transformers.Transformers{},
+	}
+}
+
+// initializeServiceAuthOptions only lets the api-service call the RPCs and only for the user it authenticated
+func initializeServiceAuthOptions() []grpc.ServerOption {
+	serverOptions, err := serviceauth.GRPCServerOptions(initializeServiceAuthConfig(), serviceauth.Policy{
+		"/transactions.RawRecordsService/FetchByRequestId":    {serviceauth.ServiceAPI},
+		"/transactions.RawRecordsService/StoreTransactions":   {serviceauth.ServiceAPI},
+		"/transactions.RawRecordsService/RegisterCard":        {serviceauth.ServiceAPI},
+		"/transactions.RawRecordsService/FetchCardSyncStates": {serviceauth.ServiceAPI},
+		"/transactions.RawRecordsService/DeleteUserData":      {serviceauth.ServiceAPI},
+		"/transactions.v2.RawRecordsService/ListRawRecords":   {serviceauth.ServiceAPI},
+	})
+	if err != nil {
+		log.Fatal(stacktrace.Propagate(err, "cannot initialize the service authentication"))
+	}
+
+	return serverOptions
+}
+
+func initializeServiceAuthConfig() serviceauth.Config {
+	return serviceauth.Config{
+		ServiceName: serviceauth.ServiceRawRecords,
+		Insecure:    initializeConfig().ServiceAuth.Insecure,
+		CertFile:    initializeConfig().ServiceAuth.CertFile,
+		KeyFile:     initializeConfig().ServiceAuth.KeyFile,
+		CAFile:      initializeConfig().ServiceAuth.CAFile,
+		TokenSecret: initializeConfig().ServiceAuth.TokenSecret,
+	}
+}
+
+func initializeCardSyncScheduler(db database.DB) *scheduler.CardSyncScheduler {
+	return scheduler.NewCardSyncScheduler(
+		db,
+		initializeOvChipkaartAPIClient(),
+		transformers.Transformers{},
+		initializeKeyring(),
+		initializeLogger(),
+		scheduler.Options{
+			PollInterval:             initializeConfig().CardSync.PollInterval,
+			SyncInterval:             initializeConfig().CardSync.SyncInterval,
+			AuthenticationBackoff:    initializeConfig().CardSync.AuthenticationBackoff,
+			MaxAuthenticationBackoff: initializeConfig().CardSync.MaxAuthenticationBackoff,
+		},
+	)
+}
+
+// initializeKeyring creates the keyring which encrypts the ov-chipkaart passwords of the cards
+func initializeKeyring() *encryption.Keyring {
+	if keyring == nil {
+		var err error
+		keyring, err = initializeConfig().CardEncryption.Keyring()
+		if err != nil {
+			log.Fatal(stacktrace.Propagate(err, "cannot initialize the card encryption keyring"))
+		}
 	}
+
+	return keyring
+}
+
+func initializeOvChipkaartAPIClient() ovchipkaart.APIClient {
+	return ovchipkaart.NewAPIService(ovchipkaart.APIServiceConfig{
+		ClientID:     initializeConfig().OvChipkaartAPI.ClientID,
+		ClientSecret: initializeConfig().OvChipkaartAPI.ClientSecret,
+		Locale:       "en",
+		Client:       httpclient.New(metrics.NewInstrumentedHTTPClient("ov-chipkaart", tracing.NewHTTPClient(&http.Client{})), ovchipkaart.HTTPClientOptions()),
+	})
 }
 
-func initializeDB() database.DB {
-	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI(os.Getenv("MONGODB_URI")))
+func initializeMongoClient() *mongo.Client {
+	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI(initializeConfig().MongoDB.URI).SetMonitor(tracing.NewMongoMonitor()))
 	if err != nil {
 		log.Fatal(stacktrace.Propagate(err, "cannot connect to mongoDB"))
 	}
-	db := client.Database(os.Getenv("MONGODB_DB_NAME"))
 
-	return mongodb.NewMongoDB(db)
+	return client
+}
+
+func initializeDB(client *mongo.Client) database.DB {
+	db := mongodb.NewMongoDB(client.Database(initializeConfig().MongoDB.DBName))
+
+	err := db.RawRecordRepository().CreateIndexes(context.Background())
+	if err != nil {
+		log.Fatal(stacktrace.Propagate(err, "cannot create raw records indexes"))
+	}
+
+	err = db.CardRepository().CreateIndexes(context.Background())
+	if err != nil {
+		log.Fatal(stacktrace.Propagate(err, "cannot create cards indexes"))
+	}
+
+	encrypted, err := db.CardRepository().EncryptPlaintextPasswords(context.Background(), func(cardID id.ID, password string) (string, error) {
+		return initializeKeyring().Encrypt(password, cardID.String())
+	})
+	if err != nil {
+		log.Fatal(stacktrace.Propagate(err, "cannot encrypt the plaintext passwords of the cards"))
+	}
+	if encrypted > 0 {
+		initializeLogger().Info(context.Background(), "encrypted the plaintext passwords of the cards", "cards", encrypted)
+	}
+
+	return db
+}
+
+// initializeTracing exports the spans to the OTLP collector and returns a function which flushes the remaining spans
+func initializeTracing() func() {
+	shutdown, err := tracing.Initialize(tracing.Config{
+		ServiceName: "raw-records-service",
+		Endpoint:    initializeConfig().Tracing.Endpoint,
+		Insecure:    initializeConfig().Tracing.Insecure,
+		SampleRatio: initializeConfig().Tracing.SampleRatio,
+	})
+	if err != nil {
+		log.Fatal(stacktrace.Propagate(err, "cannot initialize tracing"))
+	}
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		err := shutdown(ctx)
+		if err != nil {
+			initializeLogger().Error(ctx, "cannot flush the remaining spans", "err", err)
+		}
+	}
 }
 
 func initializeLogger() logger.Logger {
-	return logger.NewGoKitLogger(os.Stdout)
+	level, err := logger.ParseLevel(initializeConfig().Logging.Level)
+	if err != nil {
+		log.Fatal(stacktrace.Propagate(err, "cannot parse LOG_LEVEL"))
+	}
+
+	return logger.NewGoKitLogger(os.Stdout, level)
 }
 
+// initializeConfig loads the configuration once and exits with every problem when it is invalid
+func initializeConfig() *config.RawRecordsService {
+	if configuration == nil {
+		configuration = &config.RawRecordsService{}
+		config.MustLoad("raw-records-service", configuration)
+	}
+
+	return configuration
+}
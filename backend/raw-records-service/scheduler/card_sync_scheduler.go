@@ -0,0 +1,210 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/raw-records-service/database"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/raw-records-service/entities"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/raw-records-service/transformers"
+	internalContext "github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/context"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/encryption"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/logger"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/ovchipkaart"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/tracing"
+	"github.com/palantir/stacktrace"
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/label"
+)
+
+// TransactionsFetcher fetches the transactions of a card from the ov-chipkaart API
+type TransactionsFetcher interface {
+	FetchTransactions(ctx context.Context, options ovchipkaart.TransactionFetchOptions) (records []ovchipkaart.RawRecord, err error)
+}
+
+// Options configures how often cards are synced
+type Options struct {
+	// PollInterval is how often the scheduler looks for cards which are due
+	PollInterval time.Duration
+
+	// SyncInterval is the time between two successful syncs of the same card
+	SyncInterval time.Duration
+
+	// AuthenticationBackoff is the time to wait after the first authentication failure.
+	// It doubles after every consecutive failure until MaxAuthenticationBackoff is reached.
+	AuthenticationBackoff time.Duration
+
+	// MaxAuthenticationBackoff is the maximum time to wait after an authentication failure
+	MaxAuthenticationBackoff time.Duration
+}
+
+// CardSyncScheduler periodically appends the new transactions of every registered card to the raw records
+type CardSyncScheduler struct {
+	db           database.DB
+	fetcher      TransactionsFetcher
+	transformers transformers.Transformers
+	keyring      *encryption.Keyring
+	logger       logger.Logger
+	options      Options
+}
+
+// NewCardSyncScheduler creates a new card sync scheduler
+func NewCardSyncScheduler(
+	db database.DB,
+	fetcher TransactionsFetcher,
+	transformers transformers.Transformers,
+	keyring *encryption.Keyring,
+	logger logger.Logger,
+	options Options,
+) *CardSyncScheduler {
+	return &CardSyncScheduler{
+		db:           db,
+		fetcher:      fetcher,
+		transformers: transformers,
+		keyring:      keyring,
+		logger:       logger,
+		options:      options,
+	}
+}
+
+// Run syncs the cards which are due at every poll interval until the context is cancelled
+func (scheduler *CardSyncScheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(scheduler.options.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		scheduler.syncDueCards(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (scheduler *CardSyncScheduler) syncDueCards(ctx context.Context) {
+	cards, err := scheduler.db.CardRepository().FetchDue(ctx, time.Now().UTC())
+	if err != nil {
+		scheduler.logger.Error(ctx, "cannot fetch cards which are due", "err", err)
+		return
+	}
+
+	for _, card := range cards {
+		if ctx.Err() != nil {
+			return
+		}
+
+		err = scheduler.SyncCard(ctx, card)
+		if err != nil {
+			scheduler.logger.Warn(ctx, "cannot sync card", "card_id", card.ID.String(), "err", err)
+		}
+	}
+}
+
+// SyncCard appends the transactions of a card which are newer than the last synced transaction
+func (scheduler *CardSyncScheduler) SyncCard(ctx context.Context, card entities.Card) error {
+	ctx, span := tracing.Tracer().Start(ctx, "CardSyncScheduler.SyncCard", trace.WithAttributes(label.String("card.id", card.ID.String())))
+	defer span.End()
+
+	ctx = tracing.WithValue(ctx, internalContext.KeyUserID, card.UserID.String())
+	now := time.Now().UTC()
+
+	password, err := scheduler.keyring.Decrypt(card.EncryptedPassword, card.ID.String())
+	if err != nil {
+		return scheduler.handleFailedSync(ctx, card, stacktrace.Propagate(err, "cannot decrypt the password of the card"), now)
+	}
+
+	ovChipkaartRecords, err := scheduler.fetcher.FetchTransactions(ctx, ovchipkaart.TransactionFetchOptions{
+		Username:   card.Username,
+		Password:   password,
+		CardNumber: card.CardNumber,
+		StartDate:  card.LastTransactionDateTime,
+		EndDate:    now,
+	})
+	if err != nil && ctx.Err() != nil {
+		// the card is still due so the sync is retried after a restart instead of being recorded as failed
+		return stacktrace.Propagate(err, "the sync of the card was cancelled")
+	}
+	if err != nil {
+		return scheduler.handleFailedSync(ctx, card, err, now)
+	}
+
+	// The API works with dates so records from the day of the last transaction are fetched again.
+	// Records with the same timestamp as the last transaction are kept because storage is idempotent.
+	var newRecords []ovchipkaart.RawRecord
+	for _, record := range ovChipkaartRecords {
+		if !record.TransactionDateTime.ToTime().Before(card.LastTransactionDateTime) {
+			newRecords = append(newRecords, record)
+		}
+	}
+
+	if len(newRecords) > 0 {
+		records := scheduler.transformers.OvChipkaartRecordsToRawRecords(card, newRecords)
+		result, err := scheduler.db.RawRecordRepository().StoreMany(ctx, records)
+		if err != nil {
+			return scheduler.handleFailedSync(ctx, card, stacktrace.Propagate(err, "cannot store synced records"), now)
+		}
+
+		scheduler.logger.Info(ctx, "synced card", "card_id", card.ID.String(), "inserted", result.Inserted, "skipped", result.Skipped)
+
+		for _, record := range records {
+			if record.TransactionDateTime.After(card.LastTransactionDateTime) {
+				card.LastTransactionDateTime = record.TransactionDateTime
+			}
+		}
+	}
+
+	card.Status = entities.CardSyncStatusOk
+	card.LastSyncedAt = &now
+	card.NextSyncAt = now.Add(scheduler.options.SyncInterval)
+	card.AuthenticationFailures = 0
+	card.LastError = ""
+	card.UpdatedAt = now
+
+	err = scheduler.db.CardRepository().Update(ctx, card)
+	if err != nil {
+		return stacktrace.Propagate(err, "cannot update card after a successful sync")
+	}
+
+	return nil
+}
+
+func (scheduler *CardSyncScheduler) handleFailedSync(ctx context.Context, card entities.Card, syncErr error, now time.Time) error {
+	trace.SpanFromContext(ctx).SetStatus(codes.Unknown, stacktrace.RootCause(syncErr).Error())
+
+	card.LastError = stacktrace.RootCause(syncErr).Error()
+	card.UpdatedAt = now
+
+	if stacktrace.GetCode(syncErr) == ovchipkaart.ErrCodeUnauthorized {
+		card.Status = entities.CardSyncStatusAuthenticationFailed
+		card.AuthenticationFailures++
+		card.NextSyncAt = now.Add(scheduler.authenticationBackoff(card.AuthenticationFailures))
+	} else {
+		card.Status = entities.CardSyncStatusFailed
+		card.NextSyncAt = now.Add(scheduler.options.SyncInterval)
+	}
+
+	err := scheduler.db.CardRepository().Update(ctx, card)
+	if err != nil {
+		return stacktrace.Propagate(err, "cannot update card after a failed sync: %s", syncErr.Error())
+	}
+
+	return stacktrace.Propagate(syncErr, "cannot fetch transactions for card")
+}
+
+// authenticationBackoff doubles the backoff after every consecutive authentication failure so that
+// accounts with changed passwords don't get locked by the ov-chipkaart API.
+func (scheduler *CardSyncScheduler) authenticationBackoff(failures int) time.Duration {
+	backoff := scheduler.options.AuthenticationBackoff
+	for i := 1; i < failures && backoff < scheduler.options.MaxAuthenticationBackoff; i++ {
+		backoff *= 2
+	}
+
+	if backoff > scheduler.options.MaxAuthenticationBackoff {
+		return scheduler.options.MaxAuthenticationBackoff
+	}
+
+	return backoff
+}
@@ -0,0 +1,67 @@
+package transformers
+
+import (
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/raw-records-service/entities"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/proto/raw-records-service"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/timestamp"
+)
+
+// CardToCardSyncState converts a card into the sync state which is visible to other services
+func (t Transformers) CardToCardSyncState(card entities.Card) (*raw_records_service.CardSyncState, error) {
+	var lastSyncedAt *timestamp.Timestamp
+	if card.LastSyncedAt != nil {
+		value, err := ptypes.TimestampProto(*card.LastSyncedAt)
+		if err != nil {
+			return nil, err
+		}
+		lastSyncedAt = value
+	}
+
+	lastTransactionDateTime, err := ptypes.TimestampProto(card.LastTransactionDateTime)
+	if err != nil {
+		return nil, err
+	}
+
+	nextSyncAt, err := ptypes.TimestampProto(card.NextSyncAt)
+	if err != nil {
+		return nil, err
+	}
+
+	createdAt, err := ptypes.TimestampProto(card.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	updatedAt, err := ptypes.TimestampProto(card.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &raw_records_service.CardSyncState{
+		Id:                      card.ID.String(),
+		UserId:                  card.UserID.String(),
+		CardNumber:              card.CardNumber,
+		Status:                  card.Status.String(),
+		LastSyncedAt:            lastSyncedAt,
+		LastTransactionDateTime: lastTransactionDateTime,
+		NextSyncAt:              nextSyncAt,
+		LastError:               card.LastError,
+		CreatedAt:               createdAt,
+		UpdatedAt:               updatedAt,
+	}, nil
+}
+
+// CardsToCardSyncStatesResponse converts a list of cards into the fetch card sync states response
+func (t Transformers) CardsToCardSyncStatesResponse(cards []entities.Card) (*raw_records_service.FetchCardSyncStatesResponse, error) {
+	states := make([]*raw_records_service.CardSyncState, 0, len(cards))
+	for _, card := range cards {
+		state, err := t.CardToCardSyncState(card)
+		if err != nil {
+			return nil, err
+		}
+		states = append(states, state)
+	}
+
+	return &raw_records_service.FetchCardSyncStatesResponse{Cards: states}, nil
+}
@@ -0,0 +1,42 @@
+package transformers
+
+import (
+	"fmt"
+
+	"google.golang.org/genproto/protobuf/field_mask"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// ValidateFieldMask checks that every path in the mask is a top level field of the message
+func (t Transformers) ValidateFieldMask(message proto.Message, mask *field_mask.FieldMask) error {
+	fields := message.ProtoReflect().Descriptor().Fields()
+	for _, path := range mask.GetPaths() {
+		if fields.ByName(protoreflect.Name(path)) == nil {
+			return fmt.Errorf("%s is not a field of %s", path, message.ProtoReflect().Descriptor().Name())
+		}
+	}
+
+	return nil
+}
+
+// ApplyFieldMask clears all the fields of the message which are not in the mask.
+// The message is not changed when the mask is empty.
+func (t Transformers) ApplyFieldMask(message proto.Message, mask *field_mask.FieldMask) {
+	if len(mask.GetPaths()) == 0 {
+		return
+	}
+
+	paths := make(map[protoreflect.Name]bool, len(mask.GetPaths()))
+	for _, path := range mask.GetPaths() {
+		paths[protoreflect.Name(path)] = true
+	}
+
+	reflection := message.ProtoReflect()
+	reflection.Range(func(field protoreflect.FieldDescriptor, _ protoreflect.Value) bool {
+		if !paths[field.Name()] {
+			reflection.Clear(field)
+		}
+		return true
+	})
+}
@@ -0,0 +1,43 @@
+package transformers
+
+import (
+	"time"
+
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/raw-records-service/entities"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/id"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/ovchipkaart"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/types"
+)
+
+// OvChipkaartRecordsToRawRecords converts records fetched from the ov-chipkaart API for a card into raw records
+func (t Transformers) OvChipkaartRecordsToRawRecords(card entities.Card, ovChipkaartRecords []ovchipkaart.RawRecord) (records []entities.RawRecord) {
+	records = make([]entities.RawRecord, len(ovChipkaartRecords))
+	for index, record := range ovChipkaartRecords {
+		records[index] = entities.RawRecord{
+			ID:                     id.New(),
+			UserID:                 card.UserID,
+			CardNumber:             card.CardNumber,
+			CheckInInfo:            record.CheckInInfo,
+			CheckInText:            record.CheckInText,
+			Fare:                   record.Fare,
+			FareCalculation:        record.FareCalculation,
+			FareText:               record.FareText,
+			ModalType:              record.ModalType,
+			ProductInfo:            record.ProductInfo,
+			ProductText:            record.ProductText,
+			Pto:                    record.Pto,
+			TransactionDateTime:    record.TransactionDateTime.ToTime().UTC(),
+			TransactionInfo:        record.TransactionInfo,
+			TransactionName:        types.TransactionName(record.TransactionName),
+			EPurseMut:              record.EPurseMut,
+			EPurseMutInfo:          record.EPurseMutInfo,
+			TransactionExplanation: record.TransactionExplanation,
+			TransactionPriority:    record.TransactionPriority,
+			Source:                 types.RawRecordSourceAPI,
+			CreatedAt:              time.Now().UTC(),
+			UpdatedAt:              time.Now().UTC(),
+		}
+	}
+
+	return records
+}
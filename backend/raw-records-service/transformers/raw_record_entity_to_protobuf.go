@@ -2,6 +2,7 @@ package transformers
 
 import (
 	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/raw-records-service/entities"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/id"
 	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/proto/raw-records-service"
 	"github.com/golang/protobuf/ptypes"
 	"github.com/golang/protobuf/ptypes/wrappers"
@@ -26,18 +27,22 @@ func (t Transformers) RawRecordsToRawRecordsResponse(rawRecords []entities.RawRe
 			return nil, err
 		}
 
-		createdAt, err := ptypes.TimestampProto(rawRecord.TransactionDateTime)
+		createdAt, err := ptypes.TimestampProto(rawRecord.CreatedAt)
 		if err != nil {
 			return nil, err
 		}
 
-		updatedAt, err := ptypes.TimestampProto(rawRecord.TransactionDateTime)
+		updatedAt, err := ptypes.TimestampProto(rawRecord.UpdatedAt)
 		if err != nil {
 			return nil, err
 		}
 
 
 		records = append(records, &raw_records_service.RawRecord{
+			Id:                     rawRecord.ID.String(),
+			UserId:                 rawRecord.UserID.String(),
+			AnalyzeRequestId:       t.analyzeRequestIDToString(rawRecord.AnalyzeRequestID),
+			CardNumber:             rawRecord.CardNumber,
 			CheckInInfo:            rawRecord.CheckInInfo,
 			CheckInText:            rawRecord.CheckInText,
 			Fare:                   fare,
@@ -62,3 +67,12 @@ func (t Transformers) RawRecordsToRawRecordsResponse(rawRecords []entities.RawRe
 
 	return &raw_records_service.FetchByRequestIdResponse{RawRecords: records}, nil
 }
+
+// analyzeRequestIDToString returns an empty string for records which were synced without an analyze request
+func (t Transformers) analyzeRequestIDToString(analyzeRequestID id.ID) string {
+	if analyzeRequestID == (id.ID{}) {
+		return ""
+	}
+
+	return analyzeRequestID.String()
+}
@@ -0,0 +1,63 @@
+package transformers
+
+import (
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/raw-records-service/entities"
+	raw_records_service_v2 "github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/proto/raw-records-service/v2"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/wrappers"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// RawRecordToRawRecordV2 converts a raw record into the v2 protobuf message including its identity fields
+func (t Transformers) RawRecordToRawRecordV2(rawRecord entities.RawRecord) (*raw_records_service_v2.RawRecord, error) {
+	var fare *wrappers.DoubleValue
+	if rawRecord.Fare != nil {
+		fare = wrapperspb.Double(*rawRecord.Fare)
+	}
+
+	var ePurseMut *wrappers.DoubleValue
+	if rawRecord.EPurseMut != nil {
+		ePurseMut = wrapperspb.Double(*rawRecord.EPurseMut)
+	}
+
+	transactionDateTime, err := ptypes.TimestampProto(rawRecord.TransactionDateTime)
+	if err != nil {
+		return nil, err
+	}
+
+	createdAt, err := ptypes.TimestampProto(rawRecord.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	updatedAt, err := ptypes.TimestampProto(rawRecord.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &raw_records_service_v2.RawRecord{
+		Id:                     rawRecord.ID.String(),
+		UserId:                 rawRecord.UserID.String(),
+		AnalyzeRequestId:       t.analyzeRequestIDToString(rawRecord.AnalyzeRequestID),
+		CardNumber:             rawRecord.CardNumber,
+		CheckInInfo:            rawRecord.CheckInInfo,
+		CheckInText:            rawRecord.CheckInText,
+		Fare:                   fare,
+		FareCalculation:        rawRecord.FareCalculation,
+		FareText:               rawRecord.FareText,
+		ModalType:              rawRecord.ModalType,
+		ProductInfo:            rawRecord.ProductInfo,
+		ProductText:            rawRecord.ProductText,
+		Pto:                    rawRecord.Pto,
+		TransactionDateTime:    transactionDateTime,
+		TransactionInfo:        rawRecord.TransactionInfo,
+		TransactionName:        rawRecord.TransactionName.String(),
+		EPurseMut:              ePurseMut,
+		EPurseMutInfo:          rawRecord.EPurseMutInfo,
+		TransactionExplanation: rawRecord.TransactionExplanation,
+		TransactionPriority:    rawRecord.TransactionPriority,
+		Source:                 rawRecord.Source.String(),
+		CreatedAt:              createdAt,
+		UpdatedAt:              updatedAt,
+	}, nil
+}
@@ -11,6 +11,8 @@ import (
 func (t Transformers) TransactionsToRawRecords(
 	transactions []*transactions_service.Transaction,
 	analyzeRequestId id.ID,
+	userID id.ID,
+	cardNumber string,
 	source types.RawRecordSource,
 ) (records []entities.RawRecord) {
 
@@ -28,7 +30,9 @@ func (t Transformers) TransactionsToRawRecords(
 
 		rawRecords[index] = entities.RawRecord{
 			ID:                     id.New(),
+			UserID:                 userID,
 			AnalyzeRequestID:      	analyzeRequestId,
+			CardNumber:             cardNumber,
 			CheckInInfo:            record.CheckInInfo,
 			CheckInText:            record.CheckInText,
 			Fare:                   fare,
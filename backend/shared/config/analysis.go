@@ -0,0 +1,24 @@
+package config
+
+// Analysis is the configuration of the program in the root of the backend module which enriches the raw records
+// and calculates the price of the trips with every subscription
+type Analysis struct {
+	Logging     Logging
+	MongoDB     MongoDB
+	OvChipkaart OvChipkaartAccount
+
+	MetricsAddress     string `env:"METRICS_ADDRESS" usage:"address of the prometheus metrics server, disabled when empty"`
+	SentryDSN          string `env:"SENTRY_DSN" secret:"true" usage:"DSN of the sentry project, errors are not reported when empty"`
+	NSAPIKey           string `env:"NS_API_KEY_PUBLIC_TRAVEL_INFORMATION" required:"true" secret:"true" usage:"key of the public travel information API of the NS"`
+	CalendarificAPIKey string `env:"CALENDARIFIC_API_KEY" secret:"true" usage:"key of the calendarific API, only needed to store the national holidays"`
+}
+
+// OvChipkaartAccount contains the credentials which are only needed to import the transactions of a card.
+// The keys used to be spelled OV_CHIPKAAT_* and the client keys didn't have a prefix, the old names still work.
+type OvChipkaartAccount struct {
+	ClientID     string `env:"OV_CHIPKAART_API_CLIENT_ID" deprecated:"CLIENT_ID" usage:"client id of the ov-chipkaart API"`
+	ClientSecret string `env:"OV_CHIPKAART_API_CLIENT_SECRET" deprecated:"CLIENT_SECRET" secret:"true" usage:"client secret of the ov-chipkaart API"`
+	Username     string `env:"OV_CHIPKAART_USERNAME" deprecated:"OV_CHIPKAAT_USERNAME" usage:"username of the ov-chipkaart account"`
+	Password     string `env:"OV_CHIPKAART_PASSWORD" deprecated:"OV_CHIPKAAT_PASSWORD" secret:"true" usage:"password of the ov-chipkaart account"`
+	CardNumber   string `env:"OV_CHIPKAART_CARD_NUMBER" deprecated:"OV_CHIPKAAT_CARD_NUMBER" usage:"number of the card whose transactions are imported"`
+}
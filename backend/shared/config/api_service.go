@@ -0,0 +1,229 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/encryption"
+	"github.com/palantir/stacktrace"
+)
+
+const (
+	// CaptchaDriverRecaptcha verifies the responses with Google reCAPTCHA v3
+	CaptchaDriverRecaptcha = "recaptcha"
+
+	// CaptchaDriverHCaptcha verifies the responses with hCaptcha
+	CaptchaDriverHCaptcha = "hcaptcha"
+
+	// CaptchaDriverProofOfWork issues challenges which are solved by the browser
+	CaptchaDriverProofOfWork = "proof-of-work"
+
+	// MailerDriverLog writes the emails to the log
+	MailerDriverLog = "log"
+
+	// MailerDriverSMTP sends the emails through an SMTP server
+	MailerDriverSMTP = "smtp"
+)
+
+// APIService is the configuration of the GraphQL API
+type APIService struct {
+	Port              string `env:"PORT" default:"8080" usage:"port of the HTTP server"`
+	AppURL            string `env:"APP_URL" required:"true" usage:"public URL of the frontend which is used in emails"`
+	APIURL            string `env:"API_URL" required:"true" usage:"public URL of this service which is used in download links"`
+	TrustProxyHeaders bool   `env:"TRUST_PROXY_HEADERS" default:"false" usage:"read the client IP from the X-Forwarded-For header"`
+	TrustedProxyHops  int    `env:"TRUSTED_PROXY_HOPS" default:"1" usage:"number of trusted proxies in front of this service which append to the X-Forwarded-For header"`
+	SentryDSN         string `env:"SENTRY_DSN" secret:"true" usage:"DSN of the sentry project, errors are only logged when empty"`
+
+	HealthCheckTimeout time.Duration `env:"HEALTH_CHECK_TIMEOUT" default:"2s" usage:"timeout of each dependency check of the readiness endpoint"`
+
+	TransactionsServiceTarget string `env:"TRANSACTIONS_SERVICE_TARGET" required:"true" usage:"gRPC target of the transactions service"`
+	RawRecordsServiceTarget   string `env:"RAW_RECORDS_SERVICE_TARGET" required:"true" usage:"gRPC target of the raw records service"`
+
+	Logging        Logging
+	Tracing        Tracing
+	ServiceAuth    ServiceAuth
+	MongoDB        MongoDB
+	Redis          Redis
+	OvChipkaartAPI OvChipkaartAPI
+	JWT            JWT
+	RefreshToken   RefreshToken
+	LoginLockout   LoginLockout
+	TwoFactor      TwoFactor
+	GraphQL        GraphQL
+	Captcha        Captcha
+	Mailer         Mailer
+	DataExport     DataExport
+	Shutdown       Shutdown
+}
+
+// Validate checks the number of trusted proxies
+func (config APIService) Validate() (problems []string) {
+	if config.TrustProxyHeaders && config.TrustedProxyHops < 1 {
+		problems = append(problems, "TRUSTED_PROXY_HOPS must be at least 1 when TRUST_PROXY_HEADERS is true")
+	}
+	return problems
+}
+
+// Redis configures the connection to the cache
+type Redis struct {
+	Address  string `env:"REDIS_ADDRESS" required:"true" usage:"address of the redis server e.g. localhost:6379"`
+	Password string `env:"REDIS_PASSWORD" secret:"true" usage:"password of the redis server"`
+}
+
+// JWT configures the access tokens
+type JWT struct {
+	PrivateKeys         string        `env:"JWT_PRIVATE_KEYS" required:"true" usage:"comma separated kid=path pairs of the PEM encoded signing keys"`
+	ActiveKeyID         string        `env:"JWT_ACTIVE_KEY_ID" required:"true" usage:"kid of the key which signs new tokens"`
+	AccessTokenLifetime time.Duration `env:"ACCESS_TOKEN_LIFETIME" default:"15m" usage:"lifetime of an access token"`
+}
+
+// Validate checks the format of the key list
+func (config JWT) Validate() (problems []string) {
+	if config.PrivateKeys == "" {
+		return problems
+	}
+
+	for _, pair := range strings.Split(config.PrivateKeys, ",") {
+		if parts := strings.SplitN(strings.TrimSpace(pair), "=", 2); len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			problems = append(problems, fmt.Sprintf("JWT_PRIVATE_KEYS entry %q must be a kid=path pair", pair))
+		}
+	}
+	return problems
+}
+
+// RefreshToken configures the lifetime of the refresh token families
+type RefreshToken struct {
+	Lifetime           time.Duration `env:"REFRESH_TOKEN_LIFETIME" default:"24h" usage:"lifetime of a refresh token"`
+	RememberMeLifetime time.Duration `env:"REFRESH_TOKEN_REMEMBER_ME_LIFETIME" default:"720h" usage:"lifetime of a refresh token when remember me is checked"`
+}
+
+// LoginLockout configures the backoff after failed logins
+type LoginLockout struct {
+	Backoff    time.Duration `env:"LOGIN_LOCKOUT_BACKOFF" default:"1m" usage:"lockout after the maximum number of failed logins"`
+	MaxBackoff time.Duration `env:"LOGIN_LOCKOUT_MAX_BACKOFF" default:"24h" usage:"maximum lockout after repeated failed logins"`
+}
+
+// TwoFactor configures the TOTP second factor
+type TwoFactor struct {
+	Issuer            string        `env:"TWO_FACTOR_ISSUER" default:"OV-Chipkaart Dashboard" usage:"issuer which is shown by authenticator apps"`
+	ChallengeLifetime time.Duration `env:"TWO_FACTOR_CHALLENGE_LIFETIME" default:"5m" usage:"time to enter the code after the password"`
+	EncryptionKeys    string        `env:"TWO_FACTOR_ENCRYPTION_KEYS" required:"true" secret:"true" usage:"comma separated key-id:base64 pairs of 32 byte AES keys, old keys are kept to decrypt old secrets"`
+	EncryptionKeyID   string        `env:"TWO_FACTOR_ENCRYPTION_KEY_ID" required:"true" usage:"id of the key which encrypts new secrets"`
+}
+
+// Validate checks that the keys can be decoded and that the current key is one of them
+func (config TwoFactor) Validate() (problems []string) {
+	if config.EncryptionKeys == "" || config.EncryptionKeyID == "" {
+		return problems
+	}
+
+	_, err := config.Keyring()
+	if err != nil {
+		problems = append(problems, "TWO_FACTOR_ENCRYPTION_KEYS is invalid: "+stacktrace.RootCause(err).Error())
+	}
+	return problems
+}
+
+// Keyring creates the keyring which encrypts and decrypts the TOTP secrets
+func (config TwoFactor) Keyring() (*encryption.Keyring, error) {
+	keys, err := encryption.ParseKeys(config.EncryptionKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	return encryption.NewKeyring(keys, config.EncryptionKeyID)
+}
+
+// GraphQL configures the GraphQL server
+type GraphQL struct {
+	ComplexityLimit        int           `env:"GRAPHQL_COMPLEXITY_LIMIT" default:"3000" usage:"maximum complexity of a query"`
+	PersistedQueryLifetime time.Duration `env:"GRAPHQL_PERSISTED_QUERY_LIFETIME" default:"24h" usage:"time an automatic persisted query is kept in redis"`
+	Tracing                bool          `env:"GRAPHQL_TRACING" default:"false" usage:"add apollo tracing to the responses"`
+}
+
+// Captcha configures the verification of the captcha responses
+type Captcha struct {
+	Driver                string  `env:"CAPTCHA_DRIVER" default:"recaptcha" options:"recaptcha,hcaptcha,proof-of-work" usage:"captcha provider"`
+	RecaptchaSecret       string  `env:"RECAPTCHA_SECRET" secret:"true" usage:"secret key of reCAPTCHA"`
+	RecaptchaMinScore     float64 `env:"RECAPTCHA_MIN_SCORE" default:"0.5" usage:"minimum reCAPTCHA score of a human"`
+	RecaptchaHostname     string  `env:"RECAPTCHA_HOSTNAME" usage:"hostname of the site which renders reCAPTCHA, the host of APP_URL when empty"`
+	HCaptchaSecret        string  `env:"HCAPTCHA_SECRET" secret:"true" usage:"secret key of hCaptcha"`
+	HCaptchaSiteKey       string  `env:"HCAPTCHA_SITE_KEY" usage:"site key of hCaptcha"`
+	ProofOfWorkSecret     string  `env:"CAPTCHA_PROOF_OF_WORK_SECRET" secret:"true" usage:"key which signs the proof of work challenges"`
+	ProofOfWorkDifficulty int     `env:"CAPTCHA_PROOF_OF_WORK_DIFFICULTY" default:"20" usage:"leading zero bits of a proof of work solution"`
+}
+
+// Validate checks the settings of the selected driver
+func (config Captcha) Validate() (problems []string) {
+	switch config.Driver {
+	case CaptchaDriverRecaptcha:
+		if config.RecaptchaSecret == "" {
+			problems = append(problems, "RECAPTCHA_SECRET is required when CAPTCHA_DRIVER is recaptcha")
+		}
+		if config.RecaptchaMinScore < 0 || config.RecaptchaMinScore > 1 {
+			problems = append(problems, fmt.Sprintf("RECAPTCHA_MIN_SCORE must be between 0 and 1 but it is %g", config.RecaptchaMinScore))
+		}
+	case CaptchaDriverHCaptcha:
+		if config.HCaptchaSecret == "" {
+			problems = append(problems, "HCAPTCHA_SECRET is required when CAPTCHA_DRIVER is hcaptcha")
+		}
+		if config.HCaptchaSiteKey == "" {
+			problems = append(problems, "HCAPTCHA_SITE_KEY is required when CAPTCHA_DRIVER is hcaptcha")
+		}
+	case CaptchaDriverProofOfWork:
+		if config.ProofOfWorkSecret == "" {
+			problems = append(problems, "CAPTCHA_PROOF_OF_WORK_SECRET is required when CAPTCHA_DRIVER is proof-of-work")
+		}
+		if config.ProofOfWorkDifficulty < 1 || config.ProofOfWorkDifficulty > 256 {
+			problems = append(problems, fmt.Sprintf("CAPTCHA_PROOF_OF_WORK_DIFFICULTY must be between 1 and 256 but it is %d", config.ProofOfWorkDifficulty))
+		}
+	}
+	return problems
+}
+
+// Mailer configures how emails are sent
+type Mailer struct {
+	Driver       string `env:"MAILER_DRIVER" default:"log" options:"log,smtp" usage:"log writes the emails to stdout"`
+	SMTPHost     string `env:"SMTP_HOST" usage:"host of the SMTP server"`
+	SMTPPort     string `env:"SMTP_PORT" default:"587" usage:"port of the SMTP server"`
+	SMTPUsername string `env:"SMTP_USERNAME" secret:"true" usage:"username of the SMTP server"`
+	SMTPPassword string `env:"SMTP_PASSWORD" secret:"true" usage:"password of the SMTP server"`
+	FromAddress  string `env:"MAIL_FROM_ADDRESS" usage:"sender of the emails"`
+}
+
+// Validate checks the SMTP settings when emails are sent
+func (config Mailer) Validate() (problems []string) {
+	if config.Driver != MailerDriverSMTP {
+		return problems
+	}
+
+	if config.SMTPHost == "" {
+		problems = append(problems, "SMTP_HOST is required when MAILER_DRIVER is smtp")
+	}
+	if config.FromAddress == "" {
+		problems = append(problems, "MAIL_FROM_ADDRESS is required when MAILER_DRIVER is smtp")
+	}
+	return problems
+}
+
+// DataExport configures the archives of the personal data export
+type DataExport struct {
+	Directory       string        `env:"DATA_EXPORT_DIRECTORY" required:"true" usage:"directory where the archives are written, it must be shared by all the instances of the api service"`
+	LinkLifetime    time.Duration `env:"DATA_EXPORT_LINK_LIFETIME" default:"168h" usage:"time the download link of an archive is valid"`
+	CleanupInterval time.Duration `env:"DATA_EXPORT_CLEANUP_INTERVAL" default:"1h" usage:"how often the archives with an expired download link are deleted"`
+}
+
+// Validate checks that the cleanup doesn't spin
+func (config DataExport) Validate() (problems []string) {
+	if config.CleanupInterval <= 0 {
+		problems = append(problems, "DATA_EXPORT_CLEANUP_INTERVAL must be positive")
+	}
+	return problems
+}
+
+// Shutdown configures the graceful shutdown of the HTTP server
+type Shutdown struct {
+	DrainDelay time.Duration `env:"SHUTDOWN_DRAIN_DELAY" default:"5s" usage:"time the readiness check fails before the server stops accepting requests"`
+	Timeout    time.Duration `env:"SHUTDOWN_TIMEOUT" default:"60s" usage:"time to complete the in-flight requests"`
+}
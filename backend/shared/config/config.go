@@ -0,0 +1,235 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/redact"
+	"github.com/joho/godotenv"
+	"github.com/palantir/stacktrace"
+)
+
+const (
+	// defaultFile is read when it exists and neither --config-file nor CONFIG_FILE is set
+	defaultFile = ".env"
+
+	// secretFileSuffix is appended to a key to read its value from a file e.g. SMTP_PASSWORD_FILE=/run/secrets/smtp
+	secretFileSuffix = "_FILE"
+)
+
+// Source is the layer which a configuration value was read from
+type Source string
+
+const (
+	// SourceDefault is the value of the default tag of a field
+	SourceDefault = Source("default")
+
+	// SourceFile is a value from the config file which uses the same KEY=value format as .env files
+	SourceFile = Source("file")
+
+	// SourceEnv is a value from an environment variable
+	SourceEnv = Source("env")
+
+	// SourceFlag is a value from a command line flag e.g. --mongodb-uri for MONGODB_URI
+	SourceFlag = Source("flag")
+)
+
+// Result describes where the values of a loaded configuration come from
+type Result struct {
+	// PrintConfig is true when the service is started with --print-config
+	PrintConfig bool
+
+	// Warnings are problems which don't stop the service e.g. keys which were renamed
+	Warnings []string
+
+	fields []*field
+}
+
+// Print writes the configuration as KEY=value lines with the secrets redacted
+func (result *Result) Print(writer io.Writer) {
+	for _, field := range result.fields {
+		value := field.raw
+		if field.isSecret() && value != "" {
+			value = redact.Placeholder
+		}
+
+		_, _ = fmt.Fprintf(writer, "%s=%s # %s\n", field.key, value, field.source)
+	}
+}
+
+// Load fills the target, which must be a pointer to a struct, from the defaults, the config file, the environment
+// variables and the command line flags with later sources overriding earlier ones. Fields are mapped with the env tag
+// and all the problems with the values are returned together as a *ValidationError.
+func Load(name string, target interface{}, args []string) (*Result, error) {
+	targetValue := reflect.ValueOf(target)
+	if targetValue.Kind() != reflect.Ptr || targetValue.Elem().Kind() != reflect.Struct {
+		return nil, stacktrace.NewError("the configuration of %s must be a pointer to a struct", name)
+	}
+
+	result := &Result{fields: collectFields(targetValue.Elem())}
+
+	flagSet := flag.NewFlagSet(name, flag.ContinueOnError)
+	configFile := flagSet.String("config-file", "", "file with KEY=value lines, defaults to "+defaultFile+" or CONFIG_FILE")
+	flagSet.BoolVar(&result.PrintConfig, "print-config", false, "print the configuration with the secrets redacted and exit")
+
+	flagValues := make(map[string]*string, len(result.fields))
+	for _, field := range result.fields {
+		flagValues[field.key] = flagSet.String(field.flagName(), field.tag.Get("default"), field.tag.Get("usage"))
+	}
+
+	err := flagSet.Parse(args)
+	if err != nil {
+		return result, stacktrace.Propagate(err, "cannot parse the command line flags of %s", name)
+	}
+
+	problems := &ValidationError{}
+
+	for _, field := range result.fields {
+		field.raw, field.source = field.tag.Get("default"), SourceDefault
+	}
+
+	fileValues, err := readFile(*configFile)
+	if err != nil {
+		problems.add("cannot read the config file: %s", stacktrace.RootCause(err).Error())
+	}
+
+	result.apply(SourceFile, lookupMap(fileValues), problems)
+	result.apply(SourceEnv, os.LookupEnv, problems)
+
+	flagSet.Visit(func(visited *flag.Flag) {
+		for _, field := range result.fields {
+			if field.flagName() == visited.Name {
+				field.raw, field.source, field.fromDeprecatedKey = *flagValues[field.key], SourceFlag, false
+			}
+		}
+	})
+
+	for _, field := range result.fields {
+		if field.fromDeprecatedKey {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("%s is deprecated, use %s instead", field.deprecatedKey(), field.key))
+		}
+		field.validate(problems)
+	}
+
+	validateStructs(targetValue, problems)
+
+	if len(problems.Problems) > 0 {
+		return result, problems
+	}
+
+	return result, nil
+}
+
+// MustLoad loads the configuration of a service from os.Args and exits when it is invalid.
+// With --print-config the configuration is printed with the secrets redacted before exiting.
+func MustLoad(name string, target interface{}) {
+	result, err := Load(name, target, os.Args[1:])
+	if stacktrace.RootCause(err) == flag.ErrHelp {
+		os.Exit(0)
+	}
+
+	if result != nil {
+		for _, warning := range result.Warnings {
+			_, _ = fmt.Fprintf(os.Stderr, "%s: warning: %s\n", name, warning)
+		}
+	}
+
+	if result != nil && result.PrintConfig {
+		result.Print(os.Stdout)
+	}
+
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "%s: %s\n", name, stacktrace.RootCause(err).Error())
+		os.Exit(2)
+	}
+
+	if result.PrintConfig {
+		os.Exit(0)
+	}
+}
+
+// apply overrides the fields which are set in a source. A value can also be read from the file named by KEY_FILE
+// so that secrets which are mounted as files don't have to be copied into the environment.
+// The deprecated key of a field is only used when the source doesn't set the current key.
+func (result *Result) apply(source Source, lookup func(string) (string, bool), problems *ValidationError) {
+	for _, field := range result.fields {
+		if value, ok := lookup(field.key); ok {
+			field.raw, field.source, field.fromDeprecatedKey = value, source, false
+			continue
+		}
+
+		if deprecatedKey := field.deprecatedKey(); deprecatedKey != "" {
+			if value, ok := lookup(deprecatedKey); ok {
+				field.raw, field.source, field.fromDeprecatedKey = value, source, true
+				continue
+			}
+		}
+
+		path, ok := lookup(field.key + secretFileSuffix)
+		if !ok || path == "" {
+			continue
+		}
+
+		contents, err := ioutil.ReadFile(path)
+		if err != nil {
+			problems.add("%s%s: cannot read %s", field.key, secretFileSuffix, path)
+			continue
+		}
+
+		field.raw, field.source, field.fromDeprecatedKey = strings.TrimRight(string(contents), "\r\n"), source, false
+	}
+}
+
+// readFile reads the KEY=value lines of the config file. The default file is optional so that services can be
+// configured with environment variables only.
+func readFile(path string) (map[string]string, error) {
+	if path == "" {
+		path = os.Getenv("CONFIG_FILE")
+	}
+
+	if path == "" {
+		if _, err := os.Stat(defaultFile); os.IsNotExist(err) {
+			return map[string]string{}, nil
+		}
+		path = defaultFile
+	}
+
+	values, err := godotenv.Read(path)
+	if err != nil {
+		return map[string]string{}, stacktrace.Propagate(err, "cannot read the config file %s", path)
+	}
+
+	return values, nil
+}
+
+func lookupMap(values map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		value, ok := values[key]
+		return value, ok
+	}
+}
+
+// validator is implemented by configuration structs with rules which depend on more than one field
+type validator interface {
+	Validate() []string
+}
+
+func validateStructs(value reflect.Value, problems *ValidationError) {
+	if value.CanInterface() {
+		if structValidator, ok := value.Interface().(validator); ok {
+			problems.Problems = append(problems.Problems, structValidator.Validate()...)
+		}
+	}
+
+	structValue := reflect.Indirect(value)
+	for i := 0; i < structValue.NumField(); i++ {
+		if structValue.Type().Field(i).Tag.Get("env") == "" && structValue.Field(i).Kind() == reflect.Struct {
+			validateStructs(structValue.Field(i).Addr(), problems)
+		}
+	}
+}
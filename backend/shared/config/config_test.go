@@ -0,0 +1,324 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testNestedConfig struct {
+	Ratio float64 `env:"CONFIG_TEST_RATIO" default:"0.5"`
+}
+
+func (config testNestedConfig) Validate() (problems []string) {
+	if config.Ratio > 1 {
+		problems = append(problems, fmt.Sprintf("CONFIG_TEST_RATIO must not be more than 1 but it is %g", config.Ratio))
+	}
+	return problems
+}
+
+type testConfig struct {
+	Name    string        `env:"CONFIG_TEST_NAME" default:"default-name"`
+	Level   string        `env:"CONFIG_TEST_LEVEL" default:"info" options:"debug,info"`
+	Secret  string        `env:"CONFIG_TEST_SECRET" required:"true" secret:"true" deprecated:"CONFIG_TEST_OLD_SECRET"`
+	Timeout time.Duration `env:"CONFIG_TEST_TIMEOUT" default:"30s"`
+	Port    int           `env:"CONFIG_TEST_PORT" default:"8080"`
+	Nested  testNestedConfig
+}
+
+// testKeys are all the keys which a test can set in the environment
+var testKeys = []string{
+	"CONFIG_TEST_NAME", "CONFIG_TEST_LEVEL", "CONFIG_TEST_SECRET", "CONFIG_TEST_OLD_SECRET",
+	"CONFIG_TEST_SECRET_FILE", "CONFIG_TEST_TIMEOUT", "CONFIG_TEST_PORT", "CONFIG_TEST_RATIO",
+}
+
+// setEnv replaces the test keys in the environment and returns a function which restores them
+func setEnv(t *testing.T, values map[string]string) func() {
+	previous := map[string]*string{}
+	for _, key := range testKeys {
+		if value, ok := os.LookupEnv(key); ok {
+			previous[key] = &value
+		} else {
+			previous[key] = nil
+		}
+
+		var err error
+		if value, ok := values[key]; ok {
+			err = os.Setenv(key, value)
+		} else {
+			err = os.Unsetenv(key)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return func() {
+		for key, value := range previous {
+			if value == nil {
+				_ = os.Unsetenv(key)
+			} else {
+				_ = os.Setenv(key, *value)
+			}
+		}
+	}
+}
+
+// writeFile writes the lines of a config file into a temporary directory and returns its path
+func writeFile(t *testing.T, directory string, name string, lines ...string) string {
+	path := filepath.Join(directory, name)
+	err := ioutil.WriteFile(path, []byte(strings.Join(lines, "\n")), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	directory, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(directory) }()
+
+	secretFile := writeFile(t, directory, "secret", "secret-from-file\n")
+
+	defaults := testConfig{
+		Name:    "default-name",
+		Level:   "info",
+		Secret:  "secret",
+		Timeout: 30 * time.Second,
+		Port:    8080,
+		Nested:  testNestedConfig{Ratio: 0.5},
+	}
+
+	tests := []struct {
+		name             string
+		file             []string
+		env              map[string]string
+		args             []string
+		expected         testConfig
+		expectedSources  map[string]Source
+		expectedWarnings []string
+		expectedProblems []string
+	}{
+		{
+			name:            "defaults",
+			env:             map[string]string{"CONFIG_TEST_SECRET": "secret"},
+			expected:        defaults,
+			expectedSources: map[string]Source{"CONFIG_TEST_NAME": SourceDefault, "CONFIG_TEST_SECRET": SourceEnv},
+		},
+		{
+			name: "the file overrides the defaults",
+			file: []string{"CONFIG_TEST_NAME=file-name", "CONFIG_TEST_SECRET=secret", "CONFIG_TEST_TIMEOUT=1m"},
+			expected: testConfig{
+				Name: "file-name", Level: "info", Secret: "secret", Timeout: time.Minute, Port: 8080, Nested: testNestedConfig{Ratio: 0.5},
+			},
+			expectedSources: map[string]Source{"CONFIG_TEST_NAME": SourceFile, "CONFIG_TEST_PORT": SourceDefault},
+		},
+		{
+			name: "the environment overrides the file",
+			file: []string{"CONFIG_TEST_NAME=file-name", "CONFIG_TEST_SECRET=secret"},
+			env:  map[string]string{"CONFIG_TEST_NAME": "env-name", "CONFIG_TEST_LEVEL": "debug"},
+			expected: testConfig{
+				Name: "env-name", Level: "debug", Secret: "secret", Timeout: 30 * time.Second, Port: 8080, Nested: testNestedConfig{Ratio: 0.5},
+			},
+			expectedSources: map[string]Source{"CONFIG_TEST_NAME": SourceEnv, "CONFIG_TEST_SECRET": SourceFile},
+		},
+		{
+			name: "the flags override the environment",
+			file: []string{"CONFIG_TEST_PORT=9090"},
+			env:  map[string]string{"CONFIG_TEST_NAME": "env-name", "CONFIG_TEST_SECRET": "secret"},
+			args: []string{"--config-test-name", "flag-name", "--config-test-ratio=0.25"},
+			expected: testConfig{
+				Name: "flag-name", Level: "info", Secret: "secret", Timeout: 30 * time.Second, Port: 9090, Nested: testNestedConfig{Ratio: 0.25},
+			},
+			expectedSources: map[string]Source{"CONFIG_TEST_NAME": SourceFlag, "CONFIG_TEST_PORT": SourceFile, "CONFIG_TEST_RATIO": SourceFlag},
+		},
+		{
+			name:             "a deprecated key is used with a warning",
+			env:              map[string]string{"CONFIG_TEST_OLD_SECRET": "secret"},
+			expected:         defaults,
+			expectedSources:  map[string]Source{"CONFIG_TEST_SECRET": SourceEnv},
+			expectedWarnings: []string{"CONFIG_TEST_OLD_SECRET is deprecated, use CONFIG_TEST_SECRET instead"},
+		},
+		{
+			name:            "the current key wins over a deprecated key",
+			file:            []string{"CONFIG_TEST_OLD_SECRET=old-secret"},
+			env:             map[string]string{"CONFIG_TEST_SECRET": "secret"},
+			expected:        defaults,
+			expectedSources: map[string]Source{"CONFIG_TEST_SECRET": SourceEnv},
+		},
+		{
+			name:            "a deprecated key in the file is overridden by a flag",
+			file:            []string{"CONFIG_TEST_OLD_SECRET=old-secret"},
+			args:            []string{"--config-test-secret=secret"},
+			expected:        defaults,
+			expectedSources: map[string]Source{"CONFIG_TEST_SECRET": SourceFlag},
+		},
+		{
+			name: "a secret is read from a file",
+			env:  map[string]string{"CONFIG_TEST_SECRET_FILE": secretFile},
+			expected: testConfig{
+				Name: "default-name", Level: "info", Secret: "secret-from-file", Timeout: 30 * time.Second, Port: 8080, Nested: testNestedConfig{Ratio: 0.5},
+			},
+			expectedSources: map[string]Source{"CONFIG_TEST_SECRET": SourceEnv},
+		},
+		{
+			name:             "a missing secret file",
+			env:              map[string]string{"CONFIG_TEST_SECRET_FILE": filepath.Join(directory, "missing")},
+			expectedProblems: []string{"CONFIG_TEST_SECRET_FILE: cannot read " + filepath.Join(directory, "missing"), "CONFIG_TEST_SECRET is required"},
+		},
+		{
+			name:             "a required value is missing",
+			expectedProblems: []string{"CONFIG_TEST_SECRET is required"},
+		},
+		{
+			name:             "a value which is not one of the options",
+			env:              map[string]string{"CONFIG_TEST_SECRET": "secret", "CONFIG_TEST_LEVEL": "trace"},
+			expectedProblems: []string{`CONFIG_TEST_LEVEL must be one of debug,info but it is "trace"`},
+		},
+		{
+			name: "all the problems are returned together",
+			env:  map[string]string{"CONFIG_TEST_TIMEOUT": "30", "CONFIG_TEST_PORT": "http", "CONFIG_TEST_RATIO": "2"},
+			expectedProblems: []string{
+				"CONFIG_TEST_SECRET is required",
+				`CONFIG_TEST_TIMEOUT: cannot parse "30" as a duration e.g. 90s or 1h30m`,
+				`CONFIG_TEST_PORT: cannot parse "http" as an integer`,
+				"CONFIG_TEST_RATIO must not be more than 1 but it is 2",
+			},
+		},
+	}
+
+	for index, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			restore := setEnv(t, test.env)
+			defer restore()
+
+			path := writeFile(t, directory, fmt.Sprintf("%d.env", index), test.file...)
+
+			target := testConfig{}
+			result, err := Load("test", &target, append([]string{"--config-file", path}, test.args...))
+
+			if len(test.expectedProblems) > 0 {
+				validationError, ok := err.(*ValidationError)
+				if !ok {
+					t.Fatalf("Load returned the error %v, expected a validation error", err)
+				}
+
+				if !reflect.DeepEqual(validationError.Problems, test.expectedProblems) {
+					t.Fatalf("Load returned the problems %q, expected %q", validationError.Problems, test.expectedProblems)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Load returned an error: %s", err)
+			}
+
+			if target != test.expected {
+				t.Errorf("Load returned %+v, expected %+v", target, test.expected)
+			}
+
+			if !reflect.DeepEqual(result.Warnings, test.expectedWarnings) {
+				t.Errorf("Load returned the warnings %q, expected %q", result.Warnings, test.expectedWarnings)
+			}
+
+			for _, field := range result.fields {
+				if expected, ok := test.expectedSources[field.key]; ok && field.source != expected {
+					t.Errorf("%s was read from %s, expected %s", field.key, field.source, expected)
+				}
+			}
+		})
+	}
+}
+
+func TestResultPrint(t *testing.T) {
+	restore := setEnv(t, map[string]string{"CONFIG_TEST_SECRET": "secret", "CONFIG_TEST_NAME": "env-name"})
+	defer restore()
+
+	directory, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(directory) }()
+
+	result, err := Load("test", &testConfig{}, []string{"--config-file", writeFile(t, directory, ".env"), "--print-config"})
+	if err != nil {
+		t.Fatalf("Load returned an error: %s", err)
+	}
+
+	if !result.PrintConfig {
+		t.Errorf("PrintConfig is false, expected --print-config to set it")
+	}
+
+	output := &bytes.Buffer{}
+	result.Print(output)
+
+	expected := strings.Join([]string{
+		"CONFIG_TEST_NAME=env-name # env",
+		"CONFIG_TEST_LEVEL=info # default",
+		"CONFIG_TEST_SECRET=[REDACTED] # env",
+		"CONFIG_TEST_TIMEOUT=30s # default",
+		"CONFIG_TEST_PORT=8080 # default",
+		"CONFIG_TEST_RATIO=0.5 # default",
+	}, "\n") + "\n"
+
+	if output.String() != expected {
+		t.Errorf("Print wrote\n%s\nexpected\n%s", output.String(), expected)
+	}
+}
+
+func TestLoadAnalysisDeprecatedKeys(t *testing.T) {
+	directory, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(directory) }()
+
+	path := writeFile(t, directory, ".env",
+		"MONGODB_URI=mongodb://localhost:27017",
+		"MONGODB_DB_NAME=ov-chipkaart",
+		"NS_API_KEY_PUBLIC_TRAVEL_INFORMATION=key",
+		"CLIENT_ID=client-id",
+		"CLIENT_SECRET=client-secret",
+		"OV_CHIPKAAT_USERNAME=username",
+		"OV_CHIPKAAT_PASSWORD=password",
+		"OV_CHIPKAART_CARD_NUMBER=3528000000000000",
+		"OV_CHIPKAAT_CARD_NUMBER=3528000000000001",
+	)
+
+	target := Analysis{}
+	result, err := Load("analysis", &target, []string{"--config-file", path})
+	if err != nil {
+		t.Fatalf("Load returned an error: %s", err)
+	}
+
+	expected := OvChipkaartAccount{
+		ClientID:     "client-id",
+		ClientSecret: "client-secret",
+		Username:     "username",
+		Password:     "password",
+		CardNumber:   "3528000000000000",
+	}
+	if target.OvChipkaart != expected {
+		t.Errorf("Load returned %+v, expected %+v", target.OvChipkaart, expected)
+	}
+
+	expectedWarnings := []string{
+		"CLIENT_ID is deprecated, use OV_CHIPKAART_API_CLIENT_ID instead",
+		"CLIENT_SECRET is deprecated, use OV_CHIPKAART_API_CLIENT_SECRET instead",
+		"OV_CHIPKAAT_USERNAME is deprecated, use OV_CHIPKAART_USERNAME instead",
+		"OV_CHIPKAAT_PASSWORD is deprecated, use OV_CHIPKAART_PASSWORD instead",
+	}
+	if !reflect.DeepEqual(result.Warnings, expectedWarnings) {
+		t.Errorf("Load returned the warnings %q, expected %q", result.Warnings, expectedWarnings)
+	}
+}
@@ -0,0 +1,24 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ValidationError contains every problem with a configuration so that they can be fixed in one go
+type ValidationError struct {
+	Problems []string
+}
+
+func (err *ValidationError) add(format string, args ...interface{}) {
+	err.Problems = append(err.Problems, fmt.Sprintf(format, args...))
+}
+
+// Error lists the problems on separate lines
+func (err *ValidationError) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(err.Problems, "\n  - ")
+}
+
+func newParseError(value string, expected string) error {
+	return fmt.Errorf("cannot parse %s as %s", value, expected)
+}
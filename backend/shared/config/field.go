@@ -0,0 +1,128 @@
+package config
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// field is a configuration value which is mapped to a key with the env tag. The other supported tags are
+// default, required:"true", secret:"true", options:"a,b", deprecated:"OLD_KEY" and usage which is shown by --help.
+type field struct {
+	key    string
+	value  reflect.Value
+	tag    reflect.StructTag
+	raw    string
+	source Source
+
+	// fromDeprecatedKey is true when the value was read from the deprecated key of the field
+	fromDeprecatedKey bool
+}
+
+// collectFields returns the fields of a struct with nested structs flattened in their declaration order
+func collectFields(structValue reflect.Value) (fields []*field) {
+	for i := 0; i < structValue.NumField(); i++ {
+		structField := structValue.Type().Field(i)
+
+		key := structField.Tag.Get("env")
+		if key == "" {
+			if structValue.Field(i).Kind() == reflect.Struct {
+				fields = append(fields, collectFields(structValue.Field(i))...)
+			}
+			continue
+		}
+
+		fields = append(fields, &field{key: key, value: structValue.Field(i), tag: structField.Tag})
+	}
+
+	return fields
+}
+
+// flagName converts the key to the name of its command line flag e.g. MONGODB_URI to mongodb-uri
+func (field *field) flagName() string {
+	return strings.ToLower(strings.ReplaceAll(field.key, "_", "-"))
+}
+
+// deprecatedKey is the old name of the key which is still accepted with a warning
+func (field *field) deprecatedKey() string {
+	return field.tag.Get("deprecated")
+}
+
+func (field *field) isSecret() bool {
+	return field.tag.Get("secret") == "true"
+}
+
+func (field *field) validate(problems *ValidationError) {
+	if field.raw == "" {
+		if field.tag.Get("required") == "true" {
+			problems.add("%s is required", field.key)
+		}
+		return
+	}
+
+	if options := field.tag.Get("options"); options != "" && !contains(strings.Split(options, ","), field.raw) {
+		problems.add("%s must be one of %s but it is %q", field.key, options, field.raw)
+		return
+	}
+
+	err := field.set()
+	if err != nil {
+		problems.add("%s: %s", field.key, err.Error())
+	}
+}
+
+// set parses the raw value into the struct field. Secrets are not included in the error messages.
+func (field *field) set() error {
+	value := strconv.Quote(field.raw)
+	if field.isSecret() {
+		value = "the secret value"
+	}
+
+	if field.value.Type() == reflect.TypeOf(time.Duration(0)) {
+		duration, err := time.ParseDuration(field.raw)
+		if err != nil {
+			return newParseError(value, "a duration e.g. 90s or 1h30m")
+		}
+
+		field.value.SetInt(int64(duration))
+		return nil
+	}
+
+	switch field.value.Kind() {
+	case reflect.String:
+		field.value.SetString(field.raw)
+	case reflect.Bool:
+		result, err := strconv.ParseBool(field.raw)
+		if err != nil {
+			return newParseError(value, "a boolean")
+		}
+		field.value.SetBool(result)
+	case reflect.Int:
+		result, err := strconv.Atoi(field.raw)
+		if err != nil {
+			return newParseError(value, "an integer")
+		}
+		field.value.SetInt(int64(result))
+	case reflect.Float64:
+		result, err := strconv.ParseFloat(field.raw, 64)
+		if err != nil {
+			return newParseError(value, "a number")
+		}
+		field.value.SetFloat(result)
+	default:
+		return newParseError(value, "a "+field.value.Type().String()+" which is not supported")
+	}
+
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, item := range values {
+		if item == value {
+			return true
+		}
+	}
+
+	return false
+}
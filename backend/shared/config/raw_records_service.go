@@ -0,0 +1,70 @@
+package config
+
+import (
+	"time"
+
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/encryption"
+	"github.com/palantir/stacktrace"
+)
+
+// RawRecordsService is the configuration of the raw records service
+type RawRecordsService struct {
+	Server         GRPCServer
+	ServiceAuth    ServiceAuth
+	Logging        Logging
+	Tracing        Tracing
+	MongoDB        MongoDB
+	OvChipkaartAPI OvChipkaartAPI
+	CardSync       CardSync
+	CardEncryption CardEncryption
+
+	HealthCheckInterval time.Duration `env:"HEALTH_CHECK_INTERVAL" default:"10s" usage:"time between two checks of the mongoDB connection"`
+}
+
+// CardEncryption configures the keys which encrypt the ov-chipkaart passwords of the registered cards
+type CardEncryption struct {
+	Keys         string `env:"CARD_ENCRYPTION_KEYS" required:"true" secret:"true" usage:"comma separated key-id:base64 pairs of 32 byte AES keys, old keys are kept to decrypt old passwords"`
+	CurrentKeyID string `env:"CARD_ENCRYPTION_KEY_ID" required:"true" usage:"id of the key which encrypts new passwords"`
+}
+
+// Validate checks that the keys can be decoded and that the current key is one of them
+func (config CardEncryption) Validate() (problems []string) {
+	if config.Keys == "" || config.CurrentKeyID == "" {
+		return problems
+	}
+
+	_, err := config.Keyring()
+	if err != nil {
+		problems = append(problems, "CARD_ENCRYPTION_KEYS is invalid: "+stacktrace.RootCause(err).Error())
+	}
+	return problems
+}
+
+// Keyring creates the keyring which encrypts and decrypts the passwords
+func (config CardEncryption) Keyring() (*encryption.Keyring, error) {
+	keys, err := encryption.ParseKeys(config.Keys)
+	if err != nil {
+		return nil, err
+	}
+
+	return encryption.NewKeyring(keys, config.CurrentKeyID)
+}
+
+// CardSync configures how often the transactions of the registered cards are synced
+type CardSync struct {
+	PollInterval             time.Duration `env:"CARD_SYNC_POLL_INTERVAL" default:"1m" usage:"how often to look for cards which are due"`
+	SyncInterval             time.Duration `env:"CARD_SYNC_INTERVAL" default:"6h" usage:"time between two successful syncs of a card"`
+	AuthenticationBackoff    time.Duration `env:"CARD_SYNC_AUTHENTICATION_BACKOFF" default:"1h" usage:"time to wait after the first authentication failure"`
+	MaxAuthenticationBackoff time.Duration `env:"CARD_SYNC_MAX_AUTHENTICATION_BACKOFF" default:"168h" usage:"maximum time to wait after an authentication failure"`
+}
+
+// Validate checks that the scheduler doesn't spin
+func (config CardSync) Validate() (problems []string) {
+	if config.PollInterval <= 0 {
+		problems = append(problems, "CARD_SYNC_POLL_INTERVAL must be positive")
+	}
+	if config.MaxAuthenticationBackoff < config.AuthenticationBackoff {
+		problems = append(problems, "CARD_SYNC_MAX_AUTHENTICATION_BACKOFF must not be shorter than CARD_SYNC_AUTHENTICATION_BACKOFF")
+	}
+	return problems
+}
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// Logging configures the lines which are written to stdout
+type Logging struct {
+	Level string `env:"LOG_LEVEL" default:"info" options:"debug,info,warn,error" usage:"minimum level of the log lines"`
+}
+
+// Tracing configures the export of spans to an OTLP collector
+type Tracing struct {
+	Endpoint    string  `env:"OTEL_EXPORTER_OTLP_ENDPOINT" usage:"address of the OTLP collector, tracing is disabled when empty"`
+	Insecure    bool    `env:"OTEL_EXPORTER_OTLP_INSECURE" default:"false" usage:"connect to the collector without TLS"`
+	SampleRatio float64 `env:"TRACING_SAMPLE_RATIO" default:"1" usage:"fraction of the traces which are sampled"`
+}
+
+// Validate checks that the sample ratio is a fraction
+func (config Tracing) Validate() (problems []string) {
+	if config.SampleRatio < 0 || config.SampleRatio > 1 {
+		problems = append(problems, fmt.Sprintf("TRACING_SAMPLE_RATIO must be between 0 and 1 but it is %g", config.SampleRatio))
+	}
+	return problems
+}
+
+// MongoDB configures the connection to the database
+type MongoDB struct {
+	URI    string `env:"MONGODB_URI" required:"true" secret:"true" usage:"connection string of the mongoDB server"`
+	DBName string `env:"MONGODB_DB_NAME" required:"true" usage:"name of the database"`
+}
+
+// OvChipkaartAPI contains the credentials of the ov-chipkaart API
+type OvChipkaartAPI struct {
+	ClientID     string `env:"OV_CHIPKAART_API_CLIENT_ID" required:"true" usage:"client id of the ov-chipkaart API"`
+	ClientSecret string `env:"OV_CHIPKAART_API_CLIENT_SECRET" required:"true" secret:"true" usage:"client secret of the ov-chipkaart API"`
+}
+
+// GRPCServer configures the listeners of a gRPC service
+type GRPCServer struct {
+	Address         string        `env:"SERVER_ADDRESS" required:"true" usage:"address of the gRPC server e.g. :50051"`
+	MetricsAddress  string        `env:"METRICS_ADDRESS" usage:"address of the prometheus metrics server, disabled when empty"`
+	ShutdownTimeout time.Duration `env:"SHUTDOWN_TIMEOUT" default:"30s" usage:"time to complete the in-flight requests after a signal"`
+}
+
+// ServiceAuth configures mutual TLS and the tokens which the services use to authenticate to each other.
+// Every service presents its certificate so that a service token is only accepted from the service which it names.
+type ServiceAuth struct {
+	Insecure    bool   `env:"GRPC_INSECURE" default:"false" usage:"use plaintext gRPC connections, only for local development"`
+	CertFile    string `env:"GRPC_TLS_CERT_FILE" usage:"PEM encoded certificate of this service whose common name is the name of the service"`
+	KeyFile     string `env:"GRPC_TLS_KEY_FILE" usage:"PEM encoded private key of the certificate"`
+	CAFile      string `env:"GRPC_TLS_CA_FILE" usage:"PEM encoded CA which signs the certificates of the services"`
+	TokenSecret string `env:"SERVICE_TOKEN_SECRET" required:"true" secret:"true" usage:"shared secret which signs the service tokens"`
+}
+
+// Validate checks that the certificates of mutual TLS are configured unless TLS is disabled
+func (config ServiceAuth) Validate() (problems []string) {
+	if config.TokenSecret != "" && len(config.TokenSecret) < 32 {
+		problems = append(problems, "SERVICE_TOKEN_SECRET must be at least 32 characters long")
+	}
+
+	if config.Insecure {
+		return problems
+	}
+
+	if config.CertFile == "" || config.KeyFile == "" || config.CAFile == "" {
+		problems = append(problems, "GRPC_TLS_CERT_FILE, GRPC_TLS_KEY_FILE and GRPC_TLS_CA_FILE are required unless GRPC_INSECURE is true")
+	}
+	return problems
+}
@@ -0,0 +1,10 @@
+package config
+
+// TransactionsService is the configuration of the transactions service
+type TransactionsService struct {
+	Server         GRPCServer
+	ServiceAuth    ServiceAuth
+	Logging        Logging
+	Tracing        Tracing
+	OvChipkaartAPI OvChipkaartAPI
+}
@@ -6,4 +6,10 @@ type Key string
 const (
 	// KeyAnalyzeRequestID represents the analyze request id
 	KeyAnalyzeRequestID = Key("analyze_request_id")
+
+	// KeyRequestID represents the id of the HTTP request which started a trace
+	KeyRequestID = Key("request_id")
+
+	// KeyUserID represents the id of the user who made a request
+	KeyUserID = Key("user_id")
 )
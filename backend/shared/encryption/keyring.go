@@ -0,0 +1,127 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+	"strings"
+
+	"github.com/palantir/stacktrace"
+)
+
+const (
+	// keySize is the size of an AES-256 key in bytes
+	keySize = 32
+
+	// keyIDSeparator separates the key id from the encrypted value and the keys in the configuration
+	keyIDSeparator = ":"
+)
+
+// Keyring encrypts values with AES-GCM. Every encrypted value starts with the id of its key so that
+// the key which encrypts new values can be rotated while the old values can still be decrypted.
+type Keyring struct {
+	ciphers      map[string]cipher.AEAD
+	currentKeyID string
+}
+
+// NewKeyring creates a keyring which encrypts new values with the key of currentKeyID
+func NewKeyring(keys map[string][]byte, currentKeyID string) (*Keyring, error) {
+	keyring := &Keyring{ciphers: make(map[string]cipher.AEAD, len(keys)), currentKeyID: currentKeyID}
+
+	for keyID, key := range keys {
+		if keyID == "" || strings.Contains(keyID, keyIDSeparator) {
+			return nil, stacktrace.NewError("the key id %q must not be empty or contain %q", keyID, keyIDSeparator)
+		}
+
+		if len(key) != keySize {
+			return nil, stacktrace.NewError("the key %s must be %d bytes long but it is %d bytes long", keyID, keySize, len(key))
+		}
+
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "cannot create the cipher of key %s", keyID)
+		}
+
+		keyring.ciphers[keyID], err = cipher.NewGCM(block)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "cannot create the GCM cipher of key %s", keyID)
+		}
+	}
+
+	if _, ok := keyring.ciphers[currentKeyID]; !ok {
+		return nil, stacktrace.NewError("the current key %q is not one of the keys", currentKeyID)
+	}
+
+	return keyring, nil
+}
+
+// ParseKeys decodes a comma separated list of key-id:base64-key pairs
+func ParseKeys(value string) (map[string][]byte, error) {
+	keys := map[string][]byte{}
+	for _, pair := range strings.Split(value, ",") {
+		parts := strings.SplitN(strings.TrimSpace(pair), keyIDSeparator, 2)
+		if len(parts) != 2 {
+			return nil, stacktrace.NewError("every key must have the format key-id:base64-key")
+		}
+
+		key, err := base64.StdEncoding.DecodeString(parts[1])
+		if err != nil {
+			// the error is not propagated because it contains the key
+			return nil, stacktrace.NewError("the key %s is not base64 encoded", parts[0])
+		}
+
+		if _, ok := keys[parts[0]]; ok {
+			return nil, stacktrace.NewError("the key %s is defined more than once", parts[0])
+		}
+
+		keys[parts[0]] = key
+	}
+
+	return keys, nil
+}
+
+// Encrypt encrypts a value with the current key. The associated data e.g. the id of the entity is authenticated
+// but not stored, the same associated data must be used to decrypt the value.
+func (keyring *Keyring) Encrypt(plaintext string, associatedData string) (string, error) {
+	aead := keyring.ciphers[keyring.currentKeyID]
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", stacktrace.Propagate(err, "cannot generate a nonce")
+	}
+
+	ciphertext := aead.Seal(nonce, nonce, []byte(plaintext), []byte(associatedData))
+
+	return keyring.currentKeyID + keyIDSeparator + base64.RawStdEncoding.EncodeToString(ciphertext), nil
+}
+
+// Decrypt decrypts a value which was encrypted with any of the keys of the keyring
+func (keyring *Keyring) Decrypt(value string, associatedData string) (string, error) {
+	parts := strings.SplitN(value, keyIDSeparator, 2)
+	if len(parts) != 2 {
+		return "", stacktrace.NewError("the encrypted value doesn't start with a key id")
+	}
+
+	aead, ok := keyring.ciphers[parts[0]]
+	if !ok {
+		return "", stacktrace.NewError("the key %s which encrypted the value is not in the keyring", parts[0])
+	}
+
+	ciphertext, err := base64.RawStdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", stacktrace.Propagate(err, "cannot decode the encrypted value")
+	}
+
+	if len(ciphertext) < aead.NonceSize() {
+		return "", stacktrace.NewError("the encrypted value is too short")
+	}
+
+	plaintext, err := aead.Open(nil, ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():], []byte(associatedData))
+	if err != nil {
+		return "", stacktrace.Propagate(err, "cannot decrypt the value with key %s", parts[0])
+	}
+
+	return string(plaintext), nil
+}
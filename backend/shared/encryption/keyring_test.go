@@ -0,0 +1,223 @@
+package encryption
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+var (
+	testKey1 = bytes.Repeat([]byte{1}, keySize)
+	testKey2 = bytes.Repeat([]byte{2}, keySize)
+)
+
+func TestParseKeys(t *testing.T) {
+	encoded1 := base64.StdEncoding.EncodeToString(testKey1)
+	encoded2 := base64.StdEncoding.EncodeToString(testKey2)
+
+	tests := []struct {
+		name          string
+		value         string
+		expected      map[string][]byte
+		expectedError string
+	}{
+		{
+			name:     "single key",
+			value:    "2020-10:" + encoded1,
+			expected: map[string][]byte{"2020-10": testKey1},
+		},
+		{
+			name:     "several keys with spaces",
+			value:    "2020-10:" + encoded1 + ", 2020-11:" + encoded2,
+			expected: map[string][]byte{"2020-10": testKey1, "2020-11": testKey2},
+		},
+		{
+			name:          "key without an id",
+			value:         encoded1,
+			expectedError: "every key must have the format key-id:base64-key",
+		},
+		{
+			name:          "key which is not base64 encoded",
+			value:         "2020-10:not-base64!",
+			expectedError: "the key 2020-10 is not base64 encoded",
+		},
+		{
+			name:          "key which is defined twice",
+			value:         "2020-10:" + encoded1 + ",2020-10:" + encoded2,
+			expectedError: "the key 2020-10 is defined more than once",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			keys, err := ParseKeys(test.value)
+			if test.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), test.expectedError) {
+					t.Fatalf("ParseKeys returned the error %v, expected %q", err, test.expectedError)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("ParseKeys returned an error: %s", err)
+			}
+
+			if len(keys) != len(test.expected) {
+				t.Fatalf("ParseKeys returned %d keys, expected %d", len(keys), len(test.expected))
+			}
+
+			for keyID, key := range test.expected {
+				if !bytes.Equal(keys[keyID], key) {
+					t.Errorf("ParseKeys returned %x for key %s, expected %x", keys[keyID], keyID, key)
+				}
+			}
+		})
+	}
+}
+
+func TestNewKeyring(t *testing.T) {
+	tests := []struct {
+		name          string
+		keys          map[string][]byte
+		currentKeyID  string
+		expectedError string
+	}{
+		{
+			name:         "valid keys",
+			keys:         map[string][]byte{"1": testKey1, "2": testKey2},
+			currentKeyID: "2",
+		},
+		{
+			name:          "current key which doesn't exist",
+			keys:          map[string][]byte{"1": testKey1},
+			currentKeyID:  "2",
+			expectedError: `the current key "2" is not one of the keys`,
+		},
+		{
+			name:          "key which is too short",
+			keys:          map[string][]byte{"1": testKey1[:16]},
+			currentKeyID:  "1",
+			expectedError: "the key 1 must be 32 bytes long but it is 16 bytes long",
+		},
+		{
+			name:          "empty key id",
+			keys:          map[string][]byte{"": testKey1},
+			currentKeyID:  "",
+			expectedError: `the key id "" must not be empty`,
+		},
+		{
+			name:          "key id with the separator",
+			keys:          map[string][]byte{"1:2": testKey1},
+			currentKeyID:  "1:2",
+			expectedError: `the key id "1:2" must not be empty or contain ":"`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := NewKeyring(test.keys, test.currentKeyID)
+			if test.expectedError == "" && err != nil {
+				t.Fatalf("NewKeyring returned an error: %s", err)
+			}
+
+			if test.expectedError != "" && (err == nil || !strings.Contains(err.Error(), test.expectedError)) {
+				t.Fatalf("NewKeyring returned the error %v, expected %q", err, test.expectedError)
+			}
+		})
+	}
+}
+
+func TestKeyringDecrypt(t *testing.T) {
+	oldKeyring, err := NewKeyring(map[string][]byte{"1": testKey1}, "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the second key is rotated in while values which were encrypted with the first key can still be decrypted
+	keyring, err := NewKeyring(map[string][]byte{"1": testKey1, "2": testKey2}, "2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	oldValue, err := oldKeyring.Encrypt("old password", "user-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	value, err := keyring.Encrypt("password", "user-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(value, "2"+keyIDSeparator) {
+		t.Fatalf("Encrypt returned %s, expected it to start with the current key id", value)
+	}
+
+	// tampered flips a bit of the ciphertext without breaking the encoding
+	ciphertext, err := base64.RawStdEncoding.DecodeString(strings.TrimPrefix(value, "2"+keyIDSeparator))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ciphertext[len(ciphertext)-1] ^= 1
+	tampered := "2" + keyIDSeparator + base64.RawStdEncoding.EncodeToString(ciphertext)
+
+	tests := []struct {
+		name           string
+		keyring        *Keyring
+		value          string
+		associatedData string
+		expected       string
+		expectedError  string
+	}{
+		{name: "value of the current key", keyring: keyring, value: value, associatedData: "user-1", expected: "password"},
+		{name: "value of a rotated key", keyring: keyring, value: oldValue, associatedData: "user-1", expected: "old password"},
+		{name: "value of a key which is not in the keyring", keyring: oldKeyring, value: value, associatedData: "user-1", expectedError: "the key 2 which encrypted the value is not in the keyring"},
+		{name: "other associated data", keyring: keyring, value: value, associatedData: "user-2", expectedError: "cannot decrypt the value with key 2"},
+		{name: "tampered value", keyring: keyring, value: tampered, associatedData: "user-1", expectedError: "cannot decrypt the value with key 2"},
+		{name: "value without a key id", keyring: keyring, value: "password", associatedData: "user-1", expectedError: "the encrypted value doesn't start with a key id"},
+		{name: "value which is too short", keyring: keyring, value: "2:AAAA", associatedData: "user-1", expectedError: "the encrypted value is too short"},
+		{name: "value which is not base64 encoded", keyring: keyring, value: "2:not-base64!", associatedData: "user-1", expectedError: "cannot decode the encrypted value"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			plaintext, err := test.keyring.Decrypt(test.value, test.associatedData)
+			if test.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), test.expectedError) {
+					t.Fatalf("Decrypt returned the error %v, expected %q", err, test.expectedError)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Decrypt returned an error: %s", err)
+			}
+
+			if plaintext != test.expected {
+				t.Errorf("Decrypt returned %q, expected %q", plaintext, test.expected)
+			}
+		})
+	}
+}
+
+func TestKeyringEncryptUsesNewNonces(t *testing.T) {
+	keyring, err := NewKeyring(map[string][]byte{"1": testKey1}, "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := keyring.Encrypt("password", "user-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := keyring.Encrypt("password", "user-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if first == second {
+		t.Errorf("Encrypt returned %s twice for the same value", first)
+	}
+}
@@ -2,22 +2,25 @@ package errorhandler
 
 import (
 	"context"
-	"log"
 	"time"
 
-	"github.com/davecgh/go-spew/spew"
-	"github.com/pkg/errors"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/logger"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/redact"
 
 	"github.com/getsentry/sentry-go"
 )
 
 // SentryErrorHandler implements and error handler with Sentry
 type SentryErrorHandler struct {
-	hub *sentry.Hub
+	hub    *sentry.Hub
+	logger logger.Logger
 }
 
-// NewSentryErrorHandler creates a new sentry error handler
-func NewSentryErrorHandler(options sentry.ClientOptions) (ErrorHandler, error) {
+// NewSentryErrorHandler creates a new sentry error handler.
+// Events are redacted before they are sent because error messages can contain credentials or personal data.
+func NewSentryErrorHandler(options sentry.ClientOptions, logger logger.Logger) (ErrorHandler, error) {
+	options.BeforeSend = redactEvent
+
 	err := sentry.Init(options)
 	if err != nil {
 		return nil, err
@@ -27,11 +30,43 @@ func NewSentryErrorHandler(options sentry.ClientOptions) (ErrorHandler, error) {
 	// Set the timeout to the maximum duration the program can afford to wait.
 	defer sentry.Flush(2 * time.Second)
 
-	return &SentryErrorHandler{hub: sentry.CurrentHub()}, nil
+	return &SentryErrorHandler{hub: sentry.CurrentHub(), logger: logger}, nil
+}
+
+// CaptureError captures an error together with the correlation ids of the context
+func (handler *SentryErrorHandler) CaptureError(ctx context.Context, err error) {
+	handler.logger.Error(ctx, "captured error", "err", err)
+
+	hub := handler.hub.Clone()
+	hub.ConfigureScope(func(scope *sentry.Scope) {
+		ids := logger.CorrelationIDs(ctx)
+		scope.SetTags(ids)
+		if userID, ok := ids["user_id"]; ok {
+			scope.SetUser(sentry.User{ID: userID})
+		}
+	})
+	hub.CaptureException(err)
 }
 
-// CaptureError captures an error
-func (sentry *SentryErrorHandler) CaptureError(ctx context.Context, err error) {
-	log.Println(err)
-	sentry.hub.CaptureException(errors.Wrapf(err, spew.Sdump(ctx)))
+func redactEvent(event *sentry.Event, _ *sentry.EventHint) *sentry.Event {
+	event.Message = redact.String(event.Message)
+
+	for index := range event.Exception {
+		event.Exception[index].Value = redact.String(event.Exception[index].Value)
+	}
+
+	for _, breadcrumb := range event.Breadcrumbs {
+		breadcrumb.Message = redact.String(breadcrumb.Message)
+	}
+
+	if event.Request != nil {
+		event.Request.Data = redact.String(event.Request.Data)
+		event.Request.QueryString = redact.String(event.Request.QueryString)
+		event.Request.Cookies = ""
+		for key, value := range event.Request.Headers {
+			event.Request.Headers[key] = redact.Value(key, value).(string)
+		}
+	}
+
+	return event
 }
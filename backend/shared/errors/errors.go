@@ -20,6 +20,9 @@ var (
 
 	// ErrCodeInvalidRawRecordSource when the raw record source is invalid
 	ErrCodeInvalidRawRecordSource = stacktrace.ErrorCode(6)
+
+	// ErrCodeDuplicateEntity is thrown when an entity violates a unique index
+	ErrCodeDuplicateEntity = stacktrace.ErrorCode(7)
 )
 
 var (
@@ -0,0 +1,84 @@
+package httpclient
+
+import (
+	"sync"
+	"time"
+)
+
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+// circuitBreaker stops sending requests to a host after consecutive failures so that a struggling API
+// can recover and callers don't wait for timeouts
+type circuitBreaker struct {
+	mutex            sync.Mutex
+	state            breakerState
+	failures         int
+	openedAt         time.Time
+	failureThreshold int
+	openDuration     time.Duration
+}
+
+func newCircuitBreaker(failureThreshold int, openDuration time.Duration) *circuitBreaker {
+	return &circuitBreaker{failureThreshold: failureThreshold, openDuration: openDuration}
+}
+
+// allow returns false while the breaker is open. After the open duration a single request is allowed to probe the host.
+func (breaker *circuitBreaker) allow(now time.Time) bool {
+	breaker.mutex.Lock()
+	defer breaker.mutex.Unlock()
+
+	switch breaker.state {
+	case breakerOpen:
+		if now.Sub(breaker.openedAt) < breaker.openDuration {
+			return false
+		}
+		breaker.state = breakerHalfOpen
+		return true
+	case breakerHalfOpen:
+		return false
+	default:
+		return true
+	}
+}
+
+// record updates the breaker with the outcome of a request and returns true when the breaker is open
+func (breaker *circuitBreaker) record(failed bool, now time.Time) bool {
+	breaker.mutex.Lock()
+	defer breaker.mutex.Unlock()
+
+	if !failed {
+		breaker.state = breakerClosed
+		breaker.failures = 0
+		return false
+	}
+
+	// a negative threshold disables the breaker
+	if breaker.failureThreshold < 0 {
+		return false
+	}
+
+	breaker.failures++
+	if breaker.state == breakerHalfOpen || breaker.failures >= breaker.failureThreshold {
+		breaker.state = breakerOpen
+		breaker.openedAt = now
+	}
+
+	return breaker.state == breakerOpen
+}
+
+// release lets the next request probe the host when the probe was cancelled by its caller
+func (breaker *circuitBreaker) release() {
+	breaker.mutex.Lock()
+	defer breaker.mutex.Unlock()
+
+	if breaker.state == breakerHalfOpen {
+		breaker.state = breakerOpen
+		breaker.openedAt = time.Time{}
+	}
+}
@@ -0,0 +1,221 @@
+package httpclient
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/metrics"
+	"github.com/palantir/stacktrace"
+)
+
+const (
+	// ErrCodeCircuitOpen is returned without sending the request while the circuit breaker of the host is open
+	ErrCodeCircuitOpen = stacktrace.ErrorCode(503)
+
+	// ErrCodeResponseTooLarge is returned when reading a response body which is larger than MaxResponseBytes
+	ErrCodeResponseTooLarge = stacktrace.ErrorCode(413)
+)
+
+// HTTPClient performs http requests
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Client retries failed requests with a jittered exponential backoff, fails fast while a host is down and limits
+// the time and size of every response. The caller must close the body of the returned response.
+type Client struct {
+	client  HTTPClient
+	options Options
+
+	mutex    sync.Mutex
+	breakers map[string]*circuitBreaker
+	random   *rand.Rand
+}
+
+// New wraps a client. The wrapped client is called for every attempt so it should record the metrics and spans.
+func New(client HTTPClient, options Options) *Client {
+	return &Client{
+		client:   client,
+		options:  options.withDefaults(),
+		breakers: map[string]*circuitBreaker{},
+		random:   rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// Do sends the request until it succeeds, the retries are exhausted or the request cannot be retried.
+// 5xx and 429 responses are returned without an error after the last attempt so that callers can read them.
+func (client *Client) Do(request *http.Request) (*http.Response, error) {
+	breaker := client.breaker(request.URL.Host)
+	timeout := client.timeout(request)
+
+	for attempt := 0; ; attempt++ {
+		if !breaker.allow(time.Now()) {
+			return nil, stacktrace.NewErrorWithCode(ErrCodeCircuitOpen, "the circuit breaker of %s is open for %s", client.options.Name, request.URL.Host)
+		}
+
+		response, err := client.send(request, attempt, timeout)
+
+		if request.Context().Err() != nil {
+			// the caller gave up so the outcome doesn't say anything about the health of the host
+			breaker.release()
+		} else {
+			open := breaker.record(err != nil || response.StatusCode >= http.StatusInternalServerError, time.Now())
+			metrics.SetCircuitBreakerOpen(client.options.Name, request.URL.Host, open)
+		}
+
+		if attempt >= client.options.MaxRetries || !client.isRetryable(request, response, err) {
+			return response, err
+		}
+
+		wait, ok := client.backoff(attempt, response)
+		if !ok {
+			return response, err
+		}
+
+		if response != nil {
+			drain(response.Body)
+		}
+
+		metrics.ObserveRetry(client.options.Name)
+
+		select {
+		case <-request.Context().Done():
+			return nil, stacktrace.Propagate(request.Context().Err(), "cannot retry %s request for %s", request.Method, request.URL.Redacted())
+		case <-time.After(wait):
+		}
+	}
+}
+
+// send performs a single attempt with its own timeout which lasts until the body is closed
+func (client *Client) send(request *http.Request, attempt int, timeout time.Duration) (*http.Response, error) {
+	ctx, cancel := context.WithTimeout(request.Context(), timeout)
+	attemptRequest := request.Clone(ctx)
+
+	if attempt > 0 && request.GetBody != nil {
+		body, err := request.GetBody()
+		if err != nil {
+			cancel()
+			return nil, stacktrace.Propagate(err, "cannot rewind the body of the %s request for %s", request.Method, request.URL.Redacted())
+		}
+		attemptRequest.Body = body
+	}
+
+	response, err := client.client.Do(attemptRequest)
+	if err != nil {
+		cancel()
+		return nil, stacktrace.Propagate(err, "cannot execute %s request for %s", request.Method, request.URL.Redacted())
+	}
+
+	response.Body = newResponseBody(response.Body, client.options.MaxResponseBytes, cancel)
+	return response, nil
+}
+
+// isRetryable checks the outcome of an attempt and whether the request can be sent again
+func (client *Client) isRetryable(request *http.Request, response *http.Response, err error) bool {
+	if request.Body != nil && request.Body != http.NoBody && request.GetBody == nil {
+		return false
+	}
+
+	isIdempotent := request.Method == http.MethodGet || request.Method == http.MethodHead || request.Method == http.MethodOptions
+	if !isIdempotent && !client.retriesNonIdempotent(request) {
+		return false
+	}
+
+	if err != nil {
+		return true
+	}
+
+	return response.StatusCode == http.StatusTooManyRequests ||
+		(response.StatusCode >= http.StatusInternalServerError && response.StatusCode != http.StatusNotImplemented)
+}
+
+// backoff returns the time to wait before the next attempt. A Retry-After header overrides the backoff
+// unless it is longer than MaxRetryAfter in which case the response is returned to the caller.
+func (client *Client) backoff(attempt int, response *http.Response) (time.Duration, bool) {
+	if response != nil {
+		if retryAfter, ok := parseRetryAfter(response.Header.Get("Retry-After"), time.Now()); ok {
+			return retryAfter, retryAfter <= client.options.MaxRetryAfter
+		}
+	}
+
+	maximum := client.options.BaseBackoff
+	for i := 0; i < attempt && maximum < client.options.MaxBackoff; i++ {
+		maximum *= 2
+	}
+
+	if maximum > client.options.MaxBackoff {
+		maximum = client.options.MaxBackoff
+	}
+
+	client.mutex.Lock()
+	defer client.mutex.Unlock()
+
+	return time.Duration(client.random.Int63n(int64(maximum) + 1)), true
+}
+
+// retriesNonIdempotent checks if the URL starts with one of the endpoints whose POST and PATCH requests are queries
+func (client *Client) retriesNonIdempotent(request *http.Request) bool {
+	endpoint := client.endpoint(request)
+	for _, prefix := range client.options.NonIdempotentRetryEndpoints {
+		if strings.HasPrefix(endpoint, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// timeout returns the timeout of the longest endpoint prefix which matches the URL
+func (client *Client) timeout(request *http.Request) time.Duration {
+	endpoint := client.endpoint(request)
+
+	timeout, matched := client.options.Timeout, ""
+	for prefix, endpointTimeout := range client.options.EndpointTimeouts {
+		if strings.HasPrefix(endpoint, prefix) && len(prefix) > len(matched) {
+			timeout, matched = endpointTimeout, prefix
+		}
+	}
+
+	return timeout
+}
+
+// endpoint is the URL of the request without the query which is matched against the endpoint prefixes of the options
+func (client *Client) endpoint(request *http.Request) string {
+	return request.URL.Scheme + "://" + request.URL.Host + request.URL.Path
+}
+
+func (client *Client) breaker(host string) *circuitBreaker {
+	client.mutex.Lock()
+	defer client.mutex.Unlock()
+
+	if _, ok := client.breakers[host]; !ok {
+		client.breakers[host] = newCircuitBreaker(client.options.FailureThreshold, client.options.OpenDuration)
+	}
+
+	return client.breakers[host]
+}
+
+// parseRetryAfter supports both the number of seconds and the HTTP date format of the Retry-After header
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		if wait := date.Sub(now); wait > 0 {
+			return wait, true
+		}
+		return 0, true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,313 @@
+package httpclient
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+type doFunc func(request *http.Request) (*http.Response, error)
+
+func (do doFunc) Do(request *http.Request) (*http.Response, error) {
+	return do(request)
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		value    string
+		expected time.Duration
+		ok       bool
+	}{
+		{name: "empty", value: "", expected: 0, ok: false},
+		{name: "seconds", value: "5", expected: 5 * time.Second, ok: true},
+		{name: "zero seconds", value: "0", expected: 0, ok: true},
+		{name: "negative seconds", value: "-1", expected: 0, ok: false},
+		{name: "invalid", value: "soon", expected: 0, ok: false},
+		{name: "date in the future", value: now.Add(90 * time.Second).Format(http.TimeFormat), expected: 90 * time.Second, ok: true},
+		{name: "date in the past", value: now.Add(-time.Minute).Format(http.TimeFormat), expected: 0, ok: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			wait, ok := parseRetryAfter(test.value, now)
+			if wait != test.expected || ok != test.ok {
+				t.Errorf("parseRetryAfter(%q) = (%s, %t), expected (%s, %t)", test.value, wait, ok, test.expected, test.ok)
+			}
+		})
+	}
+}
+
+func TestClientBackoff(t *testing.T) {
+	client := New(nil, Options{BaseBackoff: 100 * time.Millisecond, MaxBackoff: time.Second, MaxRetryAfter: 30 * time.Second})
+
+	tests := []struct {
+		name       string
+		attempt    int
+		retryAfter string
+		minimum    time.Duration
+		maximum    time.Duration
+		ok         bool
+	}{
+		{name: "first retry", attempt: 0, minimum: 0, maximum: 100 * time.Millisecond, ok: true},
+		{name: "doubles after every retry", attempt: 2, minimum: 0, maximum: 400 * time.Millisecond, ok: true},
+		{name: "capped at the maximum", attempt: 10, minimum: 0, maximum: time.Second, ok: true},
+		{name: "retry after overrides the backoff", attempt: 0, retryAfter: "3", minimum: 3 * time.Second, maximum: 3 * time.Second, ok: true},
+		{name: "retry after longer than the maximum", attempt: 0, retryAfter: "60", minimum: time.Minute, maximum: time.Minute, ok: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			response := &http.Response{Header: http.Header{}}
+			if test.retryAfter != "" {
+				response.Header.Set("Retry-After", test.retryAfter)
+			}
+
+			// the backoff is random so it is sampled a few times
+			for i := 0; i < 50; i++ {
+				wait, ok := client.backoff(test.attempt, response)
+				if wait < test.minimum || wait > test.maximum || ok != test.ok {
+					t.Fatalf("backoff(%d) = (%s, %t), expected between %s and %s and %t", test.attempt, wait, ok, test.minimum, test.maximum, test.ok)
+				}
+			}
+		})
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	start := time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	type event struct {
+		// failed is recorded unless allow is checked
+		failed   bool
+		allow    bool
+		after    time.Duration
+		expected bool
+	}
+
+	tests := []struct {
+		name      string
+		threshold int
+		events    []event
+	}{
+		{
+			name:      "opens after consecutive failures",
+			threshold: 2,
+			events: []event{
+				{failed: true, expected: false},
+				{failed: true, expected: true},
+				{allow: true, after: time.Second, expected: false},
+			},
+		},
+		{
+			name:      "a success resets the failures",
+			threshold: 2,
+			events: []event{
+				{failed: true, expected: false},
+				{failed: false, expected: false},
+				{failed: true, expected: false},
+				{allow: true, expected: true},
+			},
+		},
+		{
+			name:      "a single probe is allowed after the open duration",
+			threshold: 1,
+			events: []event{
+				{failed: true, expected: true},
+				{allow: true, after: time.Minute, expected: true},
+				{allow: true, after: time.Minute, expected: false},
+				{failed: false, after: time.Minute, expected: false},
+				{allow: true, after: time.Minute, expected: true},
+			},
+		},
+		{
+			name:      "a failed probe opens the breaker again",
+			threshold: 3,
+			events: []event{
+				{failed: true, expected: false},
+				{failed: true, expected: false},
+				{failed: true, expected: true},
+				{allow: true, after: time.Minute, expected: true},
+				{failed: true, after: time.Minute, expected: true},
+				{allow: true, after: time.Minute + time.Second, expected: false},
+			},
+		},
+		{
+			name:      "a disabled breaker never opens",
+			threshold: Disabled,
+			events: []event{
+				{failed: true, expected: false},
+				{failed: true, expected: false},
+				{failed: true, expected: false},
+				{allow: true, expected: true},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			breaker := newCircuitBreaker(test.threshold, 30*time.Second)
+
+			for index, event := range test.events {
+				var result bool
+				if event.allow {
+					result = breaker.allow(start.Add(event.after))
+				} else {
+					result = breaker.record(event.failed, start.Add(event.after))
+				}
+
+				if result != event.expected {
+					t.Fatalf("event %d returned %t, expected %t", index, result, event.expected)
+				}
+			}
+		})
+	}
+}
+
+func TestClientRetries(t *testing.T) {
+	tests := []struct {
+		name             string
+		options          Options
+		method           string
+		url              string
+		expectedAttempts int
+	}{
+		{
+			name:             "the default retries",
+			options:          Options{},
+			method:           http.MethodGet,
+			url:              "https://api.example.com/v1/stations",
+			expectedAttempts: 1 + defaultMaxRetries,
+		},
+		{
+			name:             "retries can be disabled",
+			options:          Options{MaxRetries: Disabled},
+			method:           http.MethodGet,
+			url:              "https://api.example.com/v1/stations",
+			expectedAttempts: 1,
+		},
+		{
+			name:             "a POST request is not retried",
+			options:          Options{NonIdempotentRetryEndpoints: []string{"https://api.example.com/v1/transactions"}},
+			method:           http.MethodPost,
+			url:              "https://api.example.com/v1/token",
+			expectedAttempts: 1,
+		},
+		{
+			name:             "a POST request to a query endpoint is retried",
+			options:          Options{MaxRetries: 1, NonIdempotentRetryEndpoints: []string{"https://api.example.com/v1/transactions"}},
+			method:           http.MethodPost,
+			url:              "https://api.example.com/v1/transactions?offset=10",
+			expectedAttempts: 2,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			attempts := 0
+			test.options.BaseBackoff = Disabled
+			client := New(doFunc(func(request *http.Request) (*http.Response, error) {
+				attempts++
+				return &http.Response{StatusCode: http.StatusServiceUnavailable, Header: http.Header{}, Body: ioutil.NopCloser(strings.NewReader(""))}, nil
+			}), test.options)
+
+			request, err := http.NewRequest(test.method, test.url, strings.NewReader("query=1"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			response, err := client.Do(request)
+			if err != nil {
+				t.Fatalf("Do returned an error: %s", err)
+			}
+			_ = response.Body.Close()
+
+			if response.StatusCode != http.StatusServiceUnavailable {
+				t.Errorf("expected the last response to be returned but the status is %d", response.StatusCode)
+			}
+
+			if attempts != test.expectedAttempts {
+				t.Errorf("sent %d attempts, expected %d", attempts, test.expectedAttempts)
+			}
+		})
+	}
+}
+
+func TestOptionsWithDefaults(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  Options
+		expected Options
+	}{
+		{
+			name:    "zero values are replaced by the defaults",
+			options: Options{},
+			expected: Options{
+				Timeout:          defaultTimeout,
+				MaxRetries:       defaultMaxRetries,
+				BaseBackoff:      defaultBaseBackoff,
+				MaxBackoff:       defaultMaxBackoff,
+				MaxRetryAfter:    defaultMaxRetryAfter,
+				MaxResponseBytes: defaultMaxResponseBytes,
+				FailureThreshold: defaultFailureThreshold,
+				OpenDuration:     defaultOpenDuration,
+			},
+		},
+		{
+			name: "disabled values become zero",
+			options: Options{
+				MaxRetries:       Disabled,
+				BaseBackoff:      Disabled,
+				MaxBackoff:       Disabled,
+				MaxRetryAfter:    Disabled,
+				FailureThreshold: Disabled,
+			},
+			expected: Options{
+				Timeout:          defaultTimeout,
+				MaxRetries:       0,
+				BaseBackoff:      0,
+				MaxBackoff:       0,
+				MaxRetryAfter:    0,
+				MaxResponseBytes: defaultMaxResponseBytes,
+				FailureThreshold: Disabled,
+				OpenDuration:     defaultOpenDuration,
+			},
+		},
+		{
+			name:    "other values are kept",
+			options: Options{Timeout: time.Minute, MaxRetries: 5, BaseBackoff: time.Second, MaxResponseBytes: 1024},
+			expected: Options{
+				Timeout:          time.Minute,
+				MaxRetries:       5,
+				BaseBackoff:      time.Second,
+				MaxBackoff:       defaultMaxBackoff,
+				MaxRetryAfter:    defaultMaxRetryAfter,
+				MaxResponseBytes: 1024,
+				FailureThreshold: defaultFailureThreshold,
+				OpenDuration:     defaultOpenDuration,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := test.options.withDefaults()
+			result.EndpointTimeouts, result.NonIdempotentRetryEndpoints = nil, nil
+
+			if result.Timeout != test.expected.Timeout ||
+				result.MaxRetries != test.expected.MaxRetries ||
+				result.BaseBackoff != test.expected.BaseBackoff ||
+				result.MaxBackoff != test.expected.MaxBackoff ||
+				result.MaxRetryAfter != test.expected.MaxRetryAfter ||
+				result.MaxResponseBytes != test.expected.MaxResponseBytes ||
+				result.FailureThreshold != test.expected.FailureThreshold ||
+				result.OpenDuration != test.expected.OpenDuration {
+				t.Errorf("withDefaults() = %+v, expected %+v", result, test.expected)
+			}
+		})
+	}
+}
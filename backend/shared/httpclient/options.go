@@ -0,0 +1,102 @@
+package httpclient
+
+import (
+	"time"
+)
+
+const (
+	defaultTimeout          = 10 * time.Second
+	defaultMaxRetries       = 2
+	defaultBaseBackoff      = 200 * time.Millisecond
+	defaultMaxBackoff       = 5 * time.Second
+	defaultMaxRetryAfter    = 30 * time.Second
+	defaultMaxResponseBytes = 10 << 20
+	defaultFailureThreshold = 5
+	defaultOpenDuration     = 30 * time.Second
+)
+
+// Disabled turns off the retries, the backoff, the Retry-After header or the circuit breaker when it is used as the
+// value of MaxRetries, BaseBackoff, MaxBackoff, MaxRetryAfter or FailureThreshold. Their zero values mean the default.
+const Disabled = -1
+
+// Options configures the timeouts, retries and circuit breakers of a client. Zero values are replaced by the defaults.
+// Timeout, MaxResponseBytes and OpenDuration can't be disabled.
+type Options struct {
+	// Name labels the metrics and the errors of the client e.g. ov-chipkaart
+	Name string
+
+	// Timeout is the time an attempt may take including reading the response body
+	Timeout time.Duration
+
+	// EndpointTimeouts override the timeout for the URLs which start with a key e.g. https://api.example.com/v1/reports
+	EndpointTimeouts map[string]time.Duration
+
+	// MaxRetries is the number of times a request is repeated after a network error, a 5xx or a 429 response
+	MaxRetries int
+
+	// NonIdempotentRetryEndpoints also retries the POST and PATCH requests to the URLs which start with one of the
+	// prefixes. It must only contain endpoints which use these methods for queries.
+	NonIdempotentRetryEndpoints []string
+
+	// BaseBackoff is the maximum wait before the first retry. It doubles after every retry until MaxBackoff is reached
+	// and a random duration up to this maximum is used so that clients don't retry at the same time.
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+
+	// MaxRetryAfter is the longest Retry-After which is honoured. The response is returned when the API asks to wait longer.
+	MaxRetryAfter time.Duration
+
+	// MaxResponseBytes limits the size of a response body
+	MaxResponseBytes int64
+
+	// FailureThreshold is the number of consecutive failures per host which opens the circuit breaker
+	FailureThreshold int
+
+	// OpenDuration is how long requests fail fast before a single request is let through to probe the host
+	OpenDuration time.Duration
+}
+
+func (options Options) withDefaults() Options {
+	if options.Timeout == 0 {
+		options.Timeout = defaultTimeout
+	}
+	options.MaxRetries = defaultInt(options.MaxRetries, defaultMaxRetries)
+	options.BaseBackoff = defaultDuration(options.BaseBackoff, defaultBaseBackoff)
+	options.MaxBackoff = defaultDuration(options.MaxBackoff, defaultMaxBackoff)
+	options.MaxRetryAfter = defaultDuration(options.MaxRetryAfter, defaultMaxRetryAfter)
+	if options.MaxResponseBytes == 0 {
+		options.MaxResponseBytes = defaultMaxResponseBytes
+	}
+	if options.FailureThreshold == 0 {
+		options.FailureThreshold = defaultFailureThreshold
+	}
+	if options.OpenDuration == 0 {
+		options.OpenDuration = defaultOpenDuration
+	}
+
+	return options
+}
+
+// defaultInt replaces zero with the default and a disabled value with zero
+func defaultInt(value int, defaultValue int) int {
+	if value == 0 {
+		return defaultValue
+	}
+	if value < 0 {
+		return 0
+	}
+
+	return value
+}
+
+// defaultDuration replaces zero with the default and a disabled value with zero
+func defaultDuration(value time.Duration, defaultValue time.Duration) time.Duration {
+	if value == 0 {
+		return defaultValue
+	}
+	if value < 0 {
+		return 0
+	}
+
+	return value
+}
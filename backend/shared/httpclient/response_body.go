@@ -0,0 +1,59 @@
+package httpclient
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+
+	"github.com/palantir/stacktrace"
+)
+
+// responseBody limits the size of a response body and cancels the timeout of the attempt when it is closed
+type responseBody struct {
+	body      io.ReadCloser
+	remaining int64
+	limit     int64
+	cancel    context.CancelFunc
+}
+
+func newResponseBody(body io.ReadCloser, limit int64, cancel context.CancelFunc) *responseBody {
+	return &responseBody{body: body, remaining: limit, limit: limit, cancel: cancel}
+}
+
+// Read returns an error with ErrCodeResponseTooLarge after the limit is reached
+func (body *responseBody) Read(buffer []byte) (int, error) {
+	if body.remaining < 0 {
+		return 0, body.tooLarge()
+	}
+
+	// one byte more than the limit is read to tell a body of exactly the limit apart from a larger one
+	if int64(len(buffer)) > body.remaining+1 {
+		buffer = buffer[:body.remaining+1]
+	}
+
+	n, err := body.body.Read(buffer)
+	if int64(n) <= body.remaining {
+		body.remaining -= int64(n)
+		return n, err
+	}
+
+	n = int(body.remaining)
+	body.remaining = -1
+	return n, body.tooLarge()
+}
+
+// Close closes the body and releases the timeout of the attempt
+func (body *responseBody) Close() error {
+	defer body.cancel()
+	return body.body.Close()
+}
+
+func (body *responseBody) tooLarge() error {
+	return stacktrace.NewErrorWithCode(ErrCodeResponseTooLarge, "the response body is larger than %d bytes", body.limit)
+}
+
+// drain reads the rest of a body before closing it so that the connection can be reused
+func drain(body io.ReadCloser) {
+	_, _ = io.Copy(ioutil.Discard, io.LimitReader(body, 4096))
+	_ = body.Close()
+}
@@ -0,0 +1,106 @@
+package lifecycle
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// WaitForSignal blocks until the process is asked to stop with SIGINT or SIGTERM and returns the signal
+func WaitForSignal() os.Signal {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+
+	return <-signals
+}
+
+// Go runs a background task in a goroutine and returns a channel which is closed when the task returns.
+// The task must return when the context is cancelled so that the service can wait for it before closing the
+// connections which the task uses.
+func Go(ctx context.Context, task func(ctx context.Context)) <-chan struct{} {
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		task(ctx)
+	}()
+
+	return stopped
+}
+
+// Wait blocks until the background task has stopped or the timeout has passed and reports if the task stopped
+func Wait(stopped <-chan struct{}, timeout time.Duration) bool {
+	select {
+	case <-stopped:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// RegisterHealthServer implements grpc.health.v1 on the server. It must be called after all the other services were
+// registered because they are reported as serving together with the overall status of the server.
+func RegisterHealthServer(srv *grpc.Server) *health.Server {
+	healthServer := health.NewServer()
+	grpc_health_v1.RegisterHealthServer(srv, healthServer)
+
+	for service := range srv.GetServiceInfo() {
+		healthServer.SetServingStatus(service, grpc_health_v1.HealthCheckResponse_SERVING)
+	}
+
+	return healthServer
+}
+
+// WatchHealth runs the check at every interval and reports the server as not serving while it fails
+func WatchHealth(ctx context.Context, srv *grpc.Server, healthServer *health.Server, interval time.Duration, check func(ctx context.Context) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		checkCtx, cancel := context.WithTimeout(ctx, interval)
+		status := grpc_health_v1.HealthCheckResponse_SERVING
+		if check(checkCtx) != nil {
+			status = grpc_health_v1.HealthCheckResponse_NOT_SERVING
+		}
+		cancel()
+
+		// the status must not change back to serving once the server is shutting down
+		if ctx.Err() != nil {
+			return
+		}
+
+		healthServer.SetServingStatus("", status)
+		for service := range srv.GetServiceInfo() {
+			healthServer.SetServingStatus(service, status)
+		}
+	}
+}
+
+// GracefulStop reports the server as not serving, stops accepting new connections and waits for the in-flight RPCs
+// to complete. The server is stopped forcefully when they don't complete within the timeout.
+func GracefulStop(srv *grpc.Server, healthServer *health.Server, timeout time.Duration) {
+	healthServer.Shutdown()
+
+	stopped := make(chan struct{})
+	go func() {
+		srv.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(timeout):
+		srv.Stop()
+	}
+}
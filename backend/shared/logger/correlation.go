@@ -0,0 +1,36 @@
+package logger
+
+import (
+	"context"
+
+	internalContext "github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/context"
+	"go.opentelemetry.io/otel/api/trace"
+)
+
+// correlationKeys are the context values which identify the request that a line belongs to
+var correlationKeys = []internalContext.Key{
+	internalContext.KeyRequestID,
+	internalContext.KeyAnalyzeRequestID,
+	internalContext.KeyUserID,
+}
+
+// CorrelationIDs returns the ids of the request, analyze request, user and trace in the context
+func CorrelationIDs(ctx context.Context) map[string]string {
+	ids := map[string]string{}
+	if ctx == nil {
+		return ids
+	}
+
+	for _, key := range correlationKeys {
+		if value, ok := ctx.Value(key).(string); ok && value != "" {
+			ids[string(key)] = value
+		}
+	}
+
+	if spanContext := trace.SpanFromContext(ctx).SpanContext(); spanContext.IsValid() {
+		ids["trace_id"] = spanContext.TraceID.String()
+		ids["span_id"] = spanContext.SpanID.String()
+	}
+
+	return ids
+}
@@ -1,29 +1,72 @@
 package logger
 
 import (
+	"context"
 	"io"
 
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/redact"
 	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
 )
 
-// GoKitLogger is an instance of a logger using the go-kit library
+// GoKitLogger is an instance of a logger using the go-kit library which writes one JSON object per line
 type GoKitLogger struct {
 	client log.Logger
 }
 
-// NewGoKitLogger creates an instance of the go kit logger
-func NewGoKitLogger(writer io.Writer) Logger {
+// NewGoKitLogger creates an instance of the go kit logger which skips the lines below the minimum level
+func NewGoKitLogger(writer io.Writer, minimumLevel Level) Logger {
+	client := log.NewJSONLogger(log.NewSyncWriter(writer))
+	client = level.NewFilter(client, levelFilter(minimumLevel))
+	client = log.With(client, "ts", log.DefaultTimestampUTC)
+
 	return &GoKitLogger{
-		client: log.NewLogfmtLogger(writer),
+		client: client,
 	}
 }
 
-// Log is responsible for logging stuff
-func (logger GoKitLogger) Log(keyValuePairs ...interface{}) error {
-	err := logger.client.Log(keyValuePairs...)
+// Debug logs details which are only needed while developing
+func (logger GoKitLogger) Debug(ctx context.Context, msg string, keyValuePairs ...interface{}) {
+	logger.log(ctx, level.DebugValue(), msg, keyValuePairs)
+}
+
+// Info logs events which happen during normal operation
+func (logger GoKitLogger) Info(ctx context.Context, msg string, keyValuePairs ...interface{}) {
+	logger.log(ctx, level.InfoValue(), msg, keyValuePairs)
+}
+
+// Warn logs failures which are expected to happen e.g. invalid credentials
+func (logger GoKitLogger) Warn(ctx context.Context, msg string, keyValuePairs ...interface{}) {
+	logger.log(ctx, level.WarnValue(), msg, keyValuePairs)
+}
+
+// Error logs failures which need attention
+func (logger GoKitLogger) Error(ctx context.Context, msg string, keyValuePairs ...interface{}) {
+	logger.log(ctx, level.ErrorValue(), msg, keyValuePairs)
+}
+
+// log redacts the values before they are written because they can contain credentials or personal data
+func (logger GoKitLogger) log(ctx context.Context, levelValue level.Value, msg string, keyValuePairs []interface{}) {
+	line := append([]interface{}{level.Key(), levelValue, "msg", redact.String(msg)}, redact.KeyValues(keyValuePairs...)...)
+	for key, value := range CorrelationIDs(ctx) {
+		line = append(line, key, value)
+	}
+
+	err := logger.client.Log(line...)
 	if err != nil {
 		println(err.Error())
 	}
+}
 
-	return err
+func levelFilter(minimumLevel Level) level.Option {
+	switch minimumLevel {
+	case LevelDebug:
+		return level.AllowDebug()
+	case LevelWarn:
+		return level.AllowWarn()
+	case LevelError:
+		return level.AllowError()
+	default:
+		return level.AllowInfo()
+	}
 }
@@ -0,0 +1,36 @@
+package logger
+
+import (
+	"strings"
+
+	"github.com/palantir/stacktrace"
+)
+
+// Level is the minimum severity of the lines which are written
+type Level string
+
+const (
+	// LevelDebug writes all lines
+	LevelDebug = Level("debug")
+
+	// LevelInfo writes everything except debug lines
+	LevelInfo = Level("info")
+
+	// LevelWarn writes warnings and errors
+	LevelWarn = Level("warn")
+
+	// LevelError only writes errors
+	LevelError = Level("error")
+)
+
+// ParseLevel converts a string e.g. from the LOG_LEVEL environment variable to a level. The default level is info.
+func ParseLevel(value string) (Level, error) {
+	switch level := Level(strings.ToLower(value)); level {
+	case "":
+		return LevelInfo, nil
+	case LevelDebug, LevelInfo, LevelWarn, LevelError:
+		return level, nil
+	default:
+		return LevelInfo, stacktrace.NewError("%s is not a valid log level", value)
+	}
+}
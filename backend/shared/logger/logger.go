@@ -1,6 +1,12 @@
 package logger
 
-// Logger is the interface used for implementing loggers
+import "context"
+
+// Logger is the interface used for implementing loggers.
+// The correlation ids in the context are added to every line.
 type Logger interface {
-	Log(...interface{}) error
+	Debug(ctx context.Context, msg string, keyValuePairs ...interface{})
+	Info(ctx context.Context, msg string, keyValuePairs ...interface{})
+	Warn(ctx context.Context, msg string, keyValuePairs ...interface{})
+	Error(ctx context.Context, msg string, keyValuePairs ...interface{})
 }
@@ -0,0 +1,33 @@
+package metrics
+
+import (
+	grpcPrometheus "github.com/grpc-ecosystem/go-grpc-prometheus"
+	"google.golang.org/grpc"
+)
+
+func init() {
+	grpcPrometheus.EnableHandlingTimeHistogram()
+	grpcPrometheus.EnableClientHandlingTimeHistogram()
+}
+
+// GRPCServerOptions record the number, duration and status codes of the RPCs which a server handles
+func GRPCServerOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(grpcPrometheus.UnaryServerInterceptor),
+		grpc.ChainStreamInterceptor(grpcPrometheus.StreamServerInterceptor),
+	}
+}
+
+// InitializeGRPCServer creates the metrics of every registered method with a zero value.
+// It must be called after the services were registered.
+func InitializeGRPCServer(srv *grpc.Server) {
+	grpcPrometheus.Register(srv)
+}
+
+// GRPCDialOptions record the number, duration and status codes of the RPCs which a client sends
+func GRPCDialOptions() []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithChainUnaryInterceptor(grpcPrometheus.UnaryClientInterceptor),
+		grpc.WithChainStreamInterceptor(grpcPrometheus.StreamClientInterceptor),
+	}
+}
@@ -0,0 +1,120 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	outboundRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "outbound_http_requests_total",
+		Help:      "The number of requests which were sent to external APIs by status code.",
+	}, []string{"client", "method", "code"})
+
+	outboundRequestFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "outbound_http_request_failures_total",
+		Help:      "The number of requests to external APIs which failed with a network error or a 5xx response.",
+	}, []string{"client"})
+
+	outboundRequestsRateLimited = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "outbound_http_requests_rate_limited_total",
+		Help:      "The number of requests which were rejected by an external API with 429 Too Many Requests.",
+	}, []string{"client"})
+
+	outboundRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Name:      "outbound_http_request_duration_seconds",
+		Help:      "The time it took external APIs to respond.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"client"})
+
+	outboundRequestRetries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "outbound_http_request_retries_total",
+		Help:      "The number of requests to external APIs which were retried after a failure.",
+	}, []string{"client"})
+
+	circuitBreakerOpen = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Name:      "outbound_circuit_breaker_open",
+		Help:      "Whether the circuit breaker of an external API host is open.",
+	}, []string{"client", "host"})
+
+	rateLimitWaits = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Name:      "outbound_rate_limit_wait_seconds",
+		Help:      "The time spent waiting for a client side rate limiter before calling an external API.",
+		Buckets:   []float64{0, .05, .1, .25, .5, 1, 2.5, 5, 10},
+	}, []string{"client"})
+)
+
+// HTTPClient performs http requests
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// InstrumentedHTTPClient records metrics for the requests of an HTTPClient
+type InstrumentedHTTPClient struct {
+	name   string
+	client HTTPClient
+}
+
+// NewInstrumentedHTTPClient wraps a client, the name is used as the client label of the metrics
+func NewInstrumentedHTTPClient(name string, client HTTPClient) *InstrumentedHTTPClient {
+	return &InstrumentedHTTPClient{
+		name:   name,
+		client: client,
+	}
+}
+
+// Do sends the request and records its duration and outcome
+func (client *InstrumentedHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	response, err := client.client.Do(req)
+	outboundRequestDuration.WithLabelValues(client.name).Observe(time.Since(start).Seconds())
+
+	if err != nil {
+		outboundRequests.WithLabelValues(client.name, req.Method, "error").Inc()
+		outboundRequestFailures.WithLabelValues(client.name).Inc()
+		return response, err
+	}
+
+	outboundRequests.WithLabelValues(client.name, req.Method, strconv.Itoa(response.StatusCode)).Inc()
+
+	if response.StatusCode == http.StatusTooManyRequests {
+		outboundRequestsRateLimited.WithLabelValues(client.name).Inc()
+	}
+
+	if response.StatusCode >= http.StatusInternalServerError {
+		outboundRequestFailures.WithLabelValues(client.name).Inc()
+	}
+
+	return response, nil
+}
+
+// ObserveRateLimitWait records how long a client waited for its rate limiter
+func ObserveRateLimitWait(client string, wait time.Duration) {
+	rateLimitWaits.WithLabelValues(client).Observe(wait.Seconds())
+}
+
+// ObserveRetry records that a client retried a request
+func ObserveRetry(client string) {
+	outboundRequestRetries.WithLabelValues(client).Inc()
+}
+
+// SetCircuitBreakerOpen records the state of the circuit breaker of a host
+func SetCircuitBreakerOpen(client string, host string, open bool) {
+	value := 0.0
+	if open {
+		value = 1
+	}
+
+	circuitBreakerOpen.WithLabelValues(client, host).Set(value)
+}
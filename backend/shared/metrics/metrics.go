@@ -0,0 +1,23 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Namespace prefixes the name of every metric
+const Namespace = "ov_chipkaart"
+
+// Handler exposes the metrics in the prometheus text format
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// ListenAndServe exposes the metrics on /metrics for services which don't have an HTTP server
+func ListenAndServe(address string) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", Handler())
+
+	return http.ListenAndServe(address, mux)
+}
@@ -0,0 +1,26 @@
+package mongodb
+
+import (
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// duplicateKeyCode is the code of the error which is returned when a write violates a unique index
+const duplicateKeyCode = 11000
+
+// IsDuplicateKeyError checks if a write failed because it violates a unique index
+func IsDuplicateKeyError(err error) bool {
+	switch e := err.(type) {
+	case mongo.WriteException:
+		for _, writeError := range e.WriteErrors {
+			if writeError.Code == duplicateKeyCode {
+				return true
+			}
+		}
+	case mongo.BulkWriteError:
+		return e.Code == duplicateKeyCode
+	case mongo.CommandError:
+		return e.Code == duplicateKeyCode
+	}
+
+	return false
+}
@@ -2,12 +2,9 @@ package mongodb
 
 import (
 	"context"
-	"strings"
 	"time"
 
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 const (
@@ -18,11 +15,6 @@ const (
 
 	// SortOrderAscending MongoDB sort ascending flag
 	SortOrderAscending  = 1
-
-	fieldCreatedAt = "created_at"
-
-	defaultLimit = 50
-	defaultSkip  = 0
 )
 
 type Repository struct {
@@ -45,38 +37,7 @@ func (repository Repository) Collection() *mongo.Collection {
 	return repository.DB().Collection(repository.collection)
 }
 
-func (repository Repository) DefaultTimeoutContext() context.Context {
-	ctx, _ := context.WithTimeout(context.Background(), dbOperationTimeout)
-	return ctx
+// TimeoutContext returns a child of the given context which expires after the default timeout of database operations
+func (repository Repository) TimeoutContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, dbOperationTimeout)
 }
-
-func (repository Repository) GetFindOptions(skip *int, take *int, sortBy *string, sortDirection *string) *options.FindOptions {
-	findOptions := options.Find()
-
-	sortKey := fieldCreatedAt
-	if sortBy != nil {
-		sortKey = *sortBy
-	}
-
-	sortValue := SortOrderDescending
-	if sortDirection != nil {
-		if strings.ToLower(*sortDirection) == "asc" {
-			sortValue = SortOrderAscending
-		}
-	}
-
-	findOptions.SetSort(bson.D{{sortKey, sortValue}})
-
-	findOptions.SetSkip(defaultSkip)
-	if skip != nil {
-		findOptions.SetSkip(int64(*skip))
-	}
-
-	findOptions.SetLimit(defaultLimit)
-	if take != nil {
-		findOptions.SetLimit(int64(*take))
-	}
-
-	return findOptions
-}
-
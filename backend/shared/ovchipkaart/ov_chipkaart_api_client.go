@@ -2,16 +2,20 @@ package ovchipkaart
 
 import (
 	"bytes"
+	"context"
 	"io/ioutil"
 	"math"
 	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/palantir/stacktrace"
 
 	"github.com/AchoArnold/homework/services/json"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/httpclient"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/metrics"
 	"github.com/pkg/errors"
 	"go.uber.org/ratelimit"
 )
@@ -29,11 +33,16 @@ const dateFormat = "2006-01-02"
 
 const transactionRequestsPerSecond = 10
 
+// metricsClientName is the client label of the metrics of the ov-chipkaart API
+const metricsClientName = "ov-chipkaart"
+
 const (
 	// ErrCodeUnauthorized is returned when the user is not authorized
 	ErrCodeUnauthorized = stacktrace.ErrorCode(401)
 	// ErrCodeInternalServerError represents any other error
 	ErrCodeInternalServerError = stacktrace.ErrorCode(500)
+	// ErrCodeCancelled is returned when the context is cancelled or expires before the transactions are fetched
+	ErrCodeCancelled = stacktrace.ErrorCode(499)
 )
 
 type authenticationTokenResponse struct {
@@ -90,6 +99,20 @@ type APIServiceConfig struct {
 	Client       HTTPClient
 }
 
+// HTTPClientOptions returns the resilience settings of the ov-chipkaart API. The POST requests of the transactions
+// endpoint are queries so they can be retried, the token endpoints are not retried because they authenticate the user
+// and repeating a failed login can lock the account. Fetching transactions takes longer than the token endpoints.
+func HTTPClientOptions() httpclient.Options {
+	return httpclient.Options{
+		Name:    metricsClientName,
+		Timeout: 10 * time.Second,
+		EndpointTimeouts: map[string]time.Duration{
+			endpointTransactions: 30 * time.Second,
+		},
+		NonIdempotentRetryEndpoints: []string{endpointTransactions},
+	}
+}
+
 // NewAPIService Initializes the API service.
 func NewAPIService(config APIServiceConfig) APIClient {
 	return APIClient{
@@ -101,13 +124,13 @@ func NewAPIService(config APIServiceConfig) APIClient {
 }
 
 // GetAuthorisationToken fetches the auth token based on username/password combination
-func (client APIClient) GetAuthorisationToken(username string, password string) (authorisationToken string, err error) {
-	authenticationToken, err := client.getAuthenticationToken(username, password)
+func (client APIClient) GetAuthorisationToken(ctx context.Context, username string, password string) (authorisationToken string, err error) {
+	authenticationToken, err := client.getAuthenticationToken(ctx, username, password)
 	if err != nil {
 		return authorisationToken, stacktrace.PropagateWithCode(err, stacktrace.GetCode(err), "could not fetch authentication token")
 	}
 
-	authorisationTokenResponse, err := client.getAuthorisationToken(authenticationToken)
+	authorisationTokenResponse, err := client.getAuthorisationToken(ctx, authenticationToken)
 	if err != nil {
 		return authorisationToken, stacktrace.PropagateWithCode(err, stacktrace.GetCode(err), "could not fetch authorisation token")
 	}
@@ -116,21 +139,36 @@ func (client APIClient) GetAuthorisationToken(username string, password string)
 }
 
 // FetchTransactions returns the transaction records based on the parameter provided.
-func (client APIClient) FetchTransactions(options TransactionFetchOptions) (records []RawRecord, err error) {
-	authorisationToken, err := client.GetAuthorisationToken(options.Username, options.Password)
+// It stops fetching pages as soon as the context is cancelled.
+func (client APIClient) FetchTransactions(ctx context.Context, options TransactionFetchOptions) (records []RawRecord, err error) {
+	authorisationToken, err := client.GetAuthorisationToken(ctx, options.Username, options.Password)
 	if err != nil {
-		return records, stacktrace.PropagateWithCode(err, ErrCodeUnauthorized, "could not authenticate user")
+		return records, stacktrace.PropagateWithCode(err, client.errorCode(ctx, err, ErrCodeUnauthorized), "could not authenticate user")
 	}
 
-	records, err = client.getTransactions(authorisationToken, options)
+	records, err = client.getTransactions(ctx, authorisationToken, options)
 	if err != nil {
-		return records, stacktrace.PropagateWithCode(err, ErrCodeInternalServerError, "could not fetch transactions")
+		return records, stacktrace.PropagateWithCode(err, client.errorCode(ctx, err, ErrCodeInternalServerError), "could not fetch transactions")
 	}
 
 	return records, nil
 }
 
-func (client APIClient) getTransactions(authorisationToken string, options TransactionFetchOptions) ([]RawRecord, error) {
+// errorCode keeps the code of the error so that a cancelled request or a failed request to the API is not reported
+// as wrong credentials, which makes the card sync back off. Errors without a code get the default code.
+func (client APIClient) errorCode(ctx context.Context, err error, defaultCode stacktrace.ErrorCode) stacktrace.ErrorCode {
+	if ctx.Err() != nil {
+		return ErrCodeCancelled
+	}
+
+	if code := stacktrace.GetCode(err); code != stacktrace.NoCode {
+		return code
+	}
+
+	return defaultCode
+}
+
+func (client APIClient) getTransactions(ctx context.Context, authorisationToken string, options TransactionFetchOptions) ([]RawRecord, error) {
 	payload := transactionsPayload{
 		AuthorisationToken: authorisationToken,
 		MediumID:           options.CardNumber,
@@ -139,7 +177,7 @@ func (client APIClient) getTransactions(authorisationToken string, options Trans
 		EndDate:            options.EndDate.Format(dateFormat),
 	}
 
-	transactionsResponse, err := client.getTransaction(payload)
+	transactionsResponse, err := client.getTransaction(ctx, payload)
 	if err != nil {
 		return nil, stacktrace.Propagate(err, "cannot perform transactions request: payload = %+v", payload)
 	}
@@ -157,9 +195,14 @@ func (client APIClient) getTransactions(authorisationToken string, options Trans
 	for i := 1; i < numberOfRequests; i++ {
 		payload.Offset = strconv.Itoa(transactionsResponse.Response.NextRequestContext.Offset)
 
-		rateLimiter.Take()
+		waitStart := time.Now()
+		err = client.waitForRateLimit(ctx, rateLimiter)
+		metrics.ObserveRateLimitWait(metricsClientName, time.Since(waitStart))
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "stopped fetching transactions after %d of %d pages", i, numberOfRequests)
+		}
 
-		transactionsResponse, err = client.getTransaction(payload)
+		transactionsResponse, err = client.getTransaction(ctx, payload)
 		if err != nil {
 			return nil, stacktrace.Propagate(err, "cannot perform transactions request: payload = %+v", payload)
 		}
@@ -170,13 +213,30 @@ func (client APIClient) getTransactions(authorisationToken string, options Trans
 	return records, nil
 }
 
-func (client APIClient) getTransaction(payload transactionsPayload) (transactionsResponse *transactionsResponse, err error) {
+// waitForRateLimit blocks until the rate limiter allows the next request or the context is done.
+// The limiter can't be cancelled so the goroutine which waits for it returns at most one interval later.
+func (client APIClient) waitForRateLimit(ctx context.Context, rateLimiter ratelimit.Limiter) error {
+	taken := make(chan struct{})
+	go func() {
+		rateLimiter.Take()
+		close(taken)
+	}()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-taken:
+		return nil
+	}
+}
+
+func (client APIClient) getTransaction(ctx context.Context, payload transactionsPayload) (transactionsResponse *transactionsResponse, err error) {
 	payloadAsMap, err := json.JsonToStringMap(payload)
 	if err != nil {
 		return transactionsResponse, stacktrace.Propagate(err, "cannot serialize request to map %#+v", payload)
 	}
 
-	request, err := client.createPostRequest(endpointTransactions, payloadAsMap)
+	request, err := client.createPostRequest(ctx, endpointTransactions, payloadAsMap)
 	if err != nil {
 		return transactionsResponse, stacktrace.Propagate(err, "cannot create transaction request: payload = %+#v", payloadAsMap)
 	}
@@ -185,6 +245,7 @@ func (client APIClient) getTransaction(payload transactionsPayload) (transaction
 	if err != nil {
 		return transactionsResponse, stacktrace.Propagate(err, "cannot perform transaction request: payload = %+#v", request)
 	}
+	defer response.Body.Close()
 
 	err = json.JsonDecode(&transactionsResponse, response.Body)
 	if err != nil {
@@ -198,12 +259,12 @@ func (client APIClient) getTransaction(payload transactionsPayload) (transaction
 	return transactionsResponse, nil
 }
 
-func (client APIClient) getAuthorisationToken(authenticationTokenResponse authenticationTokenResponse) (authorisationToken authorisationTokenResponse, err error) {
+func (client APIClient) getAuthorisationToken(ctx context.Context, authenticationTokenResponse authenticationTokenResponse) (authorisationToken authorisationTokenResponse, err error) {
 	payload := map[string]string{
 		"authenticationToken": authenticationTokenResponse.IDToken,
 	}
 
-	request, err := client.createPostRequest(endpointAuthorisation, payload)
+	request, err := client.createPostRequest(ctx, endpointAuthorisation, payload)
 	if err != nil {
 		return authorisationToken, stacktrace.Propagate(err, "cannot create authorisation request")
 	}
@@ -212,6 +273,7 @@ func (client APIClient) getAuthorisationToken(authenticationTokenResponse authen
 	if err != nil {
 		return authorisationToken, stacktrace.Propagate(err, "cannot perform authorisation request")
 	}
+	defer response.Body.Close()
 
 	responseBody, err := ioutil.ReadAll(response.Body)
 	if err != nil {
@@ -230,7 +292,7 @@ func (client APIClient) getAuthorisationToken(authenticationTokenResponse authen
 	return authorisationToken, nil
 }
 
-func (client APIClient) getAuthenticationToken(username, password string) (authenticationToken authenticationTokenResponse, err error) {
+func (client APIClient) getAuthenticationToken(ctx context.Context, username, password string) (authenticationToken authenticationTokenResponse, err error) {
 	payload := map[string]string{
 		"username":      username,
 		"password":      password,
@@ -240,7 +302,7 @@ func (client APIClient) getAuthenticationToken(username, password string) (authe
 		"scope":         "openid",
 	}
 
-	request, err := client.createPostRequest(endpointAuthentication, payload)
+	request, err := client.createPostRequest(ctx, endpointAuthentication, payload)
 	if err != nil {
 		return authenticationToken, stacktrace.PropagateWithCode(err, ErrCodeInternalServerError, "cannot create authentication request")
 	}
@@ -249,6 +311,7 @@ func (client APIClient) getAuthenticationToken(username, password string) (authe
 	if err != nil {
 		return authenticationToken, stacktrace.PropagateWithCode(err, ErrCodeInternalServerError, "cannot perform authentication request")
 	}
+	defer response.Body.Close()
 
 	err = json.JsonDecode(&authenticationToken, response.Body)
 	if err != nil {
@@ -268,16 +331,22 @@ func (client APIClient) doHTTPRequest(request *http.Request) (*http.Response, er
 		return nil, stacktrace.Propagate(err, "cannot execute %s request for %s: ", request.Method, request.URL.String())
 	}
 
+	// 4xx responses are decoded by the callers because they contain the reason why the user is not authorized
+	if apiResponse.StatusCode >= http.StatusInternalServerError || apiResponse.StatusCode == http.StatusTooManyRequests {
+		_ = apiResponse.Body.Close()
+		return nil, stacktrace.NewError("invalid response code %d for %s request to %s", apiResponse.StatusCode, request.Method, request.URL.String())
+	}
+
 	return apiResponse, nil
 }
 
-func (client APIClient) createPostRequest(endpoint string, payload map[string]string) (*http.Request, error) {
+func (client APIClient) createPostRequest(ctx context.Context, endpoint string, payload map[string]string) (*http.Request, error) {
 	data := url.Values{}
 	for key, val := range payload {
 		data.Set(key, val)
 	}
 
-	apiRequest, err := http.NewRequest(http.MethodPost, endpoint, strings.NewReader(data.Encode()))
+	apiRequest, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(data.Encode()))
 	if err != nil {
 		return nil, stacktrace.Propagate(err, "cannot create request for URL: "+endpoint)
 	}
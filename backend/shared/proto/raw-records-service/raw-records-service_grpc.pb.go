@@ -4,7 +4,6 @@ package raw_records_service
 
 import (
 	context "context"
-	empty "github.com/golang/protobuf/ptypes/empty"
 	grpc "google.golang.org/grpc"
 	codes "google.golang.org/grpc/codes"
 	status "google.golang.org/grpc/status"
@@ -19,7 +18,10 @@ const _ = grpc.SupportPackageIsVersion7
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type RawRecordsServiceClient interface {
 	FetchByRequestId(ctx context.Context, in *FetchByRequestIdRequest, opts ...grpc.CallOption) (*FetchByRequestIdResponse, error)
-	StoreTransactions(ctx context.Context, in *StoreTransactionsRequest, opts ...grpc.CallOption) (*empty.Empty, error)
+	StoreTransactions(ctx context.Context, in *StoreTransactionsRequest, opts ...grpc.CallOption) (*StoreTransactionsResponse, error)
+	RegisterCard(ctx context.Context, in *RegisterCardRequest, opts ...grpc.CallOption) (*CardSyncState, error)
+	FetchCardSyncStates(ctx context.Context, in *FetchCardSyncStatesRequest, opts ...grpc.CallOption) (*FetchCardSyncStatesResponse, error)
+	DeleteUserData(ctx context.Context, in *DeleteUserDataRequest, opts ...grpc.CallOption) (*DeleteUserDataResponse, error)
 }
 
 type rawRecordsServiceClient struct {
@@ -39,8 +41,8 @@ func (c *rawRecordsServiceClient) FetchByRequestId(ctx context.Context, in *Fetc
 	return out, nil
 }
 
-func (c *rawRecordsServiceClient) StoreTransactions(ctx context.Context, in *StoreTransactionsRequest, opts ...grpc.CallOption) (*empty.Empty, error) {
-	out := new(empty.Empty)
+func (c *rawRecordsServiceClient) StoreTransactions(ctx context.Context, in *StoreTransactionsRequest, opts ...grpc.CallOption) (*StoreTransactionsResponse, error) {
+	out := new(StoreTransactionsResponse)
 	err := c.cc.Invoke(ctx, "/transactions.RawRecordsService/StoreTransactions", in, out, opts...)
 	if err != nil {
 		return nil, err
@@ -48,12 +50,42 @@ func (c *rawRecordsServiceClient) StoreTransactions(ctx context.Context, in *Sto
 	return out, nil
 }
 
+func (c *rawRecordsServiceClient) RegisterCard(ctx context.Context, in *RegisterCardRequest, opts ...grpc.CallOption) (*CardSyncState, error) {
+	out := new(CardSyncState)
+	err := c.cc.Invoke(ctx, "/transactions.RawRecordsService/RegisterCard", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rawRecordsServiceClient) FetchCardSyncStates(ctx context.Context, in *FetchCardSyncStatesRequest, opts ...grpc.CallOption) (*FetchCardSyncStatesResponse, error) {
+	out := new(FetchCardSyncStatesResponse)
+	err := c.cc.Invoke(ctx, "/transactions.RawRecordsService/FetchCardSyncStates", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rawRecordsServiceClient) DeleteUserData(ctx context.Context, in *DeleteUserDataRequest, opts ...grpc.CallOption) (*DeleteUserDataResponse, error) {
+	out := new(DeleteUserDataResponse)
+	err := c.cc.Invoke(ctx, "/transactions.RawRecordsService/DeleteUserData", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // RawRecordsServiceServer is the server API for RawRecordsService service.
 // All implementations must embed UnimplementedRawRecordsServiceServer
 // for forward compatibility
 type RawRecordsServiceServer interface {
 	FetchByRequestId(context.Context, *FetchByRequestIdRequest) (*FetchByRequestIdResponse, error)
-	StoreTransactions(context.Context, *StoreTransactionsRequest) (*empty.Empty, error)
+	StoreTransactions(context.Context, *StoreTransactionsRequest) (*StoreTransactionsResponse, error)
+	RegisterCard(context.Context, *RegisterCardRequest) (*CardSyncState, error)
+	FetchCardSyncStates(context.Context, *FetchCardSyncStatesRequest) (*FetchCardSyncStatesResponse, error)
+	DeleteUserData(context.Context, *DeleteUserDataRequest) (*DeleteUserDataResponse, error)
 	mustEmbedUnimplementedRawRecordsServiceServer()
 }
 
@@ -64,9 +96,18 @@ type UnimplementedRawRecordsServiceServer struct {
 func (UnimplementedRawRecordsServiceServer) FetchByRequestId(context.Context, *FetchByRequestIdRequest) (*FetchByRequestIdResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method FetchByRequestId not implemented")
 }
-func (UnimplementedRawRecordsServiceServer) StoreTransactions(context.Context, *StoreTransactionsRequest) (*empty.Empty, error) {
+func (UnimplementedRawRecordsServiceServer) StoreTransactions(context.Context, *StoreTransactionsRequest) (*StoreTransactionsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method StoreTransactions not implemented")
 }
+func (UnimplementedRawRecordsServiceServer) RegisterCard(context.Context, *RegisterCardRequest) (*CardSyncState, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RegisterCard not implemented")
+}
+func (UnimplementedRawRecordsServiceServer) FetchCardSyncStates(context.Context, *FetchCardSyncStatesRequest) (*FetchCardSyncStatesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FetchCardSyncStates not implemented")
+}
+func (UnimplementedRawRecordsServiceServer) DeleteUserData(context.Context, *DeleteUserDataRequest) (*DeleteUserDataResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteUserData not implemented")
+}
 func (UnimplementedRawRecordsServiceServer) mustEmbedUnimplementedRawRecordsServiceServer() {}
 
 // UnsafeRawRecordsServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -116,6 +157,60 @@ func _RawRecordsService_StoreTransactions_Handler(srv interface{}, ctx context.C
 	return interceptor(ctx, in, info, handler)
 }
 
+func _RawRecordsService_RegisterCard_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegisterCardRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RawRecordsServiceServer).RegisterCard(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/transactions.RawRecordsService/RegisterCard",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RawRecordsServiceServer).RegisterCard(ctx, req.(*RegisterCardRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RawRecordsService_FetchCardSyncStates_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FetchCardSyncStatesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RawRecordsServiceServer).FetchCardSyncStates(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/transactions.RawRecordsService/FetchCardSyncStates",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RawRecordsServiceServer).FetchCardSyncStates(ctx, req.(*FetchCardSyncStatesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RawRecordsService_DeleteUserData_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteUserDataRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RawRecordsServiceServer).DeleteUserData(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/transactions.RawRecordsService/DeleteUserData",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RawRecordsServiceServer).DeleteUserData(ctx, req.(*DeleteUserDataRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _RawRecordsService_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "transactions.RawRecordsService",
 	HandlerType: (*RawRecordsServiceServer)(nil),
@@ -128,6 +223,18 @@ var _RawRecordsService_serviceDesc = grpc.ServiceDesc{
 			MethodName: "StoreTransactions",
 			Handler:    _RawRecordsService_StoreTransactions_Handler,
 		},
+		{
+			MethodName: "RegisterCard",
+			Handler:    _RawRecordsService_RegisterCard_Handler,
+		},
+		{
+			MethodName: "FetchCardSyncStates",
+			Handler:    _RawRecordsService_FetchCardSyncStates_Handler,
+		},
+		{
+			MethodName: "DeleteUserData",
+			Handler:    _RawRecordsService_DeleteUserData_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "raw-records-service/raw-records-service.proto",
@@ -0,0 +1,710 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.25.0
+// 	protoc        v3.13.0
+// source: raw-records-service/v2/raw-records-service.proto
+
+package raw_records_service_v2
+
+import (
+	proto "github.com/golang/protobuf/proto"
+	timestamp "github.com/golang/protobuf/ptypes/timestamp"
+	wrappers "github.com/golang/protobuf/ptypes/wrappers"
+	field_mask "google.golang.org/genproto/protobuf/field_mask"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// This is a compile-time assertion that a sufficiently up-to-date version
+// of the legacy proto package is being used.
+const _ = proto.ProtoPackageIsVersion4
+
+type RawRecord struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id                     string                `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	UserId                 string                `protobuf:"bytes,2,opt,name=userId,proto3" json:"userId,omitempty"`
+	AnalyzeRequestId       string                `protobuf:"bytes,3,opt,name=analyzeRequestId,proto3" json:"analyzeRequestId,omitempty"`
+	CardNumber             string                `protobuf:"bytes,4,opt,name=cardNumber,proto3" json:"cardNumber,omitempty"`
+	CheckInInfo            string                `protobuf:"bytes,5,opt,name=checkInInfo,proto3" json:"checkInInfo,omitempty"`
+	CheckInText            string                `protobuf:"bytes,6,opt,name=checkInText,proto3" json:"checkInText,omitempty"`
+	Fare                   *wrappers.DoubleValue `protobuf:"bytes,7,opt,name=fare,proto3" json:"fare,omitempty"`
+	FareCalculation        string                `protobuf:"bytes,8,opt,name=fareCalculation,proto3" json:"fareCalculation,omitempty"`
+	FareText               string                `protobuf:"bytes,9,opt,name=fareText,proto3" json:"fareText,omitempty"`
+	ModalType              string                `protobuf:"bytes,10,opt,name=modalType,proto3" json:"modalType,omitempty"`
+	ProductInfo            string                `protobuf:"bytes,11,opt,name=productInfo,proto3" json:"productInfo,omitempty"`
+	ProductText            string                `protobuf:"bytes,12,opt,name=productText,proto3" json:"productText,omitempty"`
+	Pto                    string                `protobuf:"bytes,13,opt,name=pto,proto3" json:"pto,omitempty"`
+	TransactionDateTime    *timestamp.Timestamp  `protobuf:"bytes,14,opt,name=transactionDateTime,proto3" json:"transactionDateTime,omitempty"`
+	TransactionInfo        string                `protobuf:"bytes,15,opt,name=transactionInfo,proto3" json:"transactionInfo,omitempty"`
+	TransactionName        string                `protobuf:"bytes,16,opt,name=transactionName,proto3" json:"transactionName,omitempty"`
+	EPurseMut              *wrappers.DoubleValue `protobuf:"bytes,17,opt,name=ePurseMut,proto3" json:"ePurseMut,omitempty"`
+	EPurseMutInfo          string                `protobuf:"bytes,18,opt,name=ePurseMutInfo,proto3" json:"ePurseMutInfo,omitempty"`
+	TransactionExplanation string                `protobuf:"bytes,19,opt,name=transactionExplanation,proto3" json:"transactionExplanation,omitempty"`
+	TransactionPriority    string                `protobuf:"bytes,20,opt,name=transactionPriority,proto3" json:"transactionPriority,omitempty"`
+	Source                 string                `protobuf:"bytes,21,opt,name=source,proto3" json:"source,omitempty"`
+	CreatedAt              *timestamp.Timestamp  `protobuf:"bytes,22,opt,name=createdAt,proto3" json:"createdAt,omitempty"`
+	UpdatedAt              *timestamp.Timestamp  `protobuf:"bytes,23,opt,name=updatedAt,proto3" json:"updatedAt,omitempty"`
+}
+
+func (x *RawRecord) Reset() {
+	*x = RawRecord{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_raw_records_service_v2_raw_records_service_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RawRecord) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RawRecord) ProtoMessage() {}
+
+func (x *RawRecord) ProtoReflect() protoreflect.Message {
+	mi := &file_raw_records_service_v2_raw_records_service_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RawRecord.ProtoReflect.Descriptor instead.
+func (*RawRecord) Descriptor() ([]byte, []int) {
+	return file_raw_records_service_v2_raw_records_service_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *RawRecord) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *RawRecord) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *RawRecord) GetAnalyzeRequestId() string {
+	if x != nil {
+		return x.AnalyzeRequestId
+	}
+	return ""
+}
+
+func (x *RawRecord) GetCardNumber() string {
+	if x != nil {
+		return x.CardNumber
+	}
+	return ""
+}
+
+func (x *RawRecord) GetCheckInInfo() string {
+	if x != nil {
+		return x.CheckInInfo
+	}
+	return ""
+}
+
+func (x *RawRecord) GetCheckInText() string {
+	if x != nil {
+		return x.CheckInText
+	}
+	return ""
+}
+
+func (x *RawRecord) GetFare() *wrappers.DoubleValue {
+	if x != nil {
+		return x.Fare
+	}
+	return nil
+}
+
+func (x *RawRecord) GetFareCalculation() string {
+	if x != nil {
+		return x.FareCalculation
+	}
+	return ""
+}
+
+func (x *RawRecord) GetFareText() string {
+	if x != nil {
+		return x.FareText
+	}
+	return ""
+}
+
+func (x *RawRecord) GetModalType() string {
+	if x != nil {
+		return x.ModalType
+	}
+	return ""
+}
+
+func (x *RawRecord) GetProductInfo() string {
+	if x != nil {
+		return x.ProductInfo
+	}
+	return ""
+}
+
+func (x *RawRecord) GetProductText() string {
+	if x != nil {
+		return x.ProductText
+	}
+	return ""
+}
+
+func (x *RawRecord) GetPto() string {
+	if x != nil {
+		return x.Pto
+	}
+	return ""
+}
+
+func (x *RawRecord) GetTransactionDateTime() *timestamp.Timestamp {
+	if x != nil {
+		return x.TransactionDateTime
+	}
+	return nil
+}
+
+func (x *RawRecord) GetTransactionInfo() string {
+	if x != nil {
+		return x.TransactionInfo
+	}
+	return ""
+}
+
+func (x *RawRecord) GetTransactionName() string {
+	if x != nil {
+		return x.TransactionName
+	}
+	return ""
+}
+
+func (x *RawRecord) GetEPurseMut() *wrappers.DoubleValue {
+	if x != nil {
+		return x.EPurseMut
+	}
+	return nil
+}
+
+func (x *RawRecord) GetEPurseMutInfo() string {
+	if x != nil {
+		return x.EPurseMutInfo
+	}
+	return ""
+}
+
+func (x *RawRecord) GetTransactionExplanation() string {
+	if x != nil {
+		return x.TransactionExplanation
+	}
+	return ""
+}
+
+func (x *RawRecord) GetTransactionPriority() string {
+	if x != nil {
+		return x.TransactionPriority
+	}
+	return ""
+}
+
+func (x *RawRecord) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *RawRecord) GetCreatedAt() *timestamp.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *RawRecord) GetUpdatedAt() *timestamp.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+type ListRawRecordsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// userId is required, records are always scoped to a single user
+	UserId           string `protobuf:"bytes,1,opt,name=userId,proto3" json:"userId,omitempty"`
+	CardNumber       string `protobuf:"bytes,2,opt,name=cardNumber,proto3" json:"cardNumber,omitempty"`
+	AnalyzeRequestId string `protobuf:"bytes,3,opt,name=analyzeRequestId,proto3" json:"analyzeRequestId,omitempty"`
+	// startDateTime is inclusive
+	StartDateTime *timestamp.Timestamp `protobuf:"bytes,4,opt,name=startDateTime,proto3" json:"startDateTime,omitempty"`
+	// endDateTime is exclusive
+	EndDateTime      *timestamp.Timestamp `protobuf:"bytes,5,opt,name=endDateTime,proto3" json:"endDateTime,omitempty"`
+	Ptos             []string             `protobuf:"bytes,6,rep,name=ptos,proto3" json:"ptos,omitempty"`
+	ModalTypes       []string             `protobuf:"bytes,7,rep,name=modalTypes,proto3" json:"modalTypes,omitempty"`
+	TransactionNames []string             `protobuf:"bytes,8,rep,name=transactionNames,proto3" json:"transactionNames,omitempty"`
+	// pageSize is the maximum number of records in a page, it defaults to 100 and is capped at 1000
+	PageSize int32 `protobuf:"varint,9,opt,name=pageSize,proto3" json:"pageSize,omitempty"`
+	// pageToken is the nextPageToken of a previous response
+	PageToken string `protobuf:"bytes,10,opt,name=pageToken,proto3" json:"pageToken,omitempty"`
+	// fieldMask selects the RawRecord fields which are returned, all fields are returned when it is empty
+	FieldMask *field_mask.FieldMask `protobuf:"bytes,11,opt,name=fieldMask,proto3" json:"fieldMask,omitempty"`
+	// stations are matched against the transactionInfo of a record
+	Stations []string `protobuf:"bytes,12,rep,name=stations,proto3" json:"stations,omitempty"`
+	// includeTotalCount adds the number of records matching the filters to the last chunk of the page
+	IncludeTotalCount bool `protobuf:"varint,13,opt,name=includeTotalCount,proto3" json:"includeTotalCount,omitempty"`
+}
+
+func (x *ListRawRecordsRequest) Reset() {
+	*x = ListRawRecordsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_raw_records_service_v2_raw_records_service_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListRawRecordsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRawRecordsRequest) ProtoMessage() {}
+
+func (x *ListRawRecordsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_raw_records_service_v2_raw_records_service_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRawRecordsRequest.ProtoReflect.Descriptor instead.
+func (*ListRawRecordsRequest) Descriptor() ([]byte, []int) {
+	return file_raw_records_service_v2_raw_records_service_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ListRawRecordsRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *ListRawRecordsRequest) GetCardNumber() string {
+	if x != nil {
+		return x.CardNumber
+	}
+	return ""
+}
+
+func (x *ListRawRecordsRequest) GetAnalyzeRequestId() string {
+	if x != nil {
+		return x.AnalyzeRequestId
+	}
+	return ""
+}
+
+func (x *ListRawRecordsRequest) GetStartDateTime() *timestamp.Timestamp {
+	if x != nil {
+		return x.StartDateTime
+	}
+	return nil
+}
+
+func (x *ListRawRecordsRequest) GetEndDateTime() *timestamp.Timestamp {
+	if x != nil {
+		return x.EndDateTime
+	}
+	return nil
+}
+
+func (x *ListRawRecordsRequest) GetPtos() []string {
+	if x != nil {
+		return x.Ptos
+	}
+	return nil
+}
+
+func (x *ListRawRecordsRequest) GetModalTypes() []string {
+	if x != nil {
+		return x.ModalTypes
+	}
+	return nil
+}
+
+func (x *ListRawRecordsRequest) GetTransactionNames() []string {
+	if x != nil {
+		return x.TransactionNames
+	}
+	return nil
+}
+
+func (x *ListRawRecordsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListRawRecordsRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+func (x *ListRawRecordsRequest) GetFieldMask() *field_mask.FieldMask {
+	if x != nil {
+		return x.FieldMask
+	}
+	return nil
+}
+
+func (x *ListRawRecordsRequest) GetStations() []string {
+	if x != nil {
+		return x.Stations
+	}
+	return nil
+}
+
+func (x *ListRawRecordsRequest) GetIncludeTotalCount() bool {
+	if x != nil {
+		return x.IncludeTotalCount
+	}
+	return false
+}
+
+// ListRawRecordsResponse is a chunk of a page. The last chunk of a page contains the nextPageToken.
+type ListRawRecordsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	RawRecords    []*RawRecord `protobuf:"bytes,1,rep,name=rawRecords,proto3" json:"rawRecords,omitempty"`
+	NextPageToken string       `protobuf:"bytes,2,opt,name=nextPageToken,proto3" json:"nextPageToken,omitempty"`
+	// pageTokens contains the token which continues the list after each record in rawRecords
+	PageTokens []string `protobuf:"bytes,3,rep,name=pageTokens,proto3" json:"pageTokens,omitempty"`
+	TotalCount int64    `protobuf:"varint,4,opt,name=totalCount,proto3" json:"totalCount,omitempty"`
+}
+
+func (x *ListRawRecordsResponse) Reset() {
+	*x = ListRawRecordsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_raw_records_service_v2_raw_records_service_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListRawRecordsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRawRecordsResponse) ProtoMessage() {}
+
+func (x *ListRawRecordsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_raw_records_service_v2_raw_records_service_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRawRecordsResponse.ProtoReflect.Descriptor instead.
+func (*ListRawRecordsResponse) Descriptor() ([]byte, []int) {
+	return file_raw_records_service_v2_raw_records_service_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ListRawRecordsResponse) GetRawRecords() []*RawRecord {
+	if x != nil {
+		return x.RawRecords
+	}
+	return nil
+}
+
+func (x *ListRawRecordsResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+func (x *ListRawRecordsResponse) GetPageTokens() []string {
+	if x != nil {
+		return x.PageTokens
+	}
+	return nil
+}
+
+func (x *ListRawRecordsResponse) GetTotalCount() int64 {
+	if x != nil {
+		return x.TotalCount
+	}
+	return 0
+}
+
+var File_raw_records_service_v2_raw_records_service_proto protoreflect.FileDescriptor
+
+var file_raw_records_service_v2_raw_records_service_proto_rawDesc = []byte{
+	0x0a, 0x30, 0x72, 0x61, 0x77, 0x2d, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x2d, 0x73, 0x65,
+	0x72, 0x76, 0x69, 0x63, 0x65, 0x2f, 0x76, 0x32, 0x2f, 0x72, 0x61, 0x77, 0x2d, 0x72, 0x65, 0x63,
+	0x6f, 0x72, 0x64, 0x73, 0x2d, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x12, 0x0f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73,
+	0x2e, 0x76, 0x32, 0x1a, 0x1f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x77, 0x72, 0x61, 0x70, 0x70, 0x65, 0x72, 0x73, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x20, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x5f, 0x6d, 0x61, 0x73, 0x6b,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xa9, 0x07, 0x0a, 0x09, 0x52, 0x61, 0x77, 0x52, 0x65,
+	0x63, 0x6f, 0x72, 0x64, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x02, 0x69, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x75, 0x73, 0x65, 0x72, 0x49, 0x64, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x75, 0x73, 0x65, 0x72, 0x49, 0x64, 0x12, 0x2a, 0x0a, 0x10,
+	0x61, 0x6e, 0x61, 0x6c, 0x79, 0x7a, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x49, 0x64,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x10, 0x61, 0x6e, 0x61, 0x6c, 0x79, 0x7a, 0x65, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x49, 0x64, 0x12, 0x1e, 0x0a, 0x0a, 0x63, 0x61, 0x72, 0x64,
+	0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x63, 0x61,
+	0x72, 0x64, 0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x12, 0x20, 0x0a, 0x0b, 0x63, 0x68, 0x65, 0x63,
+	0x6b, 0x49, 0x6e, 0x49, 0x6e, 0x66, 0x6f, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x63,
+	0x68, 0x65, 0x63, 0x6b, 0x49, 0x6e, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x20, 0x0a, 0x0b, 0x63, 0x68,
+	0x65, 0x63, 0x6b, 0x49, 0x6e, 0x54, 0x65, 0x78, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0b, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x49, 0x6e, 0x54, 0x65, 0x78, 0x74, 0x12, 0x30, 0x0a, 0x04,
+	0x66, 0x61, 0x72, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x67, 0x6f, 0x6f,
+	0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x6f, 0x75,
+	0x62, 0x6c, 0x65, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x52, 0x04, 0x66, 0x61, 0x72, 0x65, 0x12, 0x28,
+	0x0a, 0x0f, 0x66, 0x61, 0x72, 0x65, 0x43, 0x61, 0x6c, 0x63, 0x75, 0x6c, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x66, 0x61, 0x72, 0x65, 0x43, 0x61, 0x6c,
+	0x63, 0x75, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1a, 0x0a, 0x08, 0x66, 0x61, 0x72, 0x65,
+	0x54, 0x65, 0x78, 0x74, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x66, 0x61, 0x72, 0x65,
+	0x54, 0x65, 0x78, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x6d, 0x6f, 0x64, 0x61, 0x6c, 0x54, 0x79, 0x70,
+	0x65, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6d, 0x6f, 0x64, 0x61, 0x6c, 0x54, 0x79,
+	0x70, 0x65, 0x12, 0x20, 0x0a, 0x0b, 0x70, 0x72, 0x6f, 0x64, 0x75, 0x63, 0x74, 0x49, 0x6e, 0x66,
+	0x6f, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x70, 0x72, 0x6f, 0x64, 0x75, 0x63, 0x74,
+	0x49, 0x6e, 0x66, 0x6f, 0x12, 0x20, 0x0a, 0x0b, 0x70, 0x72, 0x6f, 0x64, 0x75, 0x63, 0x74, 0x54,
+	0x65, 0x78, 0x74, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x70, 0x72, 0x6f, 0x64, 0x75,
+	0x63, 0x74, 0x54, 0x65, 0x78, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x70, 0x74, 0x6f, 0x18, 0x0d, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x03, 0x70, 0x74, 0x6f, 0x12, 0x4c, 0x0a, 0x13, 0x74, 0x72, 0x61, 0x6e,
+	0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x44, 0x61, 0x74, 0x65, 0x54, 0x69, 0x6d, 0x65, 0x18,
+	0x0e, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d,
+	0x70, 0x52, 0x13, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x44, 0x61,
+	0x74, 0x65, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x28, 0x0a, 0x0f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x61,
+	0x63, 0x74, 0x69, 0x6f, 0x6e, 0x49, 0x6e, 0x66, 0x6f, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x49, 0x6e, 0x66, 0x6f,
+	0x12, 0x28, 0x0a, 0x0f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x4e,
+	0x61, 0x6d, 0x65, 0x18, 0x10, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x74, 0x72, 0x61, 0x6e, 0x73,
+	0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x3a, 0x0a, 0x09, 0x65, 0x50,
+	0x75, 0x72, 0x73, 0x65, 0x4d, 0x75, 0x74, 0x18, 0x11, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1c, 0x2e,
+	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e,
+	0x44, 0x6f, 0x75, 0x62, 0x6c, 0x65, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x52, 0x09, 0x65, 0x50, 0x75,
+	0x72, 0x73, 0x65, 0x4d, 0x75, 0x74, 0x12, 0x24, 0x0a, 0x0d, 0x65, 0x50, 0x75, 0x72, 0x73, 0x65,
+	0x4d, 0x75, 0x74, 0x49, 0x6e, 0x66, 0x6f, 0x18, 0x12, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x65,
+	0x50, 0x75, 0x72, 0x73, 0x65, 0x4d, 0x75, 0x74, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x36, 0x0a, 0x16,
+	0x74, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x45, 0x78, 0x70, 0x6c, 0x61,
+	0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x13, 0x20, 0x01, 0x28, 0x09, 0x52, 0x16, 0x74, 0x72,
+	0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x45, 0x78, 0x70, 0x6c, 0x61, 0x6e, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x12, 0x30, 0x0a, 0x13, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74,
+	0x69, 0x6f, 0x6e, 0x50, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x18, 0x14, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x13, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x50, 0x72,
+	0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65,
+	0x18, 0x15, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x12, 0x38,
+	0x0a, 0x09, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x18, 0x16, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x63,
+	0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x12, 0x38, 0x0a, 0x09, 0x75, 0x70, 0x64, 0x61,
+	0x74, 0x65, 0x64, 0x41, 0x74, 0x18, 0x17, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69,
+	0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64,
+	0x41, 0x74, 0x22, 0x99, 0x04, 0x0a, 0x15, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x61, 0x77, 0x52, 0x65,
+	0x63, 0x6f, 0x72, 0x64, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x06,
+	0x75, 0x73, 0x65, 0x72, 0x49, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x75, 0x73,
+	0x65, 0x72, 0x49, 0x64, 0x12, 0x1e, 0x0a, 0x0a, 0x63, 0x61, 0x72, 0x64, 0x4e, 0x75, 0x6d, 0x62,
+	0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x63, 0x61, 0x72, 0x64, 0x4e, 0x75,
+	0x6d, 0x62, 0x65, 0x72, 0x12, 0x2a, 0x0a, 0x10, 0x61, 0x6e, 0x61, 0x6c, 0x79, 0x7a, 0x65, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x49, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x10,
+	0x61, 0x6e, 0x61, 0x6c, 0x79, 0x7a, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x49, 0x64,
+	0x12, 0x40, 0x0a, 0x0d, 0x73, 0x74, 0x61, 0x72, 0x74, 0x44, 0x61, 0x74, 0x65, 0x54, 0x69, 0x6d,
+	0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74,
+	0x61, 0x6d, 0x70, 0x52, 0x0d, 0x73, 0x74, 0x61, 0x72, 0x74, 0x44, 0x61, 0x74, 0x65, 0x54, 0x69,
+	0x6d, 0x65, 0x12, 0x3c, 0x0a, 0x0b, 0x65, 0x6e, 0x64, 0x44, 0x61, 0x74, 0x65, 0x54, 0x69, 0x6d,
+	0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74,
+	0x61, 0x6d, 0x70, 0x52, 0x0b, 0x65, 0x6e, 0x64, 0x44, 0x61, 0x74, 0x65, 0x54, 0x69, 0x6d, 0x65,
+	0x12, 0x12, 0x0a, 0x04, 0x70, 0x74, 0x6f, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x09, 0x52, 0x04,
+	0x70, 0x74, 0x6f, 0x73, 0x12, 0x1e, 0x0a, 0x0a, 0x6d, 0x6f, 0x64, 0x61, 0x6c, 0x54, 0x79, 0x70,
+	0x65, 0x73, 0x18, 0x07, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0a, 0x6d, 0x6f, 0x64, 0x61, 0x6c, 0x54,
+	0x79, 0x70, 0x65, 0x73, 0x12, 0x2a, 0x0a, 0x10, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74,
+	0x69, 0x6f, 0x6e, 0x4e, 0x61, 0x6d, 0x65, 0x73, 0x18, 0x08, 0x20, 0x03, 0x28, 0x09, 0x52, 0x10,
+	0x74, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x4e, 0x61, 0x6d, 0x65, 0x73,
+	0x12, 0x1a, 0x0a, 0x08, 0x70, 0x61, 0x67, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x18, 0x09, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x08, 0x70, 0x61, 0x67, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x1c, 0x0a, 0x09,
+	0x70, 0x61, 0x67, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x09, 0x70, 0x61, 0x67, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x38, 0x0a, 0x09, 0x66, 0x69,
+	0x65, 0x6c, 0x64, 0x4d, 0x61, 0x73, 0x6b, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e,
+	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e,
+	0x46, 0x69, 0x65, 0x6c, 0x64, 0x4d, 0x61, 0x73, 0x6b, 0x52, 0x09, 0x66, 0x69, 0x65, 0x6c, 0x64,
+	0x4d, 0x61, 0x73, 0x6b, 0x12, 0x1a, 0x0a, 0x08, 0x73, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73,
+	0x18, 0x0c, 0x20, 0x03, 0x28, 0x09, 0x52, 0x08, 0x73, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73,
+	0x12, 0x2c, 0x0a, 0x11, 0x69, 0x6e, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x54, 0x6f, 0x74, 0x61, 0x6c,
+	0x43, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x08, 0x52, 0x11, 0x69, 0x6e, 0x63,
+	0x6c, 0x75, 0x64, 0x65, 0x54, 0x6f, 0x74, 0x61, 0x6c, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x22, 0xba,
+	0x01, 0x0a, 0x16, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x61, 0x77, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64,
+	0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3a, 0x0a, 0x0a, 0x72, 0x61, 0x77,
+	0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1a, 0x2e,
+	0x74, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x2e, 0x76, 0x32, 0x2e,
+	0x52, 0x61, 0x77, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x52, 0x0a, 0x72, 0x61, 0x77, 0x52, 0x65,
+	0x63, 0x6f, 0x72, 0x64, 0x73, 0x12, 0x24, 0x0a, 0x0d, 0x6e, 0x65, 0x78, 0x74, 0x50, 0x61, 0x67,
+	0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x6e, 0x65,
+	0x78, 0x74, 0x50, 0x61, 0x67, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x1e, 0x0a, 0x0a, 0x70,
+	0x61, 0x67, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52,
+	0x0a, 0x70, 0x61, 0x67, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x73, 0x12, 0x1e, 0x0a, 0x0a, 0x74,
+	0x6f, 0x74, 0x61, 0x6c, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x0a, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x32, 0x7a, 0x0a, 0x11, 0x52,
+	0x61, 0x77, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65,
+	0x12, 0x65, 0x0a, 0x0e, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x61, 0x77, 0x52, 0x65, 0x63, 0x6f, 0x72,
+	0x64, 0x73, 0x12, 0x26, 0x2e, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e,
+	0x73, 0x2e, 0x76, 0x32, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x61, 0x77, 0x52, 0x65, 0x63, 0x6f,
+	0x72, 0x64, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x27, 0x2e, 0x74, 0x72, 0x61,
+	0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x2e, 0x76, 0x32, 0x2e, 0x4c, 0x69, 0x73,
+	0x74, 0x52, 0x61, 0x77, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x22, 0x00, 0x30, 0x01, 0x42, 0x2f, 0x5a, 0x2d, 0x72, 0x61, 0x77, 0x2d, 0x72,
+	0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x2d, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2f, 0x76,
+	0x32, 0x3b, 0x72, 0x61, 0x77, 0x5f, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x5f, 0x73, 0x65,
+	0x72, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x76, 0x32, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_raw_records_service_v2_raw_records_service_proto_rawDescOnce sync.Once
+	file_raw_records_service_v2_raw_records_service_proto_rawDescData = file_raw_records_service_v2_raw_records_service_proto_rawDesc
+)
+
+func file_raw_records_service_v2_raw_records_service_proto_rawDescGZIP() []byte {
+	file_raw_records_service_v2_raw_records_service_proto_rawDescOnce.Do(func() {
+		file_raw_records_service_v2_raw_records_service_proto_rawDescData = protoimpl.X.CompressGZIP(file_raw_records_service_v2_raw_records_service_proto_rawDescData)
+	})
+	return file_raw_records_service_v2_raw_records_service_proto_rawDescData
+}
+
+var file_raw_records_service_v2_raw_records_service_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_raw_records_service_v2_raw_records_service_proto_goTypes = []interface{}{
+	(*RawRecord)(nil),              // 0: transactions.v2.RawRecord
+	(*ListRawRecordsRequest)(nil),  // 1: transactions.v2.ListRawRecordsRequest
+	(*ListRawRecordsResponse)(nil), // 2: transactions.v2.ListRawRecordsResponse
+	(*wrappers.DoubleValue)(nil),   // 3: google.protobuf.DoubleValue
+	(*timestamp.Timestamp)(nil),    // 4: google.protobuf.Timestamp
+	(*field_mask.FieldMask)(nil),   // 5: google.protobuf.FieldMask
+}
+var file_raw_records_service_v2_raw_records_service_proto_depIdxs = []int32{
+	3,  // 0: transactions.v2.RawRecord.fare:type_name -> google.protobuf.DoubleValue
+	4,  // 1: transactions.v2.RawRecord.transactionDateTime:type_name -> google.protobuf.Timestamp
+	3,  // 2: transactions.v2.RawRecord.ePurseMut:type_name -> google.protobuf.DoubleValue
+	4,  // 3: transactions.v2.RawRecord.createdAt:type_name -> google.protobuf.Timestamp
+	4,  // 4: transactions.v2.RawRecord.updatedAt:type_name -> google.protobuf.Timestamp
+	4,  // 5: transactions.v2.ListRawRecordsRequest.startDateTime:type_name -> google.protobuf.Timestamp
+	4,  // 6: transactions.v2.ListRawRecordsRequest.endDateTime:type_name -> google.protobuf.Timestamp
+	5,  // 7: transactions.v2.ListRawRecordsRequest.fieldMask:type_name -> google.protobuf.FieldMask
+	0,  // 8: transactions.v2.ListRawRecordsResponse.rawRecords:type_name -> transactions.v2.RawRecord
+	1,  // 9: transactions.v2.RawRecordsService.ListRawRecords:input_type -> transactions.v2.ListRawRecordsRequest
+	2,  // 10: transactions.v2.RawRecordsService.ListRawRecords:output_type -> transactions.v2.ListRawRecordsResponse
+	10, // [10:11] is the sub-list for method output_type
+	9,  // [9:10] is the sub-list for method input_type
+	9,  // [9:9] is the sub-list for extension type_name
+	9,  // [9:9] is the sub-list for extension extendee
+	0,  // [0:9] is the sub-list for field type_name
+}
+
+func init() { file_raw_records_service_v2_raw_records_service_proto_init() }
+func file_raw_records_service_v2_raw_records_service_proto_init() {
+	if File_raw_records_service_v2_raw_records_service_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_raw_records_service_v2_raw_records_service_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RawRecord); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_raw_records_service_v2_raw_records_service_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListRawRecordsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_raw_records_service_v2_raw_records_service_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListRawRecordsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_raw_records_service_v2_raw_records_service_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_raw_records_service_v2_raw_records_service_proto_goTypes,
+		DependencyIndexes: file_raw_records_service_v2_raw_records_service_proto_depIdxs,
+		MessageInfos:      file_raw_records_service_v2_raw_records_service_proto_msgTypes,
+	}.Build()
+	File_raw_records_service_v2_raw_records_service_proto = out.File
+	file_raw_records_service_v2_raw_records_service_proto_rawDesc = nil
+	file_raw_records_service_v2_raw_records_service_proto_goTypes = nil
+	file_raw_records_service_v2_raw_records_service_proto_depIdxs = nil
+}
@@ -0,0 +1,124 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+
+package raw_records_service_v2
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion7
+
+// RawRecordsServiceClient is the client API for RawRecordsService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type RawRecordsServiceClient interface {
+	ListRawRecords(ctx context.Context, in *ListRawRecordsRequest, opts ...grpc.CallOption) (RawRecordsService_ListRawRecordsClient, error)
+}
+
+type rawRecordsServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewRawRecordsServiceClient(cc grpc.ClientConnInterface) RawRecordsServiceClient {
+	return &rawRecordsServiceClient{cc}
+}
+
+func (c *rawRecordsServiceClient) ListRawRecords(ctx context.Context, in *ListRawRecordsRequest, opts ...grpc.CallOption) (RawRecordsService_ListRawRecordsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_RawRecordsService_serviceDesc.Streams[0], "/transactions.v2.RawRecordsService/ListRawRecords", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &rawRecordsServiceListRawRecordsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type RawRecordsService_ListRawRecordsClient interface {
+	Recv() (*ListRawRecordsResponse, error)
+	grpc.ClientStream
+}
+
+type rawRecordsServiceListRawRecordsClient struct {
+	grpc.ClientStream
+}
+
+func (x *rawRecordsServiceListRawRecordsClient) Recv() (*ListRawRecordsResponse, error) {
+	m := new(ListRawRecordsResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// RawRecordsServiceServer is the server API for RawRecordsService service.
+// All implementations must embed UnimplementedRawRecordsServiceServer
+// for forward compatibility
+type RawRecordsServiceServer interface {
+	ListRawRecords(*ListRawRecordsRequest, RawRecordsService_ListRawRecordsServer) error
+	mustEmbedUnimplementedRawRecordsServiceServer()
+}
+
+// UnimplementedRawRecordsServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedRawRecordsServiceServer struct {
+}
+
+func (UnimplementedRawRecordsServiceServer) ListRawRecords(*ListRawRecordsRequest, RawRecordsService_ListRawRecordsServer) error {
+	return status.Errorf(codes.Unimplemented, "method ListRawRecords not implemented")
+}
+func (UnimplementedRawRecordsServiceServer) mustEmbedUnimplementedRawRecordsServiceServer() {}
+
+// UnsafeRawRecordsServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to RawRecordsServiceServer will
+// result in compilation errors.
+type UnsafeRawRecordsServiceServer interface {
+	mustEmbedUnimplementedRawRecordsServiceServer()
+}
+
+func RegisterRawRecordsServiceServer(s *grpc.Server, srv RawRecordsServiceServer) {
+	s.RegisterService(&_RawRecordsService_serviceDesc, srv)
+}
+
+func _RawRecordsService_ListRawRecords_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListRawRecordsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(RawRecordsServiceServer).ListRawRecords(m, &rawRecordsServiceListRawRecordsServer{stream})
+}
+
+type RawRecordsService_ListRawRecordsServer interface {
+	Send(*ListRawRecordsResponse) error
+	grpc.ServerStream
+}
+
+type rawRecordsServiceListRawRecordsServer struct {
+	grpc.ServerStream
+}
+
+func (x *rawRecordsServiceListRawRecordsServer) Send(m *ListRawRecordsResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _RawRecordsService_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "transactions.v2.RawRecordsService",
+	HandlerType: (*RawRecordsServiceServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ListRawRecords",
+			Handler:       _RawRecordsService_ListRawRecords_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "raw-records-service/v2/raw-records-service.proto",
+}
@@ -0,0 +1,105 @@
+package redact
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Placeholder replaces the values which must never be logged
+const Placeholder = "[REDACTED]"
+
+var (
+	// credentialRegex matches credentials in JSON, form and query strings e.g. "password":"secret" or client_secret=secret
+	credentialRegex = regexp.MustCompile(`(?i)("?[a-z_]*(?:password|secret|token|username)"?\s*[:=]\s*)("[^"]*"|[^\s&,;"}]+)`)
+
+	// jwtRegex matches JSON web tokens which are base64url encoded JSON objects
+	jwtRegex = regexp.MustCompile(`eyJ[A-Za-z0-9_-]*\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`)
+
+	// bearerRegex matches the values of Authorization headers
+	bearerRegex = regexp.MustCompile(`(?i)(bearer\s+)\S+`)
+
+	// accessTokenRegex matches personal access tokens of the GraphQL API
+	accessTokenRegex = regexp.MustCompile(`ovat_[A-Za-z0-9_-]+`)
+
+	emailRegex = regexp.MustCompile(`([A-Za-z0-9._%+-])[A-Za-z0-9._%+-]*@([A-Za-z0-9.-]+\.[A-Za-z]{2,})`)
+
+	// cardNumberRegex matches the 16 digits of an ov-chipkaart number with optional separators
+	cardNumberRegex = regexp.MustCompile(`\b(?:\d[ -]?){12}(\d{4})\b`)
+
+	// sensitiveKeyRegex matches the keys of values which are redacted completely
+	sensitiveKeyRegex = regexp.MustCompile(`(?i)(password|secret|token|authorization|cookie|credential|username|recovery_code)`)
+)
+
+// String masks the card numbers, credentials, tokens and emails in a text
+func String(value string) string {
+	value = credentialRegex.ReplaceAllStringFunc(value, func(match string) string {
+		parts := credentialRegex.FindStringSubmatch(match)
+		if strings.HasPrefix(parts[2], `"`) {
+			return parts[1] + `"` + Placeholder + `"`
+		}
+		return parts[1] + Placeholder
+	})
+	value = jwtRegex.ReplaceAllString(value, Placeholder)
+	value = bearerRegex.ReplaceAllString(value, "${1}"+Placeholder)
+	value = accessTokenRegex.ReplaceAllString(value, "ovat_"+Placeholder)
+	value = emailRegex.ReplaceAllString(value, "${1}***@${2}")
+	value = cardNumberRegex.ReplaceAllString(value, "************${1}")
+
+	return value
+}
+
+// Email masks the local part of an email address e.g. j***@example.com
+func Email(email string) string {
+	return emailRegex.ReplaceAllString(email, "${1}***@${2}")
+}
+
+// CardNumber masks all but the last 4 digits of a card number
+func CardNumber(cardNumber string) string {
+	if len(cardNumber) <= 4 {
+		return strings.Repeat("*", len(cardNumber))
+	}
+
+	return strings.Repeat("*", len(cardNumber)-4) + cardNumber[len(cardNumber)-4:]
+}
+
+// KeyValues masks the values of key value pairs as they are passed to a logger.
+// Values are masked by their key first e.g. password or card_number and then by their content.
+func KeyValues(keyValuePairs ...interface{}) []interface{} {
+	result := make([]interface{}, len(keyValuePairs))
+	for index := range keyValuePairs {
+		if index%2 == 0 {
+			result[index] = keyValuePairs[index]
+			continue
+		}
+
+		result[index] = Value(fmt.Sprint(keyValuePairs[index-1]), keyValuePairs[index])
+	}
+
+	return result
+}
+
+// Value masks a single value using its key
+func Value(key string, value interface{}) interface{} {
+	switch {
+	case value == nil:
+		return nil
+	case sensitiveKeyRegex.MatchString(key):
+		return Placeholder
+	case strings.Contains(strings.ToLower(key), "card_number"):
+		return CardNumber(fmt.Sprint(value))
+	}
+
+	switch typedValue := value.(type) {
+	case string:
+		return String(typedValue)
+	case error:
+		return String(typedValue.Error())
+	case fmt.Stringer:
+		return String(typedValue.String())
+	case []byte:
+		return String(string(typedValue))
+	default:
+		return value
+	}
+}
@@ -0,0 +1,235 @@
+package serviceauth
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	internalContext "github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/context"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/tracing"
+	"github.com/palantir/stacktrace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	metadataKeyAuthorization = "authorization"
+	bearerPrefix             = "Bearer "
+
+	// healthServicePrefix is the prefix of the health check RPCs which are public so that probes don't need a token
+	healthServicePrefix = "/grpc.health.v1.Health/"
+)
+
+// Policy maps the full name of an RPC e.g. /raw_records_service.RawRecordsService/StoreTransactions to the
+// services which may call it. RPCs which are not in the policy are denied.
+type Policy map[string][]string
+
+// GRPCServerOptions enable TLS and authorize every RPC with the policy
+func GRPCServerOptions(config Config, policy Policy) ([]grpc.ServerOption, error) {
+	authorizer := &authorizer{config: config, policy: policy}
+
+	options := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(authorizer.unaryServerInterceptor),
+		grpc.ChainStreamInterceptor(authorizer.streamServerInterceptor),
+	}
+
+	if config.Insecure {
+		return options, nil
+	}
+
+	serverCredentials, err := ServerCredentials(config)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "cannot create the TLS credentials of the server")
+	}
+
+	return append(options, grpc.Creds(serverCredentials)), nil
+}
+
+// GRPCDialOptions enable TLS and send a token for the audience service with every RPC. The token contains
+// the id of the user in the context so that the server can check whose data the RPC is for.
+func GRPCDialOptions(config Config, audience string) ([]grpc.DialOption, error) {
+	options := []grpc.DialOption{grpc.WithPerRPCCredentials(tokenCredentials{config: config, audience: audience})}
+
+	if config.Insecure {
+		return append(options, grpc.WithInsecure()), nil
+	}
+
+	clientCredentials, err := ClientCredentials(config)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "cannot create the TLS credentials of the client")
+	}
+
+	return append(options, grpc.WithTransportCredentials(clientCredentials)), nil
+}
+
+// AuthorizeUser checks that the caller of an RPC acts for the user who owns the data
+func AuthorizeUser(ctx context.Context, userID string) error {
+	if tracing.ValueFromContext(ctx, internalContext.KeyUserID) != userID {
+		return status.Errorf(codes.PermissionDenied, "the caller cannot access the data of user %s", userID)
+	}
+
+	return nil
+}
+
+// tokenCredentials signs a service token for every RPC
+type tokenCredentials struct {
+	config   Config
+	audience string
+}
+
+// GetRequestMetadata adds the token to the metadata of an RPC
+func (tokens tokenCredentials) GetRequestMetadata(ctx context.Context, _ ...string) (map[string]string, error) {
+	token, err := signToken(
+		tokens.config.TokenSecret,
+		tokens.config.ServiceName,
+		tokens.audience,
+		tracing.ValueFromContext(ctx, internalContext.KeyUserID),
+		time.Now(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]string{metadataKeyAuthorization: bearerPrefix + token}, nil
+}
+
+// RequireTransportSecurity prevents tokens from being sent over plaintext connections unless TLS is disabled
+func (tokens tokenCredentials) RequireTransportSecurity() bool {
+	return !tokens.config.Insecure
+}
+
+var _ credentials.PerRPCCredentials = tokenCredentials{}
+
+type authorizer struct {
+	config Config
+	policy Policy
+}
+
+func (authorizer *authorizer) unaryServerInterceptor(
+	ctx context.Context,
+	request interface{},
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (interface{}, error) {
+	ctx, err := authorizer.authorize(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	err = authorizeRequest(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	return handler(ctx, request)
+}
+
+func (authorizer *authorizer) streamServerInterceptor(
+	server interface{},
+	stream grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	ctx, err := authorizer.authorize(stream.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+
+	return handler(server, &serverStream{ServerStream: stream, ctx: ctx})
+}
+
+// authorize verifies the service token and checks that the caller may call the method. The user id of the
+// context is replaced by the subject of the token because the propagated metadata is not signed.
+func (authorizer *authorizer) authorize(ctx context.Context, method string) (context.Context, error) {
+	if strings.HasPrefix(method, healthServicePrefix) {
+		return ctx, nil
+	}
+
+	claims, err := authorizer.authenticate(ctx)
+	if err != nil {
+		return ctx, status.Error(codes.Unauthenticated, stacktrace.RootCause(err).Error())
+	}
+
+	if !contains(authorizer.policy[method], claims.Caller()) {
+		return ctx, status.Errorf(codes.PermissionDenied, "%s is not allowed to call %s", claims.Caller(), method)
+	}
+
+	return tracing.WithValue(ctx, internalContext.KeyUserID, claims.UserID()), nil
+}
+
+func (authorizer *authorizer) authenticate(ctx context.Context) (*Claims, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	values := md.Get(metadataKeyAuthorization)
+	if len(values) == 0 || !strings.HasPrefix(values[0], bearerPrefix) {
+		return nil, stacktrace.NewError("the RPC doesn't have a service token")
+	}
+
+	claims, err := parseToken(authorizer.config.TokenSecret, authorizer.config.ServiceName, strings.TrimPrefix(values[0], bearerPrefix), time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if authorizer.config.Insecure {
+		return claims, nil
+	}
+
+	// the token must be signed by the service which presented the client certificate
+	var identity string
+	if caller, ok := peer.FromContext(ctx); ok {
+		if tlsInfo, ok := caller.AuthInfo.(credentials.TLSInfo); ok {
+			identity = peerIdentity(tlsInfo.State)
+		}
+	}
+
+	if identity != claims.Caller() {
+		return nil, stacktrace.NewError("the service token of %s was sent with the certificate of %q", claims.Caller(), identity)
+	}
+
+	return claims, nil
+}
+
+// authorizeRequest checks the user of requests which contain a user id
+func authorizeRequest(ctx context.Context, request interface{}) error {
+	userRequest, ok := request.(interface{ GetUserId() string })
+	if !ok {
+		return nil
+	}
+
+	return AuthorizeUser(ctx, userRequest.GetUserId())
+}
+
+// serverStream checks the user of every received message
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context returns the context with the authorized user
+func (stream *serverStream) Context() context.Context {
+	return stream.ctx
+}
+
+// RecvMsg rejects messages for other users
+func (stream *serverStream) RecvMsg(message interface{}) error {
+	err := stream.ServerStream.RecvMsg(message)
+	if err != nil {
+		return err
+	}
+
+	return authorizeRequest(stream.ctx, message)
+}
+
+func contains(values []string, value string) bool {
+	for _, item := range values {
+		if item == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,174 @@
+package serviceauth
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"testing"
+	"time"
+
+	internalContext "github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/context"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/tracing"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const testMethod = "/raw_records_service.RawRecordsService/StoreTransactions"
+
+// peerContext adds a client certificate with the common name to the context
+func peerContext(ctx context.Context, commonName string) context.Context {
+	certificate := &x509.Certificate{Subject: pkix.Name{CommonName: commonName}}
+
+	return peer.NewContext(ctx, &peer.Peer{
+		AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{certificate}}}},
+	})
+}
+
+func TestAuthorizerAuthorize(t *testing.T) {
+	policy := Policy{testMethod: {ServiceTransactions}}
+	userID := "5f7ae4f2b3c0d54c1a6b8e1d"
+
+	sign := func(caller string) string {
+		token, err := signToken(testSecret, caller, ServiceRawRecords, userID, time.Now())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return bearerPrefix + token
+	}
+
+	tests := []struct {
+		name          string
+		insecure      bool
+		method        string
+		authorization string
+		// certificate is the common name of the client certificate, there is no certificate when it is empty
+		certificate    string
+		expectedCode   codes.Code
+		expectedUserID string
+	}{
+		{
+			name:           "allowed caller with its certificate",
+			method:         testMethod,
+			authorization:  sign(ServiceTransactions),
+			certificate:    ServiceTransactions,
+			expectedCode:   codes.OK,
+			expectedUserID: userID,
+		},
+		{
+			name:           "allowed caller without TLS",
+			insecure:       true,
+			method:         testMethod,
+			authorization:  sign(ServiceTransactions),
+			expectedCode:   codes.OK,
+			expectedUserID: userID,
+		},
+		{
+			name:          "caller which is not in the policy",
+			method:        testMethod,
+			authorization: sign(ServiceAPI),
+			certificate:   ServiceAPI,
+			expectedCode:  codes.PermissionDenied,
+		},
+		{
+			name:          "method which is not in the policy",
+			method:        "/raw_records_service.RawRecordsService/DeleteTransactions",
+			authorization: sign(ServiceTransactions),
+			certificate:   ServiceTransactions,
+			expectedCode:  codes.PermissionDenied,
+		},
+		{
+			name:          "token signed in the name of another service",
+			method:        testMethod,
+			authorization: sign(ServiceTransactions),
+			certificate:   ServiceAPI,
+			expectedCode:  codes.Unauthenticated,
+		},
+		{
+			name:          "token without a client certificate",
+			method:        testMethod,
+			authorization: sign(ServiceTransactions),
+			expectedCode:  codes.Unauthenticated,
+		},
+		{
+			name:         "missing token",
+			method:       testMethod,
+			certificate:  ServiceTransactions,
+			expectedCode: codes.Unauthenticated,
+		},
+		{
+			name:          "token without the bearer prefix",
+			method:        testMethod,
+			authorization: sign(ServiceTransactions)[len(bearerPrefix):],
+			certificate:   ServiceTransactions,
+			expectedCode:  codes.Unauthenticated,
+		},
+		{
+			name:         "health check without a token",
+			method:       healthServicePrefix + "Check",
+			expectedCode: codes.OK,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			authorizer := &authorizer{
+				config: Config{ServiceName: ServiceRawRecords, Insecure: test.insecure, TokenSecret: testSecret},
+				policy: policy,
+			}
+
+			// the propagated user id is not signed so it must be replaced by the subject of the token
+			ctx := tracing.WithValue(context.Background(), internalContext.KeyUserID, "propagated-user-id")
+			if test.authorization != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(metadataKeyAuthorization, test.authorization))
+			}
+			if test.certificate != "" {
+				ctx = peerContext(ctx, test.certificate)
+			}
+
+			ctx, err := authorizer.authorize(ctx, test.method)
+			if code := status.Code(err); code != test.expectedCode {
+				t.Fatalf("authorize returned the code %s, expected %s: %v", code, test.expectedCode, err)
+			}
+
+			if err == nil && test.expectedUserID != "" && tracing.ValueFromContext(ctx, internalContext.KeyUserID) != test.expectedUserID {
+				t.Errorf("authorize set the user %q, expected %q", tracing.ValueFromContext(ctx, internalContext.KeyUserID), test.expectedUserID)
+			}
+		})
+	}
+}
+
+type userRequest struct {
+	userID string
+}
+
+func (request userRequest) GetUserId() string {
+	return request.userID
+}
+
+func TestAuthorizeRequest(t *testing.T) {
+	ctx := tracing.WithValue(context.Background(), internalContext.KeyUserID, "5f7ae4f2b3c0d54c1a6b8e1d")
+
+	tests := []struct {
+		name         string
+		request      interface{}
+		expectedCode codes.Code
+	}{
+		{name: "request for the user", request: userRequest{userID: "5f7ae4f2b3c0d54c1a6b8e1d"}, expectedCode: codes.OK},
+		{name: "request for another user", request: userRequest{userID: "5f7ae4f2b3c0d54c1a6b8e1e"}, expectedCode: codes.PermissionDenied},
+		{name: "request without a user", request: userRequest{}, expectedCode: codes.PermissionDenied},
+		{name: "request which is not for a user", request: struct{}{}, expectedCode: codes.OK},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := authorizeRequest(ctx, test.request)
+			if code := status.Code(err); code != test.expectedCode {
+				t.Errorf("authorizeRequest returned the code %s, expected %s: %v", code, test.expectedCode, err)
+			}
+		})
+	}
+}
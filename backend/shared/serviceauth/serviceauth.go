@@ -0,0 +1,33 @@
+package serviceauth
+
+const (
+	// ServiceAPI is the identity of the GraphQL API
+	ServiceAPI = "api-service"
+
+	// ServiceRawRecords is the identity of the raw records service
+	ServiceRawRecords = "raw-records-service"
+
+	// ServiceTransactions is the identity of the transactions service
+	ServiceTransactions = "transactions-service"
+)
+
+// Config configures how a service authenticates itself to other services and the callers of its RPCs
+type Config struct {
+	// ServiceName is the identity of this service. It must match the common name of its certificate.
+	ServiceName string
+
+	// Insecure disables TLS which is only meant for local development. The service tokens are still required
+	// but any service which knows the token secret can sign them in the name of another service.
+	Insecure bool
+
+	// CertFile and KeyFile are the PEM encoded certificate of this service. It is presented to the clients
+	// and to the servers because TLS is always mutual.
+	CertFile string
+	KeyFile  string
+
+	// CAFile is the PEM encoded certificate authority which signs the certificates of all the services
+	CAFile string
+
+	// TokenSecret signs the service tokens and is shared by all the services
+	TokenSecret string
+}
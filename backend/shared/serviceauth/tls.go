@@ -0,0 +1,82 @@
+package serviceauth
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+
+	"github.com/palantir/stacktrace"
+	"google.golang.org/grpc/credentials"
+)
+
+// ServerCredentials loads the certificate of a gRPC server. The clients must present a certificate which is
+// signed by the CA so that the caller of an RPC is known even when the token secret leaks.
+func ServerCredentials(config Config) (credentials.TransportCredentials, error) {
+	certificate, err := tls.LoadX509KeyPair(config.CertFile, config.KeyFile)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "cannot load the certificate of %s", config.ServiceName)
+	}
+
+	pool, err := loadCertPool(config.CAFile)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "cannot load the CA of the client certificates")
+	}
+
+	return credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		ClientCAs:    pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		MinVersion:   tls.VersionTLS12,
+	}), nil
+}
+
+// ClientCredentials verifies the certificate of a gRPC server with the CA and presents the certificate of this service
+func ClientCredentials(config Config) (credentials.TransportCredentials, error) {
+	pool, err := loadCertPool(config.CAFile)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "cannot load the CA of the server certificates")
+	}
+
+	certificate, err := tls.LoadX509KeyPair(config.CertFile, config.KeyFile)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "cannot load the client certificate of %s", config.ServiceName)
+	}
+
+	return credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		RootCAs:      pool,
+		MinVersion:   tls.VersionTLS12,
+	}), nil
+}
+
+func loadCertPool(path string) (*x509.CertPool, error) {
+	pemBytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "cannot read %s", path)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pemBytes) {
+		return nil, stacktrace.NewError("%s doesn't contain a PEM encoded certificate", path)
+	}
+
+	return pool, nil
+}
+
+// peerIdentity is the common name of a verified client certificate or the first DNS name when the common name is empty
+func peerIdentity(state tls.ConnectionState) string {
+	if len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return ""
+	}
+
+	certificate := state.VerifiedChains[0][0]
+	if certificate.Subject.CommonName != "" {
+		return certificate.Subject.CommonName
+	}
+
+	if len(certificate.DNSNames) > 0 {
+		return certificate.DNSNames[0]
+	}
+
+	return ""
+}
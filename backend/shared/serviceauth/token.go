@@ -0,0 +1,79 @@
+package serviceauth
+
+import (
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/palantir/stacktrace"
+)
+
+const (
+	// tokenLifetime is short because a new token is signed for every RPC
+	tokenLifetime = time.Minute
+
+	// clockSkew is the tolerated difference between the clocks of two services
+	clockSkew = 30 * time.Second
+)
+
+// Claims identify the calling service and the user it acts for
+type Claims struct {
+	jwt.StandardClaims
+}
+
+// Caller is the service which signed the token
+func (claims Claims) Caller() string {
+	return claims.Issuer
+}
+
+// UserID is the user which the caller acts for. It is empty for RPCs which are not made for a user.
+func (claims Claims) UserID() string {
+	return claims.Subject
+}
+
+// signToken signs a token which is only accepted by the audience service
+func signToken(secret string, caller string, audience string, userID string, now time.Time) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, Claims{
+		StandardClaims: jwt.StandardClaims{
+			Issuer:    caller,
+			Audience:  audience,
+			Subject:   userID,
+			IssuedAt:  now.Unix(),
+			ExpiresAt: now.Add(tokenLifetime).Unix(),
+		},
+	})
+
+	tokenString, err := token.SignedString([]byte(secret))
+	if err != nil {
+		return tokenString, stacktrace.Propagate(err, "cannot sign the service token of %s", caller)
+	}
+
+	return tokenString, nil
+}
+
+// parseToken verifies the signature, the audience and the lifetime of a token
+func parseToken(secret string, audience string, tokenString string, now time.Time) (*Claims, error) {
+	claims := &Claims{}
+
+	// the claims are validated below with a tolerance for the clock skew between the services
+	parser := jwt.Parser{ValidMethods: []string{jwt.SigningMethodHS256.Alg()}, SkipClaimsValidation: true}
+	_, err := parser.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		return []byte(secret), nil
+	})
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "cannot verify the service token")
+	}
+
+	if !claims.VerifyAudience(audience, true) {
+		return nil, stacktrace.NewError("service token of %s is for %s instead of %s", claims.Issuer, claims.Audience, audience)
+	}
+
+	if !claims.VerifyExpiresAt(now.Add(-clockSkew).Unix(), true) {
+		return nil, stacktrace.NewError("service token of %s has expired", claims.Issuer)
+	}
+
+	if !claims.VerifyIssuedAt(now.Add(clockSkew).Unix(), true) {
+		return nil, stacktrace.NewError("service token of %s is issued in the future", claims.Issuer)
+	}
+
+	return claims, nil
+}
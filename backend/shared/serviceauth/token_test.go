@@ -0,0 +1,148 @@
+package serviceauth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+const testSecret = "a secret which is at least 32 characters long"
+
+func TestParseToken(t *testing.T) {
+	now := time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	// signWithMethod signs a token which is otherwise valid with another algorithm
+	signWithMethod := func(method jwt.SigningMethod, key interface{}) string {
+		token, err := jwt.NewWithClaims(method, Claims{
+			StandardClaims: jwt.StandardClaims{
+				Issuer:    ServiceAPI,
+				Audience:  ServiceRawRecords,
+				IssuedAt:  now.Unix(),
+				ExpiresAt: now.Add(tokenLifetime).Unix(),
+			},
+		}).SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+
+	tests := []struct {
+		name           string
+		secret         string
+		caller         string
+		audience       string
+		userID         string
+		signedAt       time.Time
+		token          string
+		expectedValid  bool
+		expectedCaller string
+		expectedUserID string
+	}{
+		{
+			name:           "valid token for a user",
+			secret:         testSecret,
+			caller:         ServiceAPI,
+			audience:       ServiceRawRecords,
+			userID:         "5f7ae4f2b3c0d54c1a6b8e1d",
+			signedAt:       now,
+			expectedValid:  true,
+			expectedCaller: ServiceAPI,
+			expectedUserID: "5f7ae4f2b3c0d54c1a6b8e1d",
+		},
+		{
+			name:           "valid token without a user",
+			secret:         testSecret,
+			caller:         ServiceTransactions,
+			audience:       ServiceRawRecords,
+			signedAt:       now,
+			expectedValid:  true,
+			expectedCaller: ServiceTransactions,
+		},
+		{
+			name:     "token signed with another secret",
+			secret:   "another secret which is at least 32 characters long",
+			caller:   ServiceAPI,
+			audience: ServiceRawRecords,
+			signedAt: now,
+		},
+		{
+			name:     "token for another service",
+			secret:   testSecret,
+			caller:   ServiceAPI,
+			audience: ServiceTransactions,
+			signedAt: now,
+		},
+		{
+			name:           "expired token within the clock skew",
+			secret:         testSecret,
+			caller:         ServiceAPI,
+			audience:       ServiceRawRecords,
+			signedAt:       now.Add(-tokenLifetime - clockSkew + time.Second),
+			expectedValid:  true,
+			expectedCaller: ServiceAPI,
+		},
+		{
+			name:     "expired token",
+			secret:   testSecret,
+			caller:   ServiceAPI,
+			audience: ServiceRawRecords,
+			signedAt: now.Add(-tokenLifetime - clockSkew - time.Second),
+		},
+		{
+			name:           "token from a clock which is ahead within the clock skew",
+			secret:         testSecret,
+			caller:         ServiceAPI,
+			audience:       ServiceRawRecords,
+			signedAt:       now.Add(clockSkew - time.Second),
+			expectedValid:  true,
+			expectedCaller: ServiceAPI,
+		},
+		{
+			name:     "token issued in the future",
+			secret:   testSecret,
+			caller:   ServiceAPI,
+			audience: ServiceRawRecords,
+			signedAt: now.Add(clockSkew + time.Second),
+		},
+		{
+			name:  "token signed with another algorithm",
+			token: signWithMethod(jwt.SigningMethodHS512, []byte(testSecret)),
+		},
+		{
+			name:  "unsigned token",
+			token: signWithMethod(jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType),
+		},
+		{
+			name:  "malformed token",
+			token: "not a token",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			token := test.token
+			if token == "" {
+				var err error
+				token, err = signToken(test.secret, test.caller, test.audience, test.userID, test.signedAt)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			claims, err := parseToken(testSecret, ServiceRawRecords, token, now)
+			if (err == nil) != test.expectedValid {
+				t.Fatalf("parseToken returned the error %v, expected the token to be valid: %t", err, test.expectedValid)
+			}
+
+			if err != nil {
+				return
+			}
+
+			if claims.Caller() != test.expectedCaller || claims.UserID() != test.expectedUserID {
+				t.Errorf("parseToken returned the caller %q and user %q, expected %q and %q", claims.Caller(), claims.UserID(), test.expectedCaller, test.expectedUserID)
+			}
+		})
+	}
+}
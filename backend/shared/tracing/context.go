@@ -0,0 +1,44 @@
+package tracing
+
+import (
+	"context"
+	"strings"
+
+	internalContext "github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/context"
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/semconv"
+)
+
+// propagatedKeys are the context values which are sent to other services together with the trace.
+// They are added as attributes to the spans so that traces can be found by request, user or analyze request.
+var propagatedKeys = map[internalContext.Key]label.Key{
+	internalContext.KeyRequestID:        label.Key("request.id"),
+	internalContext.KeyUserID:           semconv.EnduserIDKey,
+	internalContext.KeyAnalyzeRequestID: label.Key("analyze_request.id"),
+}
+
+// WithValue adds a propagated value to the context and to the attributes of the current span
+func WithValue(ctx context.Context, key internalContext.Key, value string) context.Context {
+	if attribute, ok := propagatedKeys[key]; ok {
+		trace.SpanFromContext(ctx).SetAttributes(attribute.String(value))
+	}
+
+	return context.WithValue(ctx, key, value)
+}
+
+// ValueFromContext returns a propagated value or an empty string when it is not set
+func ValueFromContext(ctx context.Context, key internalContext.Key) string {
+	value, _ := ctx.Value(key).(string)
+	return value
+}
+
+// RequestIDFromContext returns the id of the HTTP request which started the trace
+func RequestIDFromContext(ctx context.Context) string {
+	return ValueFromContext(ctx, internalContext.KeyRequestID)
+}
+
+// metadataKey is the gRPC metadata key of a propagated value e.g. x-request-id
+func metadataKey(key internalContext.Key) string {
+	return "x-" + strings.ReplaceAll(string(key), "_", "-")
+}
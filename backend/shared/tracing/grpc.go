@@ -0,0 +1,101 @@
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// GRPCServerOptions continue the traces of the clients and restore the propagated values from the metadata
+func GRPCServerOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(otelgrpc.UnaryServerInterceptor(Tracer()), unaryServerInterceptor),
+		grpc.ChainStreamInterceptor(otelgrpc.StreamServerInterceptor(Tracer()), streamServerInterceptor),
+	}
+}
+
+// GRPCDialOptions send the trace and the propagated values of the context with every RPC
+func GRPCDialOptions() []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithChainUnaryInterceptor(unaryClientInterceptor, otelgrpc.UnaryClientInterceptor(Tracer())),
+		grpc.WithChainStreamInterceptor(streamClientInterceptor, otelgrpc.StreamClientInterceptor(Tracer())),
+	}
+}
+
+func unaryClientInterceptor(
+	ctx context.Context,
+	method string,
+	request, reply interface{},
+	cc *grpc.ClientConn,
+	invoker grpc.UnaryInvoker,
+	options ...grpc.CallOption,
+) error {
+	return invoker(outgoingContext(ctx), method, request, reply, cc, options...)
+}
+
+func streamClientInterceptor(
+	ctx context.Context,
+	desc *grpc.StreamDesc,
+	cc *grpc.ClientConn,
+	method string,
+	streamer grpc.Streamer,
+	options ...grpc.CallOption,
+) (grpc.ClientStream, error) {
+	return streamer(outgoingContext(ctx), desc, cc, method, options...)
+}
+
+func unaryServerInterceptor(
+	ctx context.Context,
+	request interface{},
+	_ *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (interface{}, error) {
+	return handler(incomingContext(ctx), request)
+}
+
+func streamServerInterceptor(
+	server interface{},
+	stream grpc.ServerStream,
+	_ *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	return handler(server, &serverStream{ServerStream: stream, ctx: incomingContext(stream.Context())})
+}
+
+// serverStream replaces the context of a stream with the context containing the propagated values
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context returns the context of the stream
+func (stream *serverStream) Context() context.Context {
+	return stream.ctx
+}
+
+func outgoingContext(ctx context.Context) context.Context {
+	for key := range propagatedKeys {
+		if value := ValueFromContext(ctx, key); value != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, metadataKey(key), value)
+		}
+	}
+
+	return ctx
+}
+
+func incomingContext(ctx context.Context) context.Context {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ctx
+	}
+
+	for key := range propagatedKeys {
+		if values := md.Get(metadataKey(key)); len(values) > 0 {
+			ctx = WithValue(ctx, key, values[0])
+		}
+	}
+
+	return ctx
+}
@@ -0,0 +1,21 @@
+package tracing
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+// NewHTTPClient creates a span for every request which is sent by the client.
+// Requests must be created with the context of the caller for the spans to be part of its trace.
+func NewHTTPClient(client *http.Client) *http.Client {
+	transport := client.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	traced := *client
+	traced.Transport = otelhttp.NewTransport(transport)
+
+	return &traced
+}
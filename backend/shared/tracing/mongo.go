@@ -0,0 +1,78 @@
+package tracing
+
+import (
+	"context"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/event"
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/semconv"
+)
+
+// mongoMonitor creates a span for every command which is sent to MongoDB.
+// The command itself is not recorded because documents contain passwords and tokens.
+type mongoMonitor struct {
+	mutex sync.Mutex
+	spans map[mongoCommandKey]trace.Span
+}
+
+type mongoCommandKey struct {
+	connectionID string
+	requestID    int64
+}
+
+// NewMongoMonitor returns the command monitor which traces the queries of a MongoDB client
+func NewMongoMonitor() *event.CommandMonitor {
+	monitor := &mongoMonitor{spans: map[mongoCommandKey]trace.Span{}}
+
+	return &event.CommandMonitor{
+		Started:   monitor.started,
+		Succeeded: monitor.succeeded,
+		Failed:    monitor.failed,
+	}
+}
+
+func (monitor *mongoMonitor) started(ctx context.Context, evt *event.CommandStartedEvent) {
+	attributes := []label.KeyValue{
+		semconv.DBSystemMongodb,
+		semconv.DBNameKey.String(evt.DatabaseName),
+		semconv.DBOperationKey.String(evt.CommandName),
+	}
+
+	if collection, ok := evt.Command.Lookup(evt.CommandName).StringValueOK(); ok {
+		attributes = append(attributes, semconv.DBMongoDBCollectionKey.String(collection))
+	}
+
+	_, span := Tracer().Start(ctx, "mongodb."+evt.CommandName, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attributes...))
+
+	monitor.mutex.Lock()
+	monitor.spans[mongoCommandKey{connectionID: evt.ConnectionID, requestID: evt.RequestID}] = span
+	monitor.mutex.Unlock()
+}
+
+func (monitor *mongoMonitor) succeeded(_ context.Context, evt *event.CommandSucceededEvent) {
+	if span, ok := monitor.finish(evt.CommandFinishedEvent); ok {
+		span.End()
+	}
+}
+
+func (monitor *mongoMonitor) failed(_ context.Context, evt *event.CommandFailedEvent) {
+	if span, ok := monitor.finish(evt.CommandFinishedEvent); ok {
+		span.SetStatus(codes.Unknown, evt.Failure)
+		span.End()
+	}
+}
+
+func (monitor *mongoMonitor) finish(evt event.CommandFinishedEvent) (span trace.Span, ok bool) {
+	key := mongoCommandKey{connectionID: evt.ConnectionID, requestID: evt.RequestID}
+
+	monitor.mutex.Lock()
+	defer monitor.mutex.Unlock()
+
+	span, ok = monitor.spans[key]
+	delete(monitor.spans, key)
+
+	return span, ok
+}
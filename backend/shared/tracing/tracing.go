@@ -0,0 +1,74 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/palantir/stacktrace"
+	"go.opentelemetry.io/otel/api/global"
+	"go.opentelemetry.io/otel/api/propagation"
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/exporters/otlp"
+	"go.opentelemetry.io/otel/propagators"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/semconv"
+)
+
+// instrumentationName is the name of the tracer which creates the spans of the services
+const instrumentationName = "github.com/AchoArnold/ov-chipkaart-dashboard/backend"
+
+// Config configures where the spans of a service are exported to
+type Config struct {
+	// ServiceName identifies the service in the traces
+	ServiceName string
+
+	// Endpoint is the address of the OTLP collector. Spans are not exported when it is empty.
+	Endpoint string
+
+	// Insecure disables TLS when connecting to the collector
+	Insecure bool
+
+	// SampleRatio is the fraction of the traces which are started by the service that are exported.
+	// Traces which are started by another service follow the sampling decision of that service.
+	SampleRatio float64
+}
+
+// Initialize registers the tracer provider and the propagators which are used by all the instrumentation.
+// The returned function flushes the spans which were not exported yet and must be called before the service exits.
+func Initialize(config Config) (shutdown func(ctx context.Context) error, err error) {
+	global.SetPropagators(propagation.New(
+		propagation.WithInjectors(propagators.TraceContext{}),
+		propagation.WithExtractors(propagators.TraceContext{}),
+	))
+
+	if config.Endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	options := []otlp.ExporterOption{otlp.WithAddress(config.Endpoint)}
+	if config.Insecure {
+		options = append(options, otlp.WithInsecure())
+	}
+
+	exporter, err := otlp.NewExporter(options...)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "cannot create the OTLP exporter for %s", config.Endpoint)
+	}
+
+	spanProcessor := sdktrace.NewBatchSpanProcessor(exporter)
+	global.SetTracerProvider(sdktrace.NewTracerProvider(
+		sdktrace.WithConfig(sdktrace.Config{DefaultSampler: sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.SampleRatio))}),
+		sdktrace.WithResource(resource.New(semconv.ServiceNameKey.String(config.ServiceName))),
+		sdktrace.WithSpanProcessor(spanProcessor),
+	))
+
+	return func(ctx context.Context) error {
+		spanProcessor.Shutdown()
+		return exporter.Shutdown(ctx)
+	}, nil
+}
+
+// Tracer returns the tracer which is used to create spans
+func Tracer() trace.Tracer {
+	return global.Tracer(instrumentationName)
+}
@@ -7,16 +7,23 @@ import (
 	"net"
 	"net/http"
 	"os"
+	"time"
 
 	"github.com/palantir/stacktrace"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/config"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/httpclient"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/lifecycle"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/logger"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/metrics"
 	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/ovchipkaart"
 	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/proto/transactions-service"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/serviceauth"
+	"github.com/AchoArnold/ov-chipkaart-dashboard/backend/shared/tracing"
 	"github.com/golang/protobuf/ptypes"
 	"github.com/golang/protobuf/ptypes/wrappers"
-	"github.com/joho/godotenv"
 	"google.golang.org/grpc"
 	"google.golang.org/protobuf/types/known/wrapperspb"
 )
@@ -27,33 +34,118 @@ type server struct {
 	csvTransactionsService *TransactionFetcherCSVService
 }
 
-func main() {
-	err := godotenv.Load()
-	if err != nil {
-		log.Fatal("error loading .env file")
-	}
+var configuration *config.TransactionsService
 
-	log.Println("server running on port " + os.Getenv("SERVER_ADDRESS"))
+func main() {
+	initializeLogger().Info(context.Background(), "server running on port "+initializeConfig().Server.Address)
 
-	listener, err := net.Listen("tcp", os.Getenv("SERVER_ADDRESS"))
+	listener, err := net.Listen("tcp", initializeConfig().Server.Address)
 	if err != nil {
 		log.Fatalln(err)
 	}
 
+	shutdownTracing := initializeTracing()
+
 	ovChipkaartAPIClient := ovchipkaart.NewAPIService(ovchipkaart.APIServiceConfig{
-		ClientID:     os.Getenv("OV_CHIPKAART_API_CLIENT_ID"),
-		ClientSecret: os.Getenv("OV_CHIPKAART_API_CLIENT_SECRET"),
+		ClientID:     initializeConfig().OvChipkaartAPI.ClientID,
+		ClientSecret: initializeConfig().OvChipkaartAPI.ClientSecret,
 		Locale:       "en",
-		Client:       &http.Client{},
+		Client:       httpclient.New(metrics.NewInstrumentedHTTPClient("ov-chipkaart", tracing.NewHTTPClient(&http.Client{})), ovchipkaart.HTTPClientOptions()),
 	})
 
 	csvTransactionsService := NewTransactionFetcherCSVService(NewCSVIOReader())
 
-	srv := grpc.NewServer()
+	serverOptions := append(metrics.GRPCServerOptions(), tracing.GRPCServerOptions()...)
+	srv := grpc.NewServer(append(serverOptions, initializeServiceAuthOptions()...)...)
 
 	transactions_service.RegisterTransactionsServiceServer(srv, &server{ovChipkaartAPIClient: ovChipkaartAPIClient, csvTransactionsService: csvTransactionsService})
 
-	log.Fatalln(srv.Serve(listener))
+	healthServer := lifecycle.RegisterHealthServer(srv)
+	metrics.InitializeGRPCServer(srv)
+
+	if address := initializeConfig().Server.MetricsAddress; address != "" {
+		go func() {
+			log.Fatalln(metrics.ListenAndServe(address))
+		}()
+	}
+
+	go func() {
+		initializeLogger().Info(context.Background(), "shutting down", "signal", lifecycle.WaitForSignal().String())
+		lifecycle.GracefulStop(srv, healthServer, initializeConfig().Server.ShutdownTimeout)
+	}()
+
+	err = srv.Serve(listener)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	shutdownTracing()
+}
+
+// initializeServiceAuthOptions only lets the api-service fetch transactions
+func initializeServiceAuthOptions() []grpc.ServerOption {
+	serverOptions, err := serviceauth.GRPCServerOptions(initializeServiceAuthConfig(), serviceauth.Policy{
+		"/transactions.TransactionsService/FetchByCredentials": {serviceauth.ServiceAPI},
+		"/transactions.TransactionsService/FetchFromBytes":     {serviceauth.ServiceAPI},
+	})
+	if err != nil {
+		log.Fatal(stacktrace.Propagate(err, "cannot initialize the service authentication"))
+	}
+
+	return serverOptions
+}
+
+func initializeServiceAuthConfig() serviceauth.Config {
+	return serviceauth.Config{
+		ServiceName: serviceauth.ServiceTransactions,
+		Insecure:    initializeConfig().ServiceAuth.Insecure,
+		CertFile:    initializeConfig().ServiceAuth.CertFile,
+		KeyFile:     initializeConfig().ServiceAuth.KeyFile,
+		CAFile:      initializeConfig().ServiceAuth.CAFile,
+		TokenSecret: initializeConfig().ServiceAuth.TokenSecret,
+	}
+}
+
+// initializeTracing exports the spans to the OTLP collector and returns a function which flushes the remaining spans
+func initializeTracing() func() {
+	shutdown, err := tracing.Initialize(tracing.Config{
+		ServiceName: "transactions-service",
+		Endpoint:    initializeConfig().Tracing.Endpoint,
+		Insecure:    initializeConfig().Tracing.Insecure,
+		SampleRatio: initializeConfig().Tracing.SampleRatio,
+	})
+	if err != nil {
+		log.Fatal(stacktrace.Propagate(err, "cannot initialize tracing"))
+	}
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		err := shutdown(ctx)
+		if err != nil {
+			initializeLogger().Error(ctx, "cannot flush the remaining spans", "err", err)
+		}
+	}
+}
+
+func initializeLogger() logger.Logger {
+	level, err := logger.ParseLevel(initializeConfig().Logging.Level)
+	if err != nil {
+		log.Fatal(stacktrace.Propagate(err, "cannot parse LOG_LEVEL"))
+	}
+
+	return logger.NewGoKitLogger(os.Stdout, level)
+}
+
+// initializeConfig loads the configuration once and exits with every problem when it is invalid
+func initializeConfig() *config.TransactionsService {
+	if configuration == nil {
+		configuration = &config.TransactionsService{}
+		config.MustLoad("transactions-service", configuration)
+	}
+
+	return configuration
 }
 
 // RawRecordsFromBytes gets the raw records form a CSV file as bytes
@@ -75,8 +167,8 @@ func (s *server) FetchFromBytes(_ context.Context, request *transactions_service
 }
 
 // RawRecordsWithCredentials gets raw records from the ov-chipkaart API
-func (s *server) FetchByCredentials(_ context.Context, request *transactions_service.FetchByCredentialsRequest) (*transactions_service.TransactionsResponse, error) {
-	records, err := s.ovChipkaartAPIClient.FetchTransactions(ovchipkaart.TransactionFetchOptions{
+func (s *server) FetchByCredentials(ctx context.Context, request *transactions_service.FetchByCredentialsRequest) (*transactions_service.TransactionsResponse, error) {
+	records, err := s.ovChipkaartAPIClient.FetchTransactions(ctx, ovchipkaart.TransactionFetchOptions{
 		Username:   request.GetUsername(),
 		Password:   request.GetPassword(),
 		CardNumber: request.GetCardNumber(),
@@ -84,6 +176,10 @@ func (s *server) FetchByCredentials(_ context.Context, request *transactions_ser
 		EndDate:    request.GetEndDate().AsTime(),
 	})
 
+	if ctx.Err() != nil {
+		return nil, status.FromContextError(ctx.Err()).Err()
+	}
+
 	if err != nil {
 		return nil, status.Error(codes.Code(stacktrace.GetCode(err)), err.Error())
 	}